@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// AuditEntity — сущность, которой управляют маршруты с общим префиксом: её тип в журнале аудита
+// и, если задана, загрузка по ID, чтобы сравнить сущность до и после запроса
+type AuditEntity struct {
+	Type string
+	Load func(id int) (interface{}, error)
+}
+
+// auditSkipped — изменяющие по методу маршруты, которые ничего не меняют
+var auditSkipped = map[string]bool{
+	"/api/auth/verify":       true,
+	"/api/schedule/validate": true,
+}
+
+// auditHidden — поля, которые не попадают в журнал ни в каком виде
+var auditHidden = map[string]bool{
+	"password": true,
+	"salt":     true,
+}
+
+// maxAuditBody — сколько байт ответа запоминается, чтобы найти ID созданной сущности
+const maxAuditBody = 64 << 10
+
+// auditWriter копирует начало ответа, не мешая его отправке
+type auditWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditWriter) Write(data []byte) (int, error) {
+	if w.body.Len() < maxAuditBody {
+		w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *auditWriter) WriteString(s string) (int, error) {
+	if w.body.Len() < maxAuditBody {
+		w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Audit записывает в журнал аудита каждый изменяющий запрос (POST, PUT, PATCH, DELETE), в том числе отклонённый.
+// entities сопоставляет префиксам маршрутов сущности; берётся самый длинный подходящий префикс.
+// Ставится на весь роутер до регистрации маршрутов: кто выполнил запрос, становится известно
+// после AuthMiddleware (userID) или обработчика входа (userID и login).
+func Audit(auditRepo repository.AuditRepository, entities map[string]AuditEntity) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method, route := c.Request.Method, c.FullPath()
+		if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions ||
+			route == "" || auditSkipped[route] {
+			c.Next()
+			return
+		}
+
+		entity := auditEntityFor(route, entities)
+		entityID, _ := strconv.Atoi(c.Param("id"))
+		var before interface{}
+		if entityID > 0 {
+			before = loadAudited(entity, entityID)
+		}
+
+		writer := &auditWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		status := writer.Status()
+		var changes json.RawMessage
+		if status < http.StatusMultipleChoices {
+			// ID созданной сущности есть только в ответе
+			if entityID <= 0 {
+				entityID = createdID(writer.body.Bytes())
+			}
+			var after interface{}
+			if entityID > 0 && method != http.MethodDelete {
+				after = loadAudited(entity, entityID)
+			}
+			changes = auditChanges(before, after)
+		}
+
+		entry := models.AuditEntry{
+			ActorLogin: c.GetString("login"),
+			IP:         c.ClientIP(),
+			Method:     method,
+			Route:      route,
+			Action:     auditAction(method, route, status),
+			Status:     status,
+			EntityType: entity.Type,
+			Changes:    changes,
+		}
+		if userID := c.GetInt("userID"); userID != 0 {
+			entry.ActorID = &userID
+		}
+		if entityID > 0 {
+			entry.EntityID = &entityID
+		}
+
+		// Ответ уже отправлен, поэтому ошибка журнала только логируется
+		if err := auditRepo.Record(entry); err != nil {
+			log.Printf("Ошибка записи в журнал аудита (%s %s): %v", method, route, err)
+		}
+	}
+}
+
+// auditEntityFor выбирает сущность по самому длинному префиксу маршрута
+func auditEntityFor(route string, entities map[string]AuditEntity) AuditEntity {
+	var entity AuditEntity
+	longest := -1
+	for prefix, candidate := range entities {
+		if len(prefix) > longest && (route == prefix || strings.HasPrefix(route, prefix+"/")) {
+			entity, longest = candidate, len(prefix)
+		}
+	}
+	return entity
+}
+
+// loadAudited загружает сущность для сравнения; если её нет или загрузить не удалось, возвращает nil
+func loadAudited(entity AuditEntity, id int) interface{} {
+	if entity.Load == nil {
+		return nil
+	}
+	value, err := entity.Load(id)
+	if err != nil {
+		return nil
+	}
+	return value
+}
+
+// auditAction называет действие: вход, создание, изменение, удаление или последний сегмент маршрута
+// для остальных POST-запросов (compute, lock, roll-call, logout и т.п.)
+func auditAction(method, route string, status int) string {
+	switch {
+	case route == "/api/auth/login" && status < http.StatusMultipleChoices:
+		return models.AuditLogin
+	case route == "/api/auth/login":
+		return models.AuditLoginFailed
+	case method == http.MethodDelete:
+		return models.AuditDelete
+	case method == http.MethodPut || method == http.MethodPatch:
+		return models.AuditUpdate
+	case strings.HasSuffix(route, "/"):
+		return models.AuditCreate
+	default:
+		return route[strings.LastIndex(route, "/")+1:]
+	}
+}
+
+// createdID ищет ID созданной сущности в ответе: {"id": N} или {"data": {"<сущность>_id": N}}
+func createdID(body []byte) int {
+	var response struct {
+		ID   int                    `json:"id"`
+		Data map[string]interface{} `json:"data"`
+	}
+	if json.Unmarshal(body, &response) != nil {
+		return 0
+	}
+	if response.ID > 0 {
+		return response.ID
+	}
+	if len(response.Data) != 1 {
+		return 0
+	}
+	for key, value := range response.Data {
+		if id, ok := value.(float64); ok && strings.HasSuffix(key, "_id") && id > 0 {
+			return int(id)
+		}
+	}
+	return 0
+}
+
+// auditChanges сравнивает сущность до и после запроса по полям JSON: {"поле": {"old": ..., "new": ...}}.
+// При создании old пуст, при удалении — new. Без изменений возвращает nil.
+func auditChanges(before, after interface{}) json.RawMessage {
+	oldFields, newFields := auditFields(before), auditFields(after)
+
+	changes := make(map[string]map[string]interface{})
+	for name, value := range oldFields {
+		if newValue, ok := newFields[name]; !ok || !reflect.DeepEqual(value, newValue) {
+			changes[name] = map[string]interface{}{"old": value, "new": newFields[name]}
+		}
+	}
+	for name, value := range newFields {
+		if _, ok := oldFields[name]; !ok {
+			changes[name] = map[string]interface{}{"old": nil, "new": value}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// auditFields разворачивает сущность в поля её JSON без скрытых полей
+func auditFields(value interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if value == nil {
+		return fields
+	}
+	data, err := json.Marshal(value)
+	if err != nil || json.Unmarshal(data, &fields) != nil {
+		return map[string]interface{}{}
+	}
+	for name := range auditHidden {
+		delete(fields, name)
+	}
+	return fields
+}
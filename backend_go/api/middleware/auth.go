@@ -1,13 +1,15 @@
 package middleware
 
 import (
-	"database/sql"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
 	"github.com/VladislavSCV/internal/utils"
 	"github.com/gin-gonic/gin"
+	"log"
 	"net/http"
 )
 
-func AuthMiddleware(db *sql.DB) gin.HandlerFunc {
+func AuthMiddleware(sessionRepo repository.SessionRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Получаем токен из заголовка Authorization
 		token := c.GetHeader("Authorization")
@@ -25,9 +27,39 @@ func AuthMiddleware(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		// Сохраняем userID и roleID в контексте
+		// Проверяем, что сессия токена не отозвана (logout / logout-all)
+		active, err := sessionRepo.IsActive(claims.SessionID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not verify session"})
+			c.Abort()
+			return
+		}
+		if !active {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token revoked"})
+			c.Abort()
+			return
+		}
+
+		// Права роли берутся из кэша; роль могли удалить, пока жил токен
+		permissions, ok, err := roleCache.Permissions(claims.RoleID)
+		if err != nil {
+			log.Printf("Ошибка при загрузке прав роли %d: %v", claims.RoleID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not check permissions"})
+			c.Abort()
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Unknown role"})
+			c.Abort()
+			return
+		}
+
+		// Сохраняем userID, roleID и sessionID в контексте
 		c.Set("userID", claims.UserID)
 		c.Set("roleID", claims.RoleID)
+		c.Set("sessionID", claims.SessionID)
+		// scope — от чьего имени обработчики обращаются к хранилищам (см. internal/policy)
+		c.Set("scope", policy.NewScope(claims.UserID, permissions))
 
 		// Передаем управление следующему обработчику
 		c.Next()
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+)
+
+// roleCache — права ролей из базы; задаётся при старте через ConfigurePermissions
+var roleCache *policy.RoleCache
+
+// ConfigurePermissions задаёт кэш прав, по которому работают AuthMiddleware и RequirePermission
+func ConfigurePermissions(cache *policy.RoleCache) {
+	roleCache = cache
+}
+
+// RequirePermission пропускает запрос, только если у роли пользователя есть право permission.
+// Ставится после AuthMiddleware.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Получаем roleID из контекста
+		roleID, exists := c.Get("roleID")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Role not found in context"})
+			c.Abort()
+			return
+		}
+
+		allowed, err := roleCache.HasPermission(roleID.(int), permission)
+		if err != nil {
+			log.Printf("Ошибка при проверке прав роли %v: %v", roleID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not check permissions"})
+			c.Abort()
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			c.Abort()
+			return
+		}
+
+		// Передаем управление следующему обработчику
+		c.Next()
+	}
+}
@@ -6,10 +6,22 @@ import (
 	"time"
 )
 
+// Параметры лимита; переопределяются из конфигурации через ConfigureRateLimiter
+var (
+	rateLimitRequests uint = 10
+	rateLimitPeriod        = time.Minute
+)
+
+// ConfigureRateLimiter задаёт число запросов, разрешённых за период
+func ConfigureRateLimiter(requests uint, period time.Duration) {
+	rateLimitRequests = requests
+	rateLimitPeriod = period
+}
+
 func RateLimiterMiddleware() gin.HandlerFunc {
 	store := ratelimit.InMemoryStore(&ratelimit.InMemoryOptions{
-		Rate:  time.Minute, // Ограничение: 10 запросов в минуту
-		Limit: 10,
+		Rate:  rateLimitPeriod, // Ограничение: rateLimitRequests запросов за rateLimitPeriod
+		Limit: rateLimitRequests,
 	})
 
 	return ratelimit.RateLimiter(store, &ratelimit.Options{
@@ -20,9 +32,8 @@ func RateLimiterMiddleware() gin.HandlerFunc {
 			})
 		},
 		KeyFunc: func(c *gin.Context) string {
-			ip := c.ClientIP()
-			println("Client IP:", ip) // Логируем IP-адрес
-			return ip
+			// Лимит считается по IP клиента; сам IP пишет в журнал gin.Logger
+			return c.ClientIP()
 		},
 	})
 }
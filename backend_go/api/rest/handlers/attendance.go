@@ -1,9 +1,11 @@
 package handlers
 
 import (
-	"database/sql"
-	"github.com/VladislavSCV/internal/core"
+	"errors"
+	"fmt"
 	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
 	"github.com/gin-gonic/gin"
 	"log"
 	"net/http"
@@ -13,17 +15,29 @@ import (
 
 // GetAttendanceByStudentID godoc
 // @Summary Получить посещаемость студента по его ID
-// @Description Возвращает список посещаемости для конкретного студента
+// @Description Возвращает страницу посещаемости студента. format=csv или format=xlsx выгружает все отметки под фильтрами файлом, без деления на страницы
 // @Tags Attendance
 // @Accept  json
 // @Produce  json
+// @Produce  text/csv
+// @Produce  application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param   format   query  string  false  "Формат: json (по умолчанию), csv (UTF-8 с BOM, разделитель «;») или xlsx"
+// @Param   columns  query  string  false  "Колонки файла через запятую в нужном порядке: id, date, student_id, student_name, subject_name, status, lesson_id; по умолчанию все"
 // @Param   id  path  int  true  "ID студента"  example(1)
-// @Success 200 {array} models.Attendance "Успешный ответ"
+// @Param   subject_id  query  int     false  "ID предмета"
+// @Param   from        query  string  false  "Первый день (ГГГГ-ММ-ДД)"
+// @Param   to          query  string  false  "Последний день (ГГГГ-ММ-ДД)"
+// @Param   status      query  string  false  "Статус: present, absent или excused"
+// @Param   sort     query  string  false  "Сортировка: date (по умолчанию), id, status, subject_name, student_name, created_at; «-» в начале — по убыванию"
+// @Param   limit    query  int     false  "Размер страницы, от 1 до 1000; по умолчанию 50"
+// @Param   offset   query  int     false  "Сколько записей пропустить"
+// @Param   cursor   query  string  false  "next_cursor предыдущей страницы; вместо offset"
+// @Success 200 {object} ListResponse{items=[]models.AttendanceDetail} "Успешный ответ"
 // @Failure 400 {object} ErrorResponse "Неверный запрос"
-// @Failure 404 {object} ErrorResponse "Посещаемость не найдена"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/attendance/student/{id} [get]
-func GetAttendanceByStudentID(db *sql.DB) gin.HandlerFunc {
+func GetAttendanceByStudentID(attendanceRepo repository.AttendanceRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		studentID := c.Param("id")
 		log.Printf("Получен запрос на получение посещаемости для студента с ID: %s", studentID)
@@ -43,36 +57,71 @@ func GetAttendanceByStudentID(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		attendances, err := core.GetAttendanceByStudentID(db, studentIDInt)
-		if err != nil {
-			if strings.Contains(err.Error(), "not found") {
-				log.Printf("Посещаемость для студента с ID %d не найдена", studentIDInt)
-				c.JSON(http.StatusNotFound, ErrorResponse{Error: "attendance not found"})
-			} else {
-				log.Printf("Ошибка при получении посещаемости: %v", err)
-				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		scope := currentScope(c)
+		if !scope.CanReadStudent(studentIDInt) {
+			log.Printf("Пользователь %d не имеет доступа к данным студента %d", scope.UserID, studentIDInt)
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: policy.ErrForbidden.Error()})
+			return
+		}
+
+		filter := models.RecordFilter{StudentID: studentIDInt}
+		if !parseRecordFilter(c, &filter, true) {
+			return
+		}
+
+		if exportRequested(c) {
+			export, ok := startExport(c, fmt.Sprintf("attendance-student-%d", studentIDInt), attendanceExportColumns)
+			if !ok {
+				return
 			}
+			export.Finish(attendanceRepo.Export(scope, filter, func(attendance models.AttendanceDetail) error {
+				return export.Row(attendanceExportRecord(attendance))
+			}))
 			return
 		}
 
-		log.Printf("Успешно получена посещаемость для студента с ID: %d", studentIDInt)
-		c.JSON(http.StatusOK, attendances)
+		query, ok := parseListQuery(c, models.AttendanceSortFields)
+		if !ok {
+			return
+		}
+
+		attendances, page, err := attendanceRepo.List(scope, filter, query)
+		if err != nil {
+			log.Printf("Ошибка при получении посещаемости: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно получена посещаемость для студента с ID: %d (%d из %d)", studentIDInt, len(attendances), page.Total)
+		writeList(c, attendances, page, query)
 	}
 }
 
 // GetAttendanceByGroupID godoc
 // @Summary Получить посещаемость группы по её ID
-// @Description Возвращает список посещаемости для конкретной группы
+// @Description Возвращает страницу посещаемости группы. format=csv или format=xlsx выгружает все отметки под фильтрами файлом, без деления на страницы и не собирая их в памяти
 // @Tags Attendance
 // @Accept  json
 // @Produce  json
+// @Produce  text/csv
+// @Produce  application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param   format   query  string  false  "Формат: json (по умолчанию), csv (UTF-8 с BOM, разделитель «;») или xlsx"
+// @Param   columns  query  string  false  "Колонки файла через запятую в нужном порядке: id, date, student_id, student_name, subject_name, status, lesson_id; по умолчанию все"
 // @Param   id  path  int  true  "ID группы"  example(1)
-// @Success 200 {array} models.Attendance "Успешный ответ"
+// @Param   subject_id  query  int     false  "ID предмета"
+// @Param   from        query  string  false  "Первый день (ГГГГ-ММ-ДД)"
+// @Param   to          query  string  false  "Последний день (ГГГГ-ММ-ДД)"
+// @Param   status      query  string  false  "Статус: present, absent или excused"
+// @Param   sort     query  string  false  "Сортировка: date (по умолчанию), id, status, subject_name, student_name, created_at; «-» в начале — по убыванию"
+// @Param   limit    query  int     false  "Размер страницы, от 1 до 1000; по умолчанию 50"
+// @Param   offset   query  int     false  "Сколько записей пропустить"
+// @Param   cursor   query  string  false  "next_cursor предыдущей страницы; вместо offset"
+// @Success 200 {object} ListResponse{items=[]models.AttendanceDetail} "Успешный ответ"
 // @Failure 400 {object} ErrorResponse "Неверный запрос"
-// @Failure 404 {object} ErrorResponse "Посещаемость не найдена"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/attendance/group/{id} [get]
-func GetAttendanceByGroupID(db *sql.DB) gin.HandlerFunc {
+func GetAttendanceByGroupID(attendanceRepo repository.AttendanceRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		groupID := c.Param("id")
 		log.Printf("Получен запрос на получение посещаемости для группы с ID: %s", groupID)
@@ -92,20 +141,36 @@ func GetAttendanceByGroupID(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		attendances, err := core.GetAttendanceByGroupID(db, groupIDInt)
-		if err != nil {
-			if strings.Contains(err.Error(), "not found") {
-				log.Printf("Посещаемость для группы с ID %d не найдена", groupIDInt)
-				c.JSON(http.StatusNotFound, ErrorResponse{Error: "attendance not found"})
-			} else {
-				log.Printf("Ошибка при получении посещаемости: %v", err)
-				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		filter := models.RecordFilter{GroupID: groupIDInt}
+		if !parseRecordFilter(c, &filter, true) {
+			return
+		}
+
+		if exportRequested(c) {
+			export, ok := startExport(c, fmt.Sprintf("attendance-group-%d", groupIDInt), attendanceExportColumns)
+			if !ok {
+				return
 			}
+			export.Finish(attendanceRepo.Export(currentScope(c), filter, func(attendance models.AttendanceDetail) error {
+				return export.Row(attendanceExportRecord(attendance))
+			}))
+			return
+		}
+
+		query, ok := parseListQuery(c, models.AttendanceSortFields)
+		if !ok {
 			return
 		}
 
-		log.Printf("Успешно получена посещаемость для группы с ID: %d", groupIDInt)
-		c.JSON(http.StatusOK, attendances)
+		attendances, page, err := attendanceRepo.List(currentScope(c), filter, query)
+		if err != nil {
+			log.Printf("Ошибка при получении посещаемости: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно получена посещаемость для группы с ID: %d (%d из %d)", groupIDInt, len(attendances), page.Total)
+		writeList(c, attendances, page, query)
 	}
 }
 
@@ -116,11 +181,14 @@ func GetAttendanceByGroupID(db *sql.DB) gin.HandlerFunc {
 // @Accept  json
 // @Produce  json
 // @Param   attendance  body  models.Attendance  true  "Данные о посещаемости"  example({"student_id": 1, "subject_id": 1, "date": "2023-10-01T00:00:00Z", "status": "present"})
+// @Param   reason  query  string  false  "Причина изменения, до 500 символов; сохраняется в истории"
 // @Success 200 {object} SuccessResponse "Успешный ответ"
 // @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 422 {object} ErrorResponse "Занятие (lesson_id) не совпадает с предметом, датой или группой студента"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/attendance [post]
-func CreateAttendance(db *sql.DB) gin.HandlerFunc {
+func CreateAttendance(attendanceRepo repository.AttendanceRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var attendance models.Attendance
 		if err := c.ShouldBindJSON(&attendance); err != nil {
@@ -151,10 +219,24 @@ func CreateAttendance(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		attendanceID, err := core.CreateAttendance(db, attendance)
+		reason, ok := changeReason(c)
+		if !ok {
+			return
+		}
+
+		attendanceID, err := attendanceRepo.Create(currentScope(c), attendance, reason)
 		if err != nil {
 			log.Printf("Ошибка при создании посещаемости: %v", err)
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			switch {
+			case errors.Is(err, policy.ErrForbidden):
+				c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			case errors.Is(err, repository.ErrLessonMismatch):
+				c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Error: err.Error()})
+			case strings.Contains(err.Error(), "not found"):
+				c.JSON(http.StatusNotFound, ErrorResponse{Error: "attendance not found"})
+			default:
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			}
 			return
 		}
 
@@ -174,11 +256,14 @@ func CreateAttendance(db *sql.DB) gin.HandlerFunc {
 // @Produce  json
 // @Param   id  path  int  true  "ID посещаемости"  example(1)
 // @Param   attendance  body  models.Attendance  true  "Обновлённые данные о посещаемости"  example({"student_id": 1, "subject_id": 1, "date": "2023-10-01T00:00:00Z", "status": "absent"})
+// @Param   reason  query  string  false  "Причина изменения, до 500 символов; сохраняется в истории"
 // @Success 200 {object} SuccessResponse "Успешный ответ"
 // @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 422 {object} ErrorResponse "Занятие (lesson_id) не совпадает с предметом, датой или группой студента"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/attendance/{id} [put]
-func UpdateAttendance(db *sql.DB) gin.HandlerFunc {
+func UpdateAttendance(attendanceRepo repository.AttendanceRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		log.Printf("Получен запрос на обновление посещаемости с ID: %s", id)
@@ -230,9 +315,23 @@ func UpdateAttendance(db *sql.DB) gin.HandlerFunc {
 		// Устанавливаем ID отметки посещаемости из параметра запроса
 		attendance.ID = idInt
 
-		if err := core.UpdateAttendance(db, attendance); err != nil {
+		reason, ok := changeReason(c)
+		if !ok {
+			return
+		}
+
+		if err := attendanceRepo.Update(currentScope(c), attendance, reason); err != nil {
 			log.Printf("Ошибка при обновлении посещаемости: %v", err)
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			switch {
+			case errors.Is(err, policy.ErrForbidden):
+				c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			case errors.Is(err, repository.ErrLessonMismatch):
+				c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Error: err.Error()})
+			case strings.Contains(err.Error(), "not found"):
+				c.JSON(http.StatusNotFound, ErrorResponse{Error: "attendance not found"})
+			default:
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			}
 			return
 		}
 
@@ -240,3 +339,71 @@ func UpdateAttendance(db *sql.DB) gin.HandlerFunc {
 		c.JSON(http.StatusOK, SuccessResponse{Message: "Attendance updated successfully"})
 	}
 }
+
+// RollCall godoc
+// @Summary Отметить посещаемость группы
+// @Description Создаёт или обновляет отметки группы на занятии одной транзакцией. Занятие задаётся lesson_id либо группой, предметом и датой. Студенты не из группы, неверные статусы и студенты, к чьим записям нет доступа, попадают в rejected; с default_present неуказанные студенты группы отмечаются как present
+// @Tags Attendance
+// @Accept  json
+// @Produce  json
+// @Param   rollCall  body  models.RollCall  true  "Перекличка"  example({"group_id": 1, "subject_id": 1, "date": "2023-10-02T00:00:00Z", "statuses": {"3": "absent"}, "default_present": true})
+// @Param   reason  query  string  false  "Причина изменения, до 500 символов; сохраняется в истории"
+// @Success 200 {object} models.RollCallResult "Созданные, обновлённые и отклонённые отметки"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 404 {object} ErrorResponse "Группа, предмет или занятие не найдены"
+// @Failure 422 {object} ErrorResponse "Занятие не совпадает с группой, предметом или датой"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/attendance/roll-call [post]
+func RollCall(attendanceRepo repository.AttendanceRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var call models.RollCall
+		if err := c.ShouldBindJSON(&call); err != nil {
+			log.Printf("Ошибка привязки JSON: %v", err)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		// Валидация данных: без занятия нужны группа, предмет и дата
+		if call.LessonID != nil && *call.LessonID <= 0 {
+			log.Printf("Некорректный lesson_id: %d", *call.LessonID)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "lesson_id must be positive"})
+			return
+		}
+		if call.LessonID == nil && (call.GroupID <= 0 || call.SubjectID <= 0 || call.Date.IsZero()) {
+			log.Printf("Не указано занятие: группа %d, предмет %d, дата %v", call.GroupID, call.SubjectID, call.Date)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "lesson_id or group_id, subject_id and date must be provided"})
+			return
+		}
+		if len(call.Statuses) == 0 && !call.DefaultPresent {
+			log.Printf("Пустая перекличка")
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "statuses must not be empty unless default_present is set"})
+			return
+		}
+
+		log.Printf("Получен запрос на перекличку группы %d по предмету %d (отметок: %d)", call.GroupID, call.SubjectID, len(call.Statuses))
+
+		reason, ok := changeReason(c)
+		if !ok {
+			return
+		}
+
+		result, err := attendanceRepo.RollCall(currentScope(c), call, reason)
+		if err != nil {
+			log.Printf("Ошибка при перекличке: %v", err)
+			switch {
+			case errors.Is(err, repository.ErrLessonMismatch):
+				c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Error: err.Error()})
+			case strings.Contains(err.Error(), "not found"):
+				c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			}
+			return
+		}
+
+		log.Printf("Перекличка сохранена: создано %d, обновлено %d, отклонено %d",
+			len(result.Created), len(result.Updated), len(result.Rejected))
+		c.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"github.com/VladislavSCV/internal/config"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultAbsenceThreshold — порог доли пропусков без уважительной причины по умолчанию, %
+const defaultAbsenceThreshold = 20
+
+// parseStatsFilter разбирает параметры отчётов по посещаемости; при ошибке отвечает 400
+func parseStatsFilter(c *gin.Context) (models.AttendanceStatsFilter, bool) {
+	var filter models.AttendanceStatsFilter
+
+	for name, target := range map[string]*int{
+		"group_id":   &filter.GroupID,
+		"student_id": &filter.StudentID,
+		"subject_id": &filter.SubjectID,
+	} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			log.Printf("Некорректный параметр %s: %s", name, value)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: name + " must be a positive integer"})
+			return models.AttendanceStatsFilter{}, false
+		}
+		*target = n
+	}
+
+	for name, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse(config.DateLayout, value)
+		if err != nil {
+			log.Printf("Некорректная дата %s: %s", name, value)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: name + " must be in YYYY-MM-DD format"})
+			return models.AttendanceStatsFilter{}, false
+		}
+		*target = date
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		log.Printf("Некорректный период: %s - %s", c.Query("from"), c.Query("to"))
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "to must not be before from"})
+		return models.AttendanceStatsFilter{}, false
+	}
+
+	return filter, true
+}
+
+// GetAttendanceStats godoc
+// @Summary Статистика посещаемости
+// @Description Возвращает число отметок present, absent и excused и доли в процентах по студентам, предметам или группам. Учитываются только записи, доступные пользователю
+// @Tags Attendance
+// @Produce  json
+// @Param   by          query  string  false  "Разрез: student (по умолчанию), subject или group"
+// @Param   group_id    query  int     false  "ID группы"
+// @Param   student_id  query  int     false  "ID студента"
+// @Param   subject_id  query  int     false  "ID предмета"
+// @Param   from        query  string  false  "Первый день (ГГГГ-ММ-ДД)"
+// @Param   to          query  string  false  "Последний день (ГГГГ-ММ-ДД)"
+// @Success 200 {array} models.AttendanceStat "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/attendance/stats [get]
+func GetAttendanceStats(attendanceRepo repository.AttendanceRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log.Printf("Получен запрос на статистику посещаемости: %s", c.Request.URL.RawQuery)
+
+		by := c.DefaultQuery("by", models.StatsByStudent)
+		switch by {
+		case models.StatsByStudent, models.StatsBySubject, models.StatsByGroup:
+		default:
+			log.Printf("Некорректный разрез статистики: %s", by)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "by must be one of student, subject, group"})
+			return
+		}
+		filter, ok := parseStatsFilter(c)
+		if !ok {
+			return
+		}
+
+		stats, err := attendanceRepo.Stats(currentScope(c), filter, by)
+		if err != nil {
+			log.Printf("Ошибка при получении статистики посещаемости: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно получена статистика посещаемости: %d строк", len(stats))
+		c.JSON(http.StatusOK, stats)
+	}
+}
+
+// GetAttendanceTrend godoc
+// @Summary Посещаемость по месяцам
+// @Description Возвращает число отметок и доли в процентах за каждый месяц, в котором есть отметки. Учитываются только записи, доступные пользователю
+// @Tags Attendance
+// @Produce  json
+// @Param   group_id    query  int     false  "ID группы"
+// @Param   student_id  query  int     false  "ID студента"
+// @Param   subject_id  query  int     false  "ID предмета"
+// @Param   from        query  string  false  "Первый день (ГГГГ-ММ-ДД)"
+// @Param   to          query  string  false  "Последний день (ГГГГ-ММ-ДД)"
+// @Success 200 {array} models.AttendanceMonth "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/attendance/stats/monthly [get]
+func GetAttendanceTrend(attendanceRepo repository.AttendanceRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log.Printf("Получен запрос на посещаемость по месяцам: %s", c.Request.URL.RawQuery)
+
+		filter, ok := parseStatsFilter(c)
+		if !ok {
+			return
+		}
+
+		months, err := attendanceRepo.Monthly(currentScope(c), filter)
+		if err != nil {
+			log.Printf("Ошибка при получении посещаемости по месяцам: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно получена посещаемость по месяцам: %d месяцев", len(months))
+		c.JSON(http.StatusOK, months)
+	}
+}
+
+// GetAbsentees godoc
+// @Summary Студенты с частыми пропусками
+// @Description Возвращает студентов, у которых доля пропусков без уважительной причины (absent) больше порога, по убыванию этой доли
+// @Tags Attendance
+// @Produce  json
+// @Param   threshold   query  number  false  "Порог, % (по умолчанию 20)"
+// @Param   group_id    query  int     false  "ID группы"
+// @Param   subject_id  query  int     false  "ID предмета"
+// @Param   from        query  string  false  "Первый день (ГГГГ-ММ-ДД)"
+// @Param   to          query  string  false  "Последний день (ГГГГ-ММ-ДД)"
+// @Success 200 {array} models.AttendanceStat "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/attendance/stats/absentees [get]
+func GetAbsentees(attendanceRepo repository.AttendanceRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log.Printf("Получен запрос на список студентов с пропусками: %s", c.Request.URL.RawQuery)
+
+		threshold := float64(defaultAbsenceThreshold)
+		if value := c.Query("threshold"); value != "" {
+			var err error
+			threshold, err = strconv.ParseFloat(value, 64)
+			if err != nil || threshold < 0 || threshold >= 100 {
+				log.Printf("Некорректный порог: %s", value)
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "threshold must be a number from 0 to 100"})
+				return
+			}
+		}
+		filter, ok := parseStatsFilter(c)
+		if !ok {
+			return
+		}
+
+		absentees, err := attendanceRepo.Absentees(currentScope(c), filter, threshold)
+		if err != nil {
+			log.Printf("Ошибка при получении списка студентов с пропусками: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно получен список студентов с пропусками больше %.2f%%: %d", threshold, len(absentees))
+		c.JSON(http.StatusOK, absentees)
+	}
+}
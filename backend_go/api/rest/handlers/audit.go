@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"github.com/VladislavSCV/internal/config"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Ограничения выборки журнала аудита в JSON; выгрузка без limit отдаёт все подходящие записи
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 10000
+)
+
+// auditCSVHeader — колонки выгрузки журнала аудита в CSV
+var auditCSVHeader = []string{"id", "created_at", "actor_id", "actor_login", "ip", "method", "route",
+	"action", "status", "entity_type", "entity_id", "changes"}
+
+// parseAuditFilter разбирает параметры выборки журнала аудита; при ошибке отвечает 400
+func parseAuditFilter(c *gin.Context) (models.AuditFilter, bool) {
+	filter := models.AuditFilter{
+		EntityType: c.Query("entity_type"),
+		Action:     c.Query("action"),
+	}
+
+	for name, target := range map[string]*int{
+		"actor_id":  &filter.ActorID,
+		"entity_id": &filter.EntityID,
+		"limit":     &filter.Limit,
+	} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			log.Printf("Некорректный параметр %s: %s", name, value)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: name + " must be a positive integer"})
+			return models.AuditFilter{}, false
+		}
+		*target = n
+	}
+	if filter.Limit > maxAuditLimit {
+		log.Printf("Слишком большой limit: %d", filter.Limit)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "limit must be at most 10000"})
+		return models.AuditFilter{}, false
+	}
+
+	for name, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		date, err := time.ParseInLocation(config.DateLayout, value, time.Local)
+		if err != nil {
+			log.Printf("Некорректная дата %s: %s", name, value)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: name + " must be in YYYY-MM-DD format"})
+			return models.AuditFilter{}, false
+		}
+		*target = date
+	}
+	// to включает весь указанный день
+	if !filter.To.IsZero() {
+		filter.To = filter.To.AddDate(0, 0, 1)
+	}
+
+	return filter, true
+}
+
+// auditCSVRecord превращает запись журнала в строку CSV; пустые ID — пустые ячейки
+func auditCSVRecord(entry models.AuditEntry) []string {
+	optional := func(id *int) string {
+		if id == nil {
+			return ""
+		}
+		return strconv.Itoa(*id)
+	}
+	return []string{
+		strconv.Itoa(entry.ID),
+		entry.CreatedAt.Format(time.RFC3339),
+		optional(entry.ActorID),
+		entry.ActorLogin,
+		entry.IP,
+		entry.Method,
+		entry.Route,
+		entry.Action,
+		strconv.Itoa(entry.Status),
+		entry.EntityType,
+		optional(entry.EntityID),
+		string(entry.Changes),
+	}
+}
+
+// GetAuditLog godoc
+// @Summary Получить журнал аудита
+// @Description Возвращает записи журнала аудита от новых к старым: изменяющие запросы к API и попытки входа с исполнителем, IP, маршрутом, сущностью и изменёнными полями. format=csv или format=jsonl выгружает журнал файлом; без limit выгрузка содержит все подходящие записи, а JSON — последние 100
+// @Tags Admin
+// @Produce  json
+// @Produce  text/csv
+// @Produce  application/x-ndjson
+// @Param   actor_id     query  int     false  "ID исполнителя"
+// @Param   entity_type  query  string  false  "Тип сущности (user, group, schedule, grade, ...)"
+// @Param   entity_id    query  int     false  "ID сущности"
+// @Param   action       query  string  false  "Действие (create, update, delete, login, login_failed, ...)"
+// @Param   from         query  string  false  "Первый день (ГГГГ-ММ-ДД)"
+// @Param   to           query  string  false  "Последний день (ГГГГ-ММ-ДД)"
+// @Param   limit        query  int     false  "Максимум записей, до 10000"
+// @Param   format       query  string  false  "Формат: json (по умолчанию), csv или jsonl"
+// @Success 200 {array} models.AuditEntry "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/admin/audit [get]
+func GetAuditLog(auditRepo repository.AuditRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, ok := parseAuditFilter(c)
+		if !ok {
+			return
+		}
+
+		format := c.DefaultQuery("format", "json")
+		if format != "json" && format != "csv" && format != "jsonl" {
+			log.Printf("Некорректный формат журнала аудита: %s", format)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "format must be json, csv or jsonl"})
+			return
+		}
+		if format == "json" && filter.Limit == 0 {
+			filter.Limit = defaultAuditLimit
+		}
+
+		entries, err := auditRepo.Get(filter)
+		if err != nil {
+			log.Printf("Ошибка при получении журнала аудита: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		switch format {
+		case "csv":
+			c.Header("Content-Disposition", `attachment; filename="audit.csv"`)
+			c.Header("Content-Type", "text/csv; charset=utf-8")
+			c.Status(http.StatusOK)
+
+			writer := csv.NewWriter(c.Writer)
+			if err := writer.Write(auditCSVHeader); err != nil {
+				log.Printf("Ошибка выгрузки журнала аудита: %v", err)
+				return
+			}
+			for _, entry := range entries {
+				if err := writer.Write(auditCSVRecord(entry)); err != nil {
+					log.Printf("Ошибка выгрузки журнала аудита: %v", err)
+					return
+				}
+			}
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				log.Printf("Ошибка выгрузки журнала аудита: %v", err)
+			}
+		case "jsonl":
+			c.Header("Content-Disposition", `attachment; filename="audit.jsonl"`)
+			c.Header("Content-Type", "application/x-ndjson")
+			c.Status(http.StatusOK)
+
+			encoder := json.NewEncoder(c.Writer)
+			for _, entry := range entries {
+				if err := encoder.Encode(entry); err != nil {
+					log.Printf("Ошибка выгрузки журнала аудита: %v", err)
+					return
+				}
+			}
+		default:
+			c.JSON(http.StatusOK, entries)
+		}
+
+		log.Printf("Выдано записей журнала аудита: %d (%s)", len(entries), format)
+	}
+}
@@ -1,9 +1,10 @@
 package handlers
 
 import (
-	"database/sql"
-	"github.com/VladislavSCV/internal/core"
+	"errors"
+	"fmt"
 	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
 	"github.com/VladislavSCV/internal/utils"
 	"github.com/VladislavSCV/pkg"
 	"github.com/gin-gonic/gin"
@@ -24,7 +25,7 @@ import (
 // @Failure 401 {object} ErrorResponse "Неверные учетные данные"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/auth/login [post]
-func Login(db *sql.DB) gin.HandlerFunc {
+func Login(userRepo repository.UserRepository, sessionRepo repository.SessionRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var user models.User
 		if err := c.ShouldBindJSON(&user); err != nil {
@@ -40,37 +41,218 @@ func Login(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		dbUser, err := core.AuthenticateUser(db, user.Login, user.Password)
+		// Журнал аудита записывает попытку входа с этим логином (см. middleware.Audit)
+		c.Set("login", user.Login)
+
+		dbUser, err := userRepo.Authenticate(user.Login, user.Password)
 		if err != nil {
 			log.Printf("Ошибка аутентификации пользователя: %v", err)
 			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid credentials"})
 			return
 		}
 
-		token, err := utils.GenerateToken(dbUser.ID, dbUser.RoleID)
+		sessionID, err := utils.GenerateTokenID()
+		if err != nil {
+			log.Printf("Ошибка генерации ID сессии: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Could not generate token"})
+			return
+		}
+
+		refreshToken, err := utils.GenerateRefreshToken()
+		if err != nil {
+			log.Printf("Ошибка генерации refresh-токена: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Could not generate token"})
+			return
+		}
+
+		if err := sessionRepo.Create(sessionID, dbUser.ID, utils.HashRefreshToken(refreshToken), utils.RefreshTokenTTL); err != nil {
+			log.Printf("Ошибка создания сессии: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Could not create session"})
+			return
+		}
+
+		token, err := utils.GenerateToken(dbUser.ID, dbUser.RoleID, sessionID)
 		if err != nil {
 			log.Printf("Ошибка генерации токена: %v", err)
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Could not generate token"})
 			return
 		}
 
+		c.Set("userID", dbUser.ID)
 		log.Printf("Успешный вход пользователя: %s", user.Login)
-		c.JSON(http.StatusOK, LoginResponse{Token: token})
+		c.JSON(http.StatusOK, LoginResponse{
+			Token:        token,
+			RefreshToken: refreshToken,
+			ExpiresIn:    int(utils.AccessTokenTTL.Seconds()),
+		})
+	}
+}
+
+// Refresh godoc
+// @Summary Обновление токенов
+// @Description Обменивает refresh-токен на новую пару access/refresh. Старый refresh-токен становится недействительным; его повторное использование отзывает всю сессию
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param   request  body  RefreshRequest  true  "Refresh-токен"
+// @Success 200 {object} LoginResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Недействительный refresh-токен"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/auth/refresh [post]
+func Refresh(userRepo repository.UserRepository, sessionRepo repository.SessionRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req RefreshRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			log.Printf("Ошибка привязки JSON: %v", err)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if req.RefreshToken == "" {
+			log.Printf("Refresh-токен не предоставлен")
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "refresh_token is required"})
+			return
+		}
+
+		newRefreshToken, err := utils.GenerateRefreshToken()
+		if err != nil {
+			log.Printf("Ошибка генерации refresh-токена: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Could not generate token"})
+			return
+		}
+
+		session, err := sessionRepo.RotateRefreshToken(utils.HashRefreshToken(req.RefreshToken), utils.HashRefreshToken(newRefreshToken), utils.RefreshTokenTTL)
+		if err != nil {
+			if errors.Is(err, repository.ErrRefreshTokenReused) {
+				log.Printf("Повторное использование refresh-токена, сессия отозвана")
+				c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Refresh token reuse detected, session revoked"})
+			} else if errors.Is(err, repository.ErrInvalidRefreshToken) {
+				log.Printf("Недействительный refresh-токен")
+				c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid refresh token"})
+			} else {
+				log.Printf("Ошибка ротации refresh-токена: %v", err)
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			}
+			return
+		}
+
+		// Роль берём из базы, а не из старого токена: она могла измениться
+		user, err := userRepo.GetCurrent(session.UserID)
+		if err != nil {
+			log.Printf("Ошибка получения пользователя сессии: %v", err)
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid refresh token"})
+			return
+		}
+
+		token, err := utils.GenerateToken(user.ID, user.RoleID, session.ID)
+		if err != nil {
+			log.Printf("Ошибка генерации токена: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Could not generate token"})
+			return
+		}
+
+		log.Printf("Токены обновлены для пользователя: %d", user.ID)
+		c.JSON(http.StatusOK, LoginResponse{
+			Token:        token,
+			RefreshToken: newRefreshToken,
+			ExpiresIn:    int(utils.AccessTokenTTL.Seconds()),
+		})
 	}
 }
 
+// Logout godoc
+// @Summary Выход из системы
+// @Description Отзывает текущую сессию: её access- и refresh-токены перестают действовать сразу
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param   Authorization  header  string  true  "Токен авторизации"
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 401 {object} ErrorResponse "Пользователь не аутентифицирован"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/auth/logout [post]
+func Logout(sessionRepo repository.SessionRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessionID := c.GetString("sessionID")
+		if sessionID == "" {
+			log.Printf("sessionID не найден в контексте")
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "sessionID not found in context"})
+			return
+		}
+
+		if err := sessionRepo.Revoke(sessionID); err != nil {
+			log.Printf("Ошибка отзыва сессии: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Сессия %s отозвана", sessionID)
+		c.JSON(http.StatusOK, SuccessResponse{Message: "Logged out successfully"})
+	}
+}
+
+// LogoutAll godoc
+// @Summary Выход со всех устройств
+// @Description Отзывает все сессии текущего пользователя
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param   Authorization  header  string  true  "Токен авторизации"
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 401 {object} ErrorResponse "Пользователь не аутентифицирован"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/auth/logout-all [post]
+func LogoutAll(sessionRepo repository.SessionRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetInt("userID")
+		if userID == 0 {
+			log.Printf("userID не найден в контексте")
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "userID not found in context"})
+			return
+		}
+
+		if err := sessionRepo.RevokeAllForUser(userID); err != nil {
+			log.Printf("Ошибка отзыва сессий: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Все сессии пользователя %d отозваны", userID)
+		c.JSON(http.StatusOK, SuccessResponse{Message: "Logged out from all sessions"})
+	}
+}
+
+// studentRoleID находит роль student, которую получают новые пользователи
+func studentRoleID(roleRepo repository.RoleRepository) (int, error) {
+	roles, err := roleRepo.GetAll()
+	if err != nil {
+		return 0, err
+	}
+	for _, role := range roles {
+		if role.Name == "student" {
+			return role.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("role student not found")
+}
+
 // Registration godoc
 // @Summary Регистрация пользователя
-// @Description Создание нового пользователя в системе
+// @Description Создание нового пользователя в системе; доступно с правом users:manage. Пользователь всегда получает роль student: role_id из запроса игнорируется, другую роль назначает PUT /api/user/{id}/role
 // @Tags Auth
 // @Accept  json
 // @Produce  json
-// @Param   user  body  models.User  true  "Данные для регистрации"  example({"login": "newuser", "password": "newpassword123", "first_name": "John", "last_name": "Doe", "role_id": 1})
+// @Param   Authorization  header  string  true  "Токен авторизации"
+// @Param   user  body  models.User  true  "Данные для регистрации"  example({"login": "newuser", "password": "newpassword123", "first_name": "John", "last_name": "Doe", "group_id": 1})
 // @Success 200 {object} SuccessResponse "Успешный ответ"
 // @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет права users:manage"
+// @Failure 409 {object} ErrorResponse "Логин уже занят"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/auth/registration [post]
-func Registration(db *sql.DB) gin.HandlerFunc {
+func Registration(userRepo repository.UserRepository, roleRepo repository.RoleRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var user models.User
 		if err := c.ShouldBindJSON(&user); err != nil {
@@ -80,12 +262,21 @@ func Registration(db *sql.DB) gin.HandlerFunc {
 		}
 
 		// Валидация входных данных
-		if user.Login == "" || user.Password == "" || user.FirstName == "" || user.LastName == "" || user.RoleID <= 0 {
+		if user.Login == "" || user.Password == "" || user.FirstName == "" || user.LastName == "" {
 			log.Printf("Некорректные данные для регистрации: %+v", user)
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "login, password, first_name, last_name, and role_id are required"})
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "login, password, first_name and last_name are required"})
 			return
 		}
 
+		// Роль не берётся из запроса: иначе регистрация обходила бы назначение ролей с правом roles:manage
+		roleID, err := studentRoleID(roleRepo)
+		if err != nil {
+			log.Printf("Ошибка при получении роли студента: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+		user.RoleID = roleID
+
 		hashResult, err := pkg.CreateHashWithSalt(user.Password)
 		if err != nil {
 			log.Printf("Ошибка хеширования пароля: %v", err)
@@ -98,10 +289,14 @@ func Registration(db *sql.DB) gin.HandlerFunc {
 		user.CreatedAt = time.Now()
 		user.UpdatedAt = time.Now()
 
-		userID, err := core.RegisterUser(db, user)
+		userID, err := userRepo.Register(user)
 		if err != nil {
 			log.Printf("Ошибка регистрации пользователя: %v", err)
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			if errors.Is(err, repository.ErrLoginExists) {
+				c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+			} else {
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			}
 			return
 		}
 
@@ -123,7 +318,7 @@ func Registration(db *sql.DB) gin.HandlerFunc {
 // @Success 200 {object} VerifyResponse "Успешный ответ"
 // @Failure 401 {object} ErrorResponse "Неверный токен"
 // @Router /api/auth/verify [post]
-func Verify(db *sql.DB) gin.HandlerFunc {
+func Verify(sessionRepo repository.SessionRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := c.GetHeader("Authorization")
 		if token == "" {
@@ -139,6 +334,18 @@ func Verify(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
+		active, err := sessionRepo.IsActive(claims.SessionID)
+		if err != nil {
+			log.Printf("Ошибка проверки сессии: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if !active {
+			log.Printf("Сессия %s отозвана или истекла", claims.SessionID)
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Token revoked"})
+			return
+		}
+
 		log.Printf("Успешная проверка токена для пользователя: %d", claims.UserID)
 		c.JSON(http.StatusOK, VerifyResponse{
 			UserID: claims.UserID,
@@ -158,7 +365,7 @@ func Verify(db *sql.DB) gin.HandlerFunc {
 // @Failure 401 {object} ErrorResponse "Пользователь не аутентифицирован"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/auth [get]
-func GetCurrentUser(db *sql.DB) gin.HandlerFunc {
+func GetCurrentUser(userRepo repository.UserRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, exists := c.Get("userID")
 		if !exists {
@@ -174,7 +381,7 @@ func GetCurrentUser(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		user, err := core.GetCurrentUser(db, userIDInt)
+		user, err := userRepo.GetCurrent(userIDInt)
 		if err != nil {
 			log.Printf("Ошибка получения информации о пользователе: %v", err)
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
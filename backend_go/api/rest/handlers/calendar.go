@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/VladislavSCV/internal/calendar"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/VladislavSCV/internal/utils"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseFeedID разбирает параметр вида «{id}.ics»; при ошибке отвечает 400
+func parseFeedID(c *gin.Context) (int, bool) {
+	file := c.Param("file")
+	id, err := strconv.Atoi(strings.TrimSuffix(file, ".ics"))
+	if !strings.HasSuffix(file, ".ics") || err != nil || id <= 0 {
+		log.Printf("Некорректное имя ленты: %s", file)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "feed must be requested as {id}.ics"})
+		return 0, false
+	}
+	return id, true
+}
+
+// calendarOwner находит владельца токена подписки из параметра token и строит его scope по правам роли,
+// как AuthMiddleware для JWT; при ошибке отвечает 401
+func calendarOwner(c *gin.Context, calendarRepo repository.CalendarTokenRepository, userRepo repository.UserRepository, roles *policy.RoleCache) (*models.User, policy.Scope, bool) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "calendar token is required"})
+		return nil, policy.Scope{}, false
+	}
+
+	userID, err := calendarRepo.GetUserID(utils.HashCalendarToken(token))
+	var owner *models.User
+	if err == nil {
+		owner, err = userRepo.GetCurrent(userID)
+	}
+	if err != nil {
+		log.Printf("Ошибка проверки токена подписки: %v", err)
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "invalid calendar token"})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return nil, policy.Scope{}, false
+	}
+
+	permissions, ok, err := roles.Permissions(owner.RoleID)
+	if err != nil {
+		log.Printf("Ошибка при загрузке прав роли %d: %v", owner.RoleID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "could not check permissions"})
+		return nil, policy.Scope{}, false
+	}
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "invalid calendar token"})
+		return nil, policy.Scope{}, false
+	}
+
+	log.Printf("Лента расписания запрошена по токену пользователя %d", owner.ID)
+	return owner, policy.NewScope(owner.ID, permissions), true
+}
+
+// teachesGroup сообщает, ведёт ли преподаватель занятия у группы по расписанию
+func teachesGroup(scheduleRepo repository.ScheduleRepository, teacherID, groupID int) (bool, error) {
+	_, page, err := scheduleRepo.List(models.ScheduleFilter{TeacherID: teacherID, GroupID: groupID}, models.ListQuery{Sort: "id", Limit: 1})
+	if err != nil {
+		return false, err
+	}
+	return page.Total > 0, nil
+}
+
+// writeFeed отдаёт ленту iCalendar на учебный период, в который попадает сегодняшний день; между периодами лента пуста.
+// Файл собирается целиком, чтобы при ошибке вернуть 500, а не обрезанный календарь
+func writeFeed(c *gin.Context, termRepo repository.TermRepository, name, filename string, schedules []models.Schedule) {
+	term, err := termRepo.GetCurrent(calendar.Today(time.Now()))
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		log.Printf("Ошибка при получении текущего учебного периода: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := calendar.WriteFeed(&buf, name, schedules, term); err != nil {
+		log.Printf("Ошибка формирования ленты: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
+
+// IssueCalendarToken godoc
+// @Summary Выпустить токен подписки на календарь
+// @Description Выдаёт новый секретный токен для лент расписания в формате iCalendar и отзывает прежний. Токен показывается один раз; календарные приложения передают его в параметре token вместо JWT
+// @Tags Schedules
+// @Produce  json
+// @Param   Authorization  header  string  true  "Токен авторизации"
+// @Success 200 {object} CalendarTokenResponse "Успешный ответ"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/schedule/calendar/token [post]
+func IssueCalendarToken(calendarRepo repository.CalendarTokenRepository, userRepo repository.UserRepository, scheduleRepo repository.ScheduleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := currentScope(c)
+		log.Printf("Получен запрос на выпуск токена подписки для пользователя %d", scope.UserID)
+
+		user, err := userRepo.GetCurrent(scope.UserID)
+		if err != nil {
+			log.Printf("Ошибка при получении пользователя: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		token, err := utils.GenerateCalendarToken()
+		if err != nil {
+			log.Printf("Ошибка генерации токена подписки: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to generate token"})
+			return
+		}
+		if err := calendarRepo.Set(user.ID, utils.HashCalendarToken(token)); err != nil {
+			log.Printf("Ошибка сохранения токена подписки: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		feeds := []string{}
+		if user.GroupID != nil {
+			feeds = append(feeds, fmt.Sprintf("/api/schedule/group/%d.ics?token=%s", *user.GroupID, token))
+		}
+		taught, err := scheduleRepo.GetByTeacherID(user.ID)
+		if err != nil {
+			log.Printf("Ошибка при получении занятий преподавателя: %v", err)
+		} else if len(taught) > 0 {
+			feeds = append(feeds, fmt.Sprintf("/api/schedule/teacher/%d.ics?token=%s", user.ID, token))
+		}
+
+		log.Printf("Выпущен токен подписки для пользователя %d", user.ID)
+		c.JSON(http.StatusOK, CalendarTokenResponse{Token: token, Feeds: feeds})
+	}
+}
+
+// RevokeCalendarToken godoc
+// @Summary Отозвать токен подписки на календарь
+// @Description Отзывает токен подписки текущего пользователя; ленты по нему перестают открываться
+// @Tags Schedules
+// @Produce  json
+// @Param   Authorization  header  string  true  "Токен авторизации"
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/schedule/calendar/token [delete]
+func RevokeCalendarToken(calendarRepo repository.CalendarTokenRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := currentScope(c).UserID
+
+		if err := calendarRepo.Delete(userID); err != nil {
+			log.Printf("Ошибка отзыва токена подписки: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Токен подписки пользователя %d отозван", userID)
+		c.JSON(http.StatusOK, SuccessResponse{Message: "Calendar token revoked"})
+	}
+}
+
+// GetGroupFeed godoc
+// @Summary Лента расписания группы (iCalendar)
+// @Description Возвращает расписание группы в формате RFC 5545: по событию на занятие с еженедельным повторением в границах текущего учебного периода (между периодами лента пуста). Авторизация — токен подписки в параметре token: владельцу открыта лента своей группы, преподавателю — групп, в которых он ведёт занятия, администратору — любая
+// @Tags Schedules
+// @Produce  text/calendar
+// @Param   file  path  string  true  "ID группы с расширением .ics"  example(1.ics)
+// @Param   token  query  string  true  "Токен подписки"
+// @Success 200 {string} string "Календарь"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Нет или неверный токен подписки"
+// @Failure 404 {object} ErrorResponse "Группа не найдена или недоступна владельцу токена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/schedule/group/{file} [get]
+func GetGroupFeed(calendarRepo repository.CalendarTokenRepository, userRepo repository.UserRepository, groupRepo repository.GroupRepository, scheduleRepo repository.ScheduleRepository, termRepo repository.TermRepository, roles *policy.RoleCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID, ok := parseFeedID(c)
+		if !ok {
+			return
+		}
+		owner, scope, ok := calendarOwner(c, calendarRepo, userRepo, roles)
+		if !ok {
+			return
+		}
+
+		// Своя группа открыта всем; остальные — при доступе ко всем записям или преподавателю этой группы.
+		// Чужая лента выглядит как несуществующая, чтобы по токену нельзя было перебирать группы
+		allowed := (owner.GroupID != nil && *owner.GroupID == groupID) || scope.Access == policy.AccessAll
+		if !allowed && scope.Access == policy.AccessTaught {
+			teaches, err := teachesGroup(scheduleRepo, owner.ID, groupID)
+			if err != nil {
+				log.Printf("Ошибка при проверке занятий преподавателя: %v", err)
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+				return
+			}
+			allowed = teaches
+		}
+		if !allowed {
+			log.Printf("Пользователю %d недоступна лента группы %d", owner.ID, groupID)
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "group not found"})
+			return
+		}
+
+		group, err := groupRepo.GetByID(groupID)
+		if err != nil {
+			log.Printf("Ошибка при получении группы: %v", err)
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, ErrorResponse{Error: "group not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			}
+			return
+		}
+
+		schedules, err := scheduleRepo.GetByGroupID(groupID)
+		if err != nil {
+			log.Printf("Ошибка при получении расписания: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		writeFeed(c, termRepo, "Расписание "+group.Name, fmt.Sprintf("group-%d.ics", groupID), schedules)
+	}
+}
+
+// GetTeacherFeed godoc
+// @Summary Лента расписания преподавателя (iCalendar)
+// @Description Возвращает занятия преподавателя в формате RFC 5545: по событию на занятие с еженедельным повторением в границах текущего учебного периода (между периодами лента пуста). Авторизация — токен подписки в параметре token: открыта только лента самого владельца токена, администратору — любая
+// @Tags Schedules
+// @Produce  text/calendar
+// @Param   file  path  string  true  "ID преподавателя с расширением .ics"  example(2.ics)
+// @Param   token  query  string  true  "Токен подписки"
+// @Success 200 {string} string "Календарь"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Нет или неверный токен подписки"
+// @Failure 404 {object} ErrorResponse "Преподаватель не найден или лента недоступна владельцу токена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/schedule/teacher/{file} [get]
+func GetTeacherFeed(calendarRepo repository.CalendarTokenRepository, userRepo repository.UserRepository, scheduleRepo repository.ScheduleRepository, termRepo repository.TermRepository, roles *policy.RoleCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		teacherID, ok := parseFeedID(c)
+		if !ok {
+			return
+		}
+		owner, scope, ok := calendarOwner(c, calendarRepo, userRepo, roles)
+		if !ok {
+			return
+		}
+
+		// Лента преподавателя — только его собственная, если нет доступа ко всем записям
+		if owner.ID != teacherID && scope.Access != policy.AccessAll {
+			log.Printf("Пользователю %d недоступна лента преподавателя %d", owner.ID, teacherID)
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "teacher not found"})
+			return
+		}
+
+		teacher, err := userRepo.GetByID(policy.System(), teacherID)
+		if err != nil {
+			log.Printf("Ошибка при получении преподавателя: %v", err)
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, ErrorResponse{Error: "teacher not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			}
+			return
+		}
+
+		schedules, err := scheduleRepo.GetByTeacherID(teacherID)
+		if err != nil {
+			log.Printf("Ошибка при получении расписания: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		name := fmt.Sprintf("Расписание %s %s", teacher.FirstName, teacher.LastName)
+		writeFeed(c, termRepo, name, fmt.Sprintf("teacher-%d.ics", teacherID), schedules)
+	}
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/tabular"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportColumn — колонка выгрузки: ключ для параметра columns и заголовок в файле
+type exportColumn struct {
+	key   string
+	title string
+	kind  int
+}
+
+// Колонки выгрузок; значения строк — в том же порядке (gradeExportRecord и другие)
+var (
+	gradeExportColumns = []exportColumn{
+		{"id", "ID", tabular.KindNumber},
+		{"date", "Дата", tabular.KindDate},
+		{"student_id", "ID студента", tabular.KindNumber},
+		{"student_name", "Студент", tabular.KindText},
+		{"subject_name", "Предмет", tabular.KindText},
+		{"value", "Оценка", tabular.KindNumber},
+		{"grade_type", "Вид оценки", tabular.KindText},
+		{"weight", "Вес", tabular.KindNumber},
+		{"comment", "Комментарий", tabular.KindText},
+		{"lesson_id", "ID занятия", tabular.KindNumber},
+	}
+	attendanceExportColumns = []exportColumn{
+		{"id", "ID", tabular.KindNumber},
+		{"date", "Дата", tabular.KindDate},
+		{"student_id", "ID студента", tabular.KindNumber},
+		{"student_name", "Студент", tabular.KindText},
+		{"subject_name", "Предмет", tabular.KindText},
+		{"status", "Статус", tabular.KindText},
+		{"lesson_id", "ID занятия", tabular.KindNumber},
+	}
+	userExportColumns = []exportColumn{
+		{"id", "ID", tabular.KindNumber},
+		{"last_name", "Фамилия", tabular.KindText},
+		{"first_name", "Имя", tabular.KindText},
+		{"middle_name", "Отчество", tabular.KindText},
+		{"login", "Логин", tabular.KindText},
+		{"role", "Роль", tabular.KindText},
+		{"group", "Группа", tabular.KindText},
+		{"created_at", "Создан", tabular.KindDate},
+	}
+	scheduleExportColumns = []exportColumn{
+		{"id", "ID", tabular.KindNumber},
+		{"group_name", "Группа", tabular.KindText},
+		{"day_of_week", "День недели", tabular.KindNumber},
+		{"start_time", "Начало", tabular.KindText},
+		{"end_time", "Конец", tabular.KindText},
+		{"subject_name", "Предмет", tabular.KindText},
+		{"teacher_name", "Преподаватель", tabular.KindText},
+		{"location", "Аудитория", tabular.KindText},
+	}
+)
+
+func optionalID(id *int) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.Itoa(*id)
+}
+
+func gradeExportRecord(grade models.GradeDetail) []string {
+	date := grade.Date
+	if parsed, err := time.Parse(time.RFC3339, grade.Date); err == nil {
+		date = parsed.Format("2006-01-02")
+	}
+	return []string{
+		strconv.Itoa(grade.ID),
+		date,
+		strconv.Itoa(grade.StudentID),
+		grade.StudentName,
+		grade.SubjectName,
+		strconv.Itoa(grade.Value),
+		grade.GradeType,
+		strconv.FormatFloat(grade.Weight, 'f', -1, 64),
+		grade.Comment,
+		optionalID(grade.LessonID),
+	}
+}
+
+func attendanceExportRecord(attendance models.AttendanceDetail) []string {
+	return []string{
+		strconv.Itoa(attendance.ID),
+		attendance.Date.Format("2006-01-02"),
+		strconv.Itoa(attendance.StudentID),
+		attendance.StudentName,
+		attendance.SubjectName,
+		attendance.Status,
+		optionalID(attendance.LessonID),
+	}
+}
+
+func userExportRecord(user models.User) []string {
+	return []string{
+		strconv.Itoa(user.ID),
+		user.LastName,
+		user.FirstName,
+		user.MiddleName,
+		user.Login,
+		user.Role,
+		user.Group.String,
+		user.CreatedAt.Format("2006-01-02"),
+	}
+}
+
+func scheduleExportRecord(schedule models.Schedule) []string {
+	return []string{
+		strconv.Itoa(schedule.ID),
+		schedule.GroupName,
+		strconv.Itoa(schedule.DayOfWeek),
+		schedule.StartTime,
+		schedule.EndTime,
+		schedule.SubjectName,
+		schedule.TeacherName,
+		schedule.Location,
+	}
+}
+
+// exportRequested сообщает, что список запрошен файлом (format=csv или format=xlsx), а не JSON
+func exportRequested(c *gin.Context) bool {
+	format := c.Query("format")
+	return format != "" && format != "json"
+}
+
+// exporter выгружает строки в ответ по мере поступления. Заголовки ответа отправляются с первыми
+// байтами файла, поэтому ошибку, случившуюся до них, ещё можно вернуть обычным JSON
+type exporter struct {
+	c        *gin.Context
+	out      tabular.Writer
+	format   string
+	filename string
+	selected []int
+	started  bool
+	rows     int
+}
+
+// startExport разбирает format и columns и начинает файл name.<format>; при ошибке отвечает 400
+func startExport(c *gin.Context, name string, columns []exportColumn) (*exporter, bool) {
+	format := c.Query("format")
+	if format != tabular.FormatCSV && format != tabular.FormatXLSX {
+		log.Printf("Некорректный формат выгрузки: %s", format)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "format must be json, csv or xlsx"})
+		return nil, false
+	}
+
+	var selected []int
+	if value := c.Query("columns"); value != "" {
+		index := make(map[string]int, len(columns))
+		for i, column := range columns {
+			index[column.key] = i
+		}
+		seen := make(map[int]bool)
+		for _, key := range strings.Split(value, ",") {
+			i, ok := index[strings.TrimSpace(key)]
+			if !ok {
+				keys := make([]string, len(columns))
+				for j, column := range columns {
+					keys[j] = column.key
+				}
+				log.Printf("Неизвестная колонка выгрузки: %s", key)
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("unknown column %q, available: %s", strings.TrimSpace(key), strings.Join(keys, ", "))})
+				return nil, false
+			}
+			if !seen[i] {
+				seen[i] = true
+				selected = append(selected, i)
+			}
+		}
+	} else {
+		for i := range columns {
+			selected = append(selected, i)
+		}
+	}
+
+	header := make([]tabular.Column, len(selected))
+	for i, index := range selected {
+		header[i] = tabular.Column{Title: columns[index].title, Kind: columns[index].kind}
+	}
+
+	e := &exporter{c: c, format: format, filename: name + "." + format, selected: selected}
+	out, err := tabular.NewWriter(e, format, name, header)
+	if err != nil {
+		log.Printf("Ошибка начала выгрузки: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return nil, false
+	}
+	e.out = out
+	log.Printf("Начата выгрузка %s", e.filename)
+	return e, true
+}
+
+// Write получает байты файла от tabular.Writer и при первом вызове отправляет заголовки ответа
+func (e *exporter) Write(p []byte) (int, error) {
+	if !e.started {
+		e.started = true
+		e.c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", e.filename))
+		e.c.Header("Content-Type", tabular.ContentType(e.format))
+		e.c.Status(http.StatusOK)
+	}
+	return e.c.Writer.Write(p)
+}
+
+// Row пишет строку record из всех колонок выгрузки, оставляя выбранные в columns
+func (e *exporter) Row(record []string) error {
+	row := make([]string, len(e.selected))
+	for i, index := range e.selected {
+		row[i] = record[index]
+	}
+	e.rows++
+	return e.out.Write(row)
+}
+
+// Finish завершает выгрузку. Ошибку до начала ответа возвращает с подходящим статусом;
+// после начала ответа её можно только записать в лог, а файл останется оборванным
+func (e *exporter) Finish(err error) {
+	if err == nil {
+		err = e.out.Close()
+	}
+	if err == nil {
+		log.Printf("Выгрузка %s завершена: строк %d", e.filename, e.rows)
+		return
+	}
+
+	log.Printf("Ошибка выгрузки %s: %v", e.filename, err)
+	if e.started {
+		return
+	}
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, policy.ErrForbidden):
+		status = http.StatusForbidden
+	case strings.Contains(err.Error(), "not found"):
+		status = http.StatusNotFound
+	}
+	e.c.JSON(status, ErrorResponse{Error: err.Error()})
+}
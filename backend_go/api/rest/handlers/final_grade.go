@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// finalGradeErrorStatus сопоставляет ошибки хранилища итоговых оценок с HTTP-статусами
+func finalGradeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, policy.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, repository.ErrGradeLocked):
+		return http.StatusConflict
+	case strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// parseFinalGradeID разбирает ID итоговой оценки из пути; при ошибке отвечает 400
+func parseFinalGradeID(c *gin.Context) (int, bool) {
+	finalGradeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || finalGradeID <= 0 {
+		log.Printf("Некорректный ID итоговой оценки: %s", c.Param("id"))
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid final grade ID"})
+		return 0, false
+	}
+	return finalGradeID, true
+}
+
+// bindFinalGrades разбирает тело запроса с группой и предметом; при ошибке отвечает 400
+func bindFinalGrades(c *gin.Context) (FinalGradesRequest, bool) {
+	var req FinalGradesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Printf("Ошибка привязки JSON: %v", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return FinalGradesRequest{}, false
+	}
+	if req.GroupID <= 0 {
+		log.Printf("Некорректный group_id: %d", req.GroupID)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "group_id must be positive"})
+		return FinalGradesRequest{}, false
+	}
+	if req.SubjectID <= 0 {
+		log.Printf("Некорректный subject_id: %d", req.SubjectID)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "subject_id must be positive"})
+		return FinalGradesRequest{}, false
+	}
+	return req, true
+}
+
+// GetFinalGrades godoc
+// @Summary Получить итоговые оценки за период
+// @Description Возвращает итоговые оценки периода; какие записи видны, решают права records:*
+// @Tags FinalGrades
+// @Produce  json
+// @Param   id          path   int  true   "ID периода"  example(1)
+// @Param   group_id    query  int  false  "ID группы"
+// @Param   subject_id  query  int  false  "ID предмета"
+// @Success 200 {array} models.FinalGrade "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 404 {object} ErrorResponse "Период не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/terms/{id}/final-grades [get]
+func GetFinalGrades(finalGradeRepo repository.FinalGradeRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		termID, ok := parseTermID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на получение итоговых оценок периода %d: %s", termID, c.Request.URL.RawQuery)
+
+		var filter models.FinalGradeFilter
+		for name, target := range map[string]*int{"group_id": &filter.GroupID, "subject_id": &filter.SubjectID} {
+			value := c.Query(name)
+			if value == "" {
+				continue
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				log.Printf("Некорректный параметр %s: %s", name, value)
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: name + " must be a positive integer"})
+				return
+			}
+			*target = n
+		}
+
+		grades, err := finalGradeRepo.GetByTerm(currentScope(c), termID, filter)
+		if err != nil {
+			log.Printf("Ошибка при получении итоговых оценок: %v", err)
+			c.JSON(finalGradeErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно получено итоговых оценок: %d", len(grades))
+		c.JSON(http.StatusOK, grades)
+	}
+}
+
+// ComputeFinalGrades godoc
+// @Summary Рассчитать итоговые оценки
+// @Description Рассчитывает итоговые оценки группы по предмету за период: suggested — средневзвешенный балл за даты периода, value — он же, округлённый до оценки. Закрытые оценки и студенты без оценок пропускаются, остальные пересчитываются
+// @Tags FinalGrades
+// @Accept  json
+// @Produce  json
+// @Param   id       path  int                 true  "ID периода"  example(1)
+// @Param   request  body  FinalGradesRequest  true  "Группа и предмет"
+// @Success 200 {array} models.FinalGrade "Итоговые оценки группы по предмету"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет доступа к оценкам хотя бы одного студента группы"
+// @Failure 404 {object} ErrorResponse "Период, группа или предмет не найдены"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/terms/{id}/final-grades/compute [post]
+func ComputeFinalGrades(finalGradeRepo repository.FinalGradeRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		termID, ok := parseTermID(c)
+		if !ok {
+			return
+		}
+		req, ok := bindFinalGrades(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на расчёт итоговых оценок периода %d (группа %d, предмет %d)", termID, req.GroupID, req.SubjectID)
+
+		grades, err := finalGradeRepo.Compute(currentScope(c), termID, req.GroupID, req.SubjectID)
+		if err != nil {
+			log.Printf("Ошибка при расчёте итоговых оценок: %v", err)
+			c.JSON(finalGradeErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно рассчитаны итоговые оценки периода %d", termID)
+		c.JSON(http.StatusOK, grades)
+	}
+}
+
+// LockFinalGrades godoc
+// @Summary Закрыть итоговые оценки
+// @Description Закрывает итоговые оценки группы по предмету за период. После этого итоговые оценки и оценки студентов по предмету за даты периода меняются только с правом grades:override
+// @Tags FinalGrades
+// @Accept  json
+// @Produce  json
+// @Param   id       path  int                 true  "ID периода"  example(1)
+// @Param   request  body  FinalGradesRequest  true  "Группа и предмет"
+// @Success 200 {object} SuccessResponse "Число закрытых оценок"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет доступа к оценкам хотя бы одного студента группы"
+// @Failure 404 {object} ErrorResponse "Период, группа или предмет не найдены"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/terms/{id}/final-grades/lock [post]
+func LockFinalGrades(finalGradeRepo repository.FinalGradeRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		termID, ok := parseTermID(c)
+		if !ok {
+			return
+		}
+		req, ok := bindFinalGrades(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на закрытие итоговых оценок периода %d (группа %d, предмет %d)", termID, req.GroupID, req.SubjectID)
+
+		locked, err := finalGradeRepo.Lock(currentScope(c), termID, req.GroupID, req.SubjectID)
+		if err != nil {
+			log.Printf("Ошибка при закрытии итоговых оценок: %v", err)
+			c.JSON(finalGradeErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно закрыто итоговых оценок: %d", locked)
+		c.JSON(http.StatusOK, SuccessResponse{
+			Message: "Final grades locked successfully",
+			Data:    gin.H{"locked": locked},
+		})
+	}
+}
+
+// GetFinalGradeByID godoc
+// @Summary Получить итоговую оценку по ID
+// @Description Возвращает итоговую оценку; недоступная по правам records:* выглядит как отсутствующая
+// @Tags FinalGrades
+// @Produce  json
+// @Param   id  path  int  true  "ID итоговой оценки"  example(1)
+// @Success 200 {object} models.FinalGrade "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 404 {object} ErrorResponse "Итоговая оценка не найдена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/final-grades/{id} [get]
+func GetFinalGradeByID(finalGradeRepo repository.FinalGradeRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		finalGradeID, ok := parseFinalGradeID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на получение итоговой оценки с ID: %d", finalGradeID)
+
+		grade, err := finalGradeRepo.GetByID(currentScope(c), finalGradeID)
+		if err != nil {
+			log.Printf("Ошибка при получении итоговой оценки: %v", err)
+			c.JSON(finalGradeErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, grade)
+	}
+}
+
+// UpdateFinalGrade godoc
+// @Summary Изменить итоговую оценку
+// @Description Выставляет итоговую оценку вместо рассчитанной. Закрытую оценку меняет только пользователь с правом grades:override
+// @Tags FinalGrades
+// @Accept  json
+// @Produce  json
+// @Param   id       path  int                true  "ID итоговой оценки"  example(1)
+// @Param   request  body  FinalGradeRequest  true  "Оценка"
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 404 {object} ErrorResponse "Итоговая оценка не найдена"
+// @Failure 409 {object} ErrorResponse "Итоговая оценка закрыта"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/final-grades/{id} [put]
+func UpdateFinalGrade(finalGradeRepo repository.FinalGradeRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		finalGradeID, ok := parseFinalGradeID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на обновление итоговой оценки с ID: %d", finalGradeID)
+
+		var req FinalGradeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			log.Printf("Ошибка привязки JSON: %v", err)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if req.Value < models.MinGradeValue || req.Value > models.MaxGradeValue {
+			log.Printf("Некорректная оценка: %d", req.Value)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "value must be between 2 and 5"})
+			return
+		}
+
+		if err := finalGradeRepo.Update(currentScope(c), finalGradeID, req.Value); err != nil {
+			log.Printf("Ошибка при обновлении итоговой оценки: %v", err)
+			c.JSON(finalGradeErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно обновлена итоговая оценка с ID: %d", finalGradeID)
+		c.JSON(http.StatusOK, SuccessResponse{Message: "Final grade updated successfully"})
+	}
+}
+
+// UnlockFinalGrade godoc
+// @Summary Открыть итоговую оценку
+// @Description Снимает закрытие с итоговой оценки: её и оценки студента по предмету за период снова можно менять
+// @Tags FinalGrades
+// @Produce  json
+// @Param   id  path  int  true  "ID итоговой оценки"  example(1)
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет права grades:override"
+// @Failure 404 {object} ErrorResponse "Итоговая оценка не найдена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/final-grades/{id}/unlock [post]
+func UnlockFinalGrade(finalGradeRepo repository.FinalGradeRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		finalGradeID, ok := parseFinalGradeID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на открытие итоговой оценки с ID: %d", finalGradeID)
+
+		if err := finalGradeRepo.Unlock(currentScope(c), finalGradeID); err != nil {
+			log.Printf("Ошибка при открытии итоговой оценки: %v", err)
+			c.JSON(finalGradeErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно открыта итоговая оценка с ID: %d", finalGradeID)
+		c.JSON(http.StatusOK, SuccessResponse{Message: "Final grade unlocked successfully"})
+	}
+}
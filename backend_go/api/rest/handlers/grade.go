@@ -1,9 +1,11 @@
 package handlers
 
 import (
-	"database/sql"
-	"github.com/VladislavSCV/internal/core"
+	"errors"
+	"fmt"
 	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
 	"github.com/gin-gonic/gin"
 	"log"
 	"net/http"
@@ -13,17 +15,28 @@ import (
 
 // GetGradesByStudentID godoc
 // @Summary Получить оценки студента по его ID
-// @Description Возвращает список оценок для конкретного студента
+// @Description Возвращает страницу оценок студента. format=csv или format=xlsx выгружает все оценки под фильтрами файлом, без деления на страницы
 // @Tags Grades
 // @Accept  json
 // @Produce  json
+// @Produce  text/csv
+// @Produce  application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param   format   query  string  false  "Формат: json (по умолчанию), csv (UTF-8 с BOM, разделитель «;») или xlsx"
+// @Param   columns  query  string  false  "Колонки файла через запятую в нужном порядке: id, date, student_id, student_name, subject_name, value, grade_type, weight, comment, lesson_id; по умолчанию все"
 // @Param   id  path  int  true  "ID студента"  example(1)
-// @Success 200 {array} models.GradeDetail "Успешный ответ"
+// @Param   subject_id  query  int     false  "ID предмета"
+// @Param   from        query  string  false  "Первый день (ГГГГ-ММ-ДД)"
+// @Param   to          query  string  false  "Последний день (ГГГГ-ММ-ДД)"
+// @Param   sort     query  string  false  "Сортировка: date (по умолчанию), id, value, subject_name, student_name, created_at; «-» в начале — по убыванию"
+// @Param   limit    query  int     false  "Размер страницы, от 1 до 1000; по умолчанию 50"
+// @Param   offset   query  int     false  "Сколько записей пропустить"
+// @Param   cursor   query  string  false  "next_cursor предыдущей страницы; вместо offset"
+// @Success 200 {object} ListResponse{items=[]models.GradeDetail} "Успешный ответ"
 // @Failure 400 {object} ErrorResponse "Неверный запрос"
-// @Failure 404 {object} ErrorResponse "Оценки не найдены"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/grades/student/{id} [get]
-func GetGradesByStudentID(db *sql.DB) gin.HandlerFunc {
+func GetGradesByStudentID(gradeRepo repository.GradeRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		studentID := c.Param("id")
 		log.Printf("Получен запрос на получение оценок для студента с ID: %s", studentID)
@@ -43,36 +56,70 @@ func GetGradesByStudentID(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		grades, err := core.GetGradesByStudentID(db, studentIDInt)
-		if err != nil {
-			if strings.Contains(err.Error(), "not found") {
-				log.Printf("Оценки для студента с ID %d не найдены", studentIDInt)
-				c.JSON(http.StatusNotFound, ErrorResponse{Error: "grades not found"})
-			} else {
-				log.Printf("Ошибка при получении оценок: %v", err)
-				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		scope := currentScope(c)
+		if !scope.CanReadStudent(studentIDInt) {
+			log.Printf("Пользователь %d не имеет доступа к данным студента %d", scope.UserID, studentIDInt)
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: policy.ErrForbidden.Error()})
+			return
+		}
+
+		filter := models.RecordFilter{StudentID: studentIDInt}
+		if !parseRecordFilter(c, &filter, false) {
+			return
+		}
+
+		if exportRequested(c) {
+			export, ok := startExport(c, fmt.Sprintf("grades-student-%d", studentIDInt), gradeExportColumns)
+			if !ok {
+				return
 			}
+			export.Finish(gradeRepo.Export(scope, filter, func(grade models.GradeDetail) error {
+				return export.Row(gradeExportRecord(grade))
+			}))
+			return
+		}
+
+		query, ok := parseListQuery(c, models.GradeSortFields)
+		if !ok {
+			return
+		}
+
+		grades, page, err := gradeRepo.List(scope, filter, query)
+		if err != nil {
+			log.Printf("Ошибка при получении оценок: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 			return
 		}
 
-		log.Printf("Успешно получены оценки для студента с ID: %d", studentIDInt)
-		c.JSON(http.StatusOK, grades)
+		log.Printf("Успешно получены оценки для студента с ID: %d (%d из %d)", studentIDInt, len(grades), page.Total)
+		writeList(c, grades, page, query)
 	}
 }
 
 // GetGradesByGroupID godoc
 // @Summary Получить оценки группы по её ID
-// @Description Возвращает список оценок для конкретной группы
+// @Description Возвращает страницу оценок группы. format=csv или format=xlsx выгружает все оценки под фильтрами файлом, без деления на страницы и не собирая их в памяти
 // @Tags Grades
 // @Accept  json
 // @Produce  json
+// @Produce  text/csv
+// @Produce  application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param   format   query  string  false  "Формат: json (по умолчанию), csv (UTF-8 с BOM, разделитель «;») или xlsx"
+// @Param   columns  query  string  false  "Колонки файла через запятую в нужном порядке: id, date, student_id, student_name, subject_name, value, grade_type, weight, comment, lesson_id; по умолчанию все"
 // @Param   id  path  int  true  "ID группы"  example(1)
-// @Success 200 {array} models.GradeDetail "Успешный ответ"
+// @Param   subject_id  query  int     false  "ID предмета"
+// @Param   from        query  string  false  "Первый день (ГГГГ-ММ-ДД)"
+// @Param   to          query  string  false  "Последний день (ГГГГ-ММ-ДД)"
+// @Param   sort     query  string  false  "Сортировка: date (по умолчанию), id, value, subject_name, student_name, created_at; «-» в начале — по убыванию"
+// @Param   limit    query  int     false  "Размер страницы, от 1 до 1000; по умолчанию 50"
+// @Param   offset   query  int     false  "Сколько записей пропустить"
+// @Param   cursor   query  string  false  "next_cursor предыдущей страницы; вместо offset"
+// @Success 200 {object} ListResponse{items=[]models.GradeDetail} "Успешный ответ"
 // @Failure 400 {object} ErrorResponse "Неверный запрос"
-// @Failure 404 {object} ErrorResponse "Оценки не найдены"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/grades/group/{id} [get]
-func GetGradesByGroupID(db *sql.DB) gin.HandlerFunc {
+func GetGradesByGroupID(gradeRepo repository.GradeRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		groupID := c.Param("id")
 		log.Printf("Получен запрос на получение оценок для группы с ID: %s", groupID)
@@ -92,35 +139,71 @@ func GetGradesByGroupID(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		grades, err := core.GetGradesByGroupID(db, groupIDInt)
-		if err != nil {
-			if strings.Contains(err.Error(), "not found") {
-				log.Printf("Оценки для группы с ID %d не найдены", groupIDInt)
-				c.JSON(http.StatusNotFound, ErrorResponse{Error: "grades not found"})
-			} else {
-				log.Printf("Ошибка при получении оценок: %v", err)
-				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		filter := models.RecordFilter{GroupID: groupIDInt}
+		if !parseRecordFilter(c, &filter, false) {
+			return
+		}
+
+		if exportRequested(c) {
+			export, ok := startExport(c, fmt.Sprintf("grades-group-%d", groupIDInt), gradeExportColumns)
+			if !ok {
+				return
 			}
+			export.Finish(gradeRepo.Export(currentScope(c), filter, func(grade models.GradeDetail) error {
+				return export.Row(gradeExportRecord(grade))
+			}))
 			return
 		}
 
-		log.Printf("Успешно получены оценки для группы с ID: %d", groupIDInt)
-		c.JSON(http.StatusOK, grades)
+		query, ok := parseListQuery(c, models.GradeSortFields)
+		if !ok {
+			return
+		}
+
+		grades, page, err := gradeRepo.List(currentScope(c), filter, query)
+		if err != nil {
+			log.Printf("Ошибка при получении оценок: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно получены оценки для группы с ID: %d (%d из %d)", groupIDInt, len(grades), page.Total)
+		writeList(c, grades, page, query)
 	}
 }
 
+// validGradeExtras проверяет вид оценки и комментарий (комментарий обрезается по краям); при ошибке отвечает 400
+func validGradeExtras(c *gin.Context, grade *models.Grade) bool {
+	if grade.GradeTypeID != nil && *grade.GradeTypeID <= 0 {
+		log.Printf("Некорректный grade_type_id: %d", *grade.GradeTypeID)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "grade_type_id must be positive"})
+		return false
+	}
+	grade.Comment = strings.TrimSpace(grade.Comment)
+	if len([]rune(grade.Comment)) > 500 {
+		log.Printf("Слишком длинный комментарий к оценке: %d символов", len([]rune(grade.Comment)))
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "comment must be at most 500 characters"})
+		return false
+	}
+	return true
+}
+
 // CreateGrade godoc
 // @Summary Создать оценку
 // @Description Создаёт новую запись об оценке
 // @Tags Grades
 // @Accept  json
 // @Produce  json
-// @Param   grade  body  models.Grade  true  "Данные об оценке"  example({"student_id": 1, "subject_id": 1, "value": 5, "date": "2023-10-01T00:00:00Z"})
+// @Param   grade  body  models.Grade  true  "Данные об оценке"  example({"student_id": 1, "subject_id": 1, "value": 5, "date": "2023-10-01T00:00:00Z", "grade_type_id": 4, "comment": "Контрольная по теме «Интегралы»"})
+// @Param   reason  query  string  false  "Причина изменения, до 500 символов; сохраняется в истории"
 // @Success 200 {object} SuccessResponse "Успешный ответ"
 // @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 409 {object} ErrorResponse "Итоговая оценка за период закрыта; менять оценки можно только с правом grades:override"
+// @Failure 422 {object} ErrorResponse "Занятие (lesson_id) не совпадает с предметом, датой или группой студента"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/grades [post]
-func CreateGrade(db *sql.DB) gin.HandlerFunc {
+func CreateGrade(gradeRepo repository.GradeRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var grade models.Grade
 		if err := c.ShouldBindJSON(&grade); err != nil {
@@ -145,16 +228,35 @@ func CreateGrade(db *sql.DB) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "value must be between 2 and 5"})
 			return
 		}
+		if !validGradeExtras(c, &grade) {
+			return
+		}
 		if grade.Date.IsZero() {
 			log.Printf("Некорректная дата: %v", grade.Date)
 			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "date must be provided"})
 			return
 		}
 
-		gradeID, err := core.CreateGrade(db, grade)
+		reason, ok := changeReason(c)
+		if !ok {
+			return
+		}
+
+		gradeID, err := gradeRepo.Create(currentScope(c), grade, reason)
 		if err != nil {
 			log.Printf("Ошибка при создании оценки: %v", err)
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			switch {
+			case errors.Is(err, policy.ErrForbidden):
+				c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			case errors.Is(err, repository.ErrLessonMismatch):
+				c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Error: err.Error()})
+			case errors.Is(err, repository.ErrGradeLocked):
+				c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+			case strings.Contains(err.Error(), "not found"):
+				c.JSON(http.StatusNotFound, ErrorResponse{Error: "grade not found"})
+			default:
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			}
 			return
 		}
 
@@ -173,12 +275,16 @@ func CreateGrade(db *sql.DB) gin.HandlerFunc {
 // @Accept  json
 // @Produce  json
 // @Param   id  path  int  true  "ID оценки"  example(1)
-// @Param   grade  body  models.Grade  true  "Обновлённые данные об оценке"  example({"student_id": 1, "subject_id": 1, "value": 4, "date": "2023-10-01T00:00:00Z"})
+// @Param   grade  body  models.Grade  true  "Обновлённые данные об оценке"  example({"student_id": 1, "subject_id": 1, "value": 4, "date": "2023-10-01T00:00:00Z", "grade_type_id": 4, "comment": "Пересдача"})
+// @Param   reason  query  string  false  "Причина изменения, до 500 символов; сохраняется в истории"
 // @Success 200 {object} SuccessResponse "Успешный ответ"
 // @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 409 {object} ErrorResponse "Итоговая оценка за период закрыта; менять оценки можно только с правом grades:override"
+// @Failure 422 {object} ErrorResponse "Занятие (lesson_id) не совпадает с предметом, датой или группой студента"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/grades/{id} [put]
-func UpdateGrade(db *sql.DB) gin.HandlerFunc {
+func UpdateGrade(gradeRepo repository.GradeRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		log.Printf("Получен запрос на обновление оценки с ID: %s", id)
@@ -221,6 +327,9 @@ func UpdateGrade(db *sql.DB) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "value must be between 2 and 5"})
 			return
 		}
+		if !validGradeExtras(c, &grade) {
+			return
+		}
 		if grade.Date.IsZero() {
 			log.Printf("Некорректная дата: %v", grade.Date)
 			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "date must be provided"})
@@ -230,9 +339,25 @@ func UpdateGrade(db *sql.DB) gin.HandlerFunc {
 		// Устанавливаем ID оценки из параметра запроса
 		grade.ID = idInt
 
-		if err := core.UpdateGrade(db, grade); err != nil {
+		reason, ok := changeReason(c)
+		if !ok {
+			return
+		}
+
+		if err := gradeRepo.Update(currentScope(c), grade, reason); err != nil {
 			log.Printf("Ошибка при обновлении оценки: %v", err)
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			switch {
+			case errors.Is(err, policy.ErrForbidden):
+				c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			case errors.Is(err, repository.ErrLessonMismatch):
+				c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Error: err.Error()})
+			case errors.Is(err, repository.ErrGradeLocked):
+				c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+			case strings.Contains(err.Error(), "not found"):
+				c.JSON(http.StatusNotFound, ErrorResponse{Error: "grade not found"})
+			default:
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			}
 			return
 		}
 
@@ -248,11 +373,14 @@ func UpdateGrade(db *sql.DB) gin.HandlerFunc {
 // @Accept  json
 // @Produce  json
 // @Param   id  path  int  true  "ID оценки"  example(1)
+// @Param   reason  query  string  false  "Причина изменения, до 500 символов; сохраняется в истории"
 // @Success 200 {object} SuccessResponse "Успешный ответ"
 // @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 409 {object} ErrorResponse "Итоговая оценка за период закрыта; менять оценки можно только с правом grades:override"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/grades/{id} [delete]
-func DeleteGrade(db *sql.DB) gin.HandlerFunc {
+func DeleteGrade(gradeRepo repository.GradeRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		log.Printf("Получен запрос на удаление оценки с ID: %s", id)
@@ -272,9 +400,23 @@ func DeleteGrade(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		if err := core.DeleteGrade(db, idInt); err != nil {
+		reason, ok := changeReason(c)
+		if !ok {
+			return
+		}
+
+		if err := gradeRepo.Delete(currentScope(c), idInt, reason); err != nil {
 			log.Printf("Ошибка при удалении оценки: %v", err)
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			switch {
+			case errors.Is(err, policy.ErrForbidden):
+				c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			case errors.Is(err, repository.ErrGradeLocked):
+				c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+			case strings.Contains(err.Error(), "not found"):
+				c.JSON(http.StatusNotFound, ErrorResponse{Error: "grade not found"})
+			default:
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			}
 			return
 		}
 
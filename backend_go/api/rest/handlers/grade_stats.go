@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"github.com/VladislavSCV/internal/config"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// parseGradeStatsFilter разбирает параметры аналитики оценок; при ошибке отвечает 400
+func parseGradeStatsFilter(c *gin.Context) (models.GradeStatsFilter, bool) {
+	var filter models.GradeStatsFilter
+
+	for name, target := range map[string]*int{
+		"group_id":   &filter.GroupID,
+		"student_id": &filter.StudentID,
+		"subject_id": &filter.SubjectID,
+		"teacher_id": &filter.TeacherID,
+	} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			log.Printf("Некорректный параметр %s: %s", name, value)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: name + " must be a positive integer"})
+			return models.GradeStatsFilter{}, false
+		}
+		*target = n
+	}
+
+	for name, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse(config.DateLayout, value)
+		if err != nil {
+			log.Printf("Некорректная дата %s: %s", name, value)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: name + " must be in YYYY-MM-DD format"})
+			return models.GradeStatsFilter{}, false
+		}
+		*target = date
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		log.Printf("Некорректный период: %s - %s", c.Query("from"), c.Query("to"))
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "to must not be before from"})
+		return models.GradeStatsFilter{}, false
+	}
+
+	return filter, true
+}
+
+// GetGradeStats godoc
+// @Summary Аналитика оценок
+// @Description Возвращает число оценок, средний балл, медиану и распределение значений 2–5 по студентам, предметам, группам или преподавателям. Оценка относится к преподавателю занятия, а без занятия — ко всем, кто ведёт предмет у группы по расписанию. Учитываются только оценки, доступные пользователю
+// @Tags Grades
+// @Produce  json
+// @Param   by          query  string  false  "Разрез: student (по умолчанию), subject, group или teacher"
+// @Param   group_id    query  int     false  "ID группы"
+// @Param   student_id  query  int     false  "ID студента"
+// @Param   subject_id  query  int     false  "ID предмета"
+// @Param   teacher_id  query  int     false  "ID преподавателя"
+// @Param   from        query  string  false  "Первый день (ГГГГ-ММ-ДД)"
+// @Param   to          query  string  false  "Последний день (ГГГГ-ММ-ДД)"
+// @Success 200 {array} models.GradeStat "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/grades/stats [get]
+func GetGradeStats(gradeRepo repository.GradeRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log.Printf("Получен запрос на аналитику оценок: %s", c.Request.URL.RawQuery)
+
+		by := c.DefaultQuery("by", models.StatsByStudent)
+		switch by {
+		case models.StatsByStudent, models.StatsBySubject, models.StatsByGroup, models.StatsByTeacher:
+		default:
+			log.Printf("Некорректный разрез аналитики: %s", by)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "by must be one of student, subject, group, teacher"})
+			return
+		}
+		filter, ok := parseGradeStatsFilter(c)
+		if !ok {
+			return
+		}
+
+		stats, err := gradeRepo.Stats(currentScope(c), filter, by)
+		if err != nil {
+			log.Printf("Ошибка при получении аналитики оценок: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно получена аналитика оценок: %d строк", len(stats))
+		c.JSON(http.StatusOK, stats)
+	}
+}
+
+// GetGroupRanking godoc
+// @Summary Рейтинг студентов группы
+// @Description Возвращает студентов группы по убыванию среднего балла. Студенты с одинаковым средним делят место
+// @Tags Grades
+// @Produce  json
+// @Param   group_id    query  int     true   "ID группы"
+// @Param   subject_id  query  int     false  "ID предмета"
+// @Param   teacher_id  query  int     false  "ID преподавателя"
+// @Param   from        query  string  false  "Первый день (ГГГГ-ММ-ДД)"
+// @Param   to          query  string  false  "Последний день (ГГГГ-ММ-ДД)"
+// @Success 200 {array} models.GradeRank "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/grades/stats/ranking [get]
+func GetGroupRanking(gradeRepo repository.GradeRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log.Printf("Получен запрос на рейтинг группы: %s", c.Request.URL.RawQuery)
+
+		filter, ok := parseGradeStatsFilter(c)
+		if !ok {
+			return
+		}
+		if filter.GroupID == 0 {
+			log.Printf("Не указана группа для рейтинга")
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "group_id must be provided"})
+			return
+		}
+
+		ranking, err := gradeRepo.Ranking(currentScope(c), filter)
+		if err != nil {
+			log.Printf("Ошибка при получении рейтинга группы: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно получен рейтинг группы %d: %d студентов", filter.GroupID, len(ranking))
+		c.JSON(http.StatusOK, ranking)
+	}
+}
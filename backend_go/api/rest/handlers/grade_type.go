@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxGradeWeight — наибольший вес, который помещается в NUMERIC(5, 2)
+const maxGradeWeight = 999.99
+
+// gradeTypeCode — допустимый код вида оценки: строчные латинские буквы, цифры и подчёркивание
+var gradeTypeCode = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// gradeTypeErrorStatus сопоставляет ошибки хранилища видов оценок с HTTP-статусами
+func gradeTypeErrorStatus(err error) int {
+	var inUse *repository.InUseError
+	switch {
+	case errors.Is(err, repository.ErrGradeTypeExists), errors.As(err, &inUse):
+		return http.StatusConflict
+	case strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// parseGradeTypeID разбирает ID вида оценки из пути; при ошибке отвечает 400
+func parseGradeTypeID(c *gin.Context) (int, bool) {
+	gradeTypeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || gradeTypeID <= 0 {
+		log.Printf("Некорректный ID вида оценки: %s", c.Param("id"))
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid grade type ID"})
+		return 0, false
+	}
+	return gradeTypeID, true
+}
+
+// validGradeWeight проверяет, что вес положителен и помещается в колонку
+func validGradeWeight(weight float64) bool {
+	return weight > 0 && weight <= maxGradeWeight
+}
+
+// bindGradeType разбирает и проверяет тело запроса на создание или изменение вида оценки
+func bindGradeType(c *gin.Context) (models.GradeType, bool) {
+	var gradeType models.GradeType
+	if err := c.ShouldBindJSON(&gradeType); err != nil {
+		log.Printf("Ошибка привязки JSON: %v", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return models.GradeType{}, false
+	}
+
+	gradeType.Code = strings.TrimSpace(gradeType.Code)
+	gradeType.Name = strings.TrimSpace(gradeType.Name)
+	if !gradeTypeCode.MatchString(gradeType.Code) {
+		log.Printf("Некорректный код вида оценки: %s", gradeType.Code)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "code must start with a lowercase latin letter and contain only a-z, 0-9 and _ (at most 50 characters)"})
+		return models.GradeType{}, false
+	}
+	if gradeType.Name == "" || len([]rune(gradeType.Name)) > 100 {
+		log.Printf("Некорректное название вида оценки: %s", gradeType.Name)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "name is required and must be at most 100 characters"})
+		return models.GradeType{}, false
+	}
+	if !validGradeWeight(gradeType.DefaultWeight) {
+		log.Printf("Некорректный вес вида оценки: %v", gradeType.DefaultWeight)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "default_weight must be greater than 0 and at most 999.99"})
+		return models.GradeType{}, false
+	}
+
+	return models.GradeType{Code: gradeType.Code, Name: gradeType.Name, DefaultWeight: gradeType.DefaultWeight}, true
+}
+
+// GetGradeTypes godoc
+// @Summary Получить виды оценок
+// @Description Возвращает виды оценок с весами по умолчанию
+// @Tags GradeTypes
+// @Produce  json
+// @Success 200 {array} models.GradeType "Успешный ответ"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/grade-types [get]
+func GetGradeTypes(gradeTypeRepo repository.GradeTypeRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log.Printf("Получен запрос на получение видов оценок")
+
+		gradeTypes, err := gradeTypeRepo.GetAll()
+		if err != nil {
+			log.Printf("Ошибка при получении видов оценок: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно получен список видов оценок")
+		c.JSON(http.StatusOK, gradeTypes)
+	}
+}
+
+// GetGradeTypeByID godoc
+// @Summary Получить вид оценки по ID
+// @Description Возвращает вид оценки с весом по умолчанию
+// @Tags GradeTypes
+// @Produce  json
+// @Param   id  path  int  true  "ID вида оценки"  example(1)
+// @Success 200 {object} models.GradeType "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 404 {object} ErrorResponse "Вид оценки не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/grade-types/{id} [get]
+func GetGradeTypeByID(gradeTypeRepo repository.GradeTypeRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		gradeTypeID, ok := parseGradeTypeID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на получение вида оценки с ID: %d", gradeTypeID)
+
+		gradeType, err := gradeTypeRepo.GetByID(gradeTypeID)
+		if err != nil {
+			log.Printf("Ошибка при получении вида оценки: %v", err)
+			c.JSON(gradeTypeErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gradeType)
+	}
+}
+
+// CreateGradeType godoc
+// @Summary Создать вид оценки
+// @Description Создаёт вид оценки; код уникален
+// @Tags GradeTypes
+// @Accept  json
+// @Produce  json
+// @Param   gradeType  body  models.GradeType  true  "Вид оценки"  example({"code": "project", "name": "Проект", "default_weight": 2.5})
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет права grade_types:manage"
+// @Failure 409 {object} ErrorResponse "Вид оценки с таким кодом уже существует"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/grade-types [post]
+func CreateGradeType(gradeTypeRepo repository.GradeTypeRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		gradeType, ok := bindGradeType(c)
+		if !ok {
+			return
+		}
+
+		gradeTypeID, err := gradeTypeRepo.Create(gradeType)
+		if err != nil {
+			log.Printf("Ошибка при создании вида оценки: %v", err)
+			c.JSON(gradeTypeErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно создан вид оценки с ID: %d", gradeTypeID)
+		c.JSON(http.StatusOK, SuccessResponse{
+			Message: "Grade type created successfully",
+			Data:    gin.H{"grade_type_id": gradeTypeID},
+		})
+	}
+}
+
+// UpdateGradeType godoc
+// @Summary Изменить вид оценки
+// @Description Изменяет код, название и вес по умолчанию. Новый вес сразу учитывается во всех средних баллах
+// @Tags GradeTypes
+// @Accept  json
+// @Produce  json
+// @Param   id  path  int  true  "ID вида оценки"  example(1)
+// @Param   gradeType  body  models.GradeType  true  "Новые данные"  example({"code": "exam", "name": "Экзамен", "default_weight": 4})
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет права grade_types:manage"
+// @Failure 404 {object} ErrorResponse "Вид оценки не найден"
+// @Failure 409 {object} ErrorResponse "Вид оценки с таким кодом уже существует"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/grade-types/{id} [put]
+func UpdateGradeType(gradeTypeRepo repository.GradeTypeRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		gradeTypeID, ok := parseGradeTypeID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на обновление вида оценки с ID: %d", gradeTypeID)
+
+		gradeType, ok := bindGradeType(c)
+		if !ok {
+			return
+		}
+		gradeType.ID = gradeTypeID
+
+		if err := gradeTypeRepo.Update(gradeType); err != nil {
+			log.Printf("Ошибка при обновлении вида оценки: %v", err)
+			c.JSON(gradeTypeErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно обновлён вид оценки с ID: %d", gradeTypeID)
+		c.JSON(http.StatusOK, SuccessResponse{Message: "Grade type updated successfully"})
+	}
+}
+
+// DeleteGradeType godoc
+// @Summary Удалить вид оценки
+// @Description Удаляет вид оценки вместе с его весами в предметах. Если вид указан у оценок, возвращает 409 с их количеством
+// @Tags GradeTypes
+// @Produce  json
+// @Param   id  path  int  true  "ID вида оценки"  example(1)
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет права grade_types:manage"
+// @Failure 404 {object} ErrorResponse "Вид оценки не найден"
+// @Failure 409 {object} InUseResponse "Вид оценки указан у оценок"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/grade-types/{id} [delete]
+func DeleteGradeType(gradeTypeRepo repository.GradeTypeRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		gradeTypeID, ok := parseGradeTypeID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на удаление вида оценки с ID: %d", gradeTypeID)
+
+		if err := gradeTypeRepo.Delete(gradeTypeID); err != nil {
+			log.Printf("Ошибка при удалении вида оценки: %v", err)
+			var inUse *repository.InUseError
+			if errors.As(err, &inUse) {
+				c.JSON(http.StatusConflict, InUseResponse{Error: err.Error(), Dependents: inUse.Dependents})
+				return
+			}
+			c.JSON(gradeTypeErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно удалён вид оценки с ID: %d", gradeTypeID)
+		c.JSON(http.StatusOK, SuccessResponse{Message: "Grade type deleted successfully"})
+	}
+}
+
+// GetSubjectGradeWeights godoc
+// @Summary Получить веса видов оценок в предмете
+// @Description Возвращает вес каждого вида оценки в предмете; overridden показывает, задан ли вес для предмета или взят по умолчанию
+// @Tags GradeTypes
+// @Produce  json
+// @Param   id  path  int  true  "ID предмета"  example(1)
+// @Success 200 {array} models.GradeWeight "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 404 {object} ErrorResponse "Предмет не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/subjects/{id}/weights [get]
+func GetSubjectGradeWeights(gradeTypeRepo repository.GradeTypeRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subjectID, ok := parseSubjectID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на получение весов оценок предмета с ID: %d", subjectID)
+
+		weights, err := gradeTypeRepo.GetWeights(subjectID)
+		if err != nil {
+			log.Printf("Ошибка при получении весов оценок: %v", err)
+			c.JSON(gradeTypeErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, weights)
+	}
+}
+
+// SetSubjectGradeWeights godoc
+// @Summary Задать веса видов оценок в предмете
+// @Description Заменяет веса видов оценок в предмете. Виды, не указанные в запросе, получают вес по умолчанию; пустой объект сбрасывает все веса
+// @Tags GradeTypes
+// @Accept  json
+// @Produce  json
+// @Param   id  path  int  true  "ID предмета"  example(1)
+// @Param   request  body  GradeWeightsRequest  true  "Веса по ID вида оценки"  example({"weights": {"5": 4, "4": 2.5}})
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет права grade_types:manage"
+// @Failure 404 {object} ErrorResponse "Предмет или вид оценки не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/subjects/{id}/weights [put]
+func SetSubjectGradeWeights(gradeTypeRepo repository.GradeTypeRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subjectID, ok := parseSubjectID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на изменение весов оценок предмета с ID: %d", subjectID)
+
+		var req GradeWeightsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			log.Printf("Ошибка привязки JSON: %v", err)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		for gradeTypeID, weight := range req.Weights {
+			if !validGradeWeight(weight) {
+				log.Printf("Некорректный вес вида оценки %d: %v", gradeTypeID, weight)
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "weights must be greater than 0 and at most 999.99"})
+				return
+			}
+		}
+
+		if err := gradeTypeRepo.SetWeights(subjectID, req.Weights); err != nil {
+			log.Printf("Ошибка при изменении весов оценок: %v", err)
+			c.JSON(gradeTypeErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно изменены веса оценок предмета с ID: %d", subjectID)
+		c.JSON(http.StatusOK, SuccessResponse{Message: "Grade weights updated successfully"})
+	}
+}
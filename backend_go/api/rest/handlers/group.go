@@ -1,38 +1,64 @@
 package handlers
 
 import (
-	"database/sql"
-	"github.com/VladislavSCV/internal/core"
+	"errors"
+	"github.com/VladislavSCV/internal/config"
 	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
 	"github.com/gin-gonic/gin"
 	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// transferErrorStatus подбирает HTTP-статус для ошибки перевода студента
+func transferErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrAlreadyInGroup):
+		return http.StatusConflict
+	case errors.Is(err, repository.ErrNotStudent), errors.Is(err, repository.ErrInvalidTransferDate):
+		return http.StatusUnprocessableEntity
+	case strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // GetGroups godoc
 // @Summary Получить список всех групп
-// @Description Возвращает список всех групп
+// @Description Возвращает страницу групп с числом студентов
 // @Tags Groups
 // @Accept  json
 // @Produce  json
-// @Success 200 {array} models.Group "Успешный ответ"  example([{"id": 1, "name": "Group A"}, {"id": 2, "name": "Group B"}])
+// @Param   sort     query  string  false  "Сортировка: name (по умолчанию), id, student_count, created_at; «-» в начале — по убыванию"
+// @Param   limit    query  int     false  "Размер страницы, от 1 до 1000; по умолчанию 50"
+// @Param   offset   query  int     false  "Сколько записей пропустить"
+// @Param   cursor   query  string  false  "next_cursor предыдущей страницы; вместо offset"
+// @Success 200 {object} ListResponse{items=[]models.Group} "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/group [get]
-func GetGroups(db *sql.DB) gin.HandlerFunc {
+func GetGroups(groupRepo repository.GroupRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		log.Printf("Получен запрос на получение списка всех групп")
 
-		groups, err := core.GetAllGroups(db)
+		query, ok := parseListQuery(c, models.GroupSortFields)
+		if !ok {
+			return
+		}
+
+		groups, page, err := groupRepo.List(query)
 		if err != nil {
 			log.Printf("Ошибка при получении списка групп: %v", err)
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 			return
 		}
 
-		log.Printf("Успешно получен список групп")
-		c.JSON(http.StatusOK, groups)
+		log.Printf("Успешно получен список групп: %d из %d", len(groups), page.Total)
+		writeList(c, groups, page, query)
 	}
 }
 
@@ -48,7 +74,7 @@ func GetGroups(db *sql.DB) gin.HandlerFunc {
 // @Failure 404 {object} ErrorResponse "Группа не найдена"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/group/{id} [get]
-func GetGroupByID(db *sql.DB) gin.HandlerFunc {
+func GetGroupByID(groupRepo repository.GroupRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		groupID := c.Param("id")
 		log.Printf("Получен запрос на получение информации о группе с ID: %s", groupID)
@@ -68,7 +94,7 @@ func GetGroupByID(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		group, err := core.GetGroupByID(db, groupIDInt)
+		group, err := groupRepo.GetByID(groupIDInt)
 		if err != nil {
 			if strings.Contains(err.Error(), "not found") {
 				log.Printf("Группа с ID %d не найдена", groupIDInt)
@@ -96,7 +122,7 @@ func GetGroupByID(db *sql.DB) gin.HandlerFunc {
 // @Failure 400 {object} ErrorResponse "Неверный запрос"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/group [post]
-func CreateGroup(db *sql.DB) gin.HandlerFunc {
+func CreateGroup(groupRepo repository.GroupRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var group models.Group
 		if err := c.ShouldBindJSON(&group); err != nil {
@@ -112,7 +138,7 @@ func CreateGroup(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		groupID, err := core.CreateGroup(db, group)
+		groupID, err := groupRepo.Create(group)
 		if err != nil {
 			log.Printf("Ошибка при создании группы: %v", err)
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
@@ -139,7 +165,7 @@ func CreateGroup(db *sql.DB) gin.HandlerFunc {
 // @Failure 400 {object} ErrorResponse "Неверный запрос"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/group/{id} [put]
-func UpdateGroup(db *sql.DB) gin.HandlerFunc {
+func UpdateGroup(groupRepo repository.GroupRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		groupID := c.Param("id")
 		log.Printf("Получен запрос на обновление группы с ID: %s", groupID)
@@ -176,7 +202,7 @@ func UpdateGroup(db *sql.DB) gin.HandlerFunc {
 		// Устанавливаем ID группы из параметра запроса
 		group.ID = groupIDInt
 
-		if err := core.UpdateGroup(db, group); err != nil {
+		if err := groupRepo.Update(group); err != nil {
 			log.Printf("Ошибка при обновлении группы: %v", err)
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 			return
@@ -189,16 +215,18 @@ func UpdateGroup(db *sql.DB) gin.HandlerFunc {
 
 // DeleteGroup godoc
 // @Summary Удалить группу
-// @Description Удаляет группу по её ID
+// @Description Удаляет группу по её ID вместе с расписанием и занятиями. Группу, в которой когда-либо состояли студенты, удалить нельзя: её история групп нужна для прежних ведомостей
 // @Tags Groups
 // @Accept  json
 // @Produce  json
 // @Param   id  path  int  true  "ID группы"  example(1)
 // @Success 200 {object} SuccessResponse "Успешный ответ"
 // @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 404 {object} ErrorResponse "Группа не найдена"
+// @Failure 409 {object} InUseResponse "У группы есть история студентов"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/group/{id} [delete]
-func DeleteGroup(db *sql.DB) gin.HandlerFunc {
+func DeleteGroup(groupRepo repository.GroupRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		groupID := c.Param("id")
 		log.Printf("Получен запрос на удаление группы с ID: %s", groupID)
@@ -218,9 +246,17 @@ func DeleteGroup(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		if err := core.DeleteGroup(db, groupIDInt); err != nil {
+		if err := groupRepo.Delete(groupIDInt); err != nil {
 			log.Printf("Ошибка при удалении группы: %v", err)
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			var inUse *repository.InUseError
+			switch {
+			case errors.As(err, &inUse):
+				c.JSON(http.StatusConflict, InUseResponse{Error: err.Error(), Dependents: inUse.Dependents})
+			case strings.Contains(err.Error(), "not found"):
+				c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			}
 			return
 		}
 
@@ -228,3 +264,63 @@ func DeleteGroup(db *sql.DB) gin.HandlerFunc {
 		c.JSON(http.StatusOK, SuccessResponse{Message: "Group deleted successfully"})
 	}
 }
+
+// TransferStudent godoc
+// @Summary Перевести студента в группу
+// @Description Переводит студента в группу с указанного дня (по умолчанию — с сегодняшнего). Прежняя группа остаётся в истории до дня перевода, поэтому ведомости, статистика и журнал прежней группы по-прежнему включают оценки и отметки студента, поставленные до перевода.
+// @Description День перевода не может быть в будущем и должен быть позже начала текущей группы студента
+// @Tags Groups
+// @Accept  json
+// @Produce  json
+// @Param   id  path  int  true  "ID группы, в которую переводится студент"  example(2)
+// @Param   request  body  TransferRequest  true  "Студент и день перевода"
+// @Success 200 {object} models.GroupMembership "Новая запись истории групп студента"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет права users:manage"
+// @Failure 404 {object} ErrorResponse "Группа или студент не найдены"
+// @Failure 409 {object} ErrorResponse "Студент уже в этой группе"
+// @Failure 422 {object} ErrorResponse "Пользователь не студент или недопустимый день перевода"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/group/{id}/transfer [post]
+func TransferStudent(groupRepo repository.GroupRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID, err := strconv.Atoi(c.Param("id"))
+		if err != nil || groupID <= 0 {
+			log.Printf("Некорректный ID группы: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid group ID"})
+			return
+		}
+
+		var req TransferRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			log.Printf("Ошибка привязки JSON: %v", err)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if req.StudentID <= 0 {
+			log.Printf("Некорректный student_id: %d", req.StudentID)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "student_id must be positive"})
+			return
+		}
+		transfer := models.Transfer{StudentID: req.StudentID}
+		if req.Date != "" {
+			transfer.Date, err = time.Parse(config.DateLayout, req.Date)
+			if err != nil {
+				log.Printf("Некорректная дата перевода: %s", req.Date)
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "date must be in YYYY-MM-DD format"})
+				return
+			}
+		}
+
+		membership, err := groupRepo.Transfer(groupID, transfer)
+		if err != nil {
+			log.Printf("Ошибка при переводе студента %d в группу %d: %v", req.StudentID, groupID, err)
+			c.JSON(transferErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Студент %d переведён в группу %d с %s", req.StudentID, groupID, membership.ValidFrom.Format(config.DateLayout))
+		c.JSON(http.StatusOK, membership)
+	}
+}
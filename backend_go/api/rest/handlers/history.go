@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxChangeReason — длина колонки record_history.reason
+const maxChangeReason = 500
+
+// changeReason читает необязательную причину изменения из query-параметра reason; при ошибке отвечает 400
+func changeReason(c *gin.Context) (string, bool) {
+	reason := strings.TrimSpace(c.Query("reason"))
+	if utf8.RuneCountInString(reason) > maxChangeReason {
+		log.Printf("Слишком длинная причина изменения: %d символов", utf8.RuneCountInString(reason))
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "reason must be at most 500 characters"})
+		return "", false
+	}
+	return reason, true
+}
+
+// recordHistory отдаёт историю изменений записи recordType с ID из пути
+func recordHistory(c *gin.Context, historyRepo repository.HistoryRepository, recordType string) {
+	recordID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || recordID <= 0 {
+		log.Printf("Некорректный ID записи: %s", c.Param("id"))
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid ID"})
+		return
+	}
+
+	changes, err := historyRepo.Get(recordType, recordID)
+	if err != nil {
+		log.Printf("Ошибка при получении истории изменений: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if len(changes) == 0 {
+		log.Printf("История изменений записи %s %d не найдена", recordType, recordID)
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "history not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+// GetGradeHistory godoc
+// @Summary Получить историю изменений оценки
+// @Description Возвращает все изменения оценки от старых к новым: кто изменил, старое и новое значение, причину и время. История доступна и для удалённой оценки
+// @Tags Grades
+// @Accept  json
+// @Produce  json
+// @Param   id  path  int  true  "ID оценки"  example(1)
+// @Success 200 {array} models.RecordChange "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 404 {object} ErrorResponse "История не найдена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/grades/{id}/history [get]
+func GetGradeHistory(historyRepo repository.HistoryRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recordHistory(c, historyRepo, models.RecordGrade)
+	}
+}
+
+// GetAttendanceHistory godoc
+// @Summary Получить историю изменений посещаемости
+// @Description Возвращает все изменения записи о посещаемости от старых к новым: кто изменил, старое и новое значение, причину и время
+// @Tags Attendance
+// @Accept  json
+// @Produce  json
+// @Param   id  path  int  true  "ID посещаемости"  example(1)
+// @Success 200 {array} models.RecordChange "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 404 {object} ErrorResponse "История не найдена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/attendance/{id}/history [get]
+func GetAttendanceHistory(historyRepo repository.HistoryRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recordHistory(c, historyRepo, models.RecordAttendance)
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"github.com/VladislavSCV/internal/importer"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/VladislavSCV/internal/tabular"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// maxImportFile ограничивает размер файла импорта
+const maxImportFile = 10 << 20
+
+// ImportStudents godoc
+// @Summary Импортировать студентов из CSV или XLSX
+// @Description Создаёт студентов из файла: колонки name (или last_name, first_name, middle_name), group и login; русские заголовки (ФИО, Фамилия, Имя, Отчество, Группа, Логин) тоже подходят. Каждая строка проверяется, недостающие группы создаются, пароли генерируются и возвращаются в ответе один раз. Студенты и группы создаются все или ни одного: при ошибке хоть в одной строке ответ 422 со списком ошибок. dry_run=true показывает, что будет создано, ничего не сохраняя
+// @Tags Users
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param   file     formData  file    true   "Файл .csv (разделитель «,» или «;») или .xlsx (первый лист), до 10 МБ"
+// @Param   format   query     string  false  "Формат файла: csv или xlsx; по умолчанию по расширению"
+// @Param   dry_run  query     bool    false  "Только проверить и показать результат"
+// @Success 200 {object} models.ImportResult "Созданные (или, при dry_run, будущие) студенты и группы"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 422 {object} models.ImportResult "Ошибки в строках файла; ничего не сохранено"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/user/import [post]
+func ImportStudents(userRepo repository.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+		if err != nil {
+			log.Printf("Некорректный dry_run: %s", c.Query("dry_run"))
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "dry_run must be true or false"})
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFile+1<<20)
+		header, err := c.FormFile("file")
+		if err != nil {
+			log.Printf("Файл импорта не получен: %v", err)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "file must be uploaded as multipart form field \"file\""})
+			return
+		}
+		if header.Size > maxImportFile {
+			log.Printf("Слишком большой файл импорта: %d байт", header.Size)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "file must be at most 10 MB"})
+			return
+		}
+
+		format := c.DefaultQuery("format", tabular.Format(header.Filename))
+		if format != tabular.FormatCSV && format != tabular.FormatXLSX {
+			log.Printf("Неподдерживаемый формат импорта: %q (%s)", format, header.Filename)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "format must be csv or xlsx"})
+			return
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			log.Printf("Ошибка открытия файла импорта: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			log.Printf("Ошибка чтения файла импорта: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Получен запрос на импорт студентов из %s (%s, dry_run=%t)", header.Filename, format, dryRun)
+
+		result, err := importer.Students(userRepo, data, format, dryRun)
+		if err != nil {
+			log.Printf("Ошибка при импорте студентов: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if len(result.Errors) > 0 {
+			log.Printf("Импорт студентов отклонён: ошибок %d", len(result.Errors))
+			c.JSON(http.StatusUnprocessableEntity, result)
+			return
+		}
+
+		log.Printf("Импорт студентов: студентов %d, новых групп %d, сохранено: %t",
+			len(result.Students), len(result.CreatedGroups), result.Committed)
+		c.JSON(http.StatusOK, result)
+	}
+}
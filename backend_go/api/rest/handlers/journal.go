@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/VladislavSCV/internal/config"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxJournalEdits ограничивает число правок в одном сохранении журнала
+const maxJournalEdits = 1000
+
+// journalErrorStatus сопоставляет ошибки хранилища журнала с HTTP-статусами
+func journalErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, policy.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, repository.ErrGradeLocked):
+		return http.StatusConflict
+	case errors.Is(err, repository.ErrNotGroupMember), errors.Is(err, repository.ErrLessonMismatch):
+		return http.StatusUnprocessableEntity
+	case strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// parseJournalFilter разбирает группу, предмет и период журнала; без from или to недостающая граница
+// берётся из текущего учебного периода. При ошибке отвечает 400 или 404
+func parseJournalFilter(c *gin.Context, termRepo repository.TermRepository) (models.JournalFilter, bool) {
+	var filter models.JournalFilter
+	for name, target := range map[string]*int{"group_id": &filter.GroupID, "subject_id": &filter.SubjectID} {
+		value := c.Query(name)
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			log.Printf("Некорректный параметр %s: %s", name, value)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: name + " must be a positive integer"})
+			return models.JournalFilter{}, false
+		}
+		*target = n
+	}
+
+	if c.Query("from") == "" || c.Query("to") == "" {
+		term, ok := currentTerm(c, termRepo)
+		if !ok {
+			return models.JournalFilter{}, false
+		}
+		filter.From, filter.To = term.StartDate, term.EndDate
+	}
+	for name, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse(config.DateLayout, value)
+		if err != nil {
+			log.Printf("Некорректная дата %s: %s", name, value)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: name + " must be in YYYY-MM-DD format"})
+			return models.JournalFilter{}, false
+		}
+		*target = date
+	}
+	if filter.To.Before(filter.From) {
+		log.Printf("Некорректный период: %s - %s", filter.From.Format(config.DateLayout), filter.To.Format(config.DateLayout))
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "to must not be before from"})
+		return models.JournalFilter{}, false
+	}
+	if filter.To.Sub(filter.From) > maxLessonRange {
+		log.Printf("Слишком длинный период: %s - %s", filter.From.Format(config.DateLayout), filter.To.Format(config.DateLayout))
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "period must not exceed 366 days"})
+		return models.JournalFilter{}, false
+	}
+
+	return filter, true
+}
+
+// journalGradeEdit проверяет правку оценки из запроса и переводит её в models.JournalGradeEdit
+func journalGradeEdit(req JournalGradeEditRequest) (models.JournalGradeEdit, error) {
+	edit := models.JournalGradeEdit{
+		ID:          req.ID,
+		StudentID:   req.StudentID,
+		Value:       req.Value,
+		GradeTypeID: req.GradeTypeID,
+		Comment:     strings.TrimSpace(req.Comment),
+		Delete:      req.Delete,
+	}
+
+	if req.ID != nil && *req.ID <= 0 {
+		return edit, fmt.Errorf("id must be positive")
+	}
+	if req.Delete {
+		if req.ID == nil {
+			return edit, fmt.Errorf("id must be provided to delete a grade")
+		}
+		return edit, nil
+	}
+	if req.ID == nil {
+		if req.StudentID <= 0 {
+			return edit, fmt.Errorf("student_id must be positive")
+		}
+		date, err := time.Parse(config.DateLayout, req.Date)
+		if err != nil {
+			return edit, fmt.Errorf("date must be in YYYY-MM-DD format")
+		}
+		edit.Date = date
+	}
+	if req.Value < 2 || req.Value > 5 {
+		return edit, fmt.Errorf("value must be between 2 and 5")
+	}
+	if req.GradeTypeID != nil && *req.GradeTypeID <= 0 {
+		return edit, fmt.Errorf("grade_type_id must be positive")
+	}
+	if utf8.RuneCountInString(edit.Comment) > 500 {
+		return edit, fmt.Errorf("comment must be at most 500 characters")
+	}
+	return edit, nil
+}
+
+// journalAttendanceEdit проверяет правку посещаемости из запроса и переводит её в models.JournalAttendanceEdit
+func journalAttendanceEdit(req JournalAttendanceEditRequest) (models.JournalAttendanceEdit, error) {
+	edit := models.JournalAttendanceEdit{StudentID: req.StudentID, Status: req.Status}
+	if req.StudentID <= 0 {
+		return edit, fmt.Errorf("student_id must be positive")
+	}
+	date, err := time.Parse(config.DateLayout, req.Date)
+	if err != nil {
+		return edit, fmt.Errorf("date must be in YYYY-MM-DD format")
+	}
+	edit.Date = date
+	if req.Status != "present" && req.Status != "absent" && req.Status != "excused" {
+		return edit, repository.ErrInvalidAttendanceStatus
+	}
+	return edit, nil
+}
+
+// bindJournalSave разбирает и проверяет правки журнала; ошибка указывает на правку: grades[i] или attendance[i].
+// При ошибке отвечает 400
+func bindJournalSave(c *gin.Context) (models.JournalSave, bool) {
+	var req JournalSaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Printf("Ошибка привязки JSON: %v", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return models.JournalSave{}, false
+	}
+	if req.GroupID <= 0 {
+		log.Printf("Некорректный group_id: %d", req.GroupID)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "group_id must be positive"})
+		return models.JournalSave{}, false
+	}
+	if req.SubjectID <= 0 {
+		log.Printf("Некорректный subject_id: %d", req.SubjectID)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "subject_id must be positive"})
+		return models.JournalSave{}, false
+	}
+	edits := len(req.Grades) + len(req.Attendance)
+	if edits == 0 {
+		log.Printf("Пустое сохранение журнала")
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "grades or attendance must not be empty"})
+		return models.JournalSave{}, false
+	}
+	if edits > maxJournalEdits {
+		log.Printf("Слишком много правок журнала: %d", edits)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "at most 1000 edits are allowed per request"})
+		return models.JournalSave{}, false
+	}
+
+	save := models.JournalSave{GroupID: req.GroupID, SubjectID: req.SubjectID}
+	for i, gradeReq := range req.Grades {
+		edit, err := journalGradeEdit(gradeReq)
+		if err != nil {
+			log.Printf("Некорректная правка оценки %d: %v", i, err)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("grades[%d]: %v", i, err)})
+			return models.JournalSave{}, false
+		}
+		save.Grades = append(save.Grades, edit)
+	}
+	for i, attendanceReq := range req.Attendance {
+		edit, err := journalAttendanceEdit(attendanceReq)
+		if err != nil {
+			log.Printf("Некорректная правка посещаемости %d: %v", i, err)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("attendance[%d]: %v", i, err)})
+			return models.JournalSave{}, false
+		}
+		save.Attendance = append(save.Attendance, edit)
+	}
+	return save, true
+}
+
+// GetJournal godoc
+// @Summary Получить журнал группы по предмету
+// @Description Возвращает журнал за период: студенты группы по строкам, дни с занятиями, оценками или отметками по столбцам. В строке — ячейки по дням, средневзвешенный балл, число пропусков и уважительных пропусков за период. Без from или to недостающая граница берётся из учебного периода, в который попадает сегодняшний день (GET /api/terms/current); какие студенты и записи видны, решают права records:*
+// @Tags Journal
+// @Produce  json
+// @Param   group_id    query  int     true   "ID группы"
+// @Param   subject_id  query  int     true   "ID предмета"
+// @Param   from        query  string  false  "Первый день (ГГГГ-ММ-ДД)"
+// @Param   to          query  string  false  "Последний день (ГГГГ-ММ-ДД), не больше 366 дней от from"
+// @Success 200 {object} models.Journal "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 404 {object} ErrorResponse "Группа или предмет не найдены, либо период не задан, а сегодняшний день не попадает ни в один учебный период"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/journal [get]
+func GetJournal(journalRepo repository.JournalRepository, termRepo repository.TermRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, ok := parseJournalFilter(c, termRepo)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на получение журнала группы %d по предмету %d с %s по %s",
+			filter.GroupID, filter.SubjectID, filter.From.Format(config.DateLayout), filter.To.Format(config.DateLayout))
+
+		journal, err := journalRepo.Get(currentScope(c), filter)
+		if err != nil {
+			log.Printf("Ошибка при получении журнала: %v", err)
+			c.JSON(journalErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно получен журнал: студентов %d, дней %d", len(journal.Students), len(journal.Dates))
+		c.JSON(http.StatusOK, journal)
+	}
+}
+
+// SaveJournal godoc
+// @Summary Сохранить правки журнала
+// @Description Применяет правки многих ячеек журнала группы по предмету в одной транзакции: создаёт, меняет и удаляет оценки, ставит статусы посещаемости (меняя отметку студента за день или создавая новую). Если хоть одна правка не проходит, не сохраняется ни одна, а ошибка указывает на неё: grades[i] или attendance[i]
+// @Tags Journal
+// @Accept  json
+// @Produce  json
+// @Param   request  body   JournalSaveRequest  true   "Правки журнала"
+// @Param   reason   query  string              false  "Причина изменения, до 500 символов; сохраняется в истории"
+// @Success 200 {object} models.JournalSaveResult "ID созданных, изменённых и удалённых записей"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 404 {object} ErrorResponse "Группа, предмет или оценка не найдены"
+// @Failure 409 {object} ErrorResponse "Итоговая оценка за период закрыта; менять оценки можно только с правом grades:override"
+// @Failure 422 {object} ErrorResponse "Студент не состоит в группе или занятие не совпадает с записью"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/journal [put]
+func SaveJournal(journalRepo repository.JournalRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		save, ok := bindJournalSave(c)
+		if !ok {
+			return
+		}
+		reason, ok := changeReason(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на сохранение журнала группы %d по предмету %d: оценок %d, отметок %d",
+			save.GroupID, save.SubjectID, len(save.Grades), len(save.Attendance))
+
+		result, err := journalRepo.Save(currentScope(c), save, reason)
+		if err != nil {
+			log.Printf("Ошибка при сохранении журнала: %v", err)
+			c.JSON(journalErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Журнал сохранён: создано оценок %d, изменено %d, удалено %d; создано отметок %d, изменено %d",
+			len(result.CreatedGrades), len(result.UpdatedGrades), len(result.DeletedGrades),
+			len(result.CreatedAttendance), len(result.UpdatedAttendance))
+		c.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"github.com/VladislavSCV/internal/config"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLessonRange ограничивает период, на который занятия создаются одним запросом
+const maxLessonRange = 366 * 24 * time.Hour
+
+// parseLessonID разбирает ID занятия из пути; при ошибке отвечает 400
+func parseLessonID(c *gin.Context) (int, bool) {
+	lessonID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || lessonID <= 0 {
+		log.Printf("Некорректный ID занятия: %s", c.Param("id"))
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid lesson ID"})
+		return 0, false
+	}
+	return lessonID, true
+}
+
+// parseLessonFilter разбирает параметры выборки занятий; при ошибке отвечает 400
+func parseLessonFilter(c *gin.Context) (models.LessonFilter, bool) {
+	var filter models.LessonFilter
+
+	for name, target := range map[string]*int{
+		"group_id":   &filter.GroupID,
+		"teacher_id": &filter.TeacherID,
+		"period":     &filter.Period,
+	} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			log.Printf("Некорректный параметр %s: %s", name, value)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: name + " must be a positive integer"})
+			return models.LessonFilter{}, false
+		}
+		*target = n
+	}
+
+	for name, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse(config.DateLayout, value)
+		if err != nil {
+			log.Printf("Некорректная дата %s: %s", name, value)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: name + " must be in YYYY-MM-DD format"})
+			return models.LessonFilter{}, false
+		}
+		*target = date
+	}
+
+	return filter, true
+}
+
+// GetLessons godoc
+// @Summary Получить занятия
+// @Description Возвращает занятия по датам с номером пары у группы в этот день. Например, «вторая пара во вторник» — ?group_id=1&from=2024-09-03&to=2024-09-03&period=2
+// @Tags Lessons
+// @Produce  json
+// @Param   group_id    query  int     false  "ID группы"
+// @Param   teacher_id  query  int     false  "ID преподавателя"
+// @Param   period      query  int     false  "Номер пары"
+// @Param   from        query  string  false  "Первый день (ГГГГ-ММ-ДД)"
+// @Param   to          query  string  false  "Последний день (ГГГГ-ММ-ДД)"
+// @Success 200 {array} models.Lesson "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/lessons [get]
+func GetLessons(lessonRepo repository.LessonRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log.Printf("Получен запрос на получение занятий: %s", c.Request.URL.RawQuery)
+
+		filter, ok := parseLessonFilter(c)
+		if !ok {
+			return
+		}
+
+		lessons, err := lessonRepo.GetAll(filter)
+		if err != nil {
+			log.Printf("Ошибка при получении занятий: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно получено занятий: %d", len(lessons))
+		c.JSON(http.StatusOK, lessons)
+	}
+}
+
+// GetLessonByID godoc
+// @Summary Получить занятие по ID
+// @Description Возвращает занятие с номером пары
+// @Tags Lessons
+// @Produce  json
+// @Param   id  path  int  true  "ID занятия"  example(1)
+// @Success 200 {object} models.Lesson "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 404 {object} ErrorResponse "Занятие не найдено"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/lessons/{id} [get]
+func GetLessonByID(lessonRepo repository.LessonRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lessonID, ok := parseLessonID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на получение занятия с ID: %d", lessonID)
+
+		lesson, err := lessonRepo.GetByID(lessonID)
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				log.Printf("Занятие с ID %d не найдено", lessonID)
+				c.JSON(http.StatusNotFound, ErrorResponse{Error: "lesson not found"})
+			} else {
+				log.Printf("Ошибка при получении занятия: %v", err)
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, lesson)
+	}
+}
+
+// GenerateLessons godoc
+// @Summary Создать занятия по расписанию
+// @Description Создаёт занятия на каждый день периода по недельному расписанию. Без from или to недостающая граница берётся из учебного периода, в который попадает сегодняшний день (GET /api/terms/current); уже созданные занятия не меняются, поэтому запрос можно повторять
+// @Tags Lessons
+// @Accept  json
+// @Produce  json
+// @Param   request  body  GenerateLessonsRequest  true  "Период и группа"
+// @Success 200 {object} SuccessResponse "Число созданных занятий"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 404 {object} ErrorResponse "Период не задан, а сегодняшний день не попадает ни в один учебный период"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/lessons/generate [post]
+func GenerateLessons(lessonRepo repository.LessonRepository, termRepo repository.TermRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req GenerateLessonsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			log.Printf("Ошибка привязки JSON: %v", err)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if req.GroupID < 0 {
+			log.Printf("Некорректный group_id: %d", req.GroupID)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "group_id must not be negative"})
+			return
+		}
+
+		var from, to time.Time
+		if req.From == "" || req.To == "" {
+			term, ok := currentTerm(c, termRepo)
+			if !ok {
+				return
+			}
+			from, to = term.StartDate, term.EndDate
+		}
+		for name, field := range map[string]struct {
+			value  string
+			target *time.Time
+		}{"from": {req.From, &from}, "to": {req.To, &to}} {
+			if field.value == "" {
+				continue
+			}
+			date, err := time.Parse(config.DateLayout, field.value)
+			if err != nil {
+				log.Printf("Некорректная дата %s: %s", name, field.value)
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: name + " must be in YYYY-MM-DD format"})
+				return
+			}
+			*field.target = date
+		}
+		if to.Before(from) {
+			log.Printf("Некорректный период: %s - %s", from.Format(config.DateLayout), to.Format(config.DateLayout))
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "to must not be before from"})
+			return
+		}
+		if to.Sub(from) > maxLessonRange {
+			log.Printf("Слишком длинный период: %s - %s", from.Format(config.DateLayout), to.Format(config.DateLayout))
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "period must not exceed 366 days"})
+			return
+		}
+
+		log.Printf("Получен запрос на создание занятий с %s по %s (группа %d)",
+			from.Format(config.DateLayout), to.Format(config.DateLayout), req.GroupID)
+
+		created, err := lessonRepo.Generate(from, to, req.GroupID)
+		if err != nil {
+			log.Printf("Ошибка при создании занятий: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно создано занятий: %d", created)
+		c.JSON(http.StatusOK, SuccessResponse{
+			Message: "Lessons generated successfully",
+			Data: gin.H{
+				"created": created,
+				"from":    from.Format(config.DateLayout),
+				"to":      to.Format(config.DateLayout),
+			},
+		})
+	}
+}
+
+// GetLessonAttendance godoc
+// @Summary Получить посещаемость занятия
+// @Description Возвращает отметки, привязанные к занятию; какие записи видны, решают права records:*
+// @Tags Lessons
+// @Produce  json
+// @Param   id  path  int  true  "ID занятия"  example(1)
+// @Success 200 {array} models.AttendanceDetail "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 404 {object} ErrorResponse "Занятие не найдено"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/lessons/{id}/attendance [get]
+func GetLessonAttendance(lessonRepo repository.LessonRepository, attendanceRepo repository.AttendanceRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lessonID, ok := parseLessonID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на получение посещаемости занятия с ID: %d", lessonID)
+
+		if _, err := lessonRepo.GetByID(lessonID); err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				log.Printf("Занятие с ID %d не найдено", lessonID)
+				c.JSON(http.StatusNotFound, ErrorResponse{Error: "lesson not found"})
+			} else {
+				log.Printf("Ошибка при получении занятия: %v", err)
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			}
+			return
+		}
+
+		attendances, err := attendanceRepo.GetByLessonID(currentScope(c), lessonID)
+		if err != nil {
+			log.Printf("Ошибка при получении посещаемости: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно получена посещаемость занятия с ID: %d", lessonID)
+		c.JSON(http.StatusOK, attendances)
+	}
+}
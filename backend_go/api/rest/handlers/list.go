@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/VladislavSCV/internal/config"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Размер страницы списков по умолчанию и наибольший
+const (
+	defaultListLimit = 50
+	maxListLimit     = 1000
+)
+
+// encodeCursor и decodeCursor переводят курсор в непрозрачную строку для параметра cursor и обратно
+func encodeCursor(cursor models.Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (*models.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	var cursor models.Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// parseListQuery разбирает sort, limit, offset и cursor; fields — поля, по которым можно сортировать,
+// первое — сортировка по умолчанию. При ошибке отвечает 400
+func parseListQuery(c *gin.Context, fields []string) (models.ListQuery, bool) {
+	query := models.ListQuery{Limit: defaultListLimit}
+	fail := func(message string) (models.ListQuery, bool) {
+		log.Printf("Некорректные параметры списка (%s): %s", message, c.Request.URL.RawQuery)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: message})
+		return models.ListQuery{}, false
+	}
+
+	sort := c.Query("sort")
+	if sort != "" {
+		query.Sort, query.Desc = strings.TrimPrefix(sort, "-"), strings.HasPrefix(sort, "-")
+		known := false
+		for _, field := range fields {
+			known = known || field == query.Sort
+		}
+		if !known {
+			return fail(fmt.Sprintf("unknown sort field %q, available: %s", query.Sort, strings.Join(fields, ", ")))
+		}
+	}
+
+	for name, target := range map[string]*int{"limit": &query.Limit, "offset": &query.Offset} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || (name == "limit" && (n == 0 || n > maxListLimit)) {
+			return fail(fmt.Sprintf("limit must be between 1 and %d, offset must not be negative", maxListLimit))
+		}
+		*target = n
+	}
+
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := decodeCursor(value)
+		if err != nil {
+			return fail("invalid cursor")
+		}
+		if query.Offset != 0 {
+			return fail("cursor and offset cannot be used together")
+		}
+		// Курсор помнит сортировку, с которой был выдан; sort можно не повторять
+		if sort != "" && (cursor.Sort != query.Sort || cursor.Desc != query.Desc) {
+			return fail("cursor was issued for a different sort")
+		}
+		known := false
+		for _, field := range fields {
+			known = known || field == cursor.Sort
+		}
+		if !known {
+			return fail("invalid cursor")
+		}
+		query.Sort, query.Desc, query.After = cursor.Sort, cursor.Desc, cursor
+	}
+
+	if query.Sort == "" {
+		query.Sort = fields[0]
+	}
+	return query, true
+}
+
+// parseQueryIDs разбирает необязательные параметры-идентификаторы; при ошибке отвечает 400
+func parseQueryIDs(c *gin.Context, targets map[string]*int) bool {
+	for name, target := range targets {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			log.Printf("Некорректный параметр %s: %s", name, value)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: name + " must be a positive integer"})
+			return false
+		}
+		*target = n
+	}
+	return true
+}
+
+// parseRecordFilter дополняет filter параметрами subject_id, from, to и, для посещаемости, status;
+// при ошибке отвечает 400
+func parseRecordFilter(c *gin.Context, filter *models.RecordFilter, withStatus bool) bool {
+	if !parseQueryIDs(c, map[string]*int{"subject_id": &filter.SubjectID}) {
+		return false
+	}
+
+	for name, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse(config.DateLayout, value)
+		if err != nil {
+			log.Printf("Некорректная дата %s: %s", name, value)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: name + " must be in YYYY-MM-DD format"})
+			return false
+		}
+		*target = date
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		log.Printf("Некорректный период: %s - %s", c.Query("from"), c.Query("to"))
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "to must not be before from"})
+		return false
+	}
+	// to включает весь указанный день
+	if !filter.To.IsZero() {
+		filter.To = filter.To.AddDate(0, 0, 1)
+	}
+
+	if status := c.Query("status"); withStatus && status != "" {
+		if status != "present" && status != "absent" && status != "excused" {
+			log.Printf("Некорректный статус: %s", status)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "status must be present, absent or excused"})
+			return false
+		}
+		filter.Status = status
+	}
+	return true
+}
+
+// writeList отвечает страницей списка items
+func writeList(c *gin.Context, items interface{}, info models.PageInfo, query models.ListQuery) {
+	response := ListResponse{Items: items, Total: info.Total, Limit: query.Limit, Offset: query.Offset}
+	if info.Next != nil {
+		cursor := encodeCursor(*info.Next)
+		response.NextCursor = &cursor
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/VladislavSCV/internal/patch"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+)
+
+// readPatch разбирает тело PATCH/PUT-запроса по схеме сущности и возвращает изменившиеся поля.
+// При ошибке ответ уже отправлен и возвращается false.
+func readPatch(c *gin.Context, schema patch.Schema, current map[string]interface{}) (map[string]interface{}, bool) {
+	body, err := c.GetRawData()
+	if err != nil {
+		log.Printf("Ошибка чтения тела запроса: %v", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "failed to read request body"})
+		return nil, false
+	}
+
+	changes, err := schema.Apply(c.ContentType(), body, current)
+	if err != nil {
+		log.Printf("Ошибка применения патча: %v", err)
+
+		var verr *patch.ValidationError
+		switch {
+		case errors.As(err, &verr):
+			c.JSON(http.StatusUnprocessableEntity, PatchErrorResponse{
+				Error:           "invalid patch",
+				UnknownFields:   verr.Unknown,
+				ForbiddenFields: verr.Forbidden,
+				InvalidFields:   verr.Invalid,
+				Malformed:       verr.Malformed,
+			})
+		case errors.Is(err, patch.ErrTestFailed):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, patch.ErrUnsupportedMediaType):
+			c.JSON(http.StatusUnsupportedMediaType, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+		return nil, false
+	}
+
+	return changes, true
+}
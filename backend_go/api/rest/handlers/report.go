@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/VladislavSCV/internal/calendar"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/report"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// reportErrorStatus сопоставляет ошибки сборки документов с HTTP-статусами
+func reportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, policy.ErrForbidden):
+		return http.StatusForbidden
+	case strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// parseReportID разбирает ID студента или группы из пути; при ошибке отвечает 400
+func parseReportID(c *gin.Context, what string) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		log.Printf("Некорректный ID (%s): %s", what, c.Param("id"))
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid " + what + " ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// reportTermID берёт период из term_id, а без него — период, в который попадает сегодняшний день;
+// при ошибке отвечает 400 или 404
+func reportTermID(c *gin.Context, sources report.Sources) (int, bool) {
+	if value := c.Query("term_id"); value != "" {
+		termID, err := strconv.Atoi(value)
+		if err != nil || termID <= 0 {
+			log.Printf("Некорректный term_id: %s", value)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "term_id must be positive"})
+			return 0, false
+		}
+		return termID, true
+	}
+
+	term, err := sources.Terms.GetCurrent(calendar.Today(time.Now()))
+	if err != nil {
+		log.Printf("Ошибка при получении текущего периода: %v", err)
+		c.JSON(reportErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return 0, false
+	}
+	return term.ID, true
+}
+
+// writeReport отдаёт собранный файл; документ собирается целиком, чтобы при ошибке вернуть 500, а не обрезанный файл
+func writeReport(c *gin.Context, contentType, filename string, write func(*bytes.Buffer) error) {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		log.Printf("Ошибка формирования документа: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	log.Printf("Сформирован документ %s (%d байт)", filename, buf.Len())
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// GetReportCard godoc
+// @Summary Табель успеваемости студента (PDF)
+// @Description Формирует печатный табель за учебный период: шапка учебного заведения, средний балл и итоговая оценка по каждому предмету, пропуски и итоги посещаемости. Итоговая оценка, которую ещё не закрыли, помечается как предварительная. Преподаватель видит в табеле только свои предметы, студент — только свой табель
+// @Tags Reports
+// @Produce  application/pdf
+// @Param   id       path   int  true   "ID студента"  example(3)
+// @Param   term_id  query  int  false  "ID учебного периода; по умолчанию текущий"
+// @Success 200 {file} file "Табель в формате PDF"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 404 {object} ErrorResponse "Студент или период не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/reports/students/{id}/report-card [get]
+func GetReportCard(sources report.Sources) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		studentID, ok := parseReportID(c, "student")
+		if !ok {
+			return
+		}
+		termID, ok := reportTermID(c, sources)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на табель студента %d за период %d", studentID, termID)
+
+		card, err := sources.Card(currentScope(c), studentID, termID)
+		if err != nil {
+			log.Printf("Ошибка при сборке табеля: %v", err)
+			c.JSON(reportErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		writeReport(c, "application/pdf", fmt.Sprintf("report-card-%d-term-%d.pdf", studentID, termID), func(buf *bytes.Buffer) error {
+			return report.WriteCard(buf, card, time.Now())
+		})
+	}
+}
+
+// GetTranscript godoc
+// @Summary Справка об успеваемости студента (PDF)
+// @Description Формирует справку за всё обучение: по каждому учебному периоду, в котором есть записи, — средние баллы и итоговые оценки по предметам и посещаемость, в конце — средний итоговый балл и общая посещаемость
+// @Tags Reports
+// @Produce  application/pdf
+// @Param   id  path  int  true  "ID студента"  example(3)
+// @Success 200 {file} file "Справка в формате PDF"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 404 {object} ErrorResponse "Студент не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/reports/students/{id}/transcript [get]
+func GetTranscript(sources report.Sources) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		studentID, ok := parseReportID(c, "student")
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на справку об успеваемости студента %d", studentID)
+
+		transcript, err := sources.Transcript(currentScope(c), studentID)
+		if err != nil {
+			log.Printf("Ошибка при сборке справки: %v", err)
+			c.JSON(reportErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		writeReport(c, "application/pdf", fmt.Sprintf("transcript-%d.pdf", studentID), func(buf *bytes.Buffer) error {
+			return report.WriteTranscript(buf, transcript, time.Now())
+		})
+	}
+}
+
+// GetGroupReportCards godoc
+// @Summary Табели всей группы (ZIP)
+// @Description Формирует табели за учебный период для всех студентов группы, доступных пользователю, и отдаёт их ZIP-архивом: отдельный PDF на студента
+// @Tags Reports
+// @Produce  application/zip
+// @Param   id       path   int  true   "ID группы"  example(1)
+// @Param   term_id  query  int  false  "ID учебного периода; по умолчанию текущий"
+// @Success 200 {file} file "ZIP-архив с табелями"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 404 {object} ErrorResponse "Группа или период не найдены, либо в группе нет студентов"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/reports/groups/{id}/report-cards [get]
+func GetGroupReportCards(sources report.Sources) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID, ok := parseReportID(c, "group")
+		if !ok {
+			return
+		}
+		termID, ok := reportTermID(c, sources)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на табели группы %d за период %d", groupID, termID)
+
+		group, cards, err := sources.GroupCards(currentScope(c), groupID, termID)
+		if err != nil {
+			log.Printf("Ошибка при сборке табелей группы: %v", err)
+			c.JSON(reportErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+		if len(cards) == 0 {
+			log.Printf("В группе %s нет студентов", group)
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "group has no students"})
+			return
+		}
+
+		writeReport(c, "application/zip", fmt.Sprintf("report-cards-group-%d-term-%d.zip", groupID, termID), func(buf *bytes.Buffer) error {
+			return report.WriteCardsZip(buf, cards, time.Now())
+		})
+	}
+}
+
+// GetGroupTranscripts godoc
+// @Summary Справки об успеваемости всей группы (ZIP)
+// @Description Формирует справки за всё обучение для всех студентов группы, доступных пользователю, и отдаёт их ZIP-архивом: отдельный PDF на студента
+// @Tags Reports
+// @Produce  application/zip
+// @Param   id  path  int  true  "ID группы"  example(1)
+// @Success 200 {file} file "ZIP-архив со справками"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 403 {object} ErrorResponse "Нет доступа"
+// @Failure 404 {object} ErrorResponse "Группа не найдена или в ней нет студентов"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/reports/groups/{id}/transcripts [get]
+func GetGroupTranscripts(sources report.Sources) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID, ok := parseReportID(c, "group")
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на справки группы %d", groupID)
+
+		group, transcripts, err := sources.GroupTranscripts(currentScope(c), groupID)
+		if err != nil {
+			log.Printf("Ошибка при сборке справок группы: %v", err)
+			c.JSON(reportErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+		if len(transcripts) == 0 {
+			log.Printf("В группе %s нет студентов", group)
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "group has no students"})
+			return
+		}
+
+		writeReport(c, "application/zip", fmt.Sprintf("transcripts-group-%d.zip", groupID), func(buf *bytes.Buffer) error {
+			return report.WriteTranscriptsZip(buf, transcripts, time.Now())
+		})
+	}
+}
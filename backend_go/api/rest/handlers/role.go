@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// roleErrorStatus сопоставляет ошибки хранилища ролей с HTTP-статусами
+func roleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrRoleExists), errors.Is(err, repository.ErrRoleInUse), errors.Is(err, repository.ErrBuiltinRole):
+		return http.StatusConflict
+	case errors.Is(err, repository.ErrUnknownPermission):
+		return http.StatusUnprocessableEntity
+	case strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// bindRole разбирает и проверяет тело запроса на создание или изменение роли
+func bindRole(c *gin.Context) (models.Role, bool) {
+	var req RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Printf("Ошибка привязки JSON: %v", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return models.Role{}, false
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		log.Printf("Некорректное название роли")
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "name is required"})
+		return models.Role{}, false
+	}
+
+	return models.Role{Name: req.Name, Description: req.Description, Permissions: req.Permissions}, true
+}
+
+// GetRoles godoc
+// @Summary Получить список ролей
+// @Description Возвращает все роли вместе с их правами
+// @Tags Roles
+// @Produce  json
+// @Success 200 {array} models.Role "Успешный ответ"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет права roles:manage"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/roles [get]
+func GetRoles(roleRepo repository.RoleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log.Printf("Получен запрос на получение списка ролей")
+
+		roles, err := roleRepo.GetAll()
+		if err != nil {
+			log.Printf("Ошибка при получении списка ролей: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, roles)
+	}
+}
+
+// GetPermissions godoc
+// @Summary Получить каталог прав
+// @Description Возвращает все права, из которых собираются роли
+// @Tags Roles
+// @Produce  json
+// @Success 200 {array} models.Permission "Успешный ответ"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет права roles:manage"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/roles/permissions [get]
+func GetPermissions(roleRepo repository.RoleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log.Printf("Получен запрос на получение каталога прав")
+
+		permissions, err := roleRepo.GetPermissions()
+		if err != nil {
+			log.Printf("Ошибка при получении каталога прав: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, permissions)
+	}
+}
+
+// GetRoleByID godoc
+// @Summary Получить роль по ID
+// @Description Возвращает роль вместе с её правами
+// @Tags Roles
+// @Produce  json
+// @Param   id  path  int  true  "ID роли"  example(2)
+// @Success 200 {object} models.Role "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 404 {object} ErrorResponse "Роль не найдена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/roles/{id} [get]
+func GetRoleByID(roleRepo repository.RoleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roleID, err := strconv.Atoi(c.Param("id"))
+		if err != nil || roleID <= 0 {
+			log.Printf("Некорректный ID роли: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid role ID"})
+			return
+		}
+
+		role, err := roleRepo.GetByID(roleID)
+		if err != nil {
+			log.Printf("Ошибка при получении роли: %v", err)
+			c.JSON(roleErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, role)
+	}
+}
+
+// CreateRole godoc
+// @Summary Создать роль
+// @Description Создаёт роль с набором прав из каталога. Новые роли (куратор, родитель, завкафедрой) не требуют изменений в коде.
+// @Tags Roles
+// @Accept  json
+// @Produce  json
+// @Param   role  body  RoleRequest  true  "Роль"  example({"name": "curator", "description": "Куратор", "permissions": ["grades:read", "attendance:read", "records:all"]})
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 409 {object} ErrorResponse "Роль с таким названием уже есть"
+// @Failure 422 {object} ErrorResponse "Неизвестное право"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/roles [post]
+func CreateRole(roleRepo repository.RoleRepository, roles *policy.RoleCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, ok := bindRole(c)
+		if !ok {
+			return
+		}
+
+		roleID, err := roleRepo.Create(role)
+		if err != nil {
+			log.Printf("Ошибка при создании роли: %v", err)
+			c.JSON(roleErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+		roles.Invalidate()
+
+		log.Printf("Успешно создана роль с ID: %d", roleID)
+		c.JSON(http.StatusOK, SuccessResponse{
+			Message: "Role created successfully",
+			Data:    gin.H{"role_id": roleID},
+		})
+	}
+}
+
+// UpdateRole godoc
+// @Summary Обновить роль
+// @Description Меняет название и описание роли и заменяет набор её прав. Встроенные роли переименовать нельзя.
+// @Tags Roles
+// @Accept  json
+// @Produce  json
+// @Param   id  path  int  true  "ID роли"  example(4)
+// @Param   role  body  RoleRequest  true  "Роль"
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 404 {object} ErrorResponse "Роль не найдена"
+// @Failure 409 {object} ErrorResponse "Встроенная роль или занятое название"
+// @Failure 422 {object} ErrorResponse "Неизвестное право"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/roles/{id} [put]
+func UpdateRole(roleRepo repository.RoleRepository, roles *policy.RoleCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roleID, err := strconv.Atoi(c.Param("id"))
+		if err != nil || roleID <= 0 {
+			log.Printf("Некорректный ID роли: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid role ID"})
+			return
+		}
+
+		role, ok := bindRole(c)
+		if !ok {
+			return
+		}
+		role.ID = roleID
+
+		if err := roleRepo.Update(role); err != nil {
+			log.Printf("Ошибка при обновлении роли: %v", err)
+			c.JSON(roleErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+		roles.Invalidate()
+
+		log.Printf("Успешно обновлена роль с ID: %d", roleID)
+		c.JSON(http.StatusOK, SuccessResponse{Message: "Role updated successfully"})
+	}
+}
+
+// DeleteRole godoc
+// @Summary Удалить роль
+// @Description Удаляет роль, если она не встроенная и не назначена ни одному пользователю
+// @Tags Roles
+// @Produce  json
+// @Param   id  path  int  true  "ID роли"  example(4)
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 404 {object} ErrorResponse "Роль не найдена"
+// @Failure 409 {object} ErrorResponse "Встроенная роль или роль назначена пользователям"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/roles/{id} [delete]
+func DeleteRole(roleRepo repository.RoleRepository, roles *policy.RoleCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roleID, err := strconv.Atoi(c.Param("id"))
+		if err != nil || roleID <= 0 {
+			log.Printf("Некорректный ID роли: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid role ID"})
+			return
+		}
+
+		if err := roleRepo.Delete(roleID); err != nil {
+			log.Printf("Ошибка при удалении роли: %v", err)
+			c.JSON(roleErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+		roles.Invalidate()
+
+		log.Printf("Успешно удалена роль с ID: %d", roleID)
+		c.JSON(http.StatusOK, SuccessResponse{Message: "Role deleted successfully"})
+	}
+}
+
+// AssignRole godoc
+// @Summary Назначить роль пользователю
+// @Description Меняет роль пользователя и завершает все его сессии, чтобы старые токены с прежней ролью перестали действовать
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param   id  path  int  true  "ID пользователя"  example(3)
+// @Param   request  body  AssignRoleRequest  true  "Новая роль"
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 404 {object} ErrorResponse "Пользователь или роль не найдены"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/user/{id}/role [put]
+func AssignRole(roleRepo repository.RoleRepository, sessionRepo repository.SessionRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := strconv.Atoi(c.Param("id"))
+		if err != nil || userID <= 0 {
+			log.Printf("Некорректный ID пользователя: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user ID"})
+			return
+		}
+
+		var req AssignRoleRequest
+		if err := c.ShouldBindJSON(&req); err != nil || req.RoleID <= 0 {
+			log.Printf("Некорректный запрос на назначение роли: %v", err)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "role_id must be positive"})
+			return
+		}
+
+		if err := roleRepo.AssignToUser(userID, req.RoleID); err != nil {
+			log.Printf("Ошибка при назначении роли: %v", err)
+			c.JSON(roleErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+		if err := sessionRepo.RevokeAllForUser(userID); err != nil {
+			log.Printf("Ошибка при отзыве сессий пользователя %d: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Пользователю %d назначена роль %d", userID, req.RoleID)
+		c.JSON(http.StatusOK, SuccessResponse{Message: "Role assigned successfully"})
+	}
+}
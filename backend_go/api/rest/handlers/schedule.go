@@ -1,9 +1,11 @@
 package handlers
 
 import (
-	"database/sql"
-	"github.com/VladislavSCV/internal/core"
+	"errors"
+	"fmt"
 	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/patch"
+	"github.com/VladislavSCV/internal/repository"
 	"github.com/gin-gonic/gin"
 	"log"
 	"net/http"
@@ -11,44 +13,147 @@ import (
 	"strings"
 )
 
+// validScheduleInput проверяет поля нового занятия; при ошибке отвечает 400
+func validScheduleInput(c *gin.Context, schedule models.Schedule) bool {
+	if schedule.GroupID <= 0 {
+		log.Printf("Некорректный group_id: %d", schedule.GroupID)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "group_id must be positive"})
+		return false
+	}
+	if schedule.SubjectID <= 0 {
+		log.Printf("Некорректный subject_id: %d", schedule.SubjectID)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "subject_id must be positive"})
+		return false
+	}
+	if schedule.TeacherID <= 0 {
+		log.Printf("Некорректный teacher_id: %d", schedule.TeacherID)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "teacher_id must be positive"})
+		return false
+	}
+	if schedule.DayOfWeek < 1 || schedule.DayOfWeek > 7 {
+		log.Printf("Некорректный день недели: %d", schedule.DayOfWeek)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "day_of_week must be between 1 and 7"})
+		return false
+	}
+	if schedule.StartTime == "" || schedule.EndTime == "" {
+		log.Printf("Некорректное время начала или окончания занятия")
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "start_time and end_time must be provided"})
+		return false
+	}
+	if !patch.IsTime(schedule.StartTime) || !patch.IsTime(schedule.EndTime) {
+		log.Printf("Некорректный формат времени: %s - %s", schedule.StartTime, schedule.EndTime)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "start_time and end_time must be in HH:MM or HH:MM:SS format"})
+		return false
+	}
+	if schedule.Location == "" {
+		log.Printf("Некорректное местоположение")
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "location must be provided"})
+		return false
+	}
+	return true
+}
+
+// writeScheduleError отвечает на ошибку хранилища расписания: 409 со списком пересечений,
+// 400 на неверный интервал времени, 404 на отсутствующее занятие, иначе 500
+func writeScheduleError(c *gin.Context, err error) {
+	var conflict *repository.ScheduleConflictError
+	switch {
+	case errors.As(err, &conflict):
+		c.JSON(http.StatusConflict, ScheduleConflictResponse{Error: err.Error(), Conflicts: conflict.Conflicts})
+	case errors.Is(err, repository.ErrInvalidTimeRange):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "schedule not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
 // GetSchedules godoc
 // @Summary Получить общее расписание
-// @Description Возвращает список всех занятий
+// @Description Возвращает страницу занятий расписания. format=csv или format=xlsx выгружает все занятия под фильтрами файлом, без деления на страницы, по группе, дню недели и времени начала
 // @Tags Schedules
 // @Accept  json
 // @Produce  json
-// @Success 200 {array} models.Schedule "Успешный ответ"
+// @Produce  text/csv
+// @Produce  application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param   format   query  string  false  "Формат: json (по умолчанию), csv (UTF-8 с BOM, разделитель «;») или xlsx"
+// @Param   columns  query  string  false  "Колонки файла через запятую в нужном порядке: id, group_name, day_of_week, start_time, end_time, subject_name, teacher_name, location; по умолчанию все"
+// @Param   group_id     query  int  false  "ID группы"
+// @Param   subject_id   query  int  false  "ID предмета"
+// @Param   teacher_id   query  int  false  "ID преподавателя"
+// @Param   day_of_week  query  int  false  "День недели, 1–7"
+// @Param   sort     query  string  false  "Сортировка: day_of_week (по умолчанию), id, start_time, group_name, subject_name, teacher_name, location; «-» в начале — по убыванию"
+// @Param   limit    query  int     false  "Размер страницы, от 1 до 1000; по умолчанию 50"
+// @Param   offset   query  int     false  "Сколько записей пропустить"
+// @Param   cursor   query  string  false  "next_cursor предыдущей страницы; вместо offset"
+// @Success 200 {object} ListResponse{items=[]models.Schedule} "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/schedule [get]
-func GetSchedules(db *sql.DB) gin.HandlerFunc {
+func GetSchedules(scheduleRepo repository.ScheduleRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		log.Printf("Получен запрос на получение общего расписания")
 
-		schedules, err := core.GetAllSchedules(db)
+		var filter models.ScheduleFilter
+		if !parseQueryIDs(c, map[string]*int{
+			"group_id":    &filter.GroupID,
+			"subject_id":  &filter.SubjectID,
+			"teacher_id":  &filter.TeacherID,
+			"day_of_week": &filter.DayOfWeek,
+		}) {
+			return
+		}
+		if filter.DayOfWeek > 7 {
+			log.Printf("Некорректный день недели: %d", filter.DayOfWeek)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "day_of_week must be between 1 and 7"})
+			return
+		}
+		if exportRequested(c) {
+			export, ok := startExport(c, "schedule", scheduleExportColumns)
+			if !ok {
+				return
+			}
+			export.Finish(scheduleRepo.Export(filter, func(schedule models.Schedule) error {
+				return export.Row(scheduleExportRecord(schedule))
+			}))
+			return
+		}
+
+		query, ok := parseListQuery(c, models.ScheduleSortFields)
+		if !ok {
+			return
+		}
+
+		schedules, page, err := scheduleRepo.List(filter, query)
 		if err != nil {
 			log.Printf("Ошибка при получении расписания: %v", err)
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 			return
 		}
 
-		log.Printf("Успешно получено общее расписание")
-		c.JSON(http.StatusOK, schedules)
+		log.Printf("Успешно получено расписание: %d из %d", len(schedules), page.Total)
+		writeList(c, schedules, page, query)
 	}
 }
 
 // GetScheduleByID godoc
 // @Summary Получить расписание по ID группы/преподавателя
-// @Description Возвращает расписание для конкретной группы или преподавателя
+// @Description Возвращает расписание для конкретной группы или преподавателя; format=csv или format=xlsx выгружает его файлом
 // @Tags Schedules
 // @Accept  json
 // @Produce  json
+// @Produce  text/csv
+// @Produce  application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param   format   query  string  false  "Формат: json (по умолчанию), csv (UTF-8 с BOM, разделитель «;») или xlsx"
+// @Param   columns  query  string  false  "Колонки файла через запятую в нужном порядке: id, group_name, day_of_week, start_time, end_time, subject_name, teacher_name, location; по умолчанию все"
 // @Param   id  path  int  true  "ID группы или преподавателя"  example(1)
 // @Success 200 {array} models.Schedule "Успешный ответ"
 // @Failure 400 {object} ErrorResponse "Неверный запрос"
 // @Failure 404 {object} ErrorResponse "Расписание не найдено"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/schedule/{id} [get]
-func GetScheduleByID(db *sql.DB) gin.HandlerFunc {
+func GetScheduleByID(scheduleRepo repository.ScheduleRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		log.Printf("Получен запрос на получение расписания для ID: %s", id)
@@ -68,7 +173,18 @@ func GetScheduleByID(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		schedules, err := core.GetScheduleByID(db, idInt)
+		if exportRequested(c) {
+			export, ok := startExport(c, fmt.Sprintf("schedule-%d", idInt), scheduleExportColumns)
+			if !ok {
+				return
+			}
+			export.Finish(scheduleRepo.Export(models.ScheduleFilter{GroupID: idInt}, func(schedule models.Schedule) error {
+				return export.Row(scheduleExportRecord(schedule))
+			}))
+			return
+		}
+
+		schedules, err := scheduleRepo.GetByGroupID(idInt)
 		if err != nil {
 			if strings.Contains(err.Error(), "not found") {
 				log.Printf("Расписание для ID %d не найдено", idInt)
@@ -87,16 +203,17 @@ func GetScheduleByID(db *sql.DB) gin.HandlerFunc {
 
 // CreateSchedule godoc
 // @Summary Создать занятие
-// @Description Создаёт новое занятие в расписании
+// @Description Создаёт новое занятие в расписании. Занятие не должно пересекаться по времени с другими занятиями того же преподавателя, той же группы или в той же аудитории
 // @Tags Schedules
 // @Accept  json
 // @Produce  json
 // @Param   schedule  body  models.Schedule  true  "Данные о занятии"  example({"group_id": 1, "subject_id": 1, "teacher_id": 1, "day_of_week": 1, "start_time": "09:00", "end_time": "10:30", "location": "Room 101"})
 // @Success 200 {object} SuccessResponse "Успешный ответ"
-// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 400 {object} ErrorResponse "Неверный запрос или время окончания не позже времени начала"
+// @Failure 409 {object} ScheduleConflictResponse "Занятие пересекается с другими по преподавателю, группе или аудитории"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/schedule [post]
-func CreateSchedule(db *sql.DB) gin.HandlerFunc {
+func CreateSchedule(scheduleRepo repository.ScheduleRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var schedule models.Schedule
 		if err := c.ShouldBindJSON(&schedule); err != nil {
@@ -105,42 +222,14 @@ func CreateSchedule(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		// Валидация данных
-		if schedule.GroupID <= 0 {
-			log.Printf("Некорректный group_id: %d", schedule.GroupID)
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "group_id must be positive"})
-			return
-		}
-		if schedule.SubjectID <= 0 {
-			log.Printf("Некорректный subject_id: %d", schedule.SubjectID)
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "subject_id must be positive"})
-			return
-		}
-		if schedule.TeacherID <= 0 {
-			log.Printf("Некорректный teacher_id: %d", schedule.TeacherID)
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "teacher_id must be positive"})
-			return
-		}
-		if schedule.DayOfWeek < 1 || schedule.DayOfWeek > 7 {
-			log.Printf("Некорректный день недели: %d", schedule.DayOfWeek)
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "day_of_week must be between 1 and 7"})
-			return
-		}
-		if schedule.StartTime == "" || schedule.EndTime == "" {
-			log.Printf("Некорректное время начала или окончания занятия")
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "start_time and end_time must be provided"})
-			return
-		}
-		if schedule.Location == "" {
-			log.Printf("Некорректное местоположение")
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "location must be provided"})
+		if !validScheduleInput(c, schedule) {
 			return
 		}
 
-		scheduleID, err := core.CreateSchedule(db, schedule)
+		scheduleID, err := scheduleRepo.Create(schedule)
 		if err != nil {
 			log.Printf("Ошибка при создании занятия: %v", err)
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			writeScheduleError(c, err)
 			return
 		}
 
@@ -152,19 +241,64 @@ func CreateSchedule(db *sql.DB) gin.HandlerFunc {
 	}
 }
 
+// ValidateSchedule godoc
+// @Summary Проверить занятие без сохранения
+// @Description Проверяет занятие так же, как создание: поля, порядок времени начала и окончания и пересечения с другими занятиями того же преподавателя, группы или аудитории. Ничего не сохраняет. Чтобы проверить изменение существующего занятия, передайте его id — оно будет исключено из поиска пересечений.
+// @Tags Schedules
+// @Accept  json
+// @Produce  json
+// @Param   schedule  body  models.Schedule  true  "Данные о занятии"  example({"id": 0, "group_id": 1, "subject_id": 1, "teacher_id": 2, "day_of_week": 1, "start_time": "09:00", "end_time": "10:30", "location": "101"})
+// @Success 200 {object} SuccessResponse "Пересечений нет"
+// @Failure 400 {object} ErrorResponse "Неверный запрос или время окончания не позже времени начала"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет права schedule:manage"
+// @Failure 409 {object} ScheduleConflictResponse "Занятие пересекается с другими"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/schedule/validate [post]
+func ValidateSchedule(scheduleRepo repository.ScheduleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var schedule models.Schedule
+		if err := c.ShouldBindJSON(&schedule); err != nil {
+			log.Printf("Ошибка привязки JSON: %v", err)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		log.Printf("Получен запрос на проверку занятия (ID: %d)", schedule.ID)
+
+		if !validScheduleInput(c, schedule) {
+			return
+		}
+
+		if err := scheduleRepo.Validate(schedule); err != nil {
+			log.Printf("Занятие не прошло проверку: %v", err)
+			writeScheduleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, SuccessResponse{Message: "Schedule is valid"})
+	}
+}
+
 // UpdateSchedule godoc
 // @Summary Обновить занятие
-// @Description Обновляет информацию о существующем занятии
+// @Description Частично обновляет занятие. Принимает JSON Merge Patch (application/merge-patch+json или application/json) и JSON Patch (application/json-patch+json). Итоговое занятие проверяется на пересечения, как при создании.
 // @Tags Schedules
 // @Accept  json
+// @Accept  application/merge-patch+json
+// @Accept  application/json-patch+json
 // @Produce  json
 // @Param   id  path  int  true  "ID занятия"  example(1)
-// @Param   updates  body  map[string]interface{}  true  "Обновлённые данные о занятии"  example({"day_of_week": 2, "start_time": "10:00", "end_time": "11:30", "location": "Room 202"})
+// @Param   updates  body  map[string]interface{}  true  "Патч занятия"  example({"day_of_week": 2, "start_time": "10:00", "end_time": "11:30", "location": "Room 202"})
 // @Success 200 {object} SuccessResponse "Успешный ответ"
-// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 400 {object} ErrorResponse "Неверный запрос или время окончания не позже времени начала"
+// @Failure 404 {object} ErrorResponse "Занятие не найдено"
+// @Failure 409 {object} ScheduleConflictResponse "Не выполнена операция test из JSON Patch или занятие пересекается с другими"
+// @Failure 415 {object} ErrorResponse "Неподдерживаемый формат патча"
+// @Failure 422 {object} PatchErrorResponse "Неизвестные, запрещённые или некорректные поля"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/schedule/{id} [put]
-func UpdateSchedule(db *sql.DB) gin.HandlerFunc {
+// @Router /api/schedule/{id} [patch]
+func UpdateSchedule(scheduleRepo repository.ScheduleRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		log.Printf("Получен запрос на обновление занятия с ID: %s", id)
@@ -184,28 +318,29 @@ func UpdateSchedule(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		var updates map[string]interface{}
-		if err := c.ShouldBindJSON(&updates); err != nil {
-			log.Printf("Ошибка привязки JSON: %v", err)
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		schedule, err := scheduleRepo.GetByID(idInt)
+		if err != nil {
+			log.Printf("Ошибка при получении занятия: %v", err)
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, ErrorResponse{Error: "schedule not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			}
 			return
 		}
 
-		// Валидация дня недели, если он передан
-		if dayOfWeek, ok := updates["day_of_week"].(float64); ok {
-			if dayOfWeek < 1 || dayOfWeek > 7 {
-				log.Printf("Некорректный день недели: %v", dayOfWeek)
-				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "day_of_week must be between 1 and 7"})
-				return
-			}
+		updates, ok := readPatch(c, patch.ScheduleSchema, patch.ScheduleDocument(*schedule))
+		if !ok {
+			return
+		}
+		if len(updates) == 0 {
+			c.JSON(http.StatusOK, SuccessResponse{Message: "No changes"})
+			return
 		}
 
-		// Устанавливаем ID занятия из параметра запроса
-		updates["id"] = idInt
-
-		if err := core.UpdateSchedule(db, updates); err != nil {
+		if err := scheduleRepo.Update(idInt, updates); err != nil {
 			log.Printf("Ошибка при обновлении занятия: %v", err)
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			writeScheduleError(c, err)
 			return
 		}
 
@@ -225,7 +360,7 @@ func UpdateSchedule(db *sql.DB) gin.HandlerFunc {
 // @Failure 400 {object} ErrorResponse "Неверный запрос"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/schedule/{id} [delete]
-func DeleteSchedule(db *sql.DB) gin.HandlerFunc {
+func DeleteSchedule(scheduleRepo repository.ScheduleRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		log.Printf("Получен запрос на удаление занятия с ID: %s", id)
@@ -245,7 +380,7 @@ func DeleteSchedule(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		if err := core.DeleteSchedule(db, idInt); err != nil {
+		if err := scheduleRepo.Delete(idInt); err != nil {
 			log.Printf("Ошибка при удалении занятия: %v", err)
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 			return
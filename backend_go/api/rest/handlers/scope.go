@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/gin-gonic/gin"
+)
+
+// currentScope возвращает scope, который AuthMiddleware положил в контекст.
+// Без него хранилища ничего не отдают: пустой Scope не совпадает ни с одной ролью.
+func currentScope(c *gin.Context) policy.Scope {
+	if value, exists := c.Get("scope"); exists {
+		if scope, ok := value.(policy.Scope); ok {
+			return scope
+		}
+	}
+	return policy.Scope{}
+}
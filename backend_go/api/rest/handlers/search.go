@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/VladislavSCV/internal/search"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Ограничения поискового запроса
+const (
+	minSearchLength    = 2
+	maxSearchLength    = 100
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+// Search godoc
+// @Summary Поиск
+// @Description Ищет пользователей по ФИО и логину, группы и предметы по названию. Запрос без учёта регистра находит подстроки и слова с опечатками, а также записи в другой раскладке письма: «ivanov» находит «Иванов», «студент» — логин student1, а набранное не в той раскладке «bdfyjd» — тоже «Иванов». Результаты сгруппированы по типам, в каждом — от лучших совпадений к худшим. Пользователи видны те же, что и в GET /api/user/:id: студенту — только он сам, преподавателю — он и студенты его групп
+// @Tags Search
+// @Produce  json
+// @Param   q      query  string  true   "Текст запроса, от 2 до 100 символов"
+// @Param   types  query  string  false  "Где искать через запятую: users, groups, subjects (по умолчанию — везде)"
+// @Param   limit  query  int     false  "Максимум результатов каждого типа, до 50 (по умолчанию 10)"
+// @Success 200 {object} models.SearchResult "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/search [get]
+func Search(searchRepo repository.SearchRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		text := search.Normalize(c.Query("q"))
+		if length := utf8.RuneCountInString(text); length < minSearchLength || length > maxSearchLength {
+			log.Printf("Некорректная длина поискового запроса: %q", text)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "q must be from 2 to 100 characters long"})
+			return
+		}
+		query := models.SearchQuery{Variants: search.Variants(text), Types: models.SearchTypes, Limit: defaultSearchLimit}
+
+		if value := c.Query("types"); value != "" {
+			query.Types = nil
+			for _, name := range strings.Split(value, ",") {
+				name = strings.TrimSpace(name)
+				if !slices.Contains(models.SearchTypes, name) {
+					log.Printf("Неизвестный тип поиска: %s", name)
+					c.JSON(http.StatusBadRequest, ErrorResponse{Error: "types must be a comma-separated list of users, groups, subjects"})
+					return
+				}
+				query.Types = append(query.Types, name)
+			}
+		}
+
+		if value := c.Query("limit"); value != "" {
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit <= 0 || limit > maxSearchLimit {
+				log.Printf("Некорректный limit поиска: %s", value)
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "limit must be a positive integer up to 50"})
+				return
+			}
+			query.Limit = limit
+		}
+
+		result, err := searchRepo.Search(currentScope(c), query)
+		if err != nil {
+			log.Printf("Ошибка поиска %q: %v", text, err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Поиск %q: пользователей %d, групп %d, предметов %d", text, len(result.Users), len(result.Groups), len(result.Subjects))
+		c.JSON(http.StatusOK, result)
+	}
+}
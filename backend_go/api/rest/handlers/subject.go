@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// subjectErrorStatus сопоставляет ошибки хранилища предметов с HTTP-статусами
+func subjectErrorStatus(err error) int {
+	var inUse *repository.InUseError
+	switch {
+	case errors.Is(err, repository.ErrSubjectExists), errors.As(err, &inUse):
+		return http.StatusConflict
+	case strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// parseSubjectID разбирает ID предмета из пути; при ошибке отвечает 400
+func parseSubjectID(c *gin.Context) (int, bool) {
+	subjectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || subjectID <= 0 {
+		log.Printf("Некорректный ID предмета: %s", c.Param("id"))
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid subject ID"})
+		return 0, false
+	}
+	return subjectID, true
+}
+
+// bindSubject разбирает и проверяет тело запроса на создание или изменение предмета
+func bindSubject(c *gin.Context) (models.Subject, bool) {
+	var subject models.Subject
+	if err := c.ShouldBindJSON(&subject); err != nil {
+		log.Printf("Ошибка привязки JSON: %v", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return models.Subject{}, false
+	}
+
+	subject.Name = strings.TrimSpace(subject.Name)
+	if subject.Name == "" {
+		log.Printf("Некорректное название предмета")
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "subject name is required"})
+		return models.Subject{}, false
+	}
+	if len([]rune(subject.Name)) > 150 {
+		log.Printf("Слишком длинное название предмета: %s", subject.Name)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "subject name must be at most 150 characters"})
+		return models.Subject{}, false
+	}
+
+	return models.Subject{Name: subject.Name}, true
+}
+
+// GetSubjects godoc
+// @Summary Получить список предметов
+// @Description Возвращает все предметы, отсортированные по названию
+// @Tags Subjects
+// @Produce  json
+// @Success 200 {array} models.Subject "Успешный ответ"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/subjects [get]
+func GetSubjects(subjectRepo repository.SubjectRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log.Printf("Получен запрос на получение списка предметов")
+
+		subjects, err := subjectRepo.GetAll()
+		if err != nil {
+			log.Printf("Ошибка при получении списка предметов: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно получен список предметов")
+		c.JSON(http.StatusOK, subjects)
+	}
+}
+
+// GetSubjectByID godoc
+// @Summary Получить предмет по ID
+// @Description Возвращает информацию о предмете
+// @Tags Subjects
+// @Produce  json
+// @Param   id  path  int  true  "ID предмета"  example(1)
+// @Success 200 {object} models.Subject "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 404 {object} ErrorResponse "Предмет не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/subjects/{id} [get]
+func GetSubjectByID(subjectRepo repository.SubjectRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subjectID, ok := parseSubjectID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на получение предмета с ID: %d", subjectID)
+
+		subject, err := subjectRepo.GetByID(subjectID)
+		if err != nil {
+			log.Printf("Ошибка при получении предмета: %v", err)
+			c.JSON(subjectErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно получен предмет с ID: %d", subjectID)
+		c.JSON(http.StatusOK, subject)
+	}
+}
+
+// CreateSubject godoc
+// @Summary Создать предмет
+// @Description Создаёт предмет; название уникально без учёта регистра
+// @Tags Subjects
+// @Accept  json
+// @Produce  json
+// @Param   subject  body  models.Subject  true  "Данные о предмете"  example({"name": "Математика"})
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет права subjects:manage"
+// @Failure 409 {object} ErrorResponse "Предмет с таким названием уже существует"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/subjects [post]
+func CreateSubject(subjectRepo repository.SubjectRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subject, ok := bindSubject(c)
+		if !ok {
+			return
+		}
+
+		subjectID, err := subjectRepo.Create(subject)
+		if err != nil {
+			log.Printf("Ошибка при создании предмета: %v", err)
+			c.JSON(subjectErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно создан предмет с ID: %d", subjectID)
+		c.JSON(http.StatusOK, SuccessResponse{
+			Message: "Subject created successfully",
+			Data:    gin.H{"subject_id": subjectID},
+		})
+	}
+}
+
+// UpdateSubject godoc
+// @Summary Переименовать предмет
+// @Description Изменяет название предмета; название уникально без учёта регистра
+// @Tags Subjects
+// @Accept  json
+// @Produce  json
+// @Param   id  path  int  true  "ID предмета"  example(1)
+// @Param   subject  body  models.Subject  true  "Новые данные о предмете"  example({"name": "Высшая математика"})
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет права subjects:manage"
+// @Failure 404 {object} ErrorResponse "Предмет не найден"
+// @Failure 409 {object} ErrorResponse "Предмет с таким названием уже существует"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/subjects/{id} [put]
+func UpdateSubject(subjectRepo repository.SubjectRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subjectID, ok := parseSubjectID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на обновление предмета с ID: %d", subjectID)
+
+		subject, ok := bindSubject(c)
+		if !ok {
+			return
+		}
+		subject.ID = subjectID
+
+		if err := subjectRepo.Update(subject); err != nil {
+			log.Printf("Ошибка при обновлении предмета: %v", err)
+			c.JSON(subjectErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно обновлён предмет с ID: %d", subjectID)
+		c.JSON(http.StatusOK, SuccessResponse{Message: "Subject updated successfully"})
+	}
+}
+
+// DeleteSubject godoc
+// @Summary Удалить предмет
+// @Description Удаляет предмет. Если на него ссылаются занятия, оценки или посещаемость, возвращает 409 с их количеством
+// @Tags Subjects
+// @Produce  json
+// @Param   id  path  int  true  "ID предмета"  example(1)
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет права subjects:manage"
+// @Failure 404 {object} ErrorResponse "Предмет не найден"
+// @Failure 409 {object} InUseResponse "Предмет используется"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/subjects/{id} [delete]
+func DeleteSubject(subjectRepo repository.SubjectRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subjectID, ok := parseSubjectID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на удаление предмета с ID: %d", subjectID)
+
+		if err := subjectRepo.Delete(subjectID); err != nil {
+			log.Printf("Ошибка при удалении предмета: %v", err)
+			var inUse *repository.InUseError
+			if errors.As(err, &inUse) {
+				c.JSON(http.StatusConflict, InUseResponse{Error: err.Error(), Dependents: inUse.Dependents})
+				return
+			}
+			c.JSON(subjectErrorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно удалён предмет с ID: %d", subjectID)
+		c.JSON(http.StatusOK, SuccessResponse{Message: "Subject deleted successfully"})
+	}
+}
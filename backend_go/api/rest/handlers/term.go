@@ -0,0 +1,532 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/VladislavSCV/internal/calendar"
+	"github.com/VladislavSCV/internal/config"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// termErrorStatus сопоставляет ошибки хранилища учебных годов и периодов с HTTP-статусами
+func termErrorStatus(err error) int {
+	var inUse *repository.InUseError
+	switch {
+	case errors.Is(err, repository.ErrAcademicYearExists), errors.Is(err, repository.ErrTermExists),
+		errors.Is(err, repository.ErrTermOverlap), errors.As(err, &inUse):
+		return http.StatusConflict
+	case errors.Is(err, repository.ErrInvalidDateRange):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrTermOutsideYear):
+		return http.StatusUnprocessableEntity
+	case strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondTermError отвечает на ошибку хранилища; для *repository.InUseError — со списком ссылок
+func respondTermError(c *gin.Context, err error) {
+	var inUse *repository.InUseError
+	if errors.As(err, &inUse) {
+		c.JSON(http.StatusConflict, InUseResponse{Error: err.Error(), Dependents: inUse.Dependents})
+		return
+	}
+	c.JSON(termErrorStatus(err), ErrorResponse{Error: err.Error()})
+}
+
+// currentTerm возвращает период, в который попадает сегодняшний день в часовом поясе занятий;
+// при ошибке отвечает 404 или 500
+func currentTerm(c *gin.Context, termRepo repository.TermRepository) (*models.Term, bool) {
+	term, err := termRepo.GetCurrent(calendar.Today(time.Now()))
+	if err != nil {
+		log.Printf("Ошибка при получении текущего учебного периода: %v", err)
+		respondTermError(c, err)
+		return nil, false
+	}
+	return term, true
+}
+
+// parseAcademicYearID разбирает ID учебного года из пути; при ошибке отвечает 400
+func parseAcademicYearID(c *gin.Context) (int, bool) {
+	yearID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || yearID <= 0 {
+		log.Printf("Некорректный ID учебного года: %s", c.Param("id"))
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid academic year ID"})
+		return 0, false
+	}
+	return yearID, true
+}
+
+// parseTermID разбирает ID учебного периода из пути; при ошибке отвечает 400
+func parseTermID(c *gin.Context) (int, bool) {
+	termID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || termID <= 0 {
+		log.Printf("Некорректный ID учебного периода: %s", c.Param("id"))
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid term ID"})
+		return 0, false
+	}
+	return termID, true
+}
+
+// parseDateRange разбирает границы периода в формате ГГГГ-ММ-ДД; при ошибке отвечает 400
+func parseDateRange(c *gin.Context, startDate, endDate string) (time.Time, time.Time, bool) {
+	start, err := time.Parse(config.DateLayout, startDate)
+	if err != nil {
+		log.Printf("Некорректная дата start_date: %s", startDate)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "start_date must be in YYYY-MM-DD format"})
+		return time.Time{}, time.Time{}, false
+	}
+	end, err := time.Parse(config.DateLayout, endDate)
+	if err != nil {
+		log.Printf("Некорректная дата end_date: %s", endDate)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "end_date must be in YYYY-MM-DD format"})
+		return time.Time{}, time.Time{}, false
+	}
+	return start, end, true
+}
+
+// validTermName проверяет название года или периода: непустое, не длиннее колонки VARCHAR(50)
+func validTermName(c *gin.Context, name string) bool {
+	if name == "" || len([]rune(name)) > 50 {
+		log.Printf("Некорректное название: %s", name)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "name is required and must be at most 50 characters"})
+		return false
+	}
+	return true
+}
+
+// bindAcademicYear разбирает и проверяет тело запроса на создание или изменение учебного года
+func bindAcademicYear(c *gin.Context) (models.AcademicYear, bool) {
+	var req AcademicYearRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Printf("Ошибка привязки JSON: %v", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return models.AcademicYear{}, false
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if !validTermName(c, req.Name) {
+		return models.AcademicYear{}, false
+	}
+	start, end, ok := parseDateRange(c, req.StartDate, req.EndDate)
+	if !ok {
+		return models.AcademicYear{}, false
+	}
+
+	return models.AcademicYear{Name: req.Name, StartDate: start, EndDate: end}, true
+}
+
+// bindTerm разбирает и проверяет тело запроса на создание или изменение учебного периода
+func bindTerm(c *gin.Context) (models.Term, bool) {
+	var req TermRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Printf("Ошибка привязки JSON: %v", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return models.Term{}, false
+	}
+
+	if req.AcademicYearID <= 0 {
+		log.Printf("Некорректный academic_year_id: %d", req.AcademicYearID)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "academic_year_id must be positive"})
+		return models.Term{}, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if !validTermName(c, req.Name) {
+		return models.Term{}, false
+	}
+	start, end, ok := parseDateRange(c, req.StartDate, req.EndDate)
+	if !ok {
+		return models.Term{}, false
+	}
+
+	return models.Term{AcademicYearID: req.AcademicYearID, Name: req.Name, StartDate: start, EndDate: end}, true
+}
+
+// GetAcademicYears godoc
+// @Summary Получить учебные годы
+// @Description Возвращает учебные годы по дате начала
+// @Tags Terms
+// @Produce  json
+// @Success 200 {array} models.AcademicYear "Успешный ответ"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/academic-years [get]
+func GetAcademicYears(termRepo repository.TermRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log.Printf("Получен запрос на получение учебных годов")
+
+		years, err := termRepo.GetYears()
+		if err != nil {
+			log.Printf("Ошибка при получении учебных годов: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно получен список учебных годов")
+		c.JSON(http.StatusOK, years)
+	}
+}
+
+// GetAcademicYearByID godoc
+// @Summary Получить учебный год по ID
+// @Description Возвращает учебный год
+// @Tags Terms
+// @Produce  json
+// @Param   id  path  int  true  "ID учебного года"  example(1)
+// @Success 200 {object} models.AcademicYear "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 404 {object} ErrorResponse "Учебный год не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/academic-years/{id} [get]
+func GetAcademicYearByID(termRepo repository.TermRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		yearID, ok := parseAcademicYearID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на получение учебного года с ID: %d", yearID)
+
+		year, err := termRepo.GetYearByID(yearID)
+		if err != nil {
+			log.Printf("Ошибка при получении учебного года: %v", err)
+			respondTermError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, year)
+	}
+}
+
+// CreateAcademicYear godoc
+// @Summary Создать учебный год
+// @Description Создаёт учебный год; название уникально
+// @Tags Terms
+// @Accept  json
+// @Produce  json
+// @Param   year  body  AcademicYearRequest  true  "Учебный год"
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос или end_date не позже start_date"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет права terms:manage"
+// @Failure 409 {object} ErrorResponse "Учебный год с таким названием уже существует"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/academic-years [post]
+func CreateAcademicYear(termRepo repository.TermRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		year, ok := bindAcademicYear(c)
+		if !ok {
+			return
+		}
+
+		yearID, err := termRepo.CreateYear(year)
+		if err != nil {
+			log.Printf("Ошибка при создании учебного года: %v", err)
+			respondTermError(c, err)
+			return
+		}
+
+		log.Printf("Успешно создан учебный год с ID: %d", yearID)
+		c.JSON(http.StatusOK, SuccessResponse{
+			Message: "Academic year created successfully",
+			Data:    gin.H{"academic_year_id": yearID},
+		})
+	}
+}
+
+// UpdateAcademicYear godoc
+// @Summary Изменить учебный год
+// @Description Изменяет название и границы года; новые границы должны вмещать все периоды года
+// @Tags Terms
+// @Accept  json
+// @Produce  json
+// @Param   id    path  int                  true  "ID учебного года"  example(1)
+// @Param   year  body  AcademicYearRequest  true  "Новые данные"
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос или end_date не позже start_date"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет права terms:manage"
+// @Failure 404 {object} ErrorResponse "Учебный год не найден"
+// @Failure 409 {object} ErrorResponse "Учебный год с таким названием уже существует"
+// @Failure 422 {object} ErrorResponse "Периоды года выходят за новые границы"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/academic-years/{id} [put]
+func UpdateAcademicYear(termRepo repository.TermRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		yearID, ok := parseAcademicYearID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на обновление учебного года с ID: %d", yearID)
+
+		year, ok := bindAcademicYear(c)
+		if !ok {
+			return
+		}
+		year.ID = yearID
+
+		if err := termRepo.UpdateYear(year); err != nil {
+			log.Printf("Ошибка при обновлении учебного года: %v", err)
+			respondTermError(c, err)
+			return
+		}
+
+		log.Printf("Успешно обновлён учебный год с ID: %d", yearID)
+		c.JSON(http.StatusOK, SuccessResponse{Message: "Academic year updated successfully"})
+	}
+}
+
+// DeleteAcademicYear godoc
+// @Summary Удалить учебный год
+// @Description Удаляет учебный год. Если в нём есть периоды, возвращает 409 с их количеством
+// @Tags Terms
+// @Produce  json
+// @Param   id  path  int  true  "ID учебного года"  example(1)
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет права terms:manage"
+// @Failure 404 {object} ErrorResponse "Учебный год не найден"
+// @Failure 409 {object} InUseResponse "В учебном году есть периоды"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/academic-years/{id} [delete]
+func DeleteAcademicYear(termRepo repository.TermRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		yearID, ok := parseAcademicYearID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на удаление учебного года с ID: %d", yearID)
+
+		if err := termRepo.DeleteYear(yearID); err != nil {
+			log.Printf("Ошибка при удалении учебного года: %v", err)
+			respondTermError(c, err)
+			return
+		}
+
+		log.Printf("Успешно удалён учебный год с ID: %d", yearID)
+		c.JSON(http.StatusOK, SuccessResponse{Message: "Academic year deleted successfully"})
+	}
+}
+
+// GetTerms godoc
+// @Summary Получить учебные периоды
+// @Description Возвращает периоды по дате начала, все или одного учебного года
+// @Tags Terms
+// @Produce  json
+// @Param   academic_year_id  query  int  false  "ID учебного года"
+// @Success 200 {array} models.Term "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/terms [get]
+func GetTerms(termRepo repository.TermRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log.Printf("Получен запрос на получение учебных периодов: %s", c.Request.URL.RawQuery)
+
+		var yearID int
+		if value := c.Query("academic_year_id"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				log.Printf("Некорректный параметр academic_year_id: %s", value)
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "academic_year_id must be a positive integer"})
+				return
+			}
+			yearID = n
+		}
+
+		terms, err := termRepo.GetAll(yearID)
+		if err != nil {
+			log.Printf("Ошибка при получении учебных периодов: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Успешно получено учебных периодов: %d", len(terms))
+		c.JSON(http.StatusOK, terms)
+	}
+}
+
+// GetCurrentTerm godoc
+// @Summary Получить текущий учебный период
+// @Description Возвращает период, в который попадает день date; без date — сегодняшний день в часовом поясе занятий
+// @Tags Terms
+// @Produce  json
+// @Param   date  query  string  false  "День (ГГГГ-ММ-ДД)"
+// @Success 200 {object} models.Term "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 404 {object} ErrorResponse "День не попадает ни в один период"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/terms/current [get]
+func GetCurrentTerm(termRepo repository.TermRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		date := calendar.Today(time.Now())
+		if value := c.Query("date"); value != "" {
+			parsed, err := time.Parse(config.DateLayout, value)
+			if err != nil {
+				log.Printf("Некорректная дата date: %s", value)
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "date must be in YYYY-MM-DD format"})
+				return
+			}
+			date = parsed
+		}
+		log.Printf("Получен запрос на получение учебного периода на %s", date.Format(config.DateLayout))
+
+		term, err := termRepo.GetCurrent(date)
+		if err != nil {
+			log.Printf("Ошибка при получении учебного периода: %v", err)
+			respondTermError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, term)
+	}
+}
+
+// GetTermByID godoc
+// @Summary Получить учебный период по ID
+// @Description Возвращает учебный период с названием года
+// @Tags Terms
+// @Produce  json
+// @Param   id  path  int  true  "ID периода"  example(1)
+// @Success 200 {object} models.Term "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 404 {object} ErrorResponse "Период не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/terms/{id} [get]
+func GetTermByID(termRepo repository.TermRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		termID, ok := parseTermID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на получение учебного периода с ID: %d", termID)
+
+		term, err := termRepo.GetByID(termID)
+		if err != nil {
+			log.Printf("Ошибка при получении учебного периода: %v", err)
+			respondTermError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, term)
+	}
+}
+
+// CreateTerm godoc
+// @Summary Создать учебный период
+// @Description Создаёт период внутри учебного года; периоды одного года не пересекаются, названия в году уникальны
+// @Tags Terms
+// @Accept  json
+// @Produce  json
+// @Param   term  body  TermRequest  true  "Учебный период"
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос или end_date не позже start_date"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет права terms:manage"
+// @Failure 404 {object} ErrorResponse "Учебный год не найден"
+// @Failure 409 {object} ErrorResponse "Название занято или период пересекается с другим"
+// @Failure 422 {object} ErrorResponse "Период выходит за границы учебного года"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/terms [post]
+func CreateTerm(termRepo repository.TermRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		term, ok := bindTerm(c)
+		if !ok {
+			return
+		}
+
+		termID, err := termRepo.Create(term)
+		if err != nil {
+			log.Printf("Ошибка при создании учебного периода: %v", err)
+			respondTermError(c, err)
+			return
+		}
+
+		log.Printf("Успешно создан учебный период с ID: %d", termID)
+		c.JSON(http.StatusOK, SuccessResponse{
+			Message: "Term created successfully",
+			Data:    gin.H{"term_id": termID},
+		})
+	}
+}
+
+// UpdateTerm godoc
+// @Summary Изменить учебный период
+// @Description Изменяет год, название и границы периода с теми же проверками, что и при создании
+// @Tags Terms
+// @Accept  json
+// @Produce  json
+// @Param   id    path  int          true  "ID периода"  example(1)
+// @Param   term  body  TermRequest  true  "Новые данные"
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос или end_date не позже start_date"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет права terms:manage"
+// @Failure 404 {object} ErrorResponse "Период или учебный год не найден"
+// @Failure 409 {object} ErrorResponse "Название занято или период пересекается с другим"
+// @Failure 422 {object} ErrorResponse "Период выходит за границы учебного года"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/terms/{id} [put]
+func UpdateTerm(termRepo repository.TermRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		termID, ok := parseTermID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на обновление учебного периода с ID: %d", termID)
+
+		term, ok := bindTerm(c)
+		if !ok {
+			return
+		}
+		term.ID = termID
+
+		if err := termRepo.Update(term); err != nil {
+			log.Printf("Ошибка при обновлении учебного периода: %v", err)
+			respondTermError(c, err)
+			return
+		}
+
+		log.Printf("Успешно обновлён учебный период с ID: %d", termID)
+		c.JSON(http.StatusOK, SuccessResponse{Message: "Term updated successfully"})
+	}
+}
+
+// DeleteTerm godoc
+// @Summary Удалить учебный период
+// @Description Удаляет период. Если за него выставлены итоговые оценки, возвращает 409 с их количеством
+// @Tags Terms
+// @Produce  json
+// @Param   id  path  int  true  "ID периода"  example(1)
+// @Success 200 {object} SuccessResponse "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 403 {object} ErrorResponse "Нет права terms:manage"
+// @Failure 404 {object} ErrorResponse "Период не найден"
+// @Failure 409 {object} InUseResponse "За период выставлены итоговые оценки"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/terms/{id} [delete]
+func DeleteTerm(termRepo repository.TermRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		termID, ok := parseTermID(c)
+		if !ok {
+			return
+		}
+		log.Printf("Получен запрос на удаление учебного периода с ID: %d", termID)
+
+		if err := termRepo.Delete(termID); err != nil {
+			log.Printf("Ошибка при удалении учебного периода: %v", err)
+			respondTermError(c, err)
+			return
+		}
+
+		log.Printf("Успешно удалён учебный период с ID: %d", termID)
+		c.JSON(http.StatusOK, SuccessResponse{Message: "Term deleted successfully"})
+	}
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
-	"database/sql"
-	"github.com/VladislavSCV/internal/core"
+	"errors"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/patch"
+	"github.com/VladislavSCV/internal/repository"
 	"github.com/gin-gonic/gin"
 	"log"
 	"net/http"
@@ -12,51 +14,113 @@ import (
 
 // GetUsers godoc
 // @Summary Получить список всех пользователей
-// @Description Возвращает список всех пользователей
+// @Description Возвращает страницу пользователей. format=csv или format=xlsx выгружает всех пользователей под фильтрами файлом (без паролей и деления на страницы, по фамилии и имени)
 // @Tags Users
 // @Accept  json
 // @Produce  json
-// @Success 200 {array} models.User "Успешный ответ"
+// @Produce  text/csv
+// @Produce  application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param   format   query  string  false  "Формат: json (по умолчанию), csv (UTF-8 с BOM, разделитель «;») или xlsx"
+// @Param   columns  query  string  false  "Колонки файла через запятую в нужном порядке: id, last_name, first_name, middle_name, login, role, group, created_at; по умолчанию все"
+// @Param   role      query  string  false  "Роль (admin, teacher, student, ...)"
+// @Param   group_id  query  int     false  "ID группы"
+// @Param   sort     query  string  false  "Сортировка: id (по умолчанию), last_name, first_name, login, role, group, created_at; «-» в начале — по убыванию"
+// @Param   limit    query  int     false  "Размер страницы, от 1 до 1000; по умолчанию 50"
+// @Param   offset   query  int     false  "Сколько записей пропустить"
+// @Param   cursor   query  string  false  "next_cursor предыдущей страницы; вместо offset"
+// @Success 200 {object} ListResponse{items=[]models.User} "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/user [get]
-func GetUsers(db *sql.DB) gin.HandlerFunc {
+func GetUsers(userRepo repository.UserRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		log.Printf("Получен запрос на получение списка всех пользователей")
 
-		users, err := core.GetAllUsers(db)
+		filter := models.UserFilter{Role: c.Query("role")}
+		if !parseQueryIDs(c, map[string]*int{"group_id": &filter.GroupID}) {
+			return
+		}
+		if exportRequested(c) {
+			export, ok := startExport(c, "users", userExportColumns)
+			if !ok {
+				return
+			}
+			export.Finish(userRepo.Export(currentScope(c), filter, func(user models.User) error {
+				return export.Row(userExportRecord(user))
+			}))
+			return
+		}
+
+		query, ok := parseListQuery(c, models.UserSortFields)
+		if !ok {
+			return
+		}
+
+		users, page, err := userRepo.List(currentScope(c), filter, query)
 		if err != nil {
 			log.Printf("Ошибка при получении списка пользователей: %v", err)
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 			return
 		}
 
-		log.Printf("Успешно получен список всех пользователей")
-		c.JSON(http.StatusOK, users)
+		log.Printf("Успешно получен список пользователей: %d из %d", len(users), page.Total)
+		writeList(c, users, page, query)
 	}
 }
 
 // GetStudents godoc
 // @Summary Получить список студентов
-// @Description Возвращает список всех студентов
+// @Description Возвращает страницу студентов; преподаватель видит только студентов групп, в которых ведёт занятия.
+// @Description format=csv или format=xlsx выгружает всех студентов под фильтрами файлом, без деления на страницы, по фамилии и имени
 // @Tags Users
 // @Accept  json
 // @Produce  json
-// @Success 200 {array} models.User "Успешный ответ"
+// @Produce  text/csv
+// @Produce  application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param   format   query  string  false  "Формат: json (по умолчанию), csv (UTF-8 с BOM, разделитель «;») или xlsx"
+// @Param   columns  query  string  false  "Колонки файла через запятую в нужном порядке: id, last_name, first_name, middle_name, login, role, group, created_at; по умолчанию все"
+// @Param   group_id  query  int     false  "ID группы"
+// @Param   sort     query  string  false  "Сортировка: id (по умолчанию), last_name, first_name, login, role, group, created_at; «-» в начале — по убыванию"
+// @Param   limit    query  int     false  "Размер страницы, от 1 до 1000; по умолчанию 50"
+// @Param   offset   query  int     false  "Сколько записей пропустить"
+// @Param   cursor   query  string  false  "next_cursor предыдущей страницы; вместо offset"
+// @Success 200 {object} ListResponse{items=[]models.User} "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/user/students [get]
-func GetStudents(db *sql.DB) gin.HandlerFunc {
+func GetStudents(userRepo repository.UserRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		log.Printf("Получен запрос на получение списка студентов")
 
-		students, err := core.GetStudents(db)
+		filter := models.UserFilter{Role: "student"}
+		if !parseQueryIDs(c, map[string]*int{"group_id": &filter.GroupID}) {
+			return
+		}
+		if exportRequested(c) {
+			export, ok := startExport(c, "students", userExportColumns)
+			if !ok {
+				return
+			}
+			export.Finish(userRepo.Export(currentScope(c), filter, func(user models.User) error {
+				return export.Row(userExportRecord(user))
+			}))
+			return
+		}
+
+		query, ok := parseListQuery(c, models.UserSortFields)
+		if !ok {
+			return
+		}
+
+		students, page, err := userRepo.List(currentScope(c), filter, query)
 		if err != nil {
 			log.Printf("Ошибка при получении списка студентов: %v", err)
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 			return
 		}
 
-		log.Printf("Успешно получен список студентов")
-		c.JSON(http.StatusOK, students)
+		log.Printf("Успешно получен список студентов: %d из %d", len(students), page.Total)
+		writeList(c, students, page, query)
 	}
 }
 
@@ -69,11 +133,11 @@ func GetStudents(db *sql.DB) gin.HandlerFunc {
 // @Success 200 {array} models.User "Успешный ответ"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/user/teachers [get]
-func GetTeachers(db *sql.DB) gin.HandlerFunc {
+func GetTeachers(userRepo repository.UserRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		log.Printf("Получен запрос на получение списка преподавателей")
 
-		teachers, err := core.GetTeachers(db)
+		teachers, err := userRepo.GetTeachers()
 		if err != nil {
 			log.Printf("Ошибка при получении списка преподавателей: %v", err)
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
@@ -87,7 +151,8 @@ func GetTeachers(db *sql.DB) gin.HandlerFunc {
 
 // GetUserByID godoc
 // @Summary Получить информацию о пользователе по его ID
-// @Description Возвращает информацию о конкретном пользователе
+// @Description Возвращает информацию о пользователе. Студент видит только себя, преподаватель — себя и студентов своих групп;
+// @Description недоступный пользователь выглядит как несуществующий
 // @Tags Users
 // @Accept  json
 // @Produce  json
@@ -97,7 +162,7 @@ func GetTeachers(db *sql.DB) gin.HandlerFunc {
 // @Failure 404 {object} ErrorResponse "Пользователь не найден"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/user/{id} [get]
-func GetUserByID(db *sql.DB) gin.HandlerFunc {
+func GetUserByID(userRepo repository.UserRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID := c.Param("id")
 		log.Printf("Получен запрос на получение информации о пользователе с ID: %s", userID)
@@ -117,7 +182,7 @@ func GetUserByID(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		user, err := core.GetUserByID(db, userIDInt)
+		user, err := userRepo.GetByID(currentScope(c), userIDInt)
 		if err != nil {
 			if strings.Contains(err.Error(), "not found") {
 				log.Printf("Пользователь с ID %d не найден", userIDInt)
@@ -134,19 +199,72 @@ func GetUserByID(db *sql.DB) gin.HandlerFunc {
 	}
 }
 
+// GetUserGroups godoc
+// @Summary История групп пользователя
+// @Description Возвращает группы студента с периодами членства от первой к текущей. У первой группы нет valid_from, у текущей — valid_to; valid_to — день перевода, в который студент уже в новой группе. Пользователь виден тем же, кому доступен GET /api/user/:id
+// @Tags Users
+// @Produce  json
+// @Param   id  path  int  true  "ID пользователя"  example(3)
+// @Success 200 {array} models.GroupMembership "Успешный ответ"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 401 {object} ErrorResponse "Не авторизован"
+// @Failure 404 {object} ErrorResponse "Пользователь не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/user/{id}/groups [get]
+func GetUserGroups(userRepo repository.UserRepository, groupRepo repository.GroupRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := strconv.Atoi(c.Param("id"))
+		if err != nil || userID <= 0 {
+			log.Printf("Некорректный ID пользователя: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user ID"})
+			return
+		}
+
+		// Историю групп видят те же, кто видит самого пользователя
+		if _, err := userRepo.GetByID(currentScope(c), userID); err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				log.Printf("Пользователь с ID %d не найден", userID)
+				c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+			} else {
+				log.Printf("Ошибка при получении информации о пользователе: %v", err)
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			}
+			return
+		}
+
+		memberships, err := groupRepo.GetMemberships(userID)
+		if err != nil {
+			log.Printf("Ошибка при получении истории групп пользователя %d: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("Получена история групп пользователя %d: %d записей", userID, len(memberships))
+		c.JSON(http.StatusOK, memberships)
+	}
+}
+
 // UpdateUser godoc
-// @Summary Обновить информацию о пользователе
-// @Description Обновляет информацию о существующем пользователе
+// @Summary Обновить пользователя
+// @Description Частично обновляет пользователя. Принимает JSON Merge Patch (application/merge-patch+json или application/json) и JSON Patch (application/json-patch+json).
+// @Description Менять можно только first_name, middle_name, last_name и login; пароль и роль меняются отдельными операциями, а группа — переводом POST /api/group/{id}/transfer, который сохраняет историю групп.
 // @Tags Users
 // @Accept  json
+// @Accept  application/merge-patch+json
+// @Accept  application/json-patch+json
 // @Produce  json
 // @Param   id  path  int  true  "ID пользователя"  example(1)
-// @Param   updates  body  map[string]interface{}  true  "Обновлённые данные о пользователе"  example({"first_name": "John", "last_name": "Doe"})
+// @Param   updates  body  map[string]interface{}  true  "Патч пользователя"  example({"first_name": "John", "last_name": "Doe"})
 // @Success 200 {object} SuccessResponse "Успешный ответ"
 // @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 404 {object} ErrorResponse "Пользователь не найден"
+// @Failure 409 {object} ErrorResponse "Логин уже занят или не выполнена операция test из JSON Patch"
+// @Failure 415 {object} ErrorResponse "Неподдерживаемый формат патча"
+// @Failure 422 {object} PatchErrorResponse "Неизвестные, запрещённые или некорректные поля"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/user/{id} [put]
-func UpdateUser(db *sql.DB) gin.HandlerFunc {
+// @Router /api/user/{id} [patch]
+func UpdateUser(userRepo repository.UserRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID := c.Param("id")
 		log.Printf("Получен запрос на обновление пользователя с ID: %s", userID)
@@ -166,31 +284,35 @@ func UpdateUser(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		var updates map[string]interface{}
-		if err := c.ShouldBindJSON(&updates); err != nil {
-			log.Printf("Ошибка привязки JSON: %v", err)
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		user, err := userRepo.GetCurrent(userIDInt)
+		if err != nil {
+			log.Printf("Ошибка при получении пользователя: %v", err)
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			}
 			return
 		}
 
-		// Валидация обновлений
-		if firstName, ok := updates["first_name"].(string); ok && firstName == "" {
-			log.Printf("Некорректное имя пользователя: %s", firstName)
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "first_name cannot be empty"})
+		updates, ok := readPatch(c, patch.UserSchema, patch.UserDocument(*user))
+		if !ok {
 			return
 		}
-		if lastName, ok := updates["last_name"].(string); ok && lastName == "" {
-			log.Printf("Некорректная фамилия пользователя: %s", lastName)
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "last_name cannot be empty"})
+		if len(updates) == 0 {
+			c.JSON(http.StatusOK, SuccessResponse{Message: "No changes"})
 			return
 		}
 
-		// Устанавливаем ID пользователя из параметра запроса
-		updates["id"] = userIDInt
-
-		if err := core.UpdateUser(db, updates); err != nil {
+		if err := userRepo.Update(userIDInt, updates); err != nil {
 			log.Printf("Ошибка при обновлении пользователя: %v", err)
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			if errors.Is(err, repository.ErrLoginExists) {
+				c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+			} else if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			}
 			return
 		}
 
@@ -210,7 +332,7 @@ func UpdateUser(db *sql.DB) gin.HandlerFunc {
 // @Failure 400 {object} ErrorResponse "Неверный запрос"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/user/{id} [delete]
-func DeleteUser(db *sql.DB) gin.HandlerFunc {
+func DeleteUser(userRepo repository.UserRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID := c.Param("id")
 		log.Printf("Получен запрос на удаление пользователя с ID: %s", userID)
@@ -230,7 +352,7 @@ func DeleteUser(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		if err := core.DeleteUser(db, userIDInt); err != nil {
+		if err := userRepo.Delete(userIDInt); err != nil {
 			log.Printf("Ошибка при удалении пользователя: %v", err)
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 			return
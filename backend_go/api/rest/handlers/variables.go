@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"github.com/VladislavSCV/internal/database"
+	"github.com/VladislavSCV/internal/models"
 	"time"
 )
 
@@ -18,9 +19,135 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// PatchErrorResponse представляет ответ 422 на некорректный патч.
+type PatchErrorResponse struct {
+	Error           string            `json:"error"`
+	UnknownFields   []string          `json:"unknown_fields,omitempty"`
+	ForbiddenFields []string          `json:"forbidden_fields,omitempty"`
+	InvalidFields   map[string]string `json:"invalid_fields,omitempty"`
+	Malformed       []string          `json:"malformed,omitempty"`
+}
+
+// InUseResponse представляет ответ 409 на удаление записи, на которую ещё ссылаются другие.
+type InUseResponse struct {
+	Error      string         `json:"error"`
+	Dependents map[string]int `json:"dependents"` // Число ссылающихся записей по таблицам
+}
+
+// ScheduleConflictResponse представляет ответ 409 на занятие, пересекающееся с другими.
+type ScheduleConflictResponse struct {
+	Error     string                    `json:"error"`
+	Conflicts []models.ScheduleConflict `json:"conflicts"`
+}
+
+// ListResponse представляет страницу списка.
+type ListResponse struct {
+	Items      interface{} `json:"items"`
+	Total      int         `json:"total"`       // Записей под фильтрами без учёта страницы
+	Limit      int         `json:"limit"`       // 0 — без ограничения
+	Offset     int         `json:"offset"`      // Смещение страницы; при переходе по курсору — 0
+	NextCursor *string     `json:"next_cursor"` // Параметр cursor следующей страницы; null — страница последняя
+}
+
+// CalendarTokenResponse представляет выданный токен подписки на ленты расписания.
+type CalendarTokenResponse struct {
+	Token string   `json:"token"` // Показывается один раз; в базе хранится только хеш
+	Feeds []string `json:"feeds"` // Ленты пользователя: его группы и, для преподавателя, его занятий
+}
+
+// GenerateLessonsRequest представляет запрос на создание занятий по расписанию.
+type GenerateLessonsRequest struct {
+	From    string `json:"from" example:"2024-09-02"` // ГГГГ-ММ-ДД; по умолчанию — начало семестра
+	To      string `json:"to" example:"2024-12-29"`   // ГГГГ-ММ-ДД; по умолчанию — конец семестра
+	GroupID int    `json:"group_id"`                  // 0 — для всех групп
+}
+
+// GradeWeightsRequest представляет веса видов оценок в предмете.
+type GradeWeightsRequest struct {
+	Weights map[int]float64 `json:"weights"` // Вес по ID вида оценки; неуказанные виды получают вес по умолчанию
+}
+
+// AcademicYearRequest представляет запрос на создание или изменение учебного года.
+type AcademicYearRequest struct {
+	Name      string `json:"name" example:"2024/2025"`
+	StartDate string `json:"start_date" example:"2024-09-01"` // ГГГГ-ММ-ДД
+	EndDate   string `json:"end_date" example:"2025-06-30"`   // ГГГГ-ММ-ДД
+}
+
+// TermRequest представляет запрос на создание или изменение учебного периода.
+type TermRequest struct {
+	AcademicYearID int    `json:"academic_year_id" example:"1"`
+	Name           string `json:"name" example:"1 семестр"`
+	StartDate      string `json:"start_date" example:"2024-09-01"` // ГГГГ-ММ-ДД
+	EndDate        string `json:"end_date" example:"2024-12-31"`   // ГГГГ-ММ-ДД
+}
+
+// FinalGradesRequest представляет запрос на расчёт или закрытие итоговых оценок группы по предмету.
+type FinalGradesRequest struct {
+	GroupID   int `json:"group_id" example:"1"`
+	SubjectID int `json:"subject_id" example:"1"`
+}
+
+// FinalGradeRequest представляет запрос на изменение итоговой оценки.
+type FinalGradeRequest struct {
+	Value int `json:"value" example:"5"` // 2-5
+}
+
+// JournalSaveRequest представляет правки многих ячеек журнала группы по предмету; применяются все или ни одной.
+type JournalSaveRequest struct {
+	GroupID    int                            `json:"group_id" example:"1"`
+	SubjectID  int                            `json:"subject_id" example:"1"`
+	Grades     []JournalGradeEditRequest      `json:"grades"`
+	Attendance []JournalAttendanceEditRequest `json:"attendance"`
+}
+
+// JournalGradeEditRequest представляет оценку в ячейке журнала: без id — новая,
+// с id — изменение значения, вида и комментария, с delete — удаление.
+type JournalGradeEditRequest struct {
+	ID          *int   `json:"id,omitempty" example:"12"`
+	StudentID   int    `json:"student_id,omitempty" example:"3"`    // Только для новой оценки
+	Date        string `json:"date,omitempty" example:"2024-09-02"` // Только для новой оценки, ГГГГ-ММ-ДД
+	Value       int    `json:"value,omitempty" example:"5"`         // 2-5, не нужно при удалении
+	GradeTypeID *int   `json:"grade_type_id,omitempty" example:"1"`
+	Comment     string `json:"comment,omitempty"`
+	Delete      bool   `json:"delete,omitempty"`
+}
+
+// JournalAttendanceEditRequest представляет статус студента за день.
+type JournalAttendanceEditRequest struct {
+	StudentID int    `json:"student_id" example:"3"`
+	Date      string `json:"date" example:"2024-09-02"` // ГГГГ-ММ-ДД
+	Status    string `json:"status" example:"absent"`   // present, absent или excused
+}
+
+// TransferRequest представляет запрос на перевод студента в группу.
+type TransferRequest struct {
+	StudentID int    `json:"student_id" example:"3"`
+	Date      string `json:"date,omitempty" example:"2025-02-01"` // ГГГГ-ММ-ДД, с этого дня студент в новой группе; по умолчанию — сегодня
+}
+
 // LoginResponse представляет ответ на успешный вход в систему.
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // Время жизни access-токена в секундах
+}
+
+// RefreshRequest представляет запрос на обновление пары токенов.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RoleRequest представляет запрос на создание или изменение роли.
+type RoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// AssignRoleRequest представляет запрос на назначение роли пользователю.
+type AssignRoleRequest struct {
+	RoleID int `json:"role_id"`
 }
 
 // VerifyResponse представляет ответ на проверку токена.
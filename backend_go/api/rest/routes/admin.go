@@ -0,0 +1,76 @@
+package routes
+
+import (
+	"github.com/VladislavSCV/api/middleware"
+	"github.com/VladislavSCV/api/rest/handlers"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupAdminRoutes(router *gin.Engine, repos *repository.Repositories) {
+	adminGroup := router.Group("/api/admin")
+	{
+		// Применение rate limiting к маршрутам
+		adminGroup.Use(middleware.RateLimiterMiddleware())
+		adminGroup.Use(middleware.AuthMiddleware(repos.Sessions))
+
+		// Журнал аудита с фильтрами и выгрузкой в CSV / JSON Lines
+		adminGroup.GET("/audit", middleware.RequirePermission(policy.PermAuditRead), handlers.GetAuditLog(repos.Audit))
+	}
+}
+
+// auditEntities — сущности журнала аудита по префиксам маршрутов. Загрузка идёт от имени системы:
+// снимки видны только в журнале, а он доступен с правом audit:read.
+func auditEntities(repos *repository.Repositories) map[string]middleware.AuditEntity {
+	loadUser := func(id int) (interface{}, error) { return repos.Users.GetByID(policy.System(), id) }
+	// У оценок и посещаемости нет выборки по ID; их текущий вид — последний снимок в истории изменений
+	lastSnapshot := func(recordType string) func(id int) (interface{}, error) {
+		return func(id int) (interface{}, error) {
+			changes, err := repos.History.Get(recordType, id)
+			if err != nil || len(changes) == 0 {
+				return nil, err
+			}
+			return changes[len(changes)-1].NewValue, nil
+		}
+	}
+
+	return map[string]middleware.AuditEntity{
+		"/api/auth":              {Type: "session"},
+		"/api/auth/registration": {Type: "user", Load: loadUser},
+		"/api/user":              {Type: "user", Load: loadUser},
+		"/api/roles": {Type: "role", Load: func(id int) (interface{}, error) {
+			return repos.Roles.GetByID(id)
+		}},
+		"/api/group": {Type: "group", Load: func(id int) (interface{}, error) {
+			return repos.Groups.GetByID(id)
+		}},
+		"/api/subjects": {Type: "subject", Load: func(id int) (interface{}, error) {
+			return repos.Subjects.GetByID(id)
+		}},
+		"/api/grade-types": {Type: "grade_type", Load: func(id int) (interface{}, error) {
+			return repos.GradeTypes.GetByID(id)
+		}},
+		"/api/schedule": {Type: "schedule", Load: func(id int) (interface{}, error) {
+			return repos.Schedules.GetByID(id)
+		}},
+		"/api/schedule/calendar": {Type: "calendar_token"},
+		"/api/lessons": {Type: "lesson", Load: func(id int) (interface{}, error) {
+			return repos.Lessons.GetByID(id)
+		}},
+		"/api/academic-years": {Type: "academic_year", Load: func(id int) (interface{}, error) {
+			return repos.Terms.GetYearByID(id)
+		}},
+		"/api/terms": {Type: "term", Load: func(id int) (interface{}, error) {
+			return repos.Terms.GetByID(id)
+		}},
+		"/api/final-grades": {Type: "final_grade", Load: func(id int) (interface{}, error) {
+			return repos.FinalGrades.GetByID(policy.System(), id)
+		}},
+		"/api/grades":     {Type: models.RecordGrade, Load: lastSnapshot(models.RecordGrade)},
+		"/api/attendance": {Type: models.RecordAttendance, Load: lastSnapshot(models.RecordAttendance)},
+		// Отдельные оценки и отметки из сохранения журнала попадают в историю записей
+		"/api/journal": {Type: "journal"},
+	}
+}
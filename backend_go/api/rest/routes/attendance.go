@@ -1,33 +1,48 @@
 package routes
 
 import (
-	"database/sql"
 	"github.com/VladislavSCV/api/middleware"
 	"github.com/VladislavSCV/api/rest/handlers"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupAttendanceRoutes(router *gin.Engine, db *sql.DB) {
+func SetupAttendanceRoutes(router *gin.Engine, repos *repository.Repositories) {
 	attendanceGroup := router.Group("/api/attendance")
 	{
 		// Применение rate limiting к маршрутам
 		attendanceGroup.Use(middleware.RateLimiterMiddleware())
 
-		// Получение посещаемости по ID студента (доступно всем)
-		attendanceGroup.GET("/student/:id", handlers.GetAttendanceByStudentID(db))
-		// Получение посещаемости по ID группы (доступно всем)
-		attendanceGroup.GET("/group/:id", handlers.GetAttendanceByGroupID(db))
-		// Создание записи о посещаемости (доступно только преподавателям и администраторам)
+		attendanceGroup.Use(middleware.AuthMiddleware(repos.Sessions))
+
+		// Получение посещаемости по ID студента (какие записи видны, решают права records:*)
+		attendanceGroup.GET("/student/:id", middleware.RequirePermission(policy.PermAttendanceRead), handlers.GetAttendanceByStudentID(repos.Attendance))
+		// Получение посещаемости по ID группы (выборка сужается так же)
+		attendanceGroup.GET("/group/:id", middleware.RequirePermission(policy.PermAttendanceRead), handlers.GetAttendanceByGroupID(repos.Attendance))
+		// Отчёты по посещаемости (считаются только доступные записи)
+		attendanceGroup.GET("/stats", middleware.RequirePermission(policy.PermAttendanceRead), handlers.GetAttendanceStats(repos.Attendance))
+		attendanceGroup.GET("/stats/monthly", middleware.RequirePermission(policy.PermAttendanceRead), handlers.GetAttendanceTrend(repos.Attendance))
+		attendanceGroup.GET("/stats/absentees", middleware.RequirePermission(policy.PermAttendanceRead), handlers.GetAbsentees(repos.Attendance))
+		// Создание записи о посещаемости
 		attendanceGroup.POST("/",
-			middleware.AuthMiddleware(db),
-			middleware.RoleMiddleware([]string{"teacher", "admin"}),
-			handlers.CreateAttendance(db),
+			middleware.RequirePermission(policy.PermAttendanceWrite),
+			handlers.CreateAttendance(repos.Attendance),
+		)
+		// Перекличка всей группы одним запросом (те же правила для каждого студента)
+		attendanceGroup.POST("/roll-call",
+			middleware.RequirePermission(policy.PermAttendanceWrite),
+			handlers.RollCall(repos.Attendance),
 		)
-		// Обновление записи о посещаемости (доступно только преподавателям и администраторам)
+		// Обновление записи о посещаемости (те же правила)
 		attendanceGroup.PUT("/:id",
-			middleware.AuthMiddleware(db),
-			middleware.RoleMiddleware([]string{"teacher", "admin"}),
-			handlers.UpdateAttendance(db),
+			middleware.RequirePermission(policy.PermAttendanceWrite),
+			handlers.UpdateAttendance(repos.Attendance),
+		)
+		// История изменений записи (право history:read)
+		attendanceGroup.GET("/:id/history",
+			middleware.RequirePermission(policy.PermHistoryRead),
+			handlers.GetAttendanceHistory(repos.History),
 		)
 	}
 }
@@ -1,21 +1,26 @@
 package routes
 
 import (
-	"database/sql"
 	"github.com/VladislavSCV/api/middleware"
 	"github.com/VladislavSCV/api/rest/handlers"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupAuthRoutes(router *gin.Engine, db *sql.DB) {
+func SetupAuthRoutes(router *gin.Engine, repos *repository.Repositories) {
 	// Аутентификация
 	auth := router.Group("/api/auth")
 	{
 		// Применение rate limiting к маршрутам
 		auth.Use(middleware.RateLimiterMiddleware())
-		auth.POST("/login", handlers.Login(db))
-		auth.POST("/registration", handlers.Registration(db))
-		auth.POST("/verify", handlers.Verify(db))
-		auth.GET("/", middleware.AuthMiddleware(db), handlers.GetCurrentUser(db))
+		auth.POST("/login", handlers.Login(repos.Users, repos.Sessions))
+		// Пользователей заводит администратор; самостоятельная регистрация закрыта
+		auth.POST("/registration", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermUsersManage), handlers.Registration(repos.Users, repos.Roles))
+		auth.POST("/verify", handlers.Verify(repos.Sessions))
+		auth.POST("/refresh", handlers.Refresh(repos.Users, repos.Sessions))
+		auth.POST("/logout", middleware.AuthMiddleware(repos.Sessions), handlers.Logout(repos.Sessions))
+		auth.POST("/logout-all", middleware.AuthMiddleware(repos.Sessions), handlers.LogoutAll(repos.Sessions))
+		auth.GET("/", middleware.AuthMiddleware(repos.Sessions), handlers.GetCurrentUser(repos.Users))
 	}
 }
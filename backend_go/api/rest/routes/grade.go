@@ -1,23 +1,31 @@
 package routes
 
 import (
-	"database/sql"
 	"github.com/VladislavSCV/api/middleware"
 	"github.com/VladislavSCV/api/rest/handlers"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupGradeRoutes(router *gin.Engine, db *sql.DB) {
+func SetupGradeRoutes(router *gin.Engine, repos *repository.Repositories) {
 	gradeGroup := router.Group("/api/grades")
 	{
 		// Применение rate limiting к маршрутам
 		gradeGroup.Use(middleware.RateLimiterMiddleware())
-		gradeGroup.GET("/student/:id", handlers.GetGradesByStudentID(db))
-		gradeGroup.GET("/group/:id", handlers.GetGradesByGroupID(db))
+		gradeGroup.Use(middleware.AuthMiddleware(repos.Sessions))
 
-		// Ограничение доступа для создания, обновления и удаления оценок только для преподавателей
-		gradeGroup.POST("/", middleware.AuthMiddleware(db), middleware.RoleMiddleware([]string{"teacher"}), handlers.CreateGrade(db))
-		gradeGroup.PUT("/:id", middleware.AuthMiddleware(db), middleware.RoleMiddleware([]string{"teacher"}), handlers.UpdateGrade(db))
-		gradeGroup.DELETE("/:id", middleware.AuthMiddleware(db), middleware.RoleMiddleware([]string{"teacher"}), handlers.DeleteGrade(db))
+		// Какие именно оценки видны и доступны для записи, решают права records:* (internal/policy)
+		gradeGroup.GET("/student/:id", middleware.RequirePermission(policy.PermGradesRead), handlers.GetGradesByStudentID(repos.Grades))
+		gradeGroup.GET("/group/:id", middleware.RequirePermission(policy.PermGradesRead), handlers.GetGradesByGroupID(repos.Grades))
+		// Аналитика считается по тем же видимым оценкам
+		gradeGroup.GET("/stats", middleware.RequirePermission(policy.PermGradesRead), handlers.GetGradeStats(repos.Grades))
+		gradeGroup.GET("/stats/ranking", middleware.RequirePermission(policy.PermGradesRead), handlers.GetGroupRanking(repos.Grades))
+
+		gradeGroup.POST("/", middleware.RequirePermission(policy.PermGradesWrite), handlers.CreateGrade(repos.Grades))
+		gradeGroup.PUT("/:id", middleware.RequirePermission(policy.PermGradesWrite), handlers.UpdateGrade(repos.Grades))
+		gradeGroup.DELETE("/:id", middleware.RequirePermission(policy.PermGradesWrite), handlers.DeleteGrade(repos.Grades))
+		// История изменений видна только с правом history:read (по умолчанию — админу)
+		gradeGroup.GET("/:id/history", middleware.RequirePermission(policy.PermHistoryRead), handlers.GetGradeHistory(repos.History))
 	}
 }
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/VladislavSCV/api/middleware"
+	"github.com/VladislavSCV/api/rest/handlers"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupGradeTypeRoutes(router *gin.Engine, repos *repository.Repositories) {
+	gradeTypeGroup := router.Group("/api/grade-types")
+	{
+		// Применение rate limiting к маршрутам
+		gradeTypeGroup.Use(middleware.RateLimiterMiddleware())
+		gradeTypeGroup.GET("/", handlers.GetGradeTypes(repos.GradeTypes))
+		gradeTypeGroup.GET("/:id", handlers.GetGradeTypeByID(repos.GradeTypes))
+
+		// Виды оценок и их веса настраивают администраторы (право grade_types:manage)
+		gradeTypeGroup.POST("/", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermGradeTypesManage), handlers.CreateGradeType(repos.GradeTypes))
+		gradeTypeGroup.PUT("/:id", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermGradeTypesManage), handlers.UpdateGradeType(repos.GradeTypes))
+		gradeTypeGroup.DELETE("/:id", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermGradeTypesManage), handlers.DeleteGradeType(repos.GradeTypes))
+	}
+}
@@ -1,25 +1,29 @@
 package routes
 
 import (
-	"database/sql"
 	"github.com/VladislavSCV/api/middleware"
 	"github.com/VladislavSCV/api/rest/handlers"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupGroupRoutes(router *gin.Engine, db *sql.DB) {
+func SetupGroupRoutes(router *gin.Engine, repos *repository.Repositories) {
 	groupGroup := router.Group("/api/group")
 	{
 		// Применение rate limiting к маршрутам
 		groupGroup.Use(middleware.RateLimiterMiddleware())
-		groupGroup.GET("/", handlers.GetGroups(db))
-		groupGroup.GET("/:id", handlers.GetGroupByID(db)) // получение информации о группе (студенты)
+		groupGroup.GET("/", handlers.GetGroups(repos.Groups))
+		groupGroup.GET("/:id", handlers.GetGroupByID(repos.Groups)) // получение информации о группе (студенты)
 
-		// Ограничение доступа для создания группы только для администраторов
-		groupGroup.POST("/", middleware.AuthMiddleware(db), middleware.RoleMiddleware([]string{"admin"}), handlers.CreateGroup(db))
+		// Ограничение доступа для создания группы правом groups:manage
+		groupGroup.POST("/", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermGroupsManage), handlers.CreateGroup(repos.Groups))
 
-		// Ограничение доступа для обновления и удаления группы только для администраторов
-		groupGroup.PUT("/:id", middleware.AuthMiddleware(db), middleware.RoleMiddleware([]string{"admin"}), handlers.UpdateGroup(db))
-		groupGroup.DELETE("/:id", middleware.AuthMiddleware(db), middleware.RoleMiddleware([]string{"admin"}), handlers.DeleteGroup(db))
+		// Ограничение доступа для обновления и удаления группы правом groups:manage
+		groupGroup.PUT("/:id", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermGroupsManage), handlers.UpdateGroup(repos.Groups))
+		groupGroup.DELETE("/:id", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermGroupsManage), handlers.DeleteGroup(repos.Groups))
+
+		// Перевод студента меняет его группу, поэтому требует права users:manage
+		groupGroup.POST("/:id/transfer", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermUsersManage), handlers.TransferStudent(repos.Groups))
 	}
 }
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/VladislavSCV/api/middleware"
+	"github.com/VladislavSCV/api/rest/handlers"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupJournalRoutes(router *gin.Engine, repos *repository.Repositories) {
+	journalGroup := router.Group("/api/journal")
+	{
+		// Применение rate limiting к маршрутам
+		journalGroup.Use(middleware.RateLimiterMiddleware())
+		journalGroup.Use(middleware.AuthMiddleware(repos.Sessions))
+
+		// Журнал объединяет оценки и посещаемость, поэтому нужны права на обе; какие записи видны
+		// и доступны для записи, решают права records:*
+		journalGroup.GET("/",
+			middleware.RequirePermission(policy.PermGradesRead),
+			middleware.RequirePermission(policy.PermAttendanceRead),
+			handlers.GetJournal(repos.Journal, repos.Terms),
+		)
+		journalGroup.PUT("/",
+			middleware.RequirePermission(policy.PermGradesWrite),
+			middleware.RequirePermission(policy.PermAttendanceWrite),
+			handlers.SaveJournal(repos.Journal),
+		)
+	}
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/VladislavSCV/api/middleware"
+	"github.com/VladislavSCV/api/rest/handlers"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupLessonRoutes(router *gin.Engine, repos *repository.Repositories) {
+	lessonGroup := router.Group("/api/lessons")
+	{
+		// Применение rate limiting к маршрутам
+		lessonGroup.Use(middleware.RateLimiterMiddleware())
+		// Занятия, как и расписание, открыты для чтения
+		lessonGroup.GET("/", handlers.GetLessons(repos.Lessons))
+		lessonGroup.GET("/:id", handlers.GetLessonByID(repos.Lessons))
+
+		// Посещаемость занятия: выборка сужается правами records:*
+		lessonGroup.GET("/:id/attendance", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermAttendanceRead), handlers.GetLessonAttendance(repos.Lessons, repos.Attendance))
+		// Создание занятий по расписанию — тем, кто управляет расписанием
+		lessonGroup.POST("/generate", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermScheduleManage), handlers.GenerateLessons(repos.Lessons, repos.Terms))
+	}
+}
@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"github.com/VladislavSCV/api/middleware"
+	"github.com/VladislavSCV/api/rest/handlers"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/report"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupReportRoutes(router *gin.Engine, repos *repository.Repositories) {
+	sources := report.Sources{
+		Users:       repos.Users,
+		Groups:      repos.Groups,
+		Terms:       repos.Terms,
+		Grades:      repos.Grades,
+		Attendance:  repos.Attendance,
+		FinalGrades: repos.FinalGrades,
+	}
+
+	reportGroup := router.Group("/api/reports")
+	{
+		// Применение rate limiting к маршрутам
+		reportGroup.Use(middleware.RateLimiterMiddleware())
+		reportGroup.Use(middleware.AuthMiddleware(repos.Sessions))
+
+		// Табели и справки собираются из оценок и посещаемости, поэтому нужны права на обе;
+		// какие студенты и предметы попадут в документ, решают права records:*
+		reportGroup.Use(middleware.RequirePermission(policy.PermGradesRead))
+		reportGroup.Use(middleware.RequirePermission(policy.PermAttendanceRead))
+
+		reportGroup.GET("/students/:id/report-card", handlers.GetReportCard(sources))
+		reportGroup.GET("/students/:id/transcript", handlers.GetTranscript(sources))
+		reportGroup.GET("/groups/:id/report-cards", handlers.GetGroupReportCards(sources))
+		reportGroup.GET("/groups/:id/transcripts", handlers.GetGroupTranscripts(sources))
+	}
+}
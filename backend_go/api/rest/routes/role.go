@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/VladislavSCV/api/middleware"
+	"github.com/VladislavSCV/api/rest/handlers"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRoleRoutes(router *gin.Engine, repos *repository.Repositories, roles *policy.RoleCache) {
+	roleGroup := router.Group("/api/roles")
+	{
+		// Применение rate limiting к маршрутам
+		roleGroup.Use(middleware.RateLimiterMiddleware())
+		// Управление ролями доступно только с правом roles:manage
+		roleGroup.Use(middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermRolesManage))
+
+		roleGroup.GET("/", handlers.GetRoles(repos.Roles))
+		roleGroup.GET("/permissions", handlers.GetPermissions(repos.Roles))
+		roleGroup.GET("/:id", handlers.GetRoleByID(repos.Roles))
+		roleGroup.POST("/", handlers.CreateRole(repos.Roles, roles))
+		roleGroup.PUT("/:id", handlers.UpdateRole(repos.Roles, roles))
+		roleGroup.DELETE("/:id", handlers.DeleteRole(repos.Roles, roles))
+	}
+}
@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"github.com/VladislavSCV/api/middleware"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// Setup регистрирует все маршруты API. Хранилища можно подменить
+// на repository/memory, чтобы поднять API без базы (в тестах и демо-режиме).
+func Setup(router *gin.Engine, repos *repository.Repositories) {
+	// Права ролей читаются из хранилища и кэшируются; API ролей сбрасывает кэш при изменениях
+	roles := policy.NewRoleCache(repos.Roles, policy.RoleCacheTTL)
+	middleware.ConfigurePermissions(roles)
+
+	// Журнал аудита охватывает все маршруты, поэтому ставится до их регистрации
+	router.Use(middleware.Audit(repos.Audit, auditEntities(repos)))
+
+	SetupAuthRoutes(router, repos)
+	SetupUserRoutes(router, repos)
+	SetupGroupRoutes(router, repos)
+	SetupSubjectRoutes(router, repos)
+	SetupGradeTypeRoutes(router, repos)
+	SetupScheduleRoutes(router, repos, roles)
+	SetupLessonRoutes(router, repos)
+	SetupTermRoutes(router, repos)
+	SetupGradeRoutes(router, repos)
+	SetupFinalGradeRoutes(router, repos)
+	SetupJournalRoutes(router, repos)
+	SetupReportRoutes(router, repos)
+	SetupAttendanceRoutes(router, repos)
+	SetupRoleRoutes(router, repos, roles)
+	SetupAdminRoutes(router, repos)
+	SetupSearchRoutes(router, repos)
+}
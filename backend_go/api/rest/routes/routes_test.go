@@ -0,0 +1,311 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/VladislavSCV/api/middleware"
+	"github.com/VladislavSCV/internal/repository/memory"
+	"github.com/VladislavSCV/internal/utils"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// ID пользователей из memory.SeedDemo
+const (
+	student1ID = 3
+	student2ID = 4
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	log.SetOutput(io.Discard)
+	utils.ConfigureJWT("0123456789abcdef0123456789abcdef", 15*time.Minute, time.Hour)
+	middleware.ConfigureRateLimiter(10000, time.Minute)
+	os.Exit(m.Run())
+}
+
+// newServer поднимает API над хранилищем в памяти с демо-данными
+func newServer(t *testing.T) *gin.Engine {
+	t.Helper()
+	store := memory.New()
+	if err := store.SeedDemo(); err != nil {
+		t.Fatalf("SeedDemo: %v", err)
+	}
+	router := gin.New()
+	Setup(router, store.Repositories())
+	return router
+}
+
+// do выполняет запрос; body, если не nil, кодируется в JSON, а ответ — в out
+func do(t *testing.T, router *gin.Engine, method, path, token string, body, out interface{}) int {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("marshal %s %s: %v", method, path, err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if out != nil && rec.Code < 300 {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decode %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec.Code
+}
+
+type tokens struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func login(t *testing.T, router *gin.Engine, user string) tokens {
+	t.Helper()
+	var resp tokens
+	code := do(t, router, http.MethodPost, "/api/auth/login", "", map[string]string{"login": user, "password": memory.DemoPassword}, &resp)
+	if code != http.StatusOK || resp.Token == "" || resp.RefreshToken == "" {
+		t.Fatalf("login %s: status %d, tokens %+v", user, code, resp)
+	}
+	return resp
+}
+
+func TestLoginAndRefresh(t *testing.T) {
+	router := newServer(t)
+
+	if code := do(t, router, http.MethodPost, "/api/auth/login", "", map[string]string{"login": "admin", "password": "wrong"}, nil); code != http.StatusUnauthorized {
+		t.Fatalf("login with wrong password: status %d, want 401", code)
+	}
+
+	first := login(t, router, "admin")
+	if code := do(t, router, http.MethodGet, "/api/auth/", first.Token, nil, nil); code != http.StatusOK {
+		t.Fatalf("current user: status %d, want 200", code)
+	}
+
+	var second tokens
+	code := do(t, router, http.MethodPost, "/api/auth/refresh", "", map[string]string{"refresh_token": first.RefreshToken}, &second)
+	if code != http.StatusOK || second.Token == "" || second.RefreshToken == first.RefreshToken {
+		t.Fatalf("refresh: status %d, tokens %+v", code, second)
+	}
+	if code := do(t, router, http.MethodGet, "/api/auth/", second.Token, nil, nil); code != http.StatusOK {
+		t.Fatalf("current user after refresh: status %d, want 200", code)
+	}
+
+	// Повторное использование старого refresh-токена отзывает всю сессию
+	if code := do(t, router, http.MethodPost, "/api/auth/refresh", "", map[string]string{"refresh_token": first.RefreshToken}, nil); code != http.StatusUnauthorized {
+		t.Fatalf("reused refresh token: status %d, want 401", code)
+	}
+	if code := do(t, router, http.MethodGet, "/api/auth/", second.Token, nil, nil); code != http.StatusUnauthorized {
+		t.Fatalf("token of revoked session: status %d, want 401", code)
+	}
+}
+
+func TestStudentGradesScope(t *testing.T) {
+	router := newServer(t)
+	student := login(t, router, "student1").Token
+	teacher := login(t, router, "teacher").Token
+
+	tests := []struct {
+		name      string
+		token     string
+		studentID int
+		want      int
+	}{
+		{"own grades", student, student1ID, http.StatusOK},
+		{"another student's grades", student, student2ID, http.StatusForbidden},
+		{"teacher of the group", teacher, student2ID, http.StatusOK},
+		{"no token", "", student1ID, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var page struct {
+				Items []struct {
+					StudentID int `json:"student_id"`
+				} `json:"items"`
+			}
+			code := do(t, router, http.MethodGet, fmt.Sprintf("/api/grades/student/%d", tt.studentID), tt.token, nil, &page)
+			if code != tt.want {
+				t.Fatalf("status %d, want %d", code, tt.want)
+			}
+			if code == http.StatusOK && len(page.Items) == 0 {
+				t.Fatalf("no grades in the seed data")
+			}
+			for _, grade := range page.Items {
+				if grade.StudentID != tt.studentID {
+					t.Errorf("got grade of student %d", grade.StudentID)
+				}
+			}
+		})
+	}
+
+	// Список оценок группы студенту сужается до его собственных
+	var page struct {
+		Items []struct {
+			StudentID int `json:"student_id"`
+		} `json:"items"`
+	}
+	if code := do(t, router, http.MethodGet, "/api/grades/group/1", student, nil, &page); code != http.StatusOK {
+		t.Fatalf("group grades: status %d, want 200", code)
+	}
+	for _, grade := range page.Items {
+		if grade.StudentID != student1ID {
+			t.Errorf("group grades: got grade of student %d", grade.StudentID)
+		}
+	}
+}
+
+func TestSubjectCRUD(t *testing.T) {
+	router := newServer(t)
+	admin := login(t, router, "admin").Token
+	student := login(t, router, "student1").Token
+
+	if code := do(t, router, http.MethodPost, "/api/subjects/", student, map[string]string{"name": "Физика"}, nil); code != http.StatusForbidden {
+		t.Fatalf("create by student: status %d, want 403", code)
+	}
+
+	var created struct {
+		Data struct {
+			SubjectID int `json:"subject_id"`
+		} `json:"data"`
+	}
+	if code := do(t, router, http.MethodPost, "/api/subjects/", admin, map[string]string{"name": "Физика"}, &created); code != http.StatusOK || created.Data.SubjectID == 0 {
+		t.Fatalf("create: status %d, response %+v", code, created)
+	}
+	path := fmt.Sprintf("/api/subjects/%d", created.Data.SubjectID)
+
+	if code := do(t, router, http.MethodPost, "/api/subjects/", admin, map[string]string{"name": "физика"}, nil); code != http.StatusConflict {
+		t.Fatalf("create duplicate: status %d, want 409", code)
+	}
+
+	if code := do(t, router, http.MethodPut, path, admin, map[string]string{"name": "Общая физика"}, nil); code != http.StatusOK {
+		t.Fatalf("update: status %d, want 200", code)
+	}
+	var subject struct {
+		Name string `json:"name"`
+	}
+	if code := do(t, router, http.MethodGet, path, "", nil, &subject); code != http.StatusOK || subject.Name != "Общая физика" {
+		t.Fatalf("get after update: status %d, subject %+v", code, subject)
+	}
+
+	if code := do(t, router, http.MethodDelete, path, admin, nil, nil); code != http.StatusOK {
+		t.Fatalf("delete: status %d, want 200", code)
+	}
+	if code := do(t, router, http.MethodGet, path, "", nil, nil); code != http.StatusNotFound {
+		t.Fatalf("get after delete: status %d, want 404", code)
+	}
+}
+
+func TestRegistrationAssignsStudentRole(t *testing.T) {
+	router := newServer(t)
+	admin := login(t, router, "admin").Token
+	student := login(t, router, "student1").Token
+	user := map[string]interface{}{"login": "newadmin", "password": "password123", "first_name": "Олег", "last_name": "Сидоров", "role_id": 1}
+
+	if code := do(t, router, http.MethodPost, "/api/auth/registration", "", user, nil); code != http.StatusUnauthorized {
+		t.Fatalf("registration without token: status %d, want 401", code)
+	}
+	if code := do(t, router, http.MethodPost, "/api/auth/registration", student, user, nil); code != http.StatusForbidden {
+		t.Fatalf("registration by student: status %d, want 403", code)
+	}
+
+	var created struct {
+		Data struct {
+			UserID int `json:"user_id"`
+		} `json:"data"`
+	}
+	if code := do(t, router, http.MethodPost, "/api/auth/registration", admin, user, &created); code != http.StatusOK {
+		t.Fatalf("registration by admin: status %d, want 200", code)
+	}
+	var registered struct {
+		Role string `json:"role"`
+	}
+	if code := do(t, router, http.MethodGet, fmt.Sprintf("/api/user/%d", created.Data.UserID), admin, nil, &registered); code != http.StatusOK || registered.Role != "student" {
+		t.Fatalf("registered user: status %d, role %q, want student", code, registered.Role)
+	}
+}
+
+func TestUserLoginConflict(t *testing.T) {
+	router := newServer(t)
+	admin := login(t, router, "admin").Token
+	path := fmt.Sprintf("/api/user/%d", student1ID)
+
+	if code := do(t, router, http.MethodPatch, path, admin, map[string]string{"login": "student2"}, nil); code != http.StatusConflict {
+		t.Fatalf("update to a taken login: status %d, want 409", code)
+	}
+	if code := do(t, router, http.MethodPatch, path, admin, map[string]string{"login": "student1"}, nil); code != http.StatusOK {
+		t.Fatalf("update to the own login: status %d, want 200", code)
+	}
+
+	user := map[string]interface{}{"login": "student2", "password": "password123", "first_name": "Олег", "last_name": "Сидоров"}
+	if code := do(t, router, http.MethodPost, "/api/auth/registration", admin, user, nil); code != http.StatusConflict {
+		t.Fatalf("registration with a taken login: status %d, want 409", code)
+	}
+}
+
+func TestUserListCursor(t *testing.T) {
+	router := newServer(t)
+	admin := login(t, router, "admin").Token
+
+	type page struct {
+		Items []struct {
+			ID int `json:"id"`
+		} `json:"items"`
+		Total      int     `json:"total"`
+		NextCursor *string `json:"next_cursor"`
+	}
+	var all page
+	if code := do(t, router, http.MethodGet, "/api/user/?sort=-last_name&limit=1000", admin, nil, &all); code != http.StatusOK || all.NextCursor != nil {
+		t.Fatalf("full list: status %d, next cursor %v", code, all.NextCursor)
+	}
+
+	// Курсор помнит сортировку, поэтому следующие страницы запрашиваются без sort
+	var walked []int
+	path := "/api/user/?sort=-last_name&limit=1"
+	for len(walked) <= len(all.Items) {
+		var p page
+		if code := do(t, router, http.MethodGet, path, admin, nil, &p); code != http.StatusOK || len(p.Items) != 1 || p.Total != all.Total {
+			t.Fatalf("GET %s: status %d, page %+v", path, code, p)
+		}
+		walked = append(walked, p.Items[0].ID)
+		if p.NextCursor == nil {
+			break
+		}
+		path = "/api/user/?limit=1&cursor=" + *p.NextCursor
+	}
+	for i, item := range all.Items {
+		if i >= len(walked) || walked[i] != item.ID {
+			t.Fatalf("cursor walk = %v, want the order of the full list %+v", walked, all.Items)
+		}
+	}
+	if len(walked) != len(all.Items) {
+		t.Fatalf("cursor walk = %v, want %d users", walked, len(all.Items))
+	}
+
+	var first page
+	do(t, router, http.MethodGet, "/api/user/?sort=-last_name&limit=1", admin, nil, &first)
+	for name, query := range map[string]string{
+		"cursor with offset":     "limit=1&offset=1&cursor=" + *first.NextCursor,
+		"cursor of another sort": "sort=last_name&cursor=" + *first.NextCursor,
+		"malformed cursor":       "cursor=not-a-cursor",
+		"cursor of unknown sort": "cursor=eyJzIjoicGFzc3dvcmQiLCJ2IjoiIiwiaWQiOjF9",
+	} {
+		if code := do(t, router, http.MethodGet, "/api/user/?"+query, admin, nil, nil); code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", name, code)
+		}
+	}
+}
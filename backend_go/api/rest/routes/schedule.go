@@ -1,23 +1,32 @@
 package routes
 
 import (
-	"database/sql"
 	"github.com/VladislavSCV/api/middleware"
 	"github.com/VladislavSCV/api/rest/handlers"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupScheduleRoutes(router *gin.Engine, db *sql.DB) {
+func SetupScheduleRoutes(router *gin.Engine, repos *repository.Repositories, roles *policy.RoleCache) {
 	scheduleGroup := router.Group("/api/schedule")
 	{
 		// Применение rate limiting к маршрутам
 		scheduleGroup.Use(middleware.RateLimiterMiddleware())
-		scheduleGroup.GET("/", handlers.GetSchedules(db))
-		scheduleGroup.GET("/:id", handlers.GetScheduleByID(db))
+		scheduleGroup.GET("/", handlers.GetSchedules(repos.Schedules))
+		scheduleGroup.GET("/:id", handlers.GetScheduleByID(repos.Schedules))
 
-		// Ограничение доступа для создания, обновления и удаления расписания только для администраторов
-		scheduleGroup.POST("/", middleware.AuthMiddleware(db), middleware.RoleMiddleware([]string{"admin"}), handlers.CreateSchedule(db))
-		scheduleGroup.PUT("/:id", middleware.AuthMiddleware(db), middleware.RoleMiddleware([]string{"admin"}), handlers.UpdateSchedule(db))
-		scheduleGroup.DELETE("/:id", middleware.AuthMiddleware(db), middleware.RoleMiddleware([]string{"admin"}), handlers.DeleteSchedule(db))
+		// Ленты iCalendar: календарные приложения ходят с токеном подписки вместо JWT
+		scheduleGroup.GET("/group/:file", handlers.GetGroupFeed(repos.Calendar, repos.Users, repos.Groups, repos.Schedules, repos.Terms, roles))
+		scheduleGroup.GET("/teacher/:file", handlers.GetTeacherFeed(repos.Calendar, repos.Users, repos.Schedules, repos.Terms, roles))
+		scheduleGroup.POST("/calendar/token", middleware.AuthMiddleware(repos.Sessions), handlers.IssueCalendarToken(repos.Calendar, repos.Users, repos.Schedules))
+		scheduleGroup.DELETE("/calendar/token", middleware.AuthMiddleware(repos.Sessions), handlers.RevokeCalendarToken(repos.Calendar))
+
+		// Ограничение доступа для создания, обновления и удаления расписания правом schedule:manage
+		scheduleGroup.POST("/", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermScheduleManage), handlers.CreateSchedule(repos.Schedules))
+		scheduleGroup.POST("/validate", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermScheduleManage), handlers.ValidateSchedule(repos.Schedules))
+		scheduleGroup.PUT("/:id", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermScheduleManage), handlers.UpdateSchedule(repos.Schedules))
+		scheduleGroup.PATCH("/:id", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermScheduleManage), handlers.UpdateSchedule(repos.Schedules))
+		scheduleGroup.DELETE("/:id", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermScheduleManage), handlers.DeleteSchedule(repos.Schedules))
 	}
 }
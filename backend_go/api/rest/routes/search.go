@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/VladislavSCV/api/middleware"
+	"github.com/VladislavSCV/api/rest/handlers"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupSearchRoutes(router *gin.Engine, repos *repository.Repositories) {
+	searchGroup := router.Group("/api/search")
+	{
+		// Применение rate limiting к маршрутам
+		searchGroup.Use(middleware.RateLimiterMiddleware())
+		searchGroup.Use(middleware.AuthMiddleware(repos.Sessions))
+
+		// Отдельного права нет: группы и предметы открыты всем, а пользователей сужает scope
+		searchGroup.GET("", handlers.Search(repos.Search))
+	}
+}
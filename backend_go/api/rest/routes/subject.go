@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"github.com/VladislavSCV/api/middleware"
+	"github.com/VladislavSCV/api/rest/handlers"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupSubjectRoutes(router *gin.Engine, repos *repository.Repositories) {
+	subjectGroup := router.Group("/api/subjects")
+	{
+		// Применение rate limiting к маршрутам
+		subjectGroup.Use(middleware.RateLimiterMiddleware())
+		subjectGroup.GET("/", handlers.GetSubjects(repos.Subjects))
+		subjectGroup.GET("/:id", handlers.GetSubjectByID(repos.Subjects))
+		subjectGroup.GET("/:id/weights", handlers.GetSubjectGradeWeights(repos.GradeTypes))
+
+		// Изменение справочника предметов — только с правом subjects:manage
+		subjectGroup.POST("/", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermSubjectsManage), handlers.CreateSubject(repos.Subjects))
+		subjectGroup.PUT("/:id", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermSubjectsManage), handlers.UpdateSubject(repos.Subjects))
+		subjectGroup.DELETE("/:id", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermSubjectsManage), handlers.DeleteSubject(repos.Subjects))
+		// Веса видов оценок в предмете — с правом grade_types:manage
+		subjectGroup.PUT("/:id/weights", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermGradeTypesManage), handlers.SetSubjectGradeWeights(repos.GradeTypes))
+	}
+}
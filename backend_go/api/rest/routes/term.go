@@ -0,0 +1,57 @@
+package routes
+
+import (
+	"github.com/VladislavSCV/api/middleware"
+	"github.com/VladislavSCV/api/rest/handlers"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupTermRoutes(router *gin.Engine, repos *repository.Repositories) {
+	yearGroup := router.Group("/api/academic-years")
+	{
+		// Применение rate limiting к маршрутам
+		yearGroup.Use(middleware.RateLimiterMiddleware())
+		yearGroup.GET("/", handlers.GetAcademicYears(repos.Terms))
+		yearGroup.GET("/:id", handlers.GetAcademicYearByID(repos.Terms))
+
+		// Учебные годы и периоды ведут администраторы (право terms:manage)
+		yearGroup.POST("/", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermTermsManage), handlers.CreateAcademicYear(repos.Terms))
+		yearGroup.PUT("/:id", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermTermsManage), handlers.UpdateAcademicYear(repos.Terms))
+		yearGroup.DELETE("/:id", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermTermsManage), handlers.DeleteAcademicYear(repos.Terms))
+	}
+
+	termGroup := router.Group("/api/terms")
+	{
+		// Применение rate limiting к маршрутам
+		termGroup.Use(middleware.RateLimiterMiddleware())
+		// Периоды, как и расписание, открыты для чтения
+		termGroup.GET("/", handlers.GetTerms(repos.Terms))
+		termGroup.GET("/current", handlers.GetCurrentTerm(repos.Terms))
+		termGroup.GET("/:id", handlers.GetTermByID(repos.Terms))
+
+		termGroup.POST("/", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermTermsManage), handlers.CreateTerm(repos.Terms))
+		termGroup.PUT("/:id", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermTermsManage), handlers.UpdateTerm(repos.Terms))
+		termGroup.DELETE("/:id", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermTermsManage), handlers.DeleteTerm(repos.Terms))
+
+		// Итоговые оценки: какие видны и доступны для записи, решают права records:*
+		termGroup.GET("/:id/final-grades", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermGradesRead), handlers.GetFinalGrades(repos.FinalGrades))
+		termGroup.POST("/:id/final-grades/compute", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermGradesWrite), handlers.ComputeFinalGrades(repos.FinalGrades))
+		termGroup.POST("/:id/final-grades/lock", middleware.AuthMiddleware(repos.Sessions), middleware.RequirePermission(policy.PermGradesWrite), handlers.LockFinalGrades(repos.FinalGrades))
+	}
+}
+
+func SetupFinalGradeRoutes(router *gin.Engine, repos *repository.Repositories) {
+	finalGradeGroup := router.Group("/api/final-grades")
+	{
+		// Применение rate limiting к маршрутам
+		finalGradeGroup.Use(middleware.RateLimiterMiddleware())
+		finalGradeGroup.Use(middleware.AuthMiddleware(repos.Sessions))
+
+		finalGradeGroup.GET("/:id", middleware.RequirePermission(policy.PermGradesRead), handlers.GetFinalGradeByID(repos.FinalGrades))
+		// Закрытую оценку handlers.UpdateFinalGrade меняет только при праве grades:override
+		finalGradeGroup.PUT("/:id", middleware.RequirePermission(policy.PermGradesWrite), handlers.UpdateFinalGrade(repos.FinalGrades))
+		finalGradeGroup.POST("/:id/unlock", middleware.RequirePermission(policy.PermGradesOverride), handlers.UnlockFinalGrade(repos.FinalGrades))
+	}
+}
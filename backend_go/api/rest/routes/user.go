@@ -1,24 +1,40 @@
 package routes
 
 import (
-	"database/sql"
 	"github.com/VladislavSCV/api/middleware"
 	"github.com/VladislavSCV/api/rest/handlers"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupUserRoutes(router *gin.Engine, db *sql.DB) {
+func SetupUserRoutes(router *gin.Engine, repos *repository.Repositories) {
 	userGroup := router.Group("/api/user")
 	{
 		// Применение rate limiting к маршрутам
 		userGroup.Use(middleware.RateLimiterMiddleware())
-		userGroup.GET("/", handlers.GetUsers(db))
-		userGroup.GET("/students", handlers.GetStudents(db))
-		userGroup.GET("/teachers", handlers.GetTeachers(db))
-		userGroup.GET("/:id", handlers.GetUserByID(db))
+		userGroup.Use(middleware.AuthMiddleware(repos.Sessions))
 
-		// Ограничение доступа для обновления и удаления пользователей только для администраторов
-		userGroup.PUT("/:id", middleware.AuthMiddleware(db), middleware.RoleMiddleware([]string{"admin"}), handlers.UpdateUser(db))
-		userGroup.DELETE("/:id", middleware.AuthMiddleware(db), middleware.RoleMiddleware([]string{"admin"}), handlers.DeleteUser(db))
+		// Выборка дополнительно сужается по правам records:* (студентов преподаватель видит только из своих групп)
+		userGroup.GET("/", middleware.RequirePermission(policy.PermUsersRead), handlers.GetUsers(repos.Users))
+		userGroup.GET("/students", middleware.RequirePermission(policy.PermStudentsRead), handlers.GetStudents(repos.Users))
+		userGroup.GET("/teachers", handlers.GetTeachers(repos.Users))
+		userGroup.GET("/:id", handlers.GetUserByID(repos.Users))
+		userGroup.GET("/:id/groups", handlers.GetUserGroups(repos.Users, repos.Groups))
+
+		// Ограничение доступа для обновления и удаления пользователей правом users:manage
+		userGroup.PUT("/:id", middleware.RequirePermission(policy.PermUsersManage), handlers.UpdateUser(repos.Users))
+		userGroup.PATCH("/:id", middleware.RequirePermission(policy.PermUsersManage), handlers.UpdateUser(repos.Users))
+		userGroup.DELETE("/:id", middleware.RequirePermission(policy.PermUsersManage), handlers.DeleteUser(repos.Users))
+
+		// Импорт создаёт и студентов, и недостающие группы
+		userGroup.POST("/import",
+			middleware.RequirePermission(policy.PermUsersManage),
+			middleware.RequirePermission(policy.PermGroupsManage),
+			handlers.ImportStudents(repos.Users),
+		)
+
+		// Назначение роли — отдельная операция, role_id через PATCH не меняется
+		userGroup.PUT("/:id/role", middleware.RequirePermission(policy.PermRolesManage), handlers.AssignRole(repos.Roles, repos.Sessions))
 	}
 }
@@ -1 +1,200 @@
 package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"github.com/VladislavSCV/internal/config"
+	"github.com/VladislavSCV/internal/database"
+	"github.com/VladislavSCV/internal/database/migrations"
+	"github.com/VladislavSCV/internal/importer"
+	"github.com/VladislavSCV/internal/repository/postgres"
+	"github.com/VladislavSCV/internal/tabular"
+	"log"
+	"os"
+	"strconv"
+)
+
+const usage = `Usage: cli <command> [arguments]
+
+Commands:
+  migrate up [N]     применить N (по умолчанию все) неприменённых миграций
+  migrate down [N]   откатить N (по умолчанию 1) последних миграций
+  migrate status     показать состояние миграций
+  migrate redo       откатить и заново применить последнюю миграцию
+  import students [--dry-run] [--format csv|xlsx] FILE
+                     импортировать студентов из CSV или XLSX; пароли выводятся один раз
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "migrate":
+		runMigrate(os.Args[2:])
+	case "import":
+		runImport(os.Args[2:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
+
+// connect подключается к базе по настройкам из окружения
+func connect() *sql.DB {
+	cfg, err := config.LoadDatabase()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	db, err := database.ConnectDB(*cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to the database: %v", err)
+	}
+	return db
+}
+
+func runImport(args []string) {
+	if len(args) < 1 || args[0] != "students" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("import students", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "только проверить файл и показать результат")
+	format := flags.String("format", "", "формат файла: csv или xlsx (по умолчанию по расширению)")
+	flags.Parse(args[1:])
+	if flags.NArg() != 1 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = tabular.Format(path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("Failed to read file: %v", err)
+	}
+
+	db := connect()
+	defer db.Close()
+
+	result, err := importer.Students(postgres.New(db).Users, data, *format, *dryRun)
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+
+	if len(result.Errors) > 0 {
+		for _, e := range result.Errors {
+			if e.Column != "" {
+				fmt.Fprintf(os.Stderr, "line %d, %s: %s\n", e.Line, e.Column, e.Error)
+			} else {
+				fmt.Fprintf(os.Stderr, "line %d: %s\n", e.Line, e.Error)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "%d error(s), nothing imported\n", len(result.Errors))
+		db.Close()
+		os.Exit(1)
+	}
+
+	for _, group := range result.CreatedGroups {
+		fmt.Fprintf(os.Stderr, "new group  %s\n", group.Name)
+	}
+	// Логины и пароли идут в stdout отдельно от сводки, чтобы их можно было сохранить в файл
+	writer := csv.NewWriter(os.Stdout)
+	writer.Write([]string{"line", "login", "name", "group", "password"})
+	for _, student := range result.Students {
+		writer.Write([]string{strconv.Itoa(student.Line), student.Login, student.Name, student.Group, student.Password})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Fatalf("Failed to write result: %v", err)
+	}
+
+	if *dryRun {
+		fmt.Fprintf(os.Stderr, "dry run: %d student(s) and %d new group(s) would be imported\n",
+			len(result.Students), len(result.CreatedGroups))
+	} else {
+		fmt.Fprintf(os.Stderr, "imported %d student(s), created %d group(s)\n",
+			len(result.Students), len(result.CreatedGroups))
+	}
+}
+
+func runMigrate(args []string) {
+	if len(args) < 1 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	steps := 0
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			log.Fatalf("invalid number of steps: %s", args[1])
+		}
+		steps = n
+	}
+
+	db := connect()
+	defer db.Close()
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(steps)
+		for _, m := range applied {
+			fmt.Printf("applied  %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("database is up to date")
+		}
+	case "down":
+		rolledBack, err := migrator.Down(steps)
+		for _, m := range rolledBack {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		if len(rolledBack) == 0 {
+			fmt.Println("no applied migrations")
+		}
+	case "redo":
+		m, err := migrator.Redo()
+		if err != nil {
+			log.Fatalf("Redo failed: %v", err)
+		}
+		fmt.Printf("redone   %04d_%s\n", m.Version, m.Name)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Fatalf("Failed to get migration status: %v", err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied  " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Modified {
+				state += "  (MODIFIED)"
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, state)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command: %s\n\n%s", args[0], usage)
+		os.Exit(2)
+	}
+}
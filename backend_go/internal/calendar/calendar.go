@@ -0,0 +1,224 @@
+// Package calendar формирует ленты iCalendar (RFC 5545) из недельного расписания:
+// каждое занятие становится VEVENT с еженедельным RRULE в границах семестра.
+package calendar
+
+import (
+	"fmt"
+	"github.com/VladislavSCV/internal/config"
+	"github.com/VladislavSCV/internal/models"
+	"io"
+	"strings"
+	"time"
+	_ "time/tzdata" // Часовые пояса не зависят от наличия zoneinfo в образе
+)
+
+// uidDomain — правая часть UID событий; UID стабилен, пока жива строка schedules
+const uidDomain = "schedule.vladislavscv"
+
+// Settings — часовой пояс занятий
+type Settings struct {
+	Location *time.Location
+}
+
+var settings = Settings{Location: time.UTC}
+
+// Configure задаёт параметры лент из конфигурации сервера
+func Configure(cfg config.CalendarConfig) error {
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return fmt.Errorf("failed to load calendar timezone: %v", err)
+	}
+
+	settings = Settings{Location: loc}
+	return nil
+}
+
+// Today возвращает день now (полночь) в часовом поясе занятий
+func Today(now time.Time) time.Time {
+	now = now.In(settings.Location)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, settings.Location)
+}
+
+var weekdays = [...]string{"", "MO", "TU", "WE", "TH", "FR", "SA", "SU"}
+
+// WriteFeed пишет ленту с названием name, в которой занятия повторяются в границах учебного периода term.
+// Без периода (между семестрами) лента пуста; занятия, у которых в периоде нет ни одного повторения, пропускаются.
+func WriteFeed(w io.Writer, name string, schedules []models.Schedule, term *models.Term) error {
+	loc := settings.Location
+
+	f := &feed{w: w}
+	f.line("BEGIN", "VCALENDAR")
+	f.line("VERSION", "2.0")
+	f.line("PRODID", "-//VladislavSCV//Schedule//RU")
+	f.line("CALSCALE", "GREGORIAN")
+	f.line("METHOD", "PUBLISH")
+	f.line("X-WR-CALNAME", escapeText(name))
+	f.line("X-WR-TIMEZONE", loc.String())
+	if term == nil {
+		f.line("END", "VCALENDAR")
+		return f.err
+	}
+
+	// Даты периода хранятся без часового пояса: первый и последний день берутся в поясе занятий
+	termStart := inLocation(term.StartDate, loc)
+	termEnd := inLocation(term.EndDate, loc)
+	// UNTIL указывается в UTC и включает весь последний день семестра
+	until := termEnd.AddDate(0, 0, 1).Add(-time.Second).UTC()
+	writeTimezone(f, loc, termStart, termEnd.AddDate(0, 0, 1))
+
+	for _, schedule := range schedules {
+		if schedule.DayOfWeek < 1 || schedule.DayOfWeek > 7 {
+			continue
+		}
+		start, err := occurrence(termStart, schedule.DayOfWeek, schedule.StartTime)
+		if err != nil {
+			return fmt.Errorf("schedule %d: %v", schedule.ID, err)
+		}
+		end, err := occurrence(termStart, schedule.DayOfWeek, schedule.EndTime)
+		if err != nil {
+			return fmt.Errorf("schedule %d: %v", schedule.ID, err)
+		}
+		if start.After(until) {
+			continue
+		}
+
+		stamp := schedule.UpdatedAt.UTC().Format(utcLayout)
+		f.line("BEGIN", "VEVENT")
+		f.line("UID", fmt.Sprintf("schedule-%d@%s", schedule.ID, uidDomain))
+		f.line("DTSTAMP", stamp)
+		f.line("LAST-MODIFIED", stamp)
+		f.line("DTSTART;TZID="+loc.String(), start.Format(localLayout))
+		f.line("DTEND;TZID="+loc.String(), end.Format(localLayout))
+		f.line("RRULE", fmt.Sprintf("FREQ=WEEKLY;BYDAY=%s;UNTIL=%s", weekdays[schedule.DayOfWeek], until.Format(utcLayout)))
+		f.line("SUMMARY", escapeText(schedule.SubjectName))
+		if schedule.Location != "" {
+			f.line("LOCATION", escapeText(schedule.Location))
+		}
+		f.line("DESCRIPTION", escapeText(fmt.Sprintf("Преподаватель: %s\nГруппа: %s", schedule.TeacherName, schedule.GroupName)))
+		f.line("END", "VEVENT")
+	}
+
+	f.line("END", "VCALENDAR")
+	return f.err
+}
+
+const (
+	utcLayout   = "20060102T150405Z"
+	localLayout = "20060102T150405"
+)
+
+// inLocation возвращает полночь дня date в часовом поясе loc
+func inLocation(date time.Time, loc *time.Location) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+}
+
+// occurrence возвращает первое занятие в день недели dayOfWeek (1 — понедельник) не раньше termStart
+func occurrence(termStart time.Time, dayOfWeek int, clock string) (time.Time, error) {
+	t, err := parseClock(clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// time.Weekday: воскресенье — 0, в расписании — 7
+	weekday := int(termStart.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+	day := termStart.AddDate(0, 0, (dayOfWeek-weekday+7)%7)
+
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), t.Second(), 0, termStart.Location()), nil
+}
+
+func parseClock(value string) (time.Time, error) {
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", value)
+}
+
+// writeTimezone описывает часовой пояс loc на отрезке [from, to): начальное смещение
+// и все переходы внутри отрезка, чтобы TZID в DTSTART понимали и клиенты без базы IANA
+func writeTimezone(f *feed, loc *time.Location, from, to time.Time) {
+	f.line("BEGIN", "VTIMEZONE")
+	f.line("TZID", loc.String())
+
+	_, offset := from.Zone()
+	observance(f, from, offset, offset, from.IsDST())
+	for t := from; t.Before(to); {
+		next := t.AddDate(0, 0, 1)
+		if _, nextOffset := next.Zone(); nextOffset != offset {
+			// Переход внутри суток: ищем его с точностью до минуты
+			lo, hi := t, next
+			for hi.Sub(lo) > time.Minute {
+				mid := lo.Add(hi.Sub(lo) / 2)
+				if _, o := mid.Zone(); o == offset {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			transition := hi.Truncate(time.Minute)
+			observance(f, transition.In(time.FixedZone("", offset)), offset, nextOffset, transition.IsDST())
+			offset = nextOffset
+		}
+		t = next
+	}
+
+	f.line("END", "VTIMEZONE")
+}
+
+func observance(f *feed, start time.Time, from, to int, dst bool) {
+	kind := "STANDARD"
+	if dst {
+		kind = "DAYLIGHT"
+	}
+	f.line("BEGIN", kind)
+	f.line("DTSTART", start.Format(localLayout))
+	f.line("TZOFFSETFROM", formatOffset(from))
+	f.line("TZOFFSETTO", formatOffset(to))
+	f.line("END", kind)
+}
+
+func formatOffset(seconds int) string {
+	sign := '+'
+	if seconds < 0 {
+		sign = '-'
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%c%02d%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// escapeText экранирует значение типа TEXT (RFC 5545, 3.3.11)
+func escapeText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+// feed пишет строки содержимого с CRLF и переносом длинных строк по 75 октетов (RFC 5545, 3.1)
+type feed struct {
+	w   io.Writer
+	err error
+}
+
+func (f *feed) line(name, value string) {
+	if f.err != nil {
+		return
+	}
+
+	content := name + ":" + value
+	var b strings.Builder
+	width := 0
+	for _, r := range content {
+		size := len(string(r))
+		if width+size > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+
+	_, f.err = io.WriteString(f.w, b.String())
+}
@@ -0,0 +1,173 @@
+package calendar
+
+import (
+	"bytes"
+	"github.com/VladislavSCV/internal/config"
+	"github.com/VladislavSCV/internal/models"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// configure задаёт часовой пояс лент на время теста
+func configure(t *testing.T, timezone string) {
+	t.Helper()
+	previous := settings
+	if err := Configure(config.CalendarConfig{Timezone: timezone}); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	t.Cleanup(func() { settings = previous })
+}
+
+// render пишет ленту и возвращает её строки содержимого с развёрнутыми переносами
+func render(t *testing.T, schedules []models.Schedule, term *models.Term) []string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteFeed(&buf, "Расписание ИС-21", schedules, term); err != nil {
+		t.Fatalf("WriteFeed: %v", err)
+	}
+
+	raw := buf.String()
+	if !strings.HasSuffix(raw, "\r\n") {
+		t.Fatalf("feed does not end with CRLF")
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(raw, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+	}
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(raw, "\r\n ", ""), "\r\n"), "\r\n")
+}
+
+// events возвращает свойства каждого VEVENT ленты
+func events(lines []string) []map[string]string {
+	var result []map[string]string
+	var current map[string]string
+	for _, line := range lines {
+		switch {
+		case line == "BEGIN:VEVENT":
+			current = map[string]string{}
+		case line == "END:VEVENT":
+			result = append(result, current)
+			current = nil
+		case current != nil:
+			name, value, _ := strings.Cut(line, ":")
+			current[name] = value
+		}
+	}
+	return result
+}
+
+func autumnTerm() *models.Term {
+	return &models.Term{
+		Name:      "1 семестр",
+		StartDate: time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, time.December, 31, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestWriteFeed(t *testing.T) {
+	configure(t, "Europe/Berlin")
+	updated := time.Date(2026, time.August, 20, 12, 30, 0, 0, time.UTC)
+	schedules := []models.Schedule{
+		{ID: 1, DayOfWeek: 1, StartTime: "09:00:00", EndTime: "10:30:00", SubjectName: "Математика", TeacherName: "Анна Смирнова", GroupName: "ИС-21", Location: "101", UpdatedAt: updated},
+		{ID: 2, DayOfWeek: 2, StartTime: "10:40", EndTime: "12:10", SubjectName: "Базы данных; SQL, практика", TeacherName: "Олег Козлов", GroupName: "ИС-21", UpdatedAt: updated},
+		{ID: 3, DayOfWeek: 0, StartTime: "09:00", EndTime: "10:30", SubjectName: "Без дня недели"},
+	}
+	lines := render(t, schedules, autumnTerm())
+
+	if lines[0] != "BEGIN:VCALENDAR" || lines[len(lines)-1] != "END:VCALENDAR" {
+		t.Fatalf("feed is not a VCALENDAR: %q ... %q", lines[0], lines[len(lines)-1])
+	}
+	for _, want := range []string{"X-WR-CALNAME:Расписание ИС-21", "X-WR-TIMEZONE:Europe/Berlin", "TZID:Europe/Berlin"} {
+		if !slices.Contains(lines, want) {
+			t.Errorf("feed has no line %q", want)
+		}
+	}
+
+	// Переход на зимнее время 25 октября попадает в семестр и описан в VTIMEZONE
+	transition := []string{"BEGIN:STANDARD", "DTSTART:20261025T030000", "TZOFFSETFROM:+0200", "TZOFFSETTO:+0100", "END:STANDARD"}
+	if i := slices.Index(lines, "DTSTART:20261025T030000"); i < 1 || !slices.Equal(lines[i-1:i+4], transition) {
+		t.Errorf("VTIMEZONE has no transition %q", transition)
+	}
+
+	got := events(lines)
+	if len(got) != 2 {
+		t.Fatalf("got %d events, want 2 (schedule without a weekday is skipped)", len(got))
+	}
+	want := []map[string]string{
+		{
+			"UID":                        "schedule-1@" + uidDomain,
+			"DTSTAMP":                    "20260820T123000Z",
+			"LAST-MODIFIED":              "20260820T123000Z",
+			"DTSTART;TZID=Europe/Berlin": "20260907T090000",
+			"DTEND;TZID=Europe/Berlin":   "20260907T103000",
+			"RRULE":                      "FREQ=WEEKLY;BYDAY=MO;UNTIL=20261231T225959Z",
+			"SUMMARY":                    "Математика",
+			"LOCATION":                   "101",
+			"DESCRIPTION":                `Преподаватель: Анна Смирнова\nГруппа: ИС-21`,
+		},
+		{
+			"UID":                        "schedule-2@" + uidDomain,
+			"DTSTAMP":                    "20260820T123000Z",
+			"LAST-MODIFIED":              "20260820T123000Z",
+			"DTSTART;TZID=Europe/Berlin": "20260901T104000",
+			"DTEND;TZID=Europe/Berlin":   "20260901T121000",
+			"RRULE":                      "FREQ=WEEKLY;BYDAY=TU;UNTIL=20261231T225959Z",
+			"SUMMARY":                    `Базы данных\; SQL\, практика`,
+			"DESCRIPTION":                `Преподаватель: Олег Козлов\nГруппа: ИС-21`,
+		},
+	}
+	for i := range want {
+		for name, value := range want[i] {
+			if got[i][name] != value {
+				t.Errorf("event %d: %s = %q, want %q", i, name, got[i][name], value)
+			}
+		}
+		if len(got[i]) != len(want[i]) {
+			t.Errorf("event %d: properties %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWriteFeedWithoutTerm(t *testing.T) {
+	configure(t, "Europe/Moscow")
+	schedules := []models.Schedule{{ID: 1, DayOfWeek: 1, StartTime: "09:00", EndTime: "10:30", SubjectName: "Математика"}}
+
+	lines := render(t, schedules, nil)
+	if len(events(lines)) != 0 || slices.Contains(lines, "BEGIN:VTIMEZONE") {
+		t.Errorf("feed between terms has events or a time zone: %q", lines)
+	}
+}
+
+func TestWriteFeedSkipsSchedulesAfterTerm(t *testing.T) {
+	configure(t, "UTC")
+	// Семестр из одного вторника: занятие по понедельникам в него не попадает
+	term := &models.Term{StartDate: time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)}
+	schedules := []models.Schedule{
+		{ID: 1, DayOfWeek: 1, StartTime: "09:00", EndTime: "10:30"},
+		{ID: 2, DayOfWeek: 2, StartTime: "09:00", EndTime: "10:30"},
+	}
+
+	got := events(render(t, schedules, term))
+	if len(got) != 1 || got[0]["UID"] != "schedule-2@"+uidDomain {
+		t.Errorf("events = %v, want only schedule 2", got)
+	}
+}
+
+func TestWriteFeedRejectsInvalidTime(t *testing.T) {
+	configure(t, "UTC")
+	schedules := []models.Schedule{{ID: 7, DayOfWeek: 1, StartTime: "9 утра", EndTime: "10:30"}}
+
+	err := WriteFeed(&bytes.Buffer{}, "Расписание", schedules, autumnTerm())
+	if err == nil || !strings.Contains(err.Error(), "schedule 7") {
+		t.Errorf("WriteFeed() error = %v, want an error for schedule 7", err)
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	if got, want := escapeText("a\\b;c,d\ne"), `a\\b\;c\,d\ne`; got != want {
+		t.Errorf("escapeText() = %q, want %q", got, want)
+	}
+}
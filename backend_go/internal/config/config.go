@@ -0,0 +1,366 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config — вся конфигурация сервера
+type Config struct {
+	// Storage — хранилище данных: postgres (по умолчанию) или memory (демо без базы)
+	Storage   string          `yaml:"storage"`
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	JWT       JWTConfig       `yaml:"jwt"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Calendar  CalendarConfig  `yaml:"calendar"`
+	Report    ReportConfig    `yaml:"report"`
+}
+
+type ServerConfig struct {
+	Port int `yaml:"port"`
+}
+
+type DatabaseConfig struct {
+	DSN          string `yaml:"dsn"`
+	MaxOpenConns int    `yaml:"max_open_conns"`
+	MaxIdleConns int    `yaml:"max_idle_conns"`
+	AutoMigrate  bool   `yaml:"auto_migrate"` // Применять миграции при старте сервера
+}
+
+type JWTConfig struct {
+	Secret     string        `yaml:"secret"`
+	AccessTTL  time.Duration `yaml:"access_ttl"`
+	RefreshTTL time.Duration `yaml:"refresh_ttl"`
+}
+
+type RateLimitConfig struct {
+	Requests uint          `yaml:"requests"`
+	Period   time.Duration `yaml:"period"`
+}
+
+// CalendarConfig — параметры лент расписания в формате iCalendar
+type CalendarConfig struct {
+	// Timezone — часовой пояс занятий (имя из базы IANA). Границы семестра для лент
+	// берутся из учебных периодов (/api/terms), а не из конфигурации
+	Timezone string `yaml:"timezone"`
+}
+
+// ReportConfig — шапка и подпись печатных табелей и справок об успеваемости
+type ReportConfig struct {
+	// SchoolName — название учебного заведения в шапке; пустое — шапка без названия
+	SchoolName string `yaml:"school_name"`
+	// HeaderLines — строки под названием: адрес, телефон, реквизиты
+	HeaderLines []string `yaml:"header_lines"`
+	// Signatory — должность и имя подписывающего, например «Директор И. И. Иванов»; пустое — без подписи
+	Signatory string `yaml:"signatory"`
+}
+
+// DateLayout — формат дат в конфигурации
+const DateLayout = "2006-01-02"
+
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+// Минимальная длина секрета для HS256
+const minJWTSecretLength = 32
+
+// Default возвращает конфигурацию со значениями по умолчанию.
+// Секреты (DSN и ключ JWT) по умолчанию пустые и должны быть заданы явно.
+func Default() Config {
+	return Config{
+		Storage: StoragePostgres,
+		Server: ServerConfig{
+			Port: 8080,
+		},
+		Database: DatabaseConfig{
+			MaxOpenConns: 25,
+			MaxIdleConns: 5,
+		},
+		JWT: JWTConfig{
+			AccessTTL:  15 * time.Minute,
+			RefreshTTL: 30 * 24 * time.Hour,
+		},
+		RateLimit: RateLimitConfig{
+			Requests: 10,
+			Period:   time.Minute,
+		},
+		Calendar: CalendarConfig{
+			Timezone: "Europe/Moscow",
+		},
+	}
+}
+
+// Load собирает конфигурацию в порядке возрастания приоритета:
+// значения по умолчанию, YAML-файл (CONFIG_FILE, по умолчанию config.yaml),
+// файл .env (ENV_FILE, по умолчанию .env) и переменные окружения.
+// Оба файла необязательны. Итоговая конфигурация проверяется через Validate.
+func Load() (*Config, error) {
+	cfg, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// LoadDatabase загружает конфигурацию так же, как Load, но проверяет только
+// настройки базы. Нужна утилитам (cmd/cli), которым не требуются секреты сервера.
+func LoadDatabase() (*DatabaseConfig, error) {
+	cfg, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	if errs := cfg.validateDatabase(); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+
+	return &cfg.Database, nil
+}
+
+func load() (*Config, error) {
+	cfg := Default()
+
+	configFile := getEnv("CONFIG_FILE", "config.yaml")
+	if err := loadYAML(configFile, &cfg); err != nil {
+		return nil, err
+	}
+
+	envFile := getEnv("ENV_FILE", ".env")
+	if err := loadDotEnv(envFile); err != nil {
+		return nil, err
+	}
+
+	if err := applyEnv(&cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// Validate проверяет обязательные параметры и диапазоны значений
+func (c *Config) Validate() error {
+	var errs []error
+
+	switch c.Storage {
+	case StoragePostgres:
+		errs = append(errs, c.validateDatabase()...)
+	case StorageMemory:
+	default:
+		errs = append(errs, fmt.Errorf("storage must be %q or %q, got %q", StoragePostgres, StorageMemory, c.Storage))
+	}
+
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port must be between 1 and 65535, got %d", c.Server.Port))
+	}
+	if c.JWT.Secret == "" {
+		errs = append(errs, errors.New("jwt.secret is required (JWT_SECRET)"))
+	} else if len(c.JWT.Secret) < minJWTSecretLength {
+		errs = append(errs, fmt.Errorf("jwt.secret must be at least %d characters long", minJWTSecretLength))
+	}
+	if c.JWT.AccessTTL <= 0 {
+		errs = append(errs, errors.New("jwt.access_ttl must be positive"))
+	}
+	if c.JWT.RefreshTTL <= c.JWT.AccessTTL {
+		errs = append(errs, errors.New("jwt.refresh_ttl must be greater than jwt.access_ttl"))
+	}
+	if c.RateLimit.Requests == 0 {
+		errs = append(errs, errors.New("rate_limit.requests must be positive"))
+	}
+	if c.RateLimit.Period <= 0 {
+		errs = append(errs, errors.New("rate_limit.period must be positive"))
+	}
+	errs = append(errs, c.Calendar.validate()...)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+func (c *Config) validateDatabase() []error {
+	var errs []error
+
+	if c.Database.DSN == "" {
+		errs = append(errs, errors.New("database.dsn is required (DATABASE_URL)"))
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("database connection limits must not be negative"))
+	}
+
+	return errs
+}
+
+func (c CalendarConfig) validate() []error {
+	var errs []error
+
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		errs = append(errs, fmt.Errorf("calendar.timezone: %v", err))
+	}
+
+	return errs
+}
+
+// Addr возвращает адрес для http-сервера
+func (s ServerConfig) Addr() string {
+	return ":" + strconv.Itoa(s.Port)
+}
+
+func loadYAML(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read config file %s: %v", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+
+	return nil
+}
+
+// loadDotEnv читает файл вида KEY=VALUE в окружение процесса.
+// Уже заданные переменные окружения не перезаписываются.
+func loadDotEnv(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to open env file %s: %v", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("%s:%d: expected KEY=VALUE", path, lineNum)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+
+		if _, exists := os.LookupEnv(key); exists {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("%s:%d: %v", path, lineNum, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read env file %s: %v", path, err)
+	}
+
+	return nil
+}
+
+func applyEnv(cfg *Config) error {
+	var err error
+
+	if v, ok := os.LookupEnv("STORAGE"); ok {
+		cfg.Storage = v
+	}
+	if v, ok := os.LookupEnv("SERVER_PORT"); ok {
+		if cfg.Server.Port, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("invalid SERVER_PORT: %v", err)
+		}
+	}
+	if v, ok := os.LookupEnv("DATABASE_URL"); ok {
+		cfg.Database.DSN = v
+	}
+	if v, ok := os.LookupEnv("DATABASE_MAX_OPEN_CONNS"); ok {
+		if cfg.Database.MaxOpenConns, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("invalid DATABASE_MAX_OPEN_CONNS: %v", err)
+		}
+	}
+	if v, ok := os.LookupEnv("DATABASE_MAX_IDLE_CONNS"); ok {
+		if cfg.Database.MaxIdleConns, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("invalid DATABASE_MAX_IDLE_CONNS: %v", err)
+		}
+	}
+	if v, ok := os.LookupEnv("DATABASE_AUTO_MIGRATE"); ok {
+		if cfg.Database.AutoMigrate, err = strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("invalid DATABASE_AUTO_MIGRATE: %v", err)
+		}
+	}
+	if v, ok := os.LookupEnv("JWT_SECRET"); ok {
+		cfg.JWT.Secret = v
+	}
+	if v, ok := os.LookupEnv("JWT_ACCESS_TTL"); ok {
+		if cfg.JWT.AccessTTL, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("invalid JWT_ACCESS_TTL: %v", err)
+		}
+	}
+	if v, ok := os.LookupEnv("JWT_REFRESH_TTL"); ok {
+		if cfg.JWT.RefreshTTL, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("invalid JWT_REFRESH_TTL: %v", err)
+		}
+	}
+	if v, ok := os.LookupEnv("RATE_LIMIT_REQUESTS"); ok {
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid RATE_LIMIT_REQUESTS: %v", err)
+		}
+		cfg.RateLimit.Requests = uint(n)
+	}
+	if v, ok := os.LookupEnv("RATE_LIMIT_PERIOD"); ok {
+		if cfg.RateLimit.Period, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("invalid RATE_LIMIT_PERIOD: %v", err)
+		}
+	}
+	if v, ok := os.LookupEnv("CALENDAR_TIMEZONE"); ok {
+		cfg.Calendar.Timezone = v
+	}
+	if v, ok := os.LookupEnv("REPORT_SCHOOL_NAME"); ok {
+		cfg.Report.SchoolName = v
+	}
+	// Строки шапки в переменной окружения разделяются символом «|»
+	if v, ok := os.LookupEnv("REPORT_HEADER_LINES"); ok {
+		cfg.Report.HeaderLines = nil
+		for _, line := range strings.Split(v, "|") {
+			if line = strings.TrimSpace(line); line != "" {
+				cfg.Report.HeaderLines = append(cfg.Report.HeaderLines, line)
+			}
+		}
+	}
+	if v, ok := os.LookupEnv("REPORT_SIGNATORY"); ok {
+		cfg.Report.Signatory = v
+	}
+
+	return nil
+}
+
+func getEnv(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return v
+	}
+	return fallback
+}
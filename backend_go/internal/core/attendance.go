@@ -2,70 +2,87 @@ package core
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"sort"
+	"time"
 )
 
-func GetAttendanceByStudentID(db *sql.DB, studentID int) ([]models.AttendanceDetail, error) {
-	var attendances []models.AttendanceDetail
-
-	rows, err := db.Query(`
-        SELECT a.id, 
+// ListAttendance возвращает страницу отметок посещаемости, доступных scope
+func ListAttendance(db *sql.DB, scope policy.Scope, filter models.RecordFilter, page models.ListQuery) ([]models.AttendanceDetail, models.PageInfo, error) {
+	q := listQuery{
+		columns: `a.id, 
                a.student_id, 
+               u.first_name || ' ' || u.last_name AS student_name, 
                s.name AS subject_name, 
                a.date, 
                a.status, 
+               a.lesson_id, 
                a.created_at, 
-               a.updated_at
-        FROM attendance a
-        JOIN subjects s ON a.subject_id = s.id
-        WHERE a.student_id = $1
-    `, studentID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch attendance: %v", err)
+               a.updated_at`,
+		from: `attendance a
+        JOIN users u ON a.student_id = u.id
+        JOIN subjects s ON a.subject_id = s.id`,
+		sorts: map[string]string{
+			"date":         "a.date",
+			"id":           "a.id",
+			"status":       "a.status",
+			"subject_name": "s.name",
+			"student_name": "u.first_name || ' ' || u.last_name",
+			"created_at":   "a.created_at",
+		},
+		id: "a.id",
 	}
-	defer rows.Close()
+	q.recordWhere(scope, filter, "a.student_id", "a.subject_id", "a.date", "a.status")
 
-	for rows.Next() {
+	attendances, info, err := listPage(db, q, page, func(rows *sql.Rows, sortValue *string) (models.AttendanceDetail, int, error) {
 		var attendance models.AttendanceDetail
 		if err := rows.Scan(
 			&attendance.ID,
 			&attendance.StudentID,
+			&attendance.StudentName,
 			&attendance.SubjectName,
 			&attendance.Date,
 			&attendance.Status,
+			&attendance.LessonID,
 			&attendance.CreatedAt,
 			&attendance.UpdatedAt,
+			sortValue,
 		); err != nil {
-			return nil, fmt.Errorf("failed to scan attendance: %v", err)
+			return attendance, 0, fmt.Errorf("failed to scan attendance: %v", err)
 		}
-		attendances = append(attendances, attendance)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating over attendance: %v", err)
+		return attendance, attendance.ID, nil
+	})
+	if err != nil {
+		return nil, info, fmt.Errorf("failed to fetch attendance: %v", err)
 	}
 
-	return attendances, nil
+	return attendances, info, nil
 }
 
-func GetAttendanceByGroupID(db *sql.DB, groupID int) ([]models.AttendanceDetail, error) {
+// GetAttendanceByLessonID возвращает отметки, сделанные на занятии, в пределах scope
+func GetAttendanceByLessonID(db *sql.DB, scope policy.Scope, lessonID int) ([]models.AttendanceDetail, error) {
 	var attendances []models.AttendanceDetail
 
+	filter, args := recordFilter(scope, "a.student_id", "a.subject_id", "a.date", 2)
 	rows, err := db.Query(`
-        SELECT a.id, 
-               a.student_id, 
-               u.first_name || ' ' || u.last_name AS student_name, 
-               s.name AS subject_name, 
-               a.date, 
-               a.status, 
-               a.created_at, 
+        SELECT a.id,
+               a.student_id,
+               u.first_name || ' ' || u.last_name AS student_name,
+               s.name AS subject_name,
+               a.date,
+               a.status,
+               a.lesson_id,
+               a.created_at,
                a.updated_at
         FROM attendance a
         JOIN users u ON a.student_id = u.id
         JOIN subjects s ON a.subject_id = s.id
-        WHERE u.group_id = $1
-    `, groupID)
+        WHERE a.lesson_id = $1 AND `+filter+`
+        ORDER BY u.last_name, u.first_name, a.id`, append([]interface{}{lessonID}, args...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch attendance: %v", err)
 	}
@@ -80,6 +97,7 @@ func GetAttendanceByGroupID(db *sql.DB, groupID int) ([]models.AttendanceDetail,
 			&attendance.SubjectName,
 			&attendance.Date,
 			&attendance.Status,
+			&attendance.LessonID,
 			&attendance.CreatedAt,
 			&attendance.UpdatedAt,
 		); err != nil {
@@ -95,30 +113,290 @@ func GetAttendanceByGroupID(db *sql.DB, groupID int) ([]models.AttendanceDetail,
 	return attendances, nil
 }
 
-func CreateAttendance(db *sql.DB, attendance models.Attendance) (int, error) {
+// attendanceOwner возвращает студента, предмет и дату отметки, чтобы проверить доступ до изменения.
+// Строка блокируется до конца транзакции, как в gradeOwner
+func attendanceOwner(tx *sql.Tx, attendanceID int) (int, int, time.Time, error) {
+	var studentID, subjectID int
+	var date time.Time
+	err := tx.QueryRow("SELECT student_id, subject_id, date FROM attendance WHERE id = $1 FOR UPDATE", attendanceID).
+		Scan(&studentID, &subjectID, &date)
+	if err == sql.ErrNoRows {
+		return 0, 0, time.Time{}, fmt.Errorf("attendance not found")
+	} else if err != nil {
+		return 0, 0, time.Time{}, fmt.Errorf("failed to fetch attendance: %v", err)
+	}
+
+	return studentID, subjectID, date, nil
+}
+
+// CreateAttendance сохраняет отметку и запись о ней в истории изменений
+func CreateAttendance(db *sql.DB, scope policy.Scope, attendance models.Attendance, reason string) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	attendanceID, err := createAttendance(tx, scope, attendance, reason)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit attendance creation: %v", err)
+	}
+
+	return attendanceID, nil
+}
+
+// createAttendance — CreateAttendance внутри уже начатой транзакции
+func createAttendance(tx *sql.Tx, scope policy.Scope, attendance models.Attendance, reason string) (int, error) {
+	if err := authorizeRecord(tx, scope, attendance.StudentID, attendance.SubjectID, attendance.Date); err != nil {
+		return 0, err
+	}
+	if err := checkLesson(tx, attendance.LessonID, attendance.StudentID, attendance.SubjectID, attendance.Date); err != nil {
+		return 0, err
+	}
+
 	var attendanceID int
 
-	err := db.QueryRow(`
-        INSERT INTO attendance (student_id, subject_id, date, status, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, NOW(), NOW())
+	err := tx.QueryRow(`
+        INSERT INTO attendance (student_id, subject_id, date, status, lesson_id, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
         RETURNING id
-    `, attendance.StudentID, attendance.SubjectID, attendance.Date, attendance.Status).Scan(&attendanceID)
+    `, attendance.StudentID, attendance.SubjectID, attendance.Date, attendance.Status, attendance.LessonID).Scan(&attendanceID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create attendance: %v", err)
 	}
 
+	if err := logChange(tx, scope, models.RecordAttendance, attendanceID, models.ChangeCreate, nil, reason); err != nil {
+		return 0, err
+	}
+
 	return attendanceID, nil
 }
 
-func UpdateAttendance(db *sql.DB, attendance models.Attendance) error {
-	_, err := db.Exec(`
+// UpdateAttendance, как и UpdateGrade, проверяет доступ к прежней и к новой записи
+func UpdateAttendance(db *sql.DB, scope policy.Scope, attendance models.Attendance, reason string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := updateAttendance(tx, scope, attendance, reason); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit attendance update: %v", err)
+	}
+
+	return nil
+}
+
+// updateAttendance — UpdateAttendance внутри уже начатой транзакции
+func updateAttendance(tx *sql.Tx, scope policy.Scope, attendance models.Attendance, reason string) error {
+	studentID, subjectID, date, err := attendanceOwner(tx, attendance.ID)
+	if err != nil {
+		return err
+	}
+	if err := authorizeRecord(tx, scope, studentID, subjectID, date); err != nil {
+		return err
+	}
+	if err := authorizeRecord(tx, scope, attendance.StudentID, attendance.SubjectID, attendance.Date); err != nil {
+		return err
+	}
+	if err := checkLesson(tx, attendance.LessonID, attendance.StudentID, attendance.SubjectID, attendance.Date); err != nil {
+		return err
+	}
+
+	old, err := rowSnapshot(tx, models.RecordAttendance, attendance.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
         UPDATE attendance
-        SET student_id = $1, subject_id = $2, date = $3, status = $4, updated_at = NOW()
-        WHERE id = $5
-    `, attendance.StudentID, attendance.SubjectID, attendance.Date, attendance.Status, attendance.ID)
+        SET student_id = $1, subject_id = $2, date = $3, status = $4, lesson_id = $5, updated_at = NOW()
+        WHERE id = $6
+    `, attendance.StudentID, attendance.SubjectID, attendance.Date, attendance.Status, attendance.LessonID, attendance.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update attendance: %v", err)
 	}
 
+	if err := logChange(tx, scope, models.RecordAttendance, attendance.ID, models.ChangeUpdate, old, reason); err != nil {
+		return err
+	}
+
 	return nil
 }
+
+// RollCall сохраняет отметки группы одной транзакцией: для каждого студента обновляет его отметку
+// на этом занятии (или, без занятия, отметку по предмету за день) либо создаёт новую.
+// Студенты не из группы, неверные статусы и записи вне scope попадают в Rejected, не прерывая перекличку.
+// Каждая созданная и изменённая отметка попадает в историю с причиной reason.
+func RollCall(db *sql.DB, scope policy.Scope, call models.RollCall, reason string) (*models.RollCallResult, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if call.LessonID != nil {
+		var groupID, subjectID int
+		var date time.Time
+		err := tx.QueryRow(`SELECT group_id, subject_id, date FROM lessons WHERE id = $1`, *call.LessonID).
+			Scan(&groupID, &subjectID, &date)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("lesson not found")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch lesson: %v", err)
+		}
+		if (call.GroupID != 0 && call.GroupID != groupID) || (call.SubjectID != 0 && call.SubjectID != subjectID) ||
+			(!call.Date.IsZero() && call.Date.Format(lessonDateLayout) != date.Format(lessonDateLayout)) {
+			return nil, repository.ErrLessonMismatch
+		}
+		call.GroupID, call.SubjectID, call.Date = groupID, subjectID, date
+	}
+
+	var groupExists, subjectExists bool
+	err = tx.QueryRow(`
+        SELECT EXISTS (SELECT 1 FROM groups WHERE id = $1),
+               EXISTS (SELECT 1 FROM subjects WHERE id = $2)
+    `, call.GroupID, call.SubjectID).Scan(&groupExists, &subjectExists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check roll call: %v", err)
+	}
+	if !groupExists {
+		return nil, fmt.Errorf("group not found")
+	}
+	if !subjectExists {
+		return nil, fmt.Errorf("subject not found")
+	}
+
+	// Студенты группы — те, кто состоял в ней в день переклички
+	rows, err := tx.Query(`
+        SELECT gm.student_id FROM group_memberships gm
+        WHERE gm.group_id = $1 AND `+membershipCovers("gm", "$2")+`
+        ORDER BY gm.student_id
+    `, call.GroupID, call.Date.Format(lessonDateLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch group students: %v", err)
+	}
+	members := make(map[int]bool)
+	var students []int
+	for rows.Next() {
+		var studentID int
+		if err := rows.Scan(&studentID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan student: %v", err)
+		}
+		members[studentID] = true
+		students = append(students, studentID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over group students: %v", err)
+	}
+
+	statuses, result := rollCallStatuses(call, members, students)
+	date := call.Date.Format(lessonDateLayout)
+	for _, studentID := range sortedStudentIDs(statuses) {
+		if err := authorizeRecord(tx, scope, studentID, call.SubjectID, call.Date); err != nil {
+			if errors.Is(err, policy.ErrForbidden) {
+				result.Rejected = append(result.Rejected, models.RollCallRejection{StudentID: studentID, Reason: err.Error()})
+				continue
+			}
+			return nil, err
+		}
+
+		// Отметка на этом занятии, а если её нет — отметка без занятия по предмету за день.
+		// Перекличка без занятия подхватывает любую отметку по предмету за день, иначе повтор создал бы дубль
+		var attendanceID int
+		err := tx.QueryRow(`
+            SELECT id FROM attendance
+            WHERE student_id = $1
+              AND (lesson_id = $2 OR (($2::int IS NULL OR lesson_id IS NULL) AND subject_id = $3 AND date = $4::date))
+            ORDER BY lesson_id IS NULL, id
+            LIMIT 1
+            FOR UPDATE
+        `, studentID, call.LessonID, call.SubjectID, date).Scan(&attendanceID)
+		switch {
+		case err == sql.ErrNoRows:
+			err = tx.QueryRow(`
+                INSERT INTO attendance (student_id, subject_id, date, status, lesson_id, created_at, updated_at)
+                VALUES ($1, $2, $3::date, $4, $5, NOW(), NOW())
+                RETURNING id
+            `, studentID, call.SubjectID, date, statuses[studentID], call.LessonID).Scan(&attendanceID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create attendance: %v", err)
+			}
+			if err := logChange(tx, scope, models.RecordAttendance, attendanceID, models.ChangeCreate, nil, reason); err != nil {
+				return nil, err
+			}
+			result.Created = append(result.Created, studentID)
+		case err != nil:
+			return nil, fmt.Errorf("failed to fetch attendance: %v", err)
+		default:
+			old, err := rowSnapshot(tx, models.RecordAttendance, attendanceID)
+			if err != nil {
+				return nil, err
+			}
+			_, err = tx.Exec(`
+                UPDATE attendance SET status = $1, lesson_id = COALESCE($2, lesson_id), updated_at = NOW() WHERE id = $3
+            `, statuses[studentID], call.LessonID, attendanceID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update attendance: %v", err)
+			}
+			if err := logChange(tx, scope, models.RecordAttendance, attendanceID, models.ChangeUpdate, old, reason); err != nil {
+				return nil, err
+			}
+			result.Updated = append(result.Updated, studentID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit roll call: %v", err)
+	}
+
+	return result, nil
+}
+
+// rollCallStatuses отбирает отметки, которые можно сохранить: студенты группы с допустимым статусом
+// и, при DefaultPresent, остальные студенты группы со статусом present. Отклонённые попадают в результат
+func rollCallStatuses(call models.RollCall, members map[int]bool, students []int) (map[int]string, *models.RollCallResult) {
+	result := &models.RollCallResult{Created: []int{}, Updated: []int{}, Rejected: []models.RollCallRejection{}}
+	statuses := make(map[int]string)
+
+	for _, studentID := range sortedStudentIDs(call.Statuses) {
+		status := call.Statuses[studentID]
+		switch {
+		case !members[studentID]:
+			result.Rejected = append(result.Rejected, models.RollCallRejection{StudentID: studentID, Reason: repository.ErrNotGroupMember.Error()})
+		case status != "present" && status != "absent" && status != "excused":
+			result.Rejected = append(result.Rejected, models.RollCallRejection{StudentID: studentID, Reason: repository.ErrInvalidAttendanceStatus.Error()})
+		default:
+			statuses[studentID] = status
+		}
+	}
+	if call.DefaultPresent {
+		for _, studentID := range students {
+			if _, listed := call.Statuses[studentID]; !listed {
+				statuses[studentID] = "present"
+			}
+		}
+	}
+
+	return statuses, result
+}
+
+func sortedStudentIDs(statuses map[int]string) []int {
+	ids := make([]int, 0, len(statuses))
+	for id := range statuses {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
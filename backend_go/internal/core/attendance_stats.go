@@ -0,0 +1,139 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"strings"
+)
+
+// attendanceStatsQuery собирает FROM и WHERE для статистики: записи в пределах scope и фильтра.
+// Группа отметки (g) — та, в которой студент состоял в день отметки
+func attendanceStatsQuery(scope policy.Scope, filter models.AttendanceStatsFilter) (string, []interface{}) {
+	where, args := recordFilter(scope, "a.student_id", "a.subject_id", "a.date", 1)
+	conditions := []string{where}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.GroupID != 0 {
+		conditions = append(conditions, memberAt("a.student_id", arg(filter.GroupID), "a.date"))
+	}
+	if filter.StudentID != 0 {
+		conditions = append(conditions, "a.student_id = "+arg(filter.StudentID))
+	}
+	if filter.SubjectID != 0 {
+		conditions = append(conditions, "a.subject_id = "+arg(filter.SubjectID))
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "a.date >= "+arg(filter.From.Format(lessonDateLayout))+"::date")
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "a.date <= "+arg(filter.To.Format(lessonDateLayout))+"::date")
+	}
+
+	return `
+        FROM attendance a
+        JOIN users u ON a.student_id = u.id
+        JOIN subjects s ON a.subject_id = s.id
+        LEFT JOIN group_memberships mb ON mb.student_id = a.student_id AND ` + membershipCovers("mb", "a.date") + `
+        LEFT JOIN groups g ON mb.group_id = g.id
+        WHERE ` + strings.Join(conditions, " AND "), args
+}
+
+// attendanceCountColumns считает отметки по статусам внутри GROUP BY
+const attendanceCountColumns = `
+               COUNT(*) FILTER (WHERE a.status = 'present'),
+               COUNT(*) FILTER (WHERE a.status = 'absent'),
+               COUNT(*) FILTER (WHERE a.status = 'excused')`
+
+// GetAttendanceStats возвращает посещаемость в разрезе by (models.StatsBy*), упорядоченную по названию
+func GetAttendanceStats(db *sql.DB, scope policy.Scope, filter models.AttendanceStatsFilter, by string) ([]models.AttendanceStat, error) {
+	var key, name string
+	switch by {
+	case models.StatsByStudent:
+		key, name = "u.id", "u.first_name || ' ' || u.last_name"
+	case models.StatsBySubject:
+		key, name = "s.id", "s.name"
+	case models.StatsByGroup:
+		key, name = "g.id", "g.name"
+	default:
+		return nil, fmt.Errorf("unknown attendance stats grouping %q", by)
+	}
+
+	from, args := attendanceStatsQuery(scope, filter)
+	if by == models.StatsByGroup {
+		// Отметки дней, когда студент не состоял в группе, в разрез по группам не попадают
+		from += " AND g.id IS NOT NULL"
+	}
+
+	rows, err := db.Query(`
+        SELECT `+key+`, `+name+`,`+attendanceCountColumns+`
+        `+from+`
+        GROUP BY `+key+`, `+name+`
+        ORDER BY 2, 1`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attendance stats: %v", err)
+	}
+	defer rows.Close()
+
+	stats := []models.AttendanceStat{}
+	for rows.Next() {
+		var stat models.AttendanceStat
+		var present, absent, excused int
+		if err := rows.Scan(&stat.ID, &stat.Name, &present, &absent, &excused); err != nil {
+			return nil, fmt.Errorf("failed to scan attendance stats: %v", err)
+		}
+		stat.AttendanceCounts = models.NewAttendanceCounts(present, absent, excused)
+		stats = append(stats, stat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over attendance stats: %v", err)
+	}
+
+	return stats, nil
+}
+
+// GetAttendanceByMonth возвращает посещаемость по календарным месяцам в хронологическом порядке
+func GetAttendanceByMonth(db *sql.DB, scope policy.Scope, filter models.AttendanceStatsFilter) ([]models.AttendanceMonth, error) {
+	from, args := attendanceStatsQuery(scope, filter)
+	rows, err := db.Query(`
+        SELECT TO_CHAR(a.date, 'YYYY-MM') AS month,`+attendanceCountColumns+`
+        `+from+`
+        GROUP BY month
+        ORDER BY month`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attendance trend: %v", err)
+	}
+	defer rows.Close()
+
+	months := []models.AttendanceMonth{}
+	for rows.Next() {
+		var month models.AttendanceMonth
+		var present, absent, excused int
+		if err := rows.Scan(&month.Month, &present, &absent, &excused); err != nil {
+			return nil, fmt.Errorf("failed to scan attendance trend: %v", err)
+		}
+		month.AttendanceCounts = models.NewAttendanceCounts(present, absent, excused)
+		months = append(months, month)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over attendance trend: %v", err)
+	}
+
+	return months, nil
+}
+
+// GetAbsentees возвращает студентов с долей пропусков без уважительной причины больше threshold процентов
+func GetAbsentees(db *sql.DB, scope policy.Scope, filter models.AttendanceStatsFilter, threshold float64) ([]models.AttendanceStat, error) {
+	stats, err := GetAttendanceStats(db, scope, filter, models.StatsByStudent)
+	if err != nil {
+		return nil, err
+	}
+	return repository.FilterAbsentees(stats, threshold), nil
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"strings"
+)
+
+// CreateAuditEntry добавляет запись в журнал аудита. Если логин не передан, берётся текущий логин actor_id.
+func CreateAuditEntry(db *sql.DB, entry models.AuditEntry) error {
+	_, err := db.Exec(`
+        INSERT INTO audit_log (actor_id, actor_login, ip, method, route, action, status, entity_type, entity_id, changes, created_at)
+        VALUES ($1, COALESCE(NULLIF($2, ''), (SELECT login FROM users WHERE id = $1), ''), $3, $4, $5, $6, $7, $8, $9, $10::jsonb, NOW())
+    `, entry.ActorID, entry.ActorLogin, entry.IP, entry.Method, entry.Route, entry.Action, entry.Status,
+		entry.EntityType, entry.EntityID,
+		sql.NullString{String: string(entry.Changes), Valid: entry.Changes != nil})
+	if err != nil {
+		return fmt.Errorf("failed to create audit entry: %v", err)
+	}
+
+	return nil
+}
+
+// GetAuditLog возвращает записи журнала аудита от новых к старым
+func GetAuditLog(db *sql.DB, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	var where []string
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.ActorID != 0 {
+		where = append(where, "actor_id = "+arg(filter.ActorID))
+	}
+	if filter.EntityType != "" {
+		where = append(where, "entity_type = "+arg(filter.EntityType))
+	}
+	if filter.EntityID != 0 {
+		where = append(where, "entity_id = "+arg(filter.EntityID))
+	}
+	if filter.Action != "" {
+		where = append(where, "action = "+arg(filter.Action))
+	}
+	if !filter.From.IsZero() {
+		where = append(where, "created_at >= "+arg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		where = append(where, "created_at < "+arg(filter.To))
+	}
+
+	query := `
+        SELECT id, actor_id, actor_login, ip, method, route, action, status, entity_type, entity_id, changes, created_at
+        FROM audit_log`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT " + arg(filter.Limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch audit log: %v", err)
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var entry models.AuditEntry
+		var actorID, entityID sql.NullInt64
+		var changes []byte
+		if err := rows.Scan(&entry.ID, &actorID, &entry.ActorLogin, &entry.IP, &entry.Method, &entry.Route,
+			&entry.Action, &entry.Status, &entry.EntityType, &entityID, &changes, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %v", err)
+		}
+		if actorID.Valid {
+			id := int(actorID.Int64)
+			entry.ActorID = &id
+		}
+		if entityID.Valid {
+			id := int(entityID.Int64)
+			entry.EntityID = &id
+		}
+		entry.Changes = changes
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over audit log: %v", err)
+	}
+
+	return entries, nil
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
 	"golang.org/x/crypto/argon2"
 )
 
@@ -14,20 +15,26 @@ func RegisterUser(db *sql.DB, user models.User) (int, error) {
 	var userID int
 
 	// Проверка уникальности логина
-	var existingID int
-	err := db.QueryRow("SELECT id FROM users WHERE login = $1", user.Login).Scan(&existingID)
-	if err == nil {
-		return 0, fmt.Errorf("login already exists")
-	} else if err != sql.ErrNoRows {
-		return 0, fmt.Errorf("database error: %v", err)
+	taken, err := loginTaken(db, 0, user.Login)
+	if err != nil {
+		return 0, err
+	}
+	if taken {
+		return 0, repository.ErrLoginExists
 	}
 
 	// Логирование для отладки
 	fmt.Printf("Generated salt: %s\n", user.Salt)
 	fmt.Printf("Generated hash: %s\n", user.Password)
 
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
 	// Вставка нового пользователя
-	err = db.QueryRow(
+	err = tx.QueryRow(
 		`INSERT INTO users (first_name, middle_name, last_name, role_id, group_id, login, password, salt, created_at, updated_at) 
 		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW()) 
 		 RETURNING id`,
@@ -36,6 +43,23 @@ func RegisterUser(db *sql.DB, user models.User) (int, error) {
 	if err != nil {
 		return 0, fmt.Errorf("failed to register user: %v", err)
 	}
+	// Первая группа студента — запись истории групп «с самого начала». История ведётся только
+	// для студентов: TransferStudent переводит только их, а у остальных она повисла бы навсегда
+	if user.GroupID != nil {
+		var role string
+		if err := tx.QueryRow(`SELECT value FROM roles WHERE id = $1`, user.RoleID).Scan(&role); err != nil {
+			return 0, fmt.Errorf("failed to fetch role: %v", err)
+		}
+		if role == "student" {
+			if _, err := addMembership(tx, userID, *user.GroupID, nil); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %v", err)
+	}
 
 	return userID, nil
 }
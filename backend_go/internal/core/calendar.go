@@ -0,0 +1,41 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SetCalendarToken сохраняет хеш токена подписки пользователя; прежний токен перестаёт действовать
+func SetCalendarToken(db *sql.DB, userID int, tokenHash string) error {
+	_, err := db.Exec(`
+        INSERT INTO calendar_tokens (user_id, token_hash, created_at)
+        VALUES ($1, $2, NOW())
+        ON CONFLICT (user_id) DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = NOW()
+    `, userID, tokenHash)
+	if err != nil {
+		return fmt.Errorf("failed to save calendar token: %v", err)
+	}
+
+	return nil
+}
+
+func DeleteCalendarToken(db *sql.DB, userID int) error {
+	if _, err := db.Exec("DELETE FROM calendar_tokens WHERE user_id = $1", userID); err != nil {
+		return fmt.Errorf("failed to delete calendar token: %v", err)
+	}
+
+	return nil
+}
+
+// GetCalendarTokenUser возвращает владельца токена подписки по его хешу
+func GetCalendarTokenUser(db *sql.DB, tokenHash string) (int, error) {
+	var userID int
+	err := db.QueryRow("SELECT user_id FROM calendar_tokens WHERE token_hash = $1", tokenHash).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("calendar token not found")
+	} else if err != nil {
+		return 0, fmt.Errorf("failed to fetch calendar token: %v", err)
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"strings"
+)
+
+// exportWhere собирает условия выгрузки — те же, что у списка оценок или посещаемости
+func exportWhere(scope policy.Scope, filter models.RecordFilter, studentCol, subjectCol, dateCol, statusCol string) (string, []interface{}) {
+	var q listQuery
+	q.recordWhere(scope, filter, studentCol, subjectCol, dateCol, statusCol)
+	return strings.Join(q.where, " AND "), q.args
+}
+
+// ExportGrades передаёт в fn оценки, доступные scope, по одной по мере чтения из базы,
+// чтобы выгрузка большой группы не собиралась в памяти. Ошибка fn прерывает выборку
+func ExportGrades(db *sql.DB, scope policy.Scope, filter models.RecordFilter, fn func(models.GradeDetail) error) error {
+	where, args := exportWhere(scope, filter, "g.student_id", "g.subject_id", "g.date", "")
+	rows, err := db.Query(`
+        SELECT g.id, 
+               g.student_id, 
+               u.first_name || ' ' || u.last_name AS student_name, 
+               s.name AS subject_name, 
+               g.value, 
+               g.date, 
+               g.lesson_id, 
+               g.grade_type_id, 
+               COALESCE(gt.name, '') AS grade_type, 
+               `+gradeWeight+` AS weight, 
+               g.comment, 
+               g.created_at, 
+               g.updated_at
+        FROM grades g
+        `+gradeWeightJoin+`
+        JOIN users u ON g.student_id = u.id
+        JOIN subjects s ON g.subject_id = s.id
+        WHERE `+where+`
+        ORDER BY g.date, u.last_name, u.first_name, g.id`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to fetch grades: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var grade models.GradeDetail
+		if err := rows.Scan(
+			&grade.ID,
+			&grade.StudentID,
+			&grade.StudentName,
+			&grade.SubjectName,
+			&grade.Value,
+			&grade.Date,
+			&grade.LessonID,
+			&grade.GradeTypeID,
+			&grade.GradeType,
+			&grade.Weight,
+			&grade.Comment,
+			&grade.CreatedAt,
+			&grade.UpdatedAt,
+		); err != nil {
+			return fmt.Errorf("failed to scan grade: %v", err)
+		}
+		if err := fn(grade); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating over grades: %v", err)
+	}
+
+	return nil
+}
+
+// ExportAttendance передаёт в fn отметки посещаемости, доступные scope, по одной по мере чтения из базы
+func ExportAttendance(db *sql.DB, scope policy.Scope, filter models.RecordFilter, fn func(models.AttendanceDetail) error) error {
+	where, args := exportWhere(scope, filter, "a.student_id", "a.subject_id", "a.date", "a.status")
+	rows, err := db.Query(`
+        SELECT a.id, 
+               a.student_id, 
+               u.first_name || ' ' || u.last_name AS student_name, 
+               s.name AS subject_name, 
+               a.date, 
+               a.status, 
+               a.lesson_id, 
+               a.created_at, 
+               a.updated_at
+        FROM attendance a
+        JOIN users u ON a.student_id = u.id
+        JOIN subjects s ON a.subject_id = s.id
+        WHERE `+where+`
+        ORDER BY a.date, u.last_name, u.first_name, a.id`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to fetch attendance: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var attendance models.AttendanceDetail
+		if err := rows.Scan(
+			&attendance.ID,
+			&attendance.StudentID,
+			&attendance.StudentName,
+			&attendance.SubjectName,
+			&attendance.Date,
+			&attendance.Status,
+			&attendance.LessonID,
+			&attendance.CreatedAt,
+			&attendance.UpdatedAt,
+		); err != nil {
+			return fmt.Errorf("failed to scan attendance: %v", err)
+		}
+		if err := fn(attendance); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating over attendance: %v", err)
+	}
+
+	return nil
+}
+
+// ExportUsers передаёт в fn пользователей под фильтром, которых видит scope, по одному по мере чтения из базы
+func ExportUsers(db *sql.DB, scope policy.Scope, filter models.UserFilter, fn func(models.User) error) error {
+	err := listEach(db, userListQuery(scope, filter), "u.last_name, u.first_name, u.id", func(rows *sql.Rows) (models.User, error) {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.FirstName, &user.MiddleName, &user.LastName, &user.Role, &user.Group, &user.Login, &user.CreatedAt, &user.UpdatedAt); err != nil {
+			return user, fmt.Errorf("failed to scan user: %v", err)
+		}
+		return user, nil
+	}, fn)
+	if err != nil {
+		return fmt.Errorf("failed to export users: %v", err)
+	}
+
+	return nil
+}
+
+// ExportSchedules передаёт в fn занятия расписания под фильтром по одному по мере чтения из базы
+func ExportSchedules(db *sql.DB, filter models.ScheduleFilter, fn func(models.Schedule) error) error {
+	err := listEach(db, scheduleListQuery(filter), "g.name, s.day_of_week, s.start_time, s.id", func(rows *sql.Rows) (models.Schedule, error) {
+		var schedule models.Schedule
+		if err := rows.Scan(
+			&schedule.ID,
+			&schedule.GroupName,
+			&schedule.SubjectName,
+			&schedule.TeacherName,
+			&schedule.DayOfWeek,
+			&schedule.StartTime,
+			&schedule.EndTime,
+			&schedule.Location,
+			&schedule.CreatedAt,
+			&schedule.UpdatedAt,
+			&schedule.SubjectID,
+			&schedule.GroupID,
+			&schedule.TeacherID,
+		); err != nil {
+			return schedule, fmt.Errorf("failed to scan schedule: %v", err)
+		}
+		return schedule, nil
+	}, fn)
+	if err != nil {
+		return fmt.Errorf("failed to export schedules: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,314 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"strings"
+	"time"
+)
+
+const finalGradeSelect = `
+        SELECT fg.id,
+               fg.term_id,
+               fg.student_id,
+               u.first_name || ' ' || u.last_name AS student_name,
+               fg.subject_id,
+               s.name AS subject_name,
+               fg.suggested::float8,
+               fg.value,
+               fg.locked,
+               fg.locked_at,
+               fg.locked_by,
+               fg.created_at,
+               fg.updated_at
+        FROM final_grades fg
+        JOIN users u ON fg.student_id = u.id
+        JOIN subjects s ON fg.subject_id = s.id`
+
+func scanFinalGrade(row interface{ Scan(...interface{}) error }, grade *models.FinalGrade) error {
+	var suggested sql.NullFloat64
+	var lockedAt sql.NullTime
+	var lockedBy sql.NullInt64
+	if err := row.Scan(&grade.ID, &grade.TermID, &grade.StudentID, &grade.StudentName, &grade.SubjectID, &grade.SubjectName,
+		&suggested, &grade.Value, &grade.Locked, &lockedAt, &lockedBy, &grade.CreatedAt, &grade.UpdatedAt); err != nil {
+		return err
+	}
+	if suggested.Valid {
+		grade.Suggested = &suggested.Float64
+	}
+	if lockedAt.Valid {
+		grade.LockedAt = &lockedAt.Time
+	}
+	if lockedBy.Valid {
+		id := int(lockedBy.Int64)
+		grade.LockedBy = &id
+	}
+	return nil
+}
+
+// GetFinalGrades возвращает итоговые оценки периода, доступные scope
+func GetFinalGrades(db *sql.DB, scope policy.Scope, termID int, filter models.FinalGradeFilter) ([]models.FinalGrade, error) {
+	if _, err := GetTermByID(db, termID); err != nil {
+		return nil, err
+	}
+
+	// Итоговые оценки выставляются текущей группе студента, поэтому и доступ к ним — по группе на сегодня
+	where, args := recordFilter(scope, "fg.student_id", "fg.subject_id", "CURRENT_DATE", 1)
+	conditions := []string{where}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	conditions = append(conditions, "fg.term_id = "+arg(termID))
+	if filter.GroupID != 0 {
+		conditions = append(conditions, "u.group_id = "+arg(filter.GroupID))
+	}
+	if filter.SubjectID != 0 {
+		conditions = append(conditions, "fg.subject_id = "+arg(filter.SubjectID))
+	}
+
+	rows, err := db.Query(finalGradeSelect+`
+        WHERE `+strings.Join(conditions, " AND ")+`
+        ORDER BY s.name, u.last_name, u.first_name, fg.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch final grades: %v", err)
+	}
+	defer rows.Close()
+
+	grades := []models.FinalGrade{}
+	for rows.Next() {
+		var grade models.FinalGrade
+		if err := scanFinalGrade(rows, &grade); err != nil {
+			return nil, fmt.Errorf("failed to scan final grade: %v", err)
+		}
+		grades = append(grades, grade)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over final grades: %v", err)
+	}
+
+	return grades, nil
+}
+
+func GetFinalGradeByID(db *sql.DB, scope policy.Scope, finalGradeID int) (*models.FinalGrade, error) {
+	var grade models.FinalGrade
+
+	filter, args := recordFilter(scope, "fg.student_id", "fg.subject_id", "CURRENT_DATE", 2)
+	err := scanFinalGrade(db.QueryRow(finalGradeSelect+`
+        WHERE fg.id = $1 AND `+filter, append([]interface{}{finalGradeID}, args...)...), &grade)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("final grade not found")
+		}
+		return nil, fmt.Errorf("failed to fetch final grade: %v", err)
+	}
+
+	return &grade, nil
+}
+
+// authorizeGroupSubject проверяет, что группа и предмет существуют и scope может писать
+// оценки каждого студента группы по предмету; возвращает студентов группы
+func authorizeGroupSubject(db *sql.DB, scope policy.Scope, groupID, subjectID int) ([]int, error) {
+	if err := checkGroupSubject(db, groupID, subjectID); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT id FROM users WHERE group_id = $1 ORDER BY id`, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch group students: %v", err)
+	}
+	var students []int
+	for rows.Next() {
+		var studentID int
+		if err := rows.Scan(&studentID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan student: %v", err)
+		}
+		students = append(students, studentID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over group students: %v", err)
+	}
+
+	for _, studentID := range students {
+		if err := authorizeRecord(db, scope, studentID, subjectID, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	return students, nil
+}
+
+// ComputeFinalGrades рассчитывает итоговые оценки группы по предмету за период: suggested — средневзвешенный
+// балл за даты периода, value — он же, округлённый до оценки. Оценки выставляются студентам, которые состоят
+// в группе сейчас. Закрытые оценки и студенты без оценок пропускаются, незакрытые пересчитываются. Возвращает итоговые оценки группы по предмету за период.
+func ComputeFinalGrades(db *sql.DB, scope policy.Scope, termID, groupID, subjectID int) ([]models.FinalGrade, error) {
+	term, err := GetTermByID(db, termID)
+	if err != nil {
+		return nil, err
+	}
+	students, err := authorizeGroupSubject(db, scope, groupID, subjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Итог считается по всем оценкам студента за период, в том числе поставленным до перевода в группу
+	stats, err := GetGradeStats(db, policy.System(), models.GradeStatsFilter{
+		SubjectID: subjectID,
+		From:      term.StartDate,
+		To:        term.EndDate,
+	}, models.StatsByStudent)
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[int]bool, len(students))
+	for _, studentID := range students {
+		members[studentID] = true
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, stat := range stats {
+		if !members[stat.ID] {
+			continue
+		}
+		_, err := tx.Exec(`
+            INSERT INTO final_grades (term_id, student_id, subject_id, suggested, value, created_at, updated_at)
+            VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+            ON CONFLICT (term_id, student_id, subject_id) DO UPDATE
+            SET suggested = EXCLUDED.suggested, value = EXCLUDED.value, updated_at = NOW()
+            WHERE NOT final_grades.locked
+        `, termID, stat.ID, subjectID, stat.Average, models.SuggestFinalGrade(stat.Average))
+		if err != nil {
+			return nil, fmt.Errorf("failed to save final grade: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit final grades: %v", err)
+	}
+
+	return GetFinalGrades(db, scope, termID, models.FinalGradeFilter{GroupID: groupID, SubjectID: subjectID})
+}
+
+// LockFinalGrades закрывает итоговые оценки группы по предмету за период и возвращает число закрытых.
+// После этого оценки студентов по предмету за даты периода меняются только с правом grades:override.
+func LockFinalGrades(db *sql.DB, scope policy.Scope, termID, groupID, subjectID int) (int, error) {
+	if _, err := GetTermByID(db, termID); err != nil {
+		return 0, err
+	}
+	if _, err := authorizeGroupSubject(db, scope, groupID, subjectID); err != nil {
+		return 0, err
+	}
+
+	var lockedBy *int
+	if scope.UserID != 0 {
+		lockedBy = &scope.UserID
+	}
+
+	result, err := db.Exec(`
+        UPDATE final_grades fg
+        SET locked = TRUE, locked_at = NOW(), locked_by = $4, updated_at = NOW()
+        FROM users u
+        WHERE fg.student_id = u.id AND fg.term_id = $1 AND u.group_id = $2 AND fg.subject_id = $3 AND NOT fg.locked
+    `, termID, groupID, subjectID, lockedBy)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock final grades: %v", err)
+	}
+	locked, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check locked rows: %v", err)
+	}
+
+	return int(locked), nil
+}
+
+// finalGradeOwner возвращает студента, предмет и признак закрытия итоговой оценки
+func finalGradeOwner(db *sql.DB, finalGradeID int) (int, int, bool, error) {
+	var studentID, subjectID int
+	var locked bool
+	err := db.QueryRow("SELECT student_id, subject_id, locked FROM final_grades WHERE id = $1", finalGradeID).
+		Scan(&studentID, &subjectID, &locked)
+	if err == sql.ErrNoRows {
+		return 0, 0, false, fmt.Errorf("final grade not found")
+	} else if err != nil {
+		return 0, 0, false, fmt.Errorf("failed to fetch final grade: %v", err)
+	}
+
+	return studentID, subjectID, locked, nil
+}
+
+// UpdateFinalGrade меняет выставленную оценку; закрытую — только с правом grades:override
+func UpdateFinalGrade(db *sql.DB, scope policy.Scope, finalGradeID, value int) error {
+	studentID, subjectID, locked, err := finalGradeOwner(db, finalGradeID)
+	if err != nil {
+		return err
+	}
+	if err := authorizeRecord(db, scope, studentID, subjectID, time.Now()); err != nil {
+		return err
+	}
+	if locked && !scope.Override {
+		return repository.ErrGradeLocked
+	}
+
+	_, err = db.Exec("UPDATE final_grades SET value = $1, updated_at = NOW() WHERE id = $2", value, finalGradeID)
+	if err != nil {
+		return fmt.Errorf("failed to update final grade: %v", err)
+	}
+
+	return nil
+}
+
+// UnlockFinalGrade открывает итоговую оценку; требует права grades:override
+func UnlockFinalGrade(db *sql.DB, scope policy.Scope, finalGradeID int) error {
+	if !scope.Override {
+		return policy.ErrForbidden
+	}
+	if _, _, _, err := finalGradeOwner(db, finalGradeID); err != nil {
+		return err
+	}
+
+	_, err := db.Exec(`
+        UPDATE final_grades SET locked = FALSE, locked_at = NULL, locked_by = NULL, updated_at = NOW() WHERE id = $1
+    `, finalGradeID)
+	if err != nil {
+		return fmt.Errorf("failed to unlock final grade: %v", err)
+	}
+
+	return nil
+}
+
+// checkGradeUnlocked возвращает ErrGradeLocked, если итоговая оценка студента по предмету
+// за период, в который попадает date, закрыта, а у scope нет права grades:override
+func checkGradeUnlocked(db queryer, scope policy.Scope, studentID, subjectID int, date time.Time) error {
+	if scope.Override {
+		return nil
+	}
+
+	var locked bool
+	err := db.QueryRow(`
+        SELECT EXISTS (
+            SELECT 1 FROM final_grades fg
+            JOIN terms t ON fg.term_id = t.id
+            WHERE fg.student_id = $1 AND fg.subject_id = $2 AND fg.locked
+              AND $3::date BETWEEN t.start_date AND t.end_date
+        )
+    `, studentID, subjectID, date.Format(lessonDateLayout)).Scan(&locked)
+	if err != nil {
+		return fmt.Errorf("failed to check final grade lock: %v", err)
+	}
+	if locked {
+		return repository.ErrGradeLocked
+	}
+
+	return nil
+}
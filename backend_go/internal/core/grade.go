@@ -4,130 +4,246 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"time"
 )
 
-func GetGradesByStudentID(db *sql.DB, studentID int) ([]models.GradeDetail, error) {
-	var grades []models.GradeDetail
-
-	rows, err := db.Query(`
-        SELECT g.id, 
+// ListGrades возвращает страницу оценок, доступных scope
+func ListGrades(db *sql.DB, scope policy.Scope, filter models.RecordFilter, page models.ListQuery) ([]models.GradeDetail, models.PageInfo, error) {
+	q := listQuery{
+		columns: `g.id, 
                g.student_id, 
+               u.first_name || ' ' || u.last_name AS student_name, 
                s.name AS subject_name, 
                g.value, 
                g.date, 
+               g.lesson_id, 
+               g.grade_type_id, 
+               COALESCE(gt.name, '') AS grade_type, 
+               ` + gradeWeight + ` AS weight, 
+               g.comment, 
                g.created_at, 
-               g.updated_at
-        FROM grades g
-        JOIN subjects s ON g.subject_id = s.id
-        WHERE g.student_id = $1
-    `, studentID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch grades: %v", err)
+               g.updated_at`,
+		from: `grades g
+        ` + gradeWeightJoin + `
+        JOIN users u ON g.student_id = u.id
+        JOIN subjects s ON g.subject_id = s.id`,
+		sorts: map[string]string{
+			"date":         "g.date",
+			"id":           "g.id",
+			"value":        "g.value",
+			"subject_name": "s.name",
+			"student_name": "u.first_name || ' ' || u.last_name",
+			"created_at":   "g.created_at",
+		},
+		id: "g.id",
 	}
-	defer rows.Close()
+	q.recordWhere(scope, filter, "g.student_id", "g.subject_id", "g.date", "")
 
-	for rows.Next() {
+	grades, info, err := listPage(db, q, page, func(rows *sql.Rows, sortValue *string) (models.GradeDetail, int, error) {
 		var grade models.GradeDetail
 		if err := rows.Scan(
 			&grade.ID,
 			&grade.StudentID,
+			&grade.StudentName,
 			&grade.SubjectName,
 			&grade.Value,
 			&grade.Date,
+			&grade.LessonID,
+			&grade.GradeTypeID,
+			&grade.GradeType,
+			&grade.Weight,
+			&grade.Comment,
 			&grade.CreatedAt,
 			&grade.UpdatedAt,
+			sortValue,
 		); err != nil {
-			return nil, fmt.Errorf("failed to scan grade: %v", err)
+			return grade, 0, fmt.Errorf("failed to scan grade: %v", err)
 		}
-		grades = append(grades, grade)
+		return grade, grade.ID, nil
+	})
+	if err != nil {
+		return nil, info, fmt.Errorf("failed to fetch grades: %v", err)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating over grades: %v", err)
+	return grades, info, nil
+}
+
+// gradeOwner возвращает студента, предмет и дату оценки, чтобы проверить доступ и закрытие периода до изменения.
+// Строка блокируется до конца транзакции, чтобы снимок для истории совпал с тем, что будет изменено
+func gradeOwner(tx *sql.Tx, gradeID int) (int, int, time.Time, error) {
+	var studentID, subjectID int
+	var date time.Time
+	err := tx.QueryRow("SELECT student_id, subject_id, date FROM grades WHERE id = $1 FOR UPDATE", gradeID).
+		Scan(&studentID, &subjectID, &date)
+	if err == sql.ErrNoRows {
+		return 0, 0, time.Time{}, fmt.Errorf("grade not found")
+	} else if err != nil {
+		return 0, 0, time.Time{}, fmt.Errorf("failed to fetch grade: %v", err)
 	}
 
-	return grades, nil
+	return studentID, subjectID, date, nil
 }
 
-func GetGradesByGroupID(db *sql.DB, groupID int) ([]models.GradeDetail, error) {
-	var grades []models.GradeDetail
-
-	rows, err := db.Query(`
-        SELECT g.id, 
-               g.student_id, 
-               u.first_name || ' ' || u.last_name AS student_name, 
-               s.name AS subject_name, 
-               g.value, 
-               g.date, 
-               g.created_at, 
-               g.updated_at
-        FROM grades g
-        JOIN users u ON g.student_id = u.id
-        JOIN subjects s ON g.subject_id = s.id
-        WHERE u.group_id = $1
-    `, groupID)
+// CreateGrade сохраняет оценку и запись о ней в истории изменений; reason — причина из запроса, может быть пустой
+func CreateGrade(db *sql.DB, scope policy.Scope, grade models.Grade, reason string) (int, error) {
+	tx, err := db.Begin()
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch grades: %v", err)
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	for rows.Next() {
-		var grade models.GradeDetail
-		if err := rows.Scan(
-			&grade.ID,
-			&grade.StudentID,
-			&grade.StudentName,
-			&grade.SubjectName,
-			&grade.Value,
-			&grade.Date,
-			&grade.CreatedAt,
-			&grade.UpdatedAt,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan grade: %v", err)
-		}
-		grades = append(grades, grade)
+	gradeID, err := createGrade(tx, scope, grade, reason)
+	if err != nil {
+		return 0, err
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating over grades: %v", err)
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit grade creation: %v", err)
 	}
 
-	return grades, nil
+	return gradeID, nil
 }
 
-func CreateGrade(db *sql.DB, grade models.Grade) (int, error) {
+// createGrade — CreateGrade внутри уже начатой транзакции
+func createGrade(tx *sql.Tx, scope policy.Scope, grade models.Grade, reason string) (int, error) {
+	if err := authorizeRecord(tx, scope, grade.StudentID, grade.SubjectID, grade.Date); err != nil {
+		return 0, err
+	}
+	if err := checkLesson(tx, grade.LessonID, grade.StudentID, grade.SubjectID, grade.Date); err != nil {
+		return 0, err
+	}
+	if err := checkGradeUnlocked(tx, scope, grade.StudentID, grade.SubjectID, grade.Date); err != nil {
+		return 0, err
+	}
+
 	var gradeID int
 
-	err := db.QueryRow(`
-        INSERT INTO grades (student_id, subject_id, value, date, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, NOW(), NOW())
+	err := tx.QueryRow(`
+        INSERT INTO grades (student_id, subject_id, value, date, lesson_id, grade_type_id, comment, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
         RETURNING id
-    `, grade.StudentID, grade.SubjectID, grade.Value, grade.Date).Scan(&gradeID)
+    `, grade.StudentID, grade.SubjectID, grade.Value, grade.Date, grade.LessonID, grade.GradeTypeID, grade.Comment).Scan(&gradeID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create grade: %v", err)
 	}
 
+	if err := logChange(tx, scope, models.RecordGrade, gradeID, models.ChangeCreate, nil, reason); err != nil {
+		return 0, err
+	}
+
 	return gradeID, nil
 }
 
-func UpdateGrade(db *sql.DB, grade models.Grade) error {
-	_, err := db.Exec(`
+// UpdateGrade требует доступа и к прежним, и к новым студенту и предмету:
+// иначе преподаватель мог бы «перевесить» чужую оценку на свой предмет.
+// По той же причине закрытым не должен быть ни прежний, ни новый период.
+func UpdateGrade(db *sql.DB, scope policy.Scope, grade models.Grade, reason string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := updateGrade(tx, scope, grade, reason); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit grade update: %v", err)
+	}
+
+	return nil
+}
+
+// updateGrade — UpdateGrade внутри уже начатой транзакции
+func updateGrade(tx *sql.Tx, scope policy.Scope, grade models.Grade, reason string) error {
+	studentID, subjectID, date, err := gradeOwner(tx, grade.ID)
+	if err != nil {
+		return err
+	}
+	if err := authorizeRecord(tx, scope, studentID, subjectID, date); err != nil {
+		return err
+	}
+	if err := authorizeRecord(tx, scope, grade.StudentID, grade.SubjectID, grade.Date); err != nil {
+		return err
+	}
+	if err := checkLesson(tx, grade.LessonID, grade.StudentID, grade.SubjectID, grade.Date); err != nil {
+		return err
+	}
+	if err := checkGradeUnlocked(tx, scope, studentID, subjectID, date); err != nil {
+		return err
+	}
+	if err := checkGradeUnlocked(tx, scope, grade.StudentID, grade.SubjectID, grade.Date); err != nil {
+		return err
+	}
+
+	old, err := rowSnapshot(tx, models.RecordGrade, grade.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
         UPDATE grades
-        SET student_id = $1, subject_id = $2, value = $3, date = $4, updated_at = NOW()
-        WHERE id = $5
-    `, grade.StudentID, grade.SubjectID, grade.Value, grade.Date, grade.ID)
+        SET student_id = $1, subject_id = $2, value = $3, date = $4, lesson_id = $5,
+            grade_type_id = $6, comment = $7, updated_at = NOW()
+        WHERE id = $8
+    `, grade.StudentID, grade.SubjectID, grade.Value, grade.Date, grade.LessonID, grade.GradeTypeID, grade.Comment, grade.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update grade: %v", err)
 	}
 
+	if err := logChange(tx, scope, models.RecordGrade, grade.ID, models.ChangeUpdate, old, reason); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-func DeleteGrade(db *sql.DB, gradeID int) error {
-	_, err := db.Exec("DELETE FROM grades WHERE id = $1", gradeID)
+// DeleteGrade удаляет оценку; в истории остаётся её последний снимок
+func DeleteGrade(db *sql.DB, scope policy.Scope, gradeID int, reason string) error {
+	tx, err := db.Begin()
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := deleteGrade(tx, scope, gradeID, reason); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit grade deletion: %v", err)
+	}
+
+	return nil
+}
+
+// deleteGrade — DeleteGrade внутри уже начатой транзакции
+func deleteGrade(tx *sql.Tx, scope policy.Scope, gradeID int, reason string) error {
+	studentID, subjectID, date, err := gradeOwner(tx, gradeID)
+	if err != nil {
+		return err
+	}
+	if err := authorizeRecord(tx, scope, studentID, subjectID, date); err != nil {
+		return err
+	}
+	if err := checkGradeUnlocked(tx, scope, studentID, subjectID, date); err != nil {
+		return err
+	}
+
+	old, err := rowSnapshot(tx, models.RecordGrade, gradeID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM grades WHERE id = $1", gradeID); err != nil {
 		return fmt.Errorf("failed to delete grade: %v", err)
 	}
 
+	if err := logChange(tx, scope, models.RecordGrade, gradeID, models.ChangeDelete, old, reason); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/lib/pq"
+	"strings"
+)
+
+// gradeTeachers сопоставляет оценку с преподавателями: с преподавателем занятия, если оценка
+// к нему привязана, иначе со всеми, кто ведёт этот предмет по расписанию у группы, в которой студент
+// состоял в день оценки
+var gradeTeachers = `(
+            SELECT gl.id AS grade_id, l.teacher_id
+            FROM grades gl
+            JOIN lessons l ON gl.lesson_id = l.id
+            UNION
+            SELECT gs.id, sc.teacher_id
+            FROM grades gs
+            JOIN group_memberships st ON st.student_id = gs.student_id AND ` + membershipCovers("st", "gs.date") + `
+            JOIN schedules sc ON sc.group_id = st.group_id AND sc.subject_id = gs.subject_id
+            WHERE gs.lesson_id IS NULL
+        )`
+
+// GetGradeStats возвращает сводки по оценкам в разрезе by (models.StatsBy*), упорядоченные по названию.
+// Считаются только оценки, доступные scope
+func GetGradeStats(db *sql.DB, scope policy.Scope, filter models.GradeStatsFilter, by string) ([]models.GradeStat, error) {
+	var key, name, join string
+	switch by {
+	case models.StatsByStudent:
+		key, name = "u.id", "u.first_name || ' ' || u.last_name"
+	case models.StatsBySubject:
+		key, name = "s.id", "s.name"
+	case models.StatsByGroup:
+		// Оценка относится к группе, в которой студент состоял в день оценки
+		key, name = "gr.id", "gr.name"
+		join = "JOIN group_memberships mb ON mb.student_id = g.student_id AND " + membershipCovers("mb", "g.date") +
+			" JOIN groups gr ON mb.group_id = gr.id"
+	case models.StatsByTeacher:
+		key, name = "t.id", "t.first_name || ' ' || t.last_name"
+		join = "JOIN " + gradeTeachers + " tg ON tg.grade_id = g.id JOIN users t ON tg.teacher_id = t.id"
+	default:
+		return nil, fmt.Errorf("unknown grade stats grouping %q", by)
+	}
+
+	where, args := recordFilter(scope, "g.student_id", "g.subject_id", "g.date", 1)
+	conditions := []string{where}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if filter.GroupID != 0 {
+		conditions = append(conditions, memberAt("g.student_id", arg(filter.GroupID), "g.date"))
+	}
+	if filter.StudentID != 0 {
+		conditions = append(conditions, "g.student_id = "+arg(filter.StudentID))
+	}
+	if filter.SubjectID != 0 {
+		conditions = append(conditions, "g.subject_id = "+arg(filter.SubjectID))
+	}
+	if filter.TeacherID != 0 {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM "+gradeTeachers+" ft WHERE ft.grade_id = g.id AND ft.teacher_id = "+arg(filter.TeacherID)+")")
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "g.date >= "+arg(filter.From.Format(lessonDateLayout))+"::date")
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "g.date <= "+arg(filter.To.Format(lessonDateLayout))+"::date")
+	}
+
+	rows, err := db.Query(`
+        SELECT `+key+`, `+name+`, ARRAY_AGG(g.value ORDER BY g.id), ARRAY_AGG(`+gradeWeight+` ORDER BY g.id)
+        FROM grades g
+        JOIN users u ON g.student_id = u.id
+        JOIN subjects s ON g.subject_id = s.id
+        `+gradeWeightJoin+`
+        `+join+`
+        WHERE `+strings.Join(conditions, " AND ")+`
+        GROUP BY `+key+`, `+name+`
+        ORDER BY 2, 1`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch grade stats: %v", err)
+	}
+	defer rows.Close()
+
+	stats := []models.GradeStat{}
+	for rows.Next() {
+		var stat models.GradeStat
+		var values []int64
+		var weights []float64
+		if err := rows.Scan(&stat.ID, &stat.Name, pq.Array(&values), pq.Array(&weights)); err != nil {
+			return nil, fmt.Errorf("failed to scan grade stats: %v", err)
+		}
+		ints := make([]int, len(values))
+		for i, value := range values {
+			ints[i] = int(value)
+		}
+		stat.GradeSummary = models.NewGradeSummary(ints, weights)
+		stats = append(stats, stat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over grade stats: %v", err)
+	}
+
+	return stats, nil
+}
+
+// GetGroupRanking возвращает рейтинг студентов группы по среднему баллу
+func GetGroupRanking(db *sql.DB, scope policy.Scope, filter models.GradeStatsFilter) ([]models.GradeRank, error) {
+	stats, err := GetGradeStats(db, scope, filter, models.StatsByStudent)
+	if err != nil {
+		return nil, err
+	}
+	return models.RankStudents(stats), nil
+}
@@ -0,0 +1,236 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"sort"
+)
+
+// gradeWeightJoin и gradeWeight дают вес оценки g: вес вида в её предмете, иначе вес вида по умолчанию,
+// а для оценки без вида — models.DefaultGradeWeight
+const (
+	gradeWeightJoin = `LEFT JOIN grade_types gt ON g.grade_type_id = gt.id
+        LEFT JOIN subject_grade_weights sw ON sw.subject_id = g.subject_id AND sw.grade_type_id = g.grade_type_id`
+	gradeWeight = `COALESCE(sw.weight, gt.default_weight, 1)::float8`
+)
+
+func GetGradeTypes(db *sql.DB) ([]models.GradeType, error) {
+	var gradeTypes []models.GradeType
+
+	rows, err := db.Query("SELECT id, code, name, default_weight, created_at, updated_at FROM grade_types ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch grade types: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var gradeType models.GradeType
+		if err := rows.Scan(&gradeType.ID, &gradeType.Code, &gradeType.Name, &gradeType.DefaultWeight,
+			&gradeType.CreatedAt, &gradeType.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan grade type: %v", err)
+		}
+		gradeTypes = append(gradeTypes, gradeType)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over grade types: %v", err)
+	}
+
+	return gradeTypes, nil
+}
+
+func GetGradeTypeByID(db *sql.DB, gradeTypeID int) (*models.GradeType, error) {
+	var gradeType models.GradeType
+
+	err := db.QueryRow("SELECT id, code, name, default_weight, created_at, updated_at FROM grade_types WHERE id = $1", gradeTypeID).
+		Scan(&gradeType.ID, &gradeType.Code, &gradeType.Name, &gradeType.DefaultWeight, &gradeType.CreatedAt, &gradeType.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("grade type not found")
+		}
+		return nil, fmt.Errorf("failed to fetch grade type: %v", err)
+	}
+
+	return &gradeType, nil
+}
+
+// gradeTypeCodeTaken проверяет ограничение UNIQUE (code) до записи, чтобы вернуть ErrGradeTypeExists
+func gradeTypeCodeTaken(db *sql.DB, gradeTypeID int, code string) (bool, error) {
+	var exists bool
+	err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM grade_types WHERE code = $1 AND id <> $2)", code, gradeTypeID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("database error: %v", err)
+	}
+	return exists, nil
+}
+
+func CreateGradeType(db *sql.DB, gradeType models.GradeType) (int, error) {
+	taken, err := gradeTypeCodeTaken(db, 0, gradeType.Code)
+	if err != nil {
+		return 0, err
+	}
+	if taken {
+		return 0, repository.ErrGradeTypeExists
+	}
+
+	var gradeTypeID int
+	err = db.QueryRow(`
+        INSERT INTO grade_types (code, name, default_weight, created_at, updated_at)
+        VALUES ($1, $2, $3, NOW(), NOW())
+        RETURNING id
+    `, gradeType.Code, gradeType.Name, gradeType.DefaultWeight).Scan(&gradeTypeID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create grade type: %v", err)
+	}
+
+	return gradeTypeID, nil
+}
+
+func UpdateGradeType(db *sql.DB, gradeType models.GradeType) error {
+	taken, err := gradeTypeCodeTaken(db, gradeType.ID, gradeType.Code)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return repository.ErrGradeTypeExists
+	}
+
+	result, err := db.Exec(`
+        UPDATE grade_types SET code = $1, name = $2, default_weight = $3, updated_at = NOW() WHERE id = $4
+    `, gradeType.Code, gradeType.Name, gradeType.DefaultWeight, gradeType.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update grade type: %v", err)
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to check updated rows: %v", err)
+	} else if rows == 0 {
+		return fmt.Errorf("grade type not found")
+	}
+
+	return nil
+}
+
+// DeleteGradeType удаляет вид оценки вместе с его весами в предметах.
+// Пока вид указан у оценок, возвращается *repository.InUseError.
+func DeleteGradeType(db *sql.DB, gradeTypeID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow("SELECT id FROM grade_types WHERE id = $1 FOR UPDATE", gradeTypeID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("grade type not found")
+	} else if err != nil {
+		return fmt.Errorf("failed to fetch grade type: %v", err)
+	}
+
+	var grades int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM grades WHERE grade_type_id = $1", gradeTypeID).Scan(&grades); err != nil {
+		return fmt.Errorf("failed to count grade type references: %v", err)
+	}
+	if err := repository.CheckInUse("grade type", map[string]int{"grades": grades}); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM grade_types WHERE id = $1", gradeTypeID); err != nil {
+		return fmt.Errorf("failed to delete grade type: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit grade type deletion: %v", err)
+	}
+
+	return nil
+}
+
+// GetGradeWeights возвращает вес каждого вида оценки в предмете
+func GetGradeWeights(db *sql.DB, subjectID int) ([]models.GradeWeight, error) {
+	if _, err := GetSubjectByID(db, subjectID); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+        SELECT gt.id, gt.code, gt.name, COALESCE(sw.weight, gt.default_weight)::float8, sw.weight IS NOT NULL
+        FROM grade_types gt
+        LEFT JOIN subject_grade_weights sw ON sw.grade_type_id = gt.id AND sw.subject_id = $1
+        ORDER BY gt.id
+    `, subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch grade weights: %v", err)
+	}
+	defer rows.Close()
+
+	weights := []models.GradeWeight{}
+	for rows.Next() {
+		var weight models.GradeWeight
+		if err := rows.Scan(&weight.GradeTypeID, &weight.Code, &weight.Name, &weight.Weight, &weight.Overridden); err != nil {
+			return nil, fmt.Errorf("failed to scan grade weight: %v", err)
+		}
+		weights = append(weights, weight)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over grade weights: %v", err)
+	}
+
+	return weights, nil
+}
+
+// SetGradeWeights заменяет веса видов оценок в предмете: виды, не указанные в weights,
+// возвращаются к весу по умолчанию
+func SetGradeWeights(db *sql.DB, subjectID int, weights map[int]float64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow("SELECT id FROM subjects WHERE id = $1 FOR UPDATE", subjectID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("subject not found")
+	} else if err != nil {
+		return fmt.Errorf("failed to fetch subject: %v", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM subject_grade_weights WHERE subject_id = $1", subjectID); err != nil {
+		return fmt.Errorf("failed to reset grade weights: %v", err)
+	}
+
+	for _, gradeTypeID := range sortedWeightKeys(weights) {
+		var exists bool
+		if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM grade_types WHERE id = $1)", gradeTypeID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to fetch grade type: %v", err)
+		}
+		if !exists {
+			return fmt.Errorf("grade type %d not found", gradeTypeID)
+		}
+
+		_, err := tx.Exec(`
+            INSERT INTO subject_grade_weights (subject_id, grade_type_id, weight) VALUES ($1, $2, $3)
+        `, subjectID, gradeTypeID, weights[gradeTypeID])
+		if err != nil {
+			return fmt.Errorf("failed to set grade weight: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit grade weights: %v", err)
+	}
+
+	return nil
+}
+
+func sortedWeightKeys(weights map[int]float64) []int {
+	keys := make([]int, 0, len(weights))
+	for key := range weights {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
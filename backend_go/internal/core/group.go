@@ -4,35 +4,41 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
 )
 
-func GetAllGroups(db *sql.DB) ([]models.Group, error) {
-	var groups []models.Group
-
-	rows, err := db.Query(`
-        SELECT g.id, g.name, COUNT(u.id) AS student_count, g.created_at, g.updated_at
-        FROM groups g
-        LEFT JOIN users u ON g.id = u.group_id
-        GROUP BY g.id, g.name
-    `)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch groups: %v", err)
+// ListGroups возвращает страницу групп с числом студентов
+func ListGroups(db *sql.DB, page models.ListQuery) ([]models.Group, models.PageInfo, error) {
+	// Число студентов считается в подзапросе, чтобы по нему можно было сортировать и продолжать с курсора
+	q := listQuery{
+		columns: "g.id, g.name, g.student_count, g.created_at, g.updated_at",
+		from: `(
+            SELECT g.id, g.name, COUNT(u.id) AS student_count, g.created_at, g.updated_at
+            FROM groups g
+            LEFT JOIN users u ON g.id = u.group_id
+            GROUP BY g.id, g.name
+        ) g`,
+		sorts: map[string]string{
+			"name":          "g.name",
+			"id":            "g.id",
+			"student_count": "g.student_count",
+			"created_at":    "g.created_at",
+		},
+		id: "g.id",
 	}
-	defer rows.Close()
 
-	for rows.Next() {
+	groups, info, err := listPage(db, q, page, func(rows *sql.Rows, sortValue *string) (models.Group, int, error) {
 		var group models.Group
-		if err := rows.Scan(&group.ID, &group.Name, &group.StudentCount, &group.CreatedAt, &group.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan group: %v", err)
+		if err := rows.Scan(&group.ID, &group.Name, &group.StudentCount, &group.CreatedAt, &group.UpdatedAt, sortValue); err != nil {
+			return group, 0, fmt.Errorf("failed to scan group: %v", err)
 		}
-		groups = append(groups, group)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating over groups: %v", err)
+		return group, group.ID, nil
+	})
+	if err != nil {
+		return nil, info, fmt.Errorf("failed to fetch groups: %v", err)
 	}
 
-	return groups, nil
+	return groups, info, nil
 }
 
 func GetGroupByID(db *sql.DB, groupID int) (*models.GroupDetail, error) {
@@ -131,11 +137,39 @@ func UpdateGroup(db *sql.DB, group models.Group) error {
 	return nil
 }
 
+// DeleteGroup удаляет группу вместе с расписанием и занятиями. Пока у группы есть история студентов,
+// возвращается *repository.InUseError, чтобы история не терялась
 func DeleteGroup(db *sql.DB, groupID int) error {
-	_, err := db.Exec("DELETE FROM groups WHERE id = $1", groupID)
+	tx, err := db.Begin()
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow("SELECT id FROM groups WHERE id = $1 FOR UPDATE", groupID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("group not found")
+	} else if err != nil {
+		return fmt.Errorf("failed to fetch group: %v", err)
+	}
+
+	var memberships int
+	err = tx.QueryRow("SELECT COUNT(*) FROM group_memberships WHERE group_id = $1", groupID).Scan(&memberships)
+	if err != nil {
+		return fmt.Errorf("failed to count group references: %v", err)
+	}
+	if err := repository.CheckInUse("group", map[string]int{"group_memberships": memberships}); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM groups WHERE id = $1", groupID); err != nil {
 		return fmt.Errorf("failed to delete group: %v", err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit group deletion: %v", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+)
+
+// historyTables — таблица записей каждого типа из истории изменений
+var historyTables = map[string]string{
+	models.RecordGrade:      "grades",
+	models.RecordAttendance: "attendance",
+}
+
+// rowSnapshot возвращает строку записи как JSON (to_jsonb), чтобы сохранить её в истории
+func rowSnapshot(tx *sql.Tx, recordType string, recordID int) ([]byte, error) {
+	var snapshot []byte
+	err := tx.QueryRow("SELECT to_jsonb(r) FROM "+historyTables[recordType]+" r WHERE id = $1", recordID).Scan(&snapshot)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%s not found", recordType)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s snapshot: %v", recordType, err)
+	}
+	return snapshot, nil
+}
+
+// logChange добавляет запись в историю в той же транзакции, что и само изменение.
+// old — снимок до изменения (nil при создании); снимок после берётся из таблицы, при удалении его нет.
+func logChange(tx *sql.Tx, scope policy.Scope, recordType string, recordID int, action string, old []byte, reason string) error {
+	var current []byte
+	if action != models.ChangeDelete {
+		var err error
+		if current, err = rowSnapshot(tx, recordType, recordID); err != nil {
+			return err
+		}
+	}
+
+	var actorID *int
+	if scope.UserID != 0 {
+		actorID = &scope.UserID
+	}
+
+	_, err := tx.Exec(`
+        INSERT INTO record_history (record_type, record_id, action, actor_id, old_value, new_value, reason, created_at)
+        VALUES ($1, $2, $3, $4, $5::jsonb, $6::jsonb, $7, NOW())
+    `, recordType, recordID, action, actorID,
+		sql.NullString{String: string(old), Valid: old != nil},
+		sql.NullString{String: string(current), Valid: current != nil},
+		reason)
+	if err != nil {
+		return fmt.Errorf("failed to record %s history: %v", recordType, err)
+	}
+
+	return nil
+}
+
+// GetRecordHistory возвращает историю изменений записи от старых изменений к новым,
+// в том числе для уже удалённой записи
+func GetRecordHistory(db *sql.DB, recordType string, recordID int) ([]models.RecordChange, error) {
+	if _, ok := historyTables[recordType]; !ok {
+		return nil, fmt.Errorf("unknown record type %q", recordType)
+	}
+
+	rows, err := db.Query(`
+        SELECT h.id,
+               h.record_type,
+               h.record_id,
+               h.action,
+               h.actor_id,
+               COALESCE(u.first_name || ' ' || u.last_name, '') AS actor_name,
+               h.old_value,
+               h.new_value,
+               h.reason,
+               h.created_at
+        FROM record_history h
+        LEFT JOIN users u ON h.actor_id = u.id
+        WHERE h.record_type = $1 AND h.record_id = $2
+        ORDER BY h.id`, recordType, recordID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch record history: %v", err)
+	}
+	defer rows.Close()
+
+	changes := []models.RecordChange{}
+	for rows.Next() {
+		var change models.RecordChange
+		var actorID sql.NullInt64
+		var oldValue, newValue []byte
+		if err := rows.Scan(&change.ID, &change.RecordType, &change.RecordID, &change.Action, &actorID,
+			&change.ActorName, &oldValue, &newValue, &change.Reason, &change.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan record history: %v", err)
+		}
+		if actorID.Valid {
+			id := int(actorID.Int64)
+			change.ActorID = &id
+		}
+		change.OldValue, change.NewValue = oldValue, newValue
+		changes = append(changes, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over record history: %v", err)
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+)
+
+// ImportStudents создаёт студентов и недостающие группы (по точному совпадению названия) в одной транзакции.
+// Занятые логины попадают в ошибки результата; если ошибки есть или dryRun, транзакция откатывается
+func ImportStudents(db *sql.DB, students []models.StudentImport, dryRun bool) (*models.ImportResult, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var roleID int
+	if err := tx.QueryRow("SELECT id FROM roles WHERE value = 'student'").Scan(&roleID); err != nil {
+		return nil, fmt.Errorf("failed to get student role: %v", err)
+	}
+
+	result := models.NewImportResult(dryRun)
+	groups := make(map[string]int)
+	for _, student := range students {
+		var existingID int
+		err := tx.QueryRow("SELECT id FROM users WHERE login = $1", student.Login).Scan(&existingID)
+		if err == nil {
+			result.AddError(student.Line, "login", "login already exists")
+			continue
+		} else if err != sql.ErrNoRows {
+			return nil, fmt.Errorf("database error: %v", err)
+		}
+
+		groupID, ok := groups[student.GroupName]
+		if !ok {
+			err := tx.QueryRow("SELECT id FROM groups WHERE name = $1", student.GroupName).Scan(&groupID)
+			if err == sql.ErrNoRows {
+				if !dryRun {
+					err = tx.QueryRow(`
+						INSERT INTO groups (name, created_at, updated_at)
+						VALUES ($1, NOW(), NOW())
+						RETURNING id
+					`, student.GroupName).Scan(&groupID)
+				} else {
+					err = nil
+				}
+				if err != nil {
+					return nil, fmt.Errorf("failed to create group: %v", err)
+				}
+				result.CreatedGroups = append(result.CreatedGroups, models.ImportedGroup{ID: groupID, Name: student.GroupName})
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to get group: %v", err)
+			}
+			groups[student.GroupName] = groupID
+		}
+
+		imported := models.ImportedStudent{
+			Line:    student.Line,
+			Login:   student.Login,
+			Name:    student.FullName(),
+			Group:   student.GroupName,
+			GroupID: groupID,
+		}
+		if !dryRun {
+			err = tx.QueryRow(`
+				INSERT INTO users (first_name, middle_name, last_name, role_id, group_id, login, password, salt, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+				RETURNING id
+			`, student.FirstName, student.MiddleName, student.LastName, roleID, groupID,
+				student.Login, student.Password, student.Salt,
+			).Scan(&imported.UserID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to register user: %v", err)
+			}
+			if _, err := addMembership(tx, imported.UserID, groupID, nil); err != nil {
+				return nil, err
+			}
+		}
+		result.Students = append(result.Students, imported)
+	}
+
+	if dryRun || len(result.Errors) > 0 {
+		result.ClearIDs()
+		return result, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	result.Committed = true
+	return result, nil
+}
@@ -0,0 +1,319 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"sort"
+	"time"
+)
+
+// checkGroupSubject проверяет, что группа и предмет существуют
+func checkGroupSubject(db queryer, groupID, subjectID int) error {
+	var groupExists, subjectExists bool
+	err := db.QueryRow(`
+        SELECT EXISTS (SELECT 1 FROM groups WHERE id = $1),
+               EXISTS (SELECT 1 FROM subjects WHERE id = $2)
+    `, groupID, subjectID).Scan(&groupExists, &subjectExists)
+	if err != nil {
+		return fmt.Errorf("failed to check group and subject: %v", err)
+	}
+	if !groupExists {
+		return fmt.Errorf("group not found")
+	}
+	if !subjectExists {
+		return fmt.Errorf("subject not found")
+	}
+	return nil
+}
+
+// GetJournal собирает журнал группы по предмету: строки — студенты, состоявшие в группе в периоде, чьи записи
+// видны scope, столбцы — дни периода с занятиями по расписанию, оценками или отметками. Оценки и отметки
+// попадают в журнал, только если сделаны, пока студент был в группе
+func GetJournal(db *sql.DB, scope policy.Scope, filter models.JournalFilter) (*models.Journal, error) {
+	if err := checkGroupSubject(db, filter.GroupID, filter.SubjectID); err != nil {
+		return nil, err
+	}
+
+	from, to := filter.From.Format(lessonDateLayout), filter.To.Format(lessonDateLayout)
+	journal := &models.Journal{
+		GroupID:   filter.GroupID,
+		SubjectID: filter.SubjectID,
+		From:      from,
+		To:        to,
+		Dates:     []string{},
+		Students:  []models.JournalRow{},
+	}
+
+	// Студент виден преподавателю, если в периоде состоял в группе, где тот ведёт предмет
+	studentFilter, args := scopedRecords(scope, "u.id", "s.id", memberDuring("u.id", "sc.group_id", "$3", "$4"), 5)
+	rows, err := db.Query(`
+        SELECT u.id, u.first_name || ' ' || u.last_name
+        FROM users u
+        JOIN subjects s ON s.id = $2
+        WHERE `+memberDuring("u.id", "$1", "$3", "$4")+` AND `+studentFilter+`
+        ORDER BY u.last_name, u.first_name, u.id`,
+		append([]interface{}{filter.GroupID, filter.SubjectID, from, to}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch journal students: %v", err)
+	}
+	for rows.Next() {
+		var row models.JournalRow
+		if err := rows.Scan(&row.StudentID, &row.StudentName); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan journal student: %v", err)
+		}
+		journal.Students = append(journal.Students, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over journal students: %v", err)
+	}
+
+	dates := make(map[string]bool)
+	lessonRows, err := db.Query(`
+        SELECT DISTINCT date FROM lessons
+        WHERE group_id = $1 AND subject_id = $2 AND date BETWEEN $3::date AND $4::date
+    `, filter.GroupID, filter.SubjectID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch journal lessons: %v", err)
+	}
+	for lessonRows.Next() {
+		var date time.Time
+		if err := lessonRows.Scan(&date); err != nil {
+			lessonRows.Close()
+			return nil, fmt.Errorf("failed to scan lesson date: %v", err)
+		}
+		dates[date.Format(lessonDateLayout)] = true
+	}
+	lessonRows.Close()
+	if err := lessonRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over journal lessons: %v", err)
+	}
+
+	recordArgs := []interface{}{filter.GroupID, filter.SubjectID, from, to}
+
+	gradeFilter, gradeArgs := recordFilter(scope, "g.student_id", "g.subject_id", "g.date", 5)
+	gradeRows, err := db.Query(`
+        SELECT g.id, g.student_id, g.date, g.value, g.grade_type_id, COALESCE(gt.name, ''), g.comment
+        FROM grades g
+        LEFT JOIN grade_types gt ON g.grade_type_id = gt.id
+        WHERE `+memberAt("g.student_id", "$1", "g.date")+` AND g.subject_id = $2 AND g.date BETWEEN $3::date AND $4::date AND `+gradeFilter+`
+        ORDER BY g.date, g.id`, append(recordArgs, gradeArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch journal grades: %v", err)
+	}
+	grades := make(map[int]map[string][]models.JournalGrade)
+	for gradeRows.Next() {
+		var grade models.JournalGrade
+		var studentID int
+		var date time.Time
+		if err := gradeRows.Scan(&grade.ID, &studentID, &date, &grade.Value, &grade.GradeTypeID, &grade.GradeType, &grade.Comment); err != nil {
+			gradeRows.Close()
+			return nil, fmt.Errorf("failed to scan journal grade: %v", err)
+		}
+		if grades[studentID] == nil {
+			grades[studentID] = make(map[string][]models.JournalGrade)
+		}
+		day := date.Format(lessonDateLayout)
+		grades[studentID][day] = append(grades[studentID][day], grade)
+		dates[day] = true
+	}
+	gradeRows.Close()
+	if err := gradeRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over journal grades: %v", err)
+	}
+
+	attendanceFilter, attendanceArgs := recordFilter(scope, "a.student_id", "a.subject_id", "a.date", 5)
+	attendanceRows, err := db.Query(`
+        SELECT a.id, a.student_id, a.date, a.status, a.lesson_id
+        FROM attendance a
+        WHERE `+memberAt("a.student_id", "$1", "a.date")+` AND a.subject_id = $2 AND a.date BETWEEN $3::date AND $4::date AND `+attendanceFilter+`
+        ORDER BY a.date, a.lesson_id IS NULL, a.id`, append(recordArgs, attendanceArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch journal attendance: %v", err)
+	}
+	attendance := make(map[int]map[string][]models.JournalAttendance)
+	for attendanceRows.Next() {
+		var mark models.JournalAttendance
+		var studentID int
+		var date time.Time
+		if err := attendanceRows.Scan(&mark.ID, &studentID, &date, &mark.Status, &mark.LessonID); err != nil {
+			attendanceRows.Close()
+			return nil, fmt.Errorf("failed to scan journal attendance: %v", err)
+		}
+		if attendance[studentID] == nil {
+			attendance[studentID] = make(map[string][]models.JournalAttendance)
+		}
+		day := date.Format(lessonDateLayout)
+		attendance[studentID][day] = append(attendance[studentID][day], mark)
+		dates[day] = true
+	}
+	attendanceRows.Close()
+	if err := attendanceRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over journal attendance: %v", err)
+	}
+
+	// Средние — те же, что в аналитике оценок за период
+	stats, err := GetGradeStats(db, scope, models.GradeStatsFilter{
+		GroupID:   filter.GroupID,
+		SubjectID: filter.SubjectID,
+		From:      filter.From,
+		To:        filter.To,
+	}, models.StatsByStudent)
+	if err != nil {
+		return nil, err
+	}
+	averages := make(map[int]float64)
+	for _, stat := range stats {
+		averages[stat.ID] = stat.Average
+	}
+
+	for day := range dates {
+		journal.Dates = append(journal.Dates, day)
+	}
+	sort.Strings(journal.Dates)
+	journal.Fill(grades, attendance, averages)
+
+	return journal, nil
+}
+
+// SaveJournal применяет правки журнала одной транзакцией: при первой ошибке откатываются все.
+// Студенты должны состоять в группе в день записи; оценки и отметки проверяются так же, как при записи по одной,
+// и каждая попадает в историю изменений с причиной reason.
+func SaveJournal(db *sql.DB, scope policy.Scope, save models.JournalSave, reason string) (*models.JournalSaveResult, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkGroupSubject(tx, save.GroupID, save.SubjectID); err != nil {
+		return nil, err
+	}
+
+	result := models.NewJournalSaveResult()
+	for i, edit := range save.Grades {
+		if err := saveJournalGrade(tx, scope, save, edit, reason, result); err != nil {
+			return nil, &repository.JournalEditError{Field: "grades", Index: i, Err: err}
+		}
+	}
+	for i, edit := range save.Attendance {
+		if err := saveJournalAttendance(tx, scope, save, edit, reason, result); err != nil {
+			return nil, &repository.JournalEditError{Field: "attendance", Index: i, Err: err}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit journal: %v", err)
+	}
+
+	return result, nil
+}
+
+// checkGroupMember проверяет, что студент состоял в группе журнала в день записи date
+func checkGroupMember(tx *sql.Tx, studentID, groupID int, date time.Time) error {
+	var member bool
+	err := tx.QueryRow(`SELECT `+memberAt("$1", "$2", "$3"), studentID, groupID, date.Format(lessonDateLayout)).Scan(&member)
+	if err != nil {
+		return fmt.Errorf("failed to check group member: %v", err)
+	}
+	if !member {
+		return repository.ErrNotGroupMember
+	}
+	return nil
+}
+
+func saveJournalGrade(tx *sql.Tx, scope policy.Scope, save models.JournalSave, edit models.JournalGradeEdit,
+	reason string, result *models.JournalSaveResult) error {
+	if edit.ID == nil {
+		if err := checkGroupMember(tx, edit.StudentID, save.GroupID, edit.Date); err != nil {
+			return err
+		}
+		gradeID, err := createGrade(tx, scope, models.Grade{
+			StudentID:   edit.StudentID,
+			SubjectID:   save.SubjectID,
+			Value:       edit.Value,
+			Date:        edit.Date,
+			GradeTypeID: edit.GradeTypeID,
+			Comment:     edit.Comment,
+		}, reason)
+		if err != nil {
+			return err
+		}
+		result.CreatedGrades = append(result.CreatedGrades, gradeID)
+		return nil
+	}
+
+	// Правка ячейки не переносит оценку: студент, предмет, дата и занятие остаются прежними
+	grade := models.Grade{ID: *edit.ID}
+	err := tx.QueryRow(`SELECT student_id, subject_id, date, lesson_id FROM grades WHERE id = $1`, grade.ID).
+		Scan(&grade.StudentID, &grade.SubjectID, &grade.Date, &grade.LessonID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("grade not found")
+	} else if err != nil {
+		return fmt.Errorf("failed to fetch grade: %v", err)
+	}
+	if grade.SubjectID != save.SubjectID {
+		return fmt.Errorf("grade not found")
+	}
+	if err := checkGroupMember(tx, grade.StudentID, save.GroupID, grade.Date); err != nil {
+		return err
+	}
+
+	if edit.Delete {
+		if err := deleteGrade(tx, scope, grade.ID, reason); err != nil {
+			return err
+		}
+		result.DeletedGrades = append(result.DeletedGrades, grade.ID)
+		return nil
+	}
+
+	grade.Value, grade.GradeTypeID, grade.Comment = edit.Value, edit.GradeTypeID, edit.Comment
+	if err := updateGrade(tx, scope, grade, reason); err != nil {
+		return err
+	}
+	result.UpdatedGrades = append(result.UpdatedGrades, grade.ID)
+	return nil
+}
+
+// saveJournalAttendance меняет отметку студента по предмету за день (сначала отметку на занятии, как RollCall)
+// или создаёт новую без занятия
+func saveJournalAttendance(tx *sql.Tx, scope policy.Scope, save models.JournalSave, edit models.JournalAttendanceEdit,
+	reason string, result *models.JournalSaveResult) error {
+	if err := checkGroupMember(tx, edit.StudentID, save.GroupID, edit.Date); err != nil {
+		return err
+	}
+
+	attendance := models.Attendance{
+		StudentID: edit.StudentID,
+		SubjectID: save.SubjectID,
+		Date:      edit.Date,
+		Status:    edit.Status,
+	}
+	err := tx.QueryRow(`
+        SELECT id, lesson_id FROM attendance
+        WHERE student_id = $1 AND subject_id = $2 AND date = $3::date
+        ORDER BY lesson_id IS NULL, id
+        LIMIT 1
+    `, edit.StudentID, save.SubjectID, edit.Date.Format(lessonDateLayout)).Scan(&attendance.ID, &attendance.LessonID)
+	switch {
+	case err == sql.ErrNoRows:
+		attendanceID, err := createAttendance(tx, scope, attendance, reason)
+		if err != nil {
+			return err
+		}
+		result.CreatedAttendance = append(result.CreatedAttendance, attendanceID)
+	case err != nil:
+		return fmt.Errorf("failed to fetch attendance: %v", err)
+	default:
+		if err := updateAttendance(tx, scope, attendance, reason); err != nil {
+			return err
+		}
+		result.UpdatedAttendance = append(result.UpdatedAttendance, attendance.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"strings"
+	"time"
+)
+
+// lessonDateLayout — формат дат, которые передаются в запросы как DATE
+const lessonDateLayout = "2006-01-02"
+
+// queryLessons выбирает занятия с номером пары. Номер считается среди всех занятий группы за день,
+// поэтому условия по датам (inner) применяются до оконной функции, а остальные (outer) — после.
+func queryLessons(db *sql.DB, inner, outer []string, args []interface{}) ([]models.Lesson, error) {
+	innerWhere, outerWhere := "TRUE", "TRUE"
+	if len(inner) > 0 {
+		innerWhere = strings.Join(inner, " AND ")
+	}
+	if len(outer) > 0 {
+		outerWhere = strings.Join(outer, " AND ")
+	}
+
+	rows, err := db.Query(`
+        SELECT l.id,
+               l.schedule_id,
+               l.group_id,
+               g.name AS group_name,
+               l.subject_id,
+               sub.name AS subject_name,
+               l.teacher_id,
+               t.first_name || ' ' || t.last_name AS teacher_name,
+               l.date,
+               l.period,
+               l.start_time,
+               l.end_time,
+               l.location,
+               l.created_at,
+               l.updated_at
+        FROM (
+            SELECT ls.*, ROW_NUMBER() OVER (PARTITION BY ls.group_id, ls.date ORDER BY ls.start_time, ls.id) AS period
+            FROM lessons ls
+            WHERE `+innerWhere+`
+        ) l
+        JOIN groups g ON l.group_id = g.id
+        JOIN subjects sub ON l.subject_id = sub.id
+        JOIN users t ON l.teacher_id = t.id
+        WHERE `+outerWhere+`
+        ORDER BY l.date, l.start_time, l.id
+    `, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch lessons: %v", err)
+	}
+	defer rows.Close()
+
+	var lessons []models.Lesson
+	for rows.Next() {
+		var lesson models.Lesson
+		var scheduleID sql.NullInt64
+		if err := rows.Scan(
+			&lesson.ID,
+			&scheduleID,
+			&lesson.GroupID,
+			&lesson.GroupName,
+			&lesson.SubjectID,
+			&lesson.SubjectName,
+			&lesson.TeacherID,
+			&lesson.TeacherName,
+			&lesson.Date,
+			&lesson.Period,
+			&lesson.StartTime,
+			&lesson.EndTime,
+			&lesson.Location,
+			&lesson.CreatedAt,
+			&lesson.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan lesson: %v", err)
+		}
+		if scheduleID.Valid {
+			id := int(scheduleID.Int64)
+			lesson.ScheduleID = &id
+		}
+		lessons = append(lessons, lesson)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over lessons: %v", err)
+	}
+
+	return lessons, nil
+}
+
+func GetLessons(db *sql.DB, filter models.LessonFilter) ([]models.Lesson, error) {
+	var inner, outer []string
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if !filter.From.IsZero() {
+		inner = append(inner, "ls.date >= "+arg(filter.From.Format(lessonDateLayout))+"::date")
+	}
+	if !filter.To.IsZero() {
+		inner = append(inner, "ls.date <= "+arg(filter.To.Format(lessonDateLayout))+"::date")
+	}
+	if filter.GroupID != 0 {
+		outer = append(outer, "l.group_id = "+arg(filter.GroupID))
+	}
+	if filter.TeacherID != 0 {
+		outer = append(outer, "l.teacher_id = "+arg(filter.TeacherID))
+	}
+	if filter.Period != 0 {
+		outer = append(outer, "l.period = "+arg(filter.Period))
+	}
+
+	return queryLessons(db, inner, outer, args)
+}
+
+func GetLessonByID(db *sql.DB, lessonID int) (*models.Lesson, error) {
+	lessons, err := queryLessons(db,
+		[]string{"ls.date = (SELECT date FROM lessons WHERE id = $1)"},
+		[]string{"l.id = $1"},
+		[]interface{}{lessonID})
+	if err != nil {
+		return nil, err
+	}
+	if len(lessons) == 0 {
+		return nil, fmt.Errorf("lesson not found")
+	}
+
+	return &lessons[0], nil
+}
+
+// GenerateLessons создаёт занятия по недельному расписанию на каждый день из [from, to].
+// groupID = 0 — для всех групп. Уже созданные занятия не меняются; возвращается число новых.
+func GenerateLessons(db *sql.DB, from, to time.Time, groupID int) (int, error) {
+	res, err := db.Exec(`
+        INSERT INTO lessons (schedule_id, group_id, subject_id, teacher_id, date, start_time, end_time, location, created_at, updated_at)
+        SELECT s.id, s.group_id, s.subject_id, s.teacher_id, d::date, s.start_time, s.end_time, s.location, NOW(), NOW()
+        FROM schedules s
+        JOIN generate_series($1::date, $2::date, INTERVAL '1 day') AS d ON EXTRACT(ISODOW FROM d) = s.day_of_week
+        WHERE $3 = 0 OR s.group_id = $3
+        ON CONFLICT (schedule_id, date) DO NOTHING
+    `, from.Format(lessonDateLayout), to.Format(lessonDateLayout), groupID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate lessons: %v", err)
+	}
+
+	created, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count generated lessons: %v", err)
+	}
+
+	return int(created), nil
+}
+
+// checkLesson проверяет ссылку записи на занятие: занятие должно быть по тому же предмету,
+// в тот же день и у группы, в которой студент был в день занятия. lessonID = nil — запись без занятия.
+func checkLesson(db queryer, lessonID *int, studentID, subjectID int, date time.Time) error {
+	if lessonID == nil {
+		return nil
+	}
+
+	var matches bool
+	err := db.QueryRow(`
+        SELECT EXISTS (
+            SELECT 1 FROM lessons l
+            WHERE l.id = $1 AND l.subject_id = $3 AND l.date = $4::date AND `+memberAt("$2", "l.group_id", "l.date")+`
+        )
+    `, *lessonID, studentID, subjectID, date.Format(lessonDateLayout)).Scan(&matches)
+	if err != nil {
+		return fmt.Errorf("failed to check lesson: %v", err)
+	}
+	if !matches {
+		return repository.ErrLessonMismatch
+	}
+
+	return nil
+}
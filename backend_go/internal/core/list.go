@@ -0,0 +1,151 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"strings"
+)
+
+// listQuery — выборка для постраничного списка
+type listQuery struct {
+	columns string // Колонки SELECT
+	from    string // Таблицы с JOIN
+	where   []string
+	args    []interface{}
+	sorts   map[string]string // Поле сортировки → выражение SQL
+	id      string            // Уникальная колонка: упорядочивает равные значения и входит в курсор
+}
+
+func (q *listQuery) arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+// scoped добавляет условие доступа из recordFilter или userFilter, собранное с номера следующего параметра
+func (q *listQuery) scoped(build func(arg int) (string, []interface{})) {
+	condition, args := build(len(q.args) + 1)
+	q.where = append(q.where, condition)
+	q.args = append(q.args, args...)
+}
+
+// listPage считает записи под условиями q и читает страницу page. scan читает строку выборки,
+// в последней колонке которой — значение поля сортировки текстом, и возвращает запись с её ID.
+// Читается на строку больше Limit: так видно, есть ли следующая страница
+func listPage[T any](db *sql.DB, q listQuery, page models.ListQuery, scan func(rows *sql.Rows, sortValue *string) (T, int, error)) ([]T, models.PageInfo, error) {
+	var info models.PageInfo
+	sortExpr, ok := q.sorts[page.Sort]
+	if !ok {
+		return nil, info, fmt.Errorf("unknown sort field: %s", page.Sort)
+	}
+
+	where := "TRUE"
+	if len(q.where) > 0 {
+		where = strings.Join(q.where, " AND ")
+	}
+	if err := db.QueryRow("SELECT COUNT(*) FROM "+q.from+" WHERE "+where, q.args...).Scan(&info.Total); err != nil {
+		return nil, info, fmt.Errorf("failed to count rows: %v", err)
+	}
+
+	direction, compare := "ASC", ">"
+	if page.Desc {
+		direction, compare = "DESC", "<"
+	}
+	if page.After != nil {
+		where += fmt.Sprintf(" AND (%s, %s) %s (%s, %s)", sortExpr, q.id, compare, q.arg(page.After.Value), q.arg(page.After.ID))
+	}
+	query := "SELECT " + q.columns + ", (" + sortExpr + ")::text FROM " + q.from + " WHERE " + where +
+		" ORDER BY " + sortExpr + " " + direction + ", " + q.id + " " + direction
+	if page.Limit > 0 {
+		query += " LIMIT " + q.arg(page.Limit+1)
+	}
+	if page.Offset > 0 {
+		query += " OFFSET " + q.arg(page.Offset)
+	}
+
+	rows, err := db.Query(query, q.args...)
+	if err != nil {
+		return nil, info, fmt.Errorf("failed to fetch rows: %v", err)
+	}
+	defer rows.Close()
+
+	items := []T{}
+	var last models.Cursor
+	for rows.Next() {
+		if page.Limit > 0 && len(items) == page.Limit {
+			last.Sort, last.Desc = page.Sort, page.Desc
+			info.Next = &last
+			break
+		}
+		item, id, err := scan(rows, &last.Value)
+		if err != nil {
+			return nil, info, err
+		}
+		last.ID = id
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, info, fmt.Errorf("error iterating over rows: %v", err)
+	}
+
+	return items, info, nil
+}
+
+// listEach читает все записи под условиями q в порядке order и передаёт их в fn по одной,
+// не собирая выборку в памяти. Ошибка fn прерывает чтение
+func listEach[T any](db *sql.DB, q listQuery, order string, scan func(rows *sql.Rows) (T, error), fn func(T) error) error {
+	where := "TRUE"
+	if len(q.where) > 0 {
+		where = strings.Join(q.where, " AND ")
+	}
+	rows, err := db.Query("SELECT "+q.columns+" FROM "+q.from+" WHERE "+where+" ORDER BY "+order, q.args...)
+	if err != nil {
+		return fmt.Errorf("failed to fetch rows: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating over rows: %v", err)
+	}
+
+	return nil
+}
+
+// recordWhere добавляет к выборке оценок или посещаемости доступ scope и условия filter.
+// Колонки записи: studentCol, subjectCol, dateCol и statusCol (пустая — у записи нет статуса).
+// Группа фильтра — та, в которой студент состоял в день записи
+func (q *listQuery) recordWhere(scope policy.Scope, filter models.RecordFilter, studentCol, subjectCol, dateCol, statusCol string) {
+	q.scoped(func(arg int) (string, []interface{}) {
+		return recordFilter(scope, studentCol, subjectCol, dateCol, arg)
+	})
+	if filter.StudentID != 0 {
+		q.where = append(q.where, studentCol+" = "+q.arg(filter.StudentID))
+	}
+	if filter.GroupID != 0 {
+		q.where = append(q.where, memberAt(studentCol, q.arg(filter.GroupID), dateCol))
+	}
+	if filter.SubjectID != 0 {
+		q.where = append(q.where, subjectCol+" = "+q.arg(filter.SubjectID))
+	}
+	if !filter.From.IsZero() {
+		q.where = append(q.where, dateCol+" >= "+q.arg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		q.where = append(q.where, dateCol+" < "+q.arg(filter.To))
+	}
+	if filter.Status != "" && statusCol != "" {
+		q.where = append(q.where, statusCol+" = "+q.arg(filter.Status))
+	}
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"time"
+)
+
+// membershipCovers — условие «запись истории групп alias включает день dateExpr»
+func membershipCovers(alias, dateExpr string) string {
+	return fmt.Sprintf("(%[1]s.valid_from IS NULL OR %[1]s.valid_from <= (%[2]s)::date) AND (%[1]s.valid_to IS NULL OR %[1]s.valid_to > (%[2]s)::date)",
+		alias, dateExpr)
+}
+
+// memberAt — условие «студент studentCol состоял в группе groupExpr в день dateExpr».
+// По нему групповые выборки берут оценки и отметки, сделанные, пока студент был в группе
+func memberAt(studentCol, groupExpr, dateExpr string) string {
+	return fmt.Sprintf(`EXISTS (
+            SELECT 1 FROM group_memberships gm
+            WHERE gm.student_id = %s AND gm.group_id = %s AND %s
+        )`, studentCol, groupExpr, membershipCovers("gm", dateExpr))
+}
+
+// memberDuring — условие «студент studentCol состоял в группе groupExpr хотя бы день из [fromExpr, toExpr]»
+func memberDuring(studentCol, groupExpr, fromExpr, toExpr string) string {
+	return fmt.Sprintf(`EXISTS (
+            SELECT 1 FROM group_memberships gm
+            WHERE gm.student_id = %s AND gm.group_id = %s
+              AND (gm.valid_from IS NULL OR gm.valid_from <= (%s)::date) AND (gm.valid_to IS NULL OR gm.valid_to > (%s)::date)
+        )`, studentCol, groupExpr, toExpr, fromExpr)
+}
+
+// addMembership открывает запись истории групп студента с дня from (nil — с самого начала)
+func addMembership(tx *sql.Tx, studentID, groupID int, from *time.Time) (int, error) {
+	var membershipID int
+	var validFrom interface{}
+	if from != nil {
+		validFrom = from.Format(lessonDateLayout)
+	}
+	err := tx.QueryRow(`
+        INSERT INTO group_memberships (student_id, group_id, valid_from, created_at)
+        VALUES ($1, $2, $3::date, NOW())
+        RETURNING id
+    `, studentID, groupID, validFrom).Scan(&membershipID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add group membership: %v", err)
+	}
+	return membershipID, nil
+}
+
+// TransferStudent переводит студента в группу groupID с дня transfer.Date (пустой — сегодня): закрывает
+// текущую запись истории этим днём, открывает новую и меняет users.group_id.
+// День перевода должен быть позже начала прежних записей и не позже сегодняшнего
+func TransferStudent(db *sql.DB, groupID int, transfer models.Transfer) (*models.GroupMembership, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var groupName string
+	err = tx.QueryRow(`SELECT name FROM groups WHERE id = $1`, groupID).Scan(&groupName)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("group not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to fetch group: %v", err)
+	}
+
+	var role string
+	var currentGroupID sql.NullInt64
+	err = tx.QueryRow(`
+        SELECT r.value, u.group_id
+        FROM users u
+        JOIN roles r ON u.role_id = r.id
+        WHERE u.id = $1
+        FOR UPDATE OF u
+    `, transfer.StudentID).Scan(&role, &currentGroupID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("student not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to fetch student: %v", err)
+	}
+	if role != "student" {
+		return nil, repository.ErrNotStudent
+	}
+	if currentGroupID.Valid && int(currentGroupID.Int64) == groupID {
+		return nil, repository.ErrAlreadyInGroup
+	}
+
+	today := time.Now()
+	date := transfer.Date
+	if date.IsZero() {
+		date = today
+	}
+	day := date.Format(lessonDateLayout)
+	// Новая запись не должна начаться раньше конца или в день начала прежних
+	var lastFrom, lastTo sql.NullString
+	err = tx.QueryRow(`
+        SELECT MAX(valid_from)::text, MAX(valid_to)::text FROM group_memberships WHERE student_id = $1
+    `, transfer.StudentID).Scan(&lastFrom, &lastTo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch group memberships: %v", err)
+	}
+	if day > today.Format(lessonDateLayout) || (lastFrom.Valid && day <= lastFrom.String) || (lastTo.Valid && day < lastTo.String) {
+		return nil, repository.ErrInvalidTransferDate
+	}
+
+	if _, err := tx.Exec(`
+        UPDATE group_memberships SET valid_to = $2::date WHERE student_id = $1 AND valid_to IS NULL
+    `, transfer.StudentID, day); err != nil {
+		return nil, fmt.Errorf("failed to close group membership: %v", err)
+	}
+	membershipID, err := addMembership(tx, transfer.StudentID, groupID, &date)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`UPDATE users SET group_id = $1, updated_at = NOW() WHERE id = $2`, groupID, transfer.StudentID); err != nil {
+		return nil, fmt.Errorf("failed to update student group: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	validFrom, _ := time.Parse(lessonDateLayout, day)
+	return &models.GroupMembership{
+		ID:        membershipID,
+		StudentID: transfer.StudentID,
+		GroupID:   groupID,
+		GroupName: groupName,
+		ValidFrom: &validFrom,
+	}, nil
+}
+
+// GetGroupMemberships возвращает историю групп студента от старых записей к новым
+func GetGroupMemberships(db *sql.DB, studentID int) ([]models.GroupMembership, error) {
+	rows, err := db.Query(`
+        SELECT m.id, m.student_id, m.group_id, g.name, m.valid_from, m.valid_to
+        FROM group_memberships m
+        JOIN groups g ON m.group_id = g.id
+        WHERE m.student_id = $1
+        ORDER BY m.valid_from NULLS FIRST, m.id
+    `, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch group memberships: %v", err)
+	}
+	defer rows.Close()
+
+	memberships := []models.GroupMembership{}
+	for rows.Next() {
+		var membership models.GroupMembership
+		var validFrom, validTo sql.NullTime
+		if err := rows.Scan(&membership.ID, &membership.StudentID, &membership.GroupID, &membership.GroupName, &validFrom, &validTo); err != nil {
+			return nil, fmt.Errorf("failed to scan group membership: %v", err)
+		}
+		if validFrom.Valid {
+			membership.ValidFrom = &validFrom.Time
+		}
+		if validTo.Valid {
+			membership.ValidTo = &validTo.Time
+		}
+		memberships = append(memberships, membership)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over group memberships: %v", err)
+	}
+
+	return memberships, nil
+}
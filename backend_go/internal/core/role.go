@@ -0,0 +1,254 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/lib/pq"
+)
+
+const roleSelect = `
+        SELECT r.id, r.value, r.description, r.builtin,
+               COALESCE(array_agg(p.name ORDER BY p.name) FILTER (WHERE p.name IS NOT NULL), '{}')
+        FROM roles r
+        LEFT JOIN role_permissions rp ON rp.role_id = r.id
+        LEFT JOIN permissions p ON p.id = rp.permission_id
+`
+
+func scanRole(row interface{ Scan(...interface{}) error }) (models.Role, error) {
+	var role models.Role
+	err := row.Scan(&role.ID, &role.Name, &role.Description, &role.Builtin, pq.Array(&role.Permissions))
+	return role, err
+}
+
+// GetRoles возвращает все роли вместе с их правами
+func GetRoles(db *sql.DB) ([]models.Role, error) {
+	var roles []models.Role
+
+	rows, err := db.Query(roleSelect + " GROUP BY r.id ORDER BY r.id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch roles: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan role: %v", err)
+		}
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over roles: %v", err)
+	}
+
+	return roles, nil
+}
+
+func GetRoleByID(db *sql.DB, roleID int) (*models.Role, error) {
+	role, err := scanRole(db.QueryRow(roleSelect+" WHERE r.id = $1 GROUP BY r.id", roleID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("role not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to fetch role: %v", err)
+	}
+
+	return &role, nil
+}
+
+// GetPermissions возвращает каталог прав
+func GetPermissions(db *sql.DB) ([]models.Permission, error) {
+	var permissions []models.Permission
+
+	rows, err := db.Query("SELECT id, name, description FROM permissions ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch permissions: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var permission models.Permission
+		if err := rows.Scan(&permission.ID, &permission.Name, &permission.Description); err != nil {
+			return nil, fmt.Errorf("failed to scan permission: %v", err)
+		}
+		permissions = append(permissions, permission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over permissions: %v", err)
+	}
+
+	return permissions, nil
+}
+
+func CreateRole(db *sql.DB, role models.Role) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM roles WHERE value = $1)", role.Name).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("database error: %v", err)
+	}
+	if exists {
+		return 0, repository.ErrRoleExists
+	}
+
+	var roleID int
+	err = tx.QueryRow(`
+        INSERT INTO roles (value, description, builtin)
+        VALUES ($1, $2, FALSE)
+        RETURNING id
+    `, role.Name, role.Description).Scan(&roleID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create role: %v", err)
+	}
+
+	if err := setRolePermissions(tx, roleID, role.Permissions); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit role: %v", err)
+	}
+
+	return roleID, nil
+}
+
+// UpdateRole меняет описание и заменяет набор прав; встроенные роли переименовать нельзя
+func UpdateRole(db *sql.DB, role models.Role) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var name string
+	var builtin bool
+	err = tx.QueryRow("SELECT value, builtin FROM roles WHERE id = $1 FOR UPDATE", role.ID).Scan(&name, &builtin)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("role not found")
+	} else if err != nil {
+		return fmt.Errorf("failed to fetch role: %v", err)
+	}
+	if builtin && name != role.Name {
+		return repository.ErrBuiltinRole
+	}
+
+	var exists bool
+	if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM roles WHERE value = $1 AND id <> $2)", role.Name, role.ID).Scan(&exists); err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+	if exists {
+		return repository.ErrRoleExists
+	}
+
+	if _, err := tx.Exec("UPDATE roles SET value = $1, description = $2 WHERE id = $3", role.Name, role.Description, role.ID); err != nil {
+		return fmt.Errorf("failed to update role: %v", err)
+	}
+	if _, err := tx.Exec("DELETE FROM role_permissions WHERE role_id = $1", role.ID); err != nil {
+		return fmt.Errorf("failed to update role permissions: %v", err)
+	}
+	if err := setRolePermissions(tx, role.ID, role.Permissions); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit role: %v", err)
+	}
+
+	return nil
+}
+
+func DeleteRole(db *sql.DB, roleID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var builtin bool
+	err = tx.QueryRow("SELECT builtin FROM roles WHERE id = $1 FOR UPDATE", roleID).Scan(&builtin)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("role not found")
+	} else if err != nil {
+		return fmt.Errorf("failed to fetch role: %v", err)
+	}
+	if builtin {
+		return repository.ErrBuiltinRole
+	}
+
+	var inUse bool
+	if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM users WHERE role_id = $1)", roleID).Scan(&inUse); err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+	if inUse {
+		return repository.ErrRoleInUse
+	}
+
+	if _, err := tx.Exec("DELETE FROM roles WHERE id = $1", roleID); err != nil {
+		return fmt.Errorf("failed to delete role: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit role deletion: %v", err)
+	}
+
+	return nil
+}
+
+// AssignRole назначает пользователю роль
+func AssignRole(db *sql.DB, userID, roleID int) error {
+	var exists bool
+	if err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM roles WHERE id = $1)", roleID).Scan(&exists); err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+	if !exists {
+		return fmt.Errorf("role not found")
+	}
+
+	res, err := db.Exec("UPDATE users SET role_id = $1, updated_at = NOW() WHERE id = $2", roleID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to assign role: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to assign role: %v", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// setRolePermissions привязывает права к роли; все имена должны быть из каталога
+func setRolePermissions(tx *sql.Tx, roleID int, permissions []string) error {
+	for _, name := range permissions {
+		res, err := tx.Exec(`
+            INSERT INTO role_permissions (role_id, permission_id)
+            SELECT $1, id FROM permissions WHERE name = $2
+            ON CONFLICT DO NOTHING
+        `, roleID, name)
+		if err != nil {
+			return fmt.Errorf("failed to set role permissions: %v", err)
+		}
+		if affected, err := res.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to set role permissions: %v", err)
+		} else if affected == 0 {
+			var known bool
+			if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM permissions WHERE name = $1)", name).Scan(&known); err != nil {
+				return fmt.Errorf("database error: %v", err)
+			}
+			if !known {
+				return fmt.Errorf("%w: %s", repository.ErrUnknownPermission, name)
+			}
+		}
+	}
+
+	return nil
+}
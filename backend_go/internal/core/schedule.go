@@ -4,9 +4,87 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/patch"
+	"github.com/VladislavSCV/internal/repository"
+	"strings"
 )
 
-func GetAllSchedules(db *sql.DB) ([]models.Schedule, error) {
+// scheduleListQuery — выборка занятий расписания под фильтром; общая для списка и выгрузки
+func scheduleListQuery(filter models.ScheduleFilter) listQuery {
+	q := listQuery{
+		columns: `s.id, 
+               g.name AS group_name, 
+               sub.name AS subject_name, 
+               t.first_name || ' ' || t.last_name AS teacher_name, 
+               s.day_of_week, 
+               s.start_time, 
+               s.end_time, 
+               s.location, 
+               s.created_at, 
+               s.updated_at,
+               sub.id AS subject_id,
+               g.id AS group_id,
+               t.id AS teacher_id`,
+		from: `schedules s
+        JOIN groups g ON s.group_id = g.id
+        JOIN subjects sub ON s.subject_id = sub.id
+        JOIN users t ON s.teacher_id = t.id`,
+		sorts: map[string]string{
+			"day_of_week":  "s.day_of_week",
+			"id":           "s.id",
+			"start_time":   "s.start_time",
+			"group_name":   "g.name",
+			"subject_name": "sub.name",
+			"teacher_name": "t.first_name || ' ' || t.last_name",
+			"location":     "s.location",
+		},
+		id: "s.id",
+	}
+	for column, value := range map[string]int{
+		"s.group_id":    filter.GroupID,
+		"s.subject_id":  filter.SubjectID,
+		"s.teacher_id":  filter.TeacherID,
+		"s.day_of_week": filter.DayOfWeek,
+	} {
+		if value != 0 {
+			q.where = append(q.where, column+" = "+q.arg(value))
+		}
+	}
+	return q
+}
+
+// ListSchedules возвращает страницу занятий расписания
+func ListSchedules(db *sql.DB, filter models.ScheduleFilter, page models.ListQuery) ([]models.Schedule, models.PageInfo, error) {
+	schedules, info, err := listPage(db, scheduleListQuery(filter), page, func(rows *sql.Rows, sortValue *string) (models.Schedule, int, error) {
+		var schedule models.Schedule
+		if err := rows.Scan(
+			&schedule.ID,
+			&schedule.GroupName,
+			&schedule.SubjectName,
+			&schedule.TeacherName,
+			&schedule.DayOfWeek,
+			&schedule.StartTime,
+			&schedule.EndTime,
+			&schedule.Location,
+			&schedule.CreatedAt,
+			&schedule.UpdatedAt,
+			&schedule.SubjectID,
+			&schedule.GroupID,
+			&schedule.TeacherID,
+			sortValue,
+		); err != nil {
+			return schedule, 0, fmt.Errorf("failed to scan schedule: %v", err)
+		}
+		return schedule, schedule.ID, nil
+	})
+	if err != nil {
+		return nil, info, fmt.Errorf("failed to fetch schedules: %v", err)
+	}
+
+	return schedules, info, nil
+}
+
+func GetScheduleByID(db *sql.DB, id int) ([]models.Schedule, error) {
 	var schedules []models.Schedule
 
 	rows, err := db.Query(`
@@ -20,16 +98,17 @@ func GetAllSchedules(db *sql.DB) ([]models.Schedule, error) {
                s.location, 
                s.created_at, 
                s.updated_at,
-			   sub.id AS subject_id,
+               sub.id AS subject_id,
 			   g.id AS group_id,
                t.id AS teacher_id
         FROM schedules s
         JOIN groups g ON s.group_id = g.id
         JOIN subjects sub ON s.subject_id = sub.id
         JOIN users t ON s.teacher_id = t.id
-    `)
+        WHERE s.group_id = $1
+    `, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch schedules: %v", err)
+		return nil, fmt.Errorf("failed to fetch schedule: %v", err)
 	}
 	defer rows.Close()
 
@@ -62,29 +141,30 @@ func GetAllSchedules(db *sql.DB) ([]models.Schedule, error) {
 	return schedules, nil
 }
 
-func GetScheduleByID(db *sql.DB, id int) ([]models.Schedule, error) {
+// GetSchedulesByTeacherID возвращает занятия преподавателя
+func GetSchedulesByTeacherID(db *sql.DB, teacherID int) ([]models.Schedule, error) {
 	var schedules []models.Schedule
 
 	rows, err := db.Query(`
-        SELECT s.id, 
-               g.name AS group_name, 
-               sub.name AS subject_name, 
-               t.first_name || ' ' || t.last_name AS teacher_name, 
-               s.day_of_week, 
-               s.start_time, 
-               s.end_time, 
-               s.location, 
-               s.created_at, 
+        SELECT s.id,
+               g.name AS group_name,
+               sub.name AS subject_name,
+               t.first_name || ' ' || t.last_name AS teacher_name,
+               s.day_of_week,
+               s.start_time,
+               s.end_time,
+               s.location,
+               s.created_at,
                s.updated_at,
                sub.id AS subject_id,
-			   g.id AS group_id,
+               g.id AS group_id,
                t.id AS teacher_id
         FROM schedules s
         JOIN groups g ON s.group_id = g.id
         JOIN subjects sub ON s.subject_id = sub.id
         JOIN users t ON s.teacher_id = t.id
-        WHERE s.group_id = $1
-    `, id)
+        WHERE s.teacher_id = $1
+    `, teacherID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch schedule: %v", err)
 	}
@@ -119,10 +199,142 @@ func GetScheduleByID(db *sql.DB, id int) ([]models.Schedule, error) {
 	return schedules, nil
 }
 
+// queryer — *sql.DB или *sql.Tx
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// lockSchedules блокирует запись в schedules до конца транзакции, чтобы два параллельных
+// запроса не заняли одно и то же время, пройдя проверку конфликтов каждый по отдельности
+func lockSchedules(tx *sql.Tx) error {
+	if _, err := tx.Exec("LOCK TABLE schedules IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return fmt.Errorf("failed to lock schedules: %v", err)
+	}
+	return nil
+}
+
+// checkScheduleTimes проверяет, что занятие заканчивается позже, чем начинается
+func checkScheduleTimes(schedule models.Schedule) error {
+	if patch.NormalizeTime(schedule.EndTime) <= patch.NormalizeTime(schedule.StartTime) {
+		return repository.ErrInvalidTimeRange
+	}
+	return nil
+}
+
+// findScheduleConflicts ищет занятия в тот же день недели, пересекающиеся по времени
+// с переданным и совпадающие с ним по преподавателю, группе или аудитории.
+// Само занятие (schedule.ID) в результат не попадает.
+func findScheduleConflicts(q queryer, schedule models.Schedule) ([]models.ScheduleConflict, error) {
+	rows, err := q.Query(`
+        SELECT s.id,
+               g.name AS group_name,
+               sub.name AS subject_name,
+               t.first_name || ' ' || t.last_name AS teacher_name,
+               s.day_of_week,
+               s.start_time,
+               s.end_time,
+               s.location,
+               s.created_at,
+               s.updated_at,
+               sub.id AS subject_id,
+               g.id AS group_id,
+               t.id AS teacher_id
+        FROM schedules s
+        JOIN groups g ON s.group_id = g.id
+        JOIN subjects sub ON s.subject_id = sub.id
+        JOIN users t ON s.teacher_id = t.id
+        WHERE s.id <> $1
+          AND s.day_of_week = $2
+          AND s.start_time < $4::time
+          AND s.end_time > $3::time
+          AND (s.teacher_id = $5
+               OR s.group_id = $6
+               OR ($7::text <> '' AND LOWER(TRIM(s.location)) = LOWER($7::text)))
+        ORDER BY s.start_time, s.id
+    `, schedule.ID, schedule.DayOfWeek, schedule.StartTime, schedule.EndTime,
+		schedule.TeacherID, schedule.GroupID, strings.TrimSpace(schedule.Location))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check schedule conflicts: %v", err)
+	}
+	defer rows.Close()
+
+	var conflicts []models.ScheduleConflict
+	for rows.Next() {
+		var other models.Schedule
+		if err := rows.Scan(
+			&other.ID,
+			&other.GroupName,
+			&other.SubjectName,
+			&other.TeacherName,
+			&other.DayOfWeek,
+			&other.StartTime,
+			&other.EndTime,
+			&other.Location,
+			&other.CreatedAt,
+			&other.UpdatedAt,
+			&other.SubjectID,
+			&other.GroupID,
+			&other.TeacherID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %v", err)
+		}
+		conflicts = append(conflicts, models.ScheduleConflict{
+			Schedule: other,
+			Reasons:  schedule.ConflictReasons(other),
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over schedules: %v", err)
+	}
+
+	return conflicts, nil
+}
+
+// checkScheduleConflicts возвращает *repository.ScheduleConflictError, если занятие с чем-то пересекается
+func checkScheduleConflicts(q queryer, schedule models.Schedule) error {
+	conflicts, err := findScheduleConflicts(q, schedule)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return &repository.ScheduleConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// ValidateSchedule проверяет занятие так же, как CreateSchedule и UpdateSchedule, но ничего не сохраняет.
+// Ненулевой schedule.ID исключает из проверки само редактируемое занятие.
+func ValidateSchedule(db *sql.DB, schedule models.Schedule) error {
+	if err := checkScheduleTimes(schedule); err != nil {
+		return err
+	}
+	return checkScheduleConflicts(db, schedule)
+}
+
 func CreateSchedule(db *sql.DB, schedule models.Schedule) (int, error) {
-	var scheduleID int
+	if err := checkScheduleTimes(schedule); err != nil {
+		return 0, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockSchedules(tx); err != nil {
+		return 0, err
+	}
+
+	schedule.ID = 0
+	if err := checkScheduleConflicts(tx, schedule); err != nil {
+		return 0, err
+	}
 
-	err := db.QueryRow(`
+	var scheduleID int
+	err = tx.QueryRow(`
         INSERT INTO schedules (group_id, subject_id, teacher_id, day_of_week, start_time, end_time, location, created_at, updated_at)
         VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
         RETURNING id
@@ -131,29 +343,80 @@ func CreateSchedule(db *sql.DB, schedule models.Schedule) (int, error) {
 		return 0, fmt.Errorf("failed to create schedule: %v", err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit schedule: %v", err)
+	}
+
 	return scheduleID, nil
 }
 
-func UpdateSchedule(db *sql.DB, updates map[string]interface{}) error {
-	query := "UPDATE schedules SET "
-	var args []interface{}
-	i := 1
+// GetScheduleEntry возвращает одно занятие по его id
+func GetScheduleEntry(db *sql.DB, scheduleID int) (*models.Schedule, error) {
+	return getScheduleEntry(db, scheduleID)
+}
+
+func getScheduleEntry(q queryer, scheduleID int) (*models.Schedule, error) {
+	var schedule models.Schedule
+	err := q.QueryRow(`
+        SELECT id, group_id, subject_id, teacher_id, day_of_week, start_time, end_time, location, created_at, updated_at
+        FROM schedules
+        WHERE id = $1
+    `, scheduleID).Scan(
+		&schedule.ID,
+		&schedule.GroupID,
+		&schedule.SubjectID,
+		&schedule.TeacherID,
+		&schedule.DayOfWeek,
+		&schedule.StartTime,
+		&schedule.EndTime,
+		&schedule.Location,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("schedule not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to fetch schedule: %v", err)
+	}
+
+	return &schedule, nil
+}
+
+// UpdateSchedule меняет только поля из patch.ScheduleSchema; остальные ключи отклоняются.
+// Изменённое занятие проверяется на конфликты в той же транзакции; при конфликте изменения откатываются.
+func UpdateSchedule(db *sql.DB, scheduleID int, updates map[string]interface{}) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
 
-	for k, v := range updates {
-		query += fmt.Sprintf("%s = $%d, ", k, i) // Используем $ для подстановки
-		args = append(args, v)
-		i++
+	if err := lockSchedules(tx); err != nil {
+		return err
 	}
 
-	// Удаляем последнюю запятую
-	query = query[:len(query)-2]
-	query += fmt.Sprintf(" WHERE id = $%d", i)
-	args = append(args, updates["id"])
+	found, err := updateColumns(tx, "schedules", patch.ScheduleSchema, scheduleID, updates)
+	if err != nil {
+		return fmt.Errorf("failed to update schedule: %v", err)
+	}
+	if !found {
+		return fmt.Errorf("schedule not found")
+	}
 
-	// Выполнение запроса
-	_, err := db.Exec(query, args...)
+	// Проверяем итоговое состояние строки: в патче могут быть не все поля
+	schedule, err := getScheduleEntry(tx, scheduleID)
 	if err != nil {
-		return fmt.Errorf("failed to update user: %v", err)
+		return err
+	}
+	if err := checkScheduleTimes(*schedule); err != nil {
+		return err
+	}
+	if err := checkScheduleConflicts(tx, *schedule); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit schedule update: %v", err)
 	}
 
 	return nil
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"fmt"
+	"github.com/VladislavSCV/internal/policy"
+	"time"
+)
+
+// recordFilter возвращает условие WHERE, которое оставляет только оценки/посещаемость,
+// доступные scope. studentCol и subjectCol — колонки записи, dateExpr — её день, arg — номер следующего параметра.
+// При records:taught запись видна, если в день dateExpr студент состоял в группе, где преподаватель ведёт предмет.
+func recordFilter(scope policy.Scope, studentCol, subjectCol, dateExpr string, arg int) (string, []interface{}) {
+	return scopedRecords(scope, studentCol, subjectCol, memberAt(studentCol, "sc.group_id", dateExpr), arg)
+}
+
+// scopedRecords — recordFilter, в котором при records:taught студент связан с группой расписания sc.group_id
+// условием member, а не членством в день записи
+func scopedRecords(scope policy.Scope, studentCol, subjectCol, member string, arg int) (string, []interface{}) {
+	switch scope.Access {
+	case policy.AccessAll:
+		return "TRUE", nil
+	case policy.AccessTaught:
+		return fmt.Sprintf(`EXISTS (
+            SELECT 1 FROM schedules sc
+            WHERE sc.subject_id = %s AND sc.teacher_id = $%d AND %s
+        )`, subjectCol, arg, member), []interface{}{scope.UserID}
+	case policy.AccessOwn:
+		return fmt.Sprintf("%s = $%d", studentCol, arg), []interface{}{scope.UserID}
+	default:
+		return "FALSE", nil
+	}
+}
+
+// userFilter оставляет пользователей, которых видит scope: при records:taught — себя и студентов,
+// состоявших когда-либо в его группах, при records:own — только себя. idCol — колонка id пользователя.
+func userFilter(scope policy.Scope, idCol string, arg int) (string, []interface{}) {
+	switch scope.Access {
+	case policy.AccessAll:
+		return "TRUE", nil
+	case policy.AccessTaught:
+		return fmt.Sprintf(`(%s = $%d OR EXISTS (
+            SELECT 1 FROM schedules sc
+            JOIN group_memberships gm ON gm.group_id = sc.group_id
+            WHERE gm.student_id = %s AND sc.teacher_id = $%d
+        ))`, idCol, arg, idCol, arg), []interface{}{scope.UserID}
+	case policy.AccessOwn:
+		return fmt.Sprintf("%s = $%d", idCol, arg), []interface{}{scope.UserID}
+	default:
+		return "FALSE", nil
+	}
+}
+
+// authorizeRecord проверяет, может ли scope писать оценки/посещаемость студента по предмету за день date.
+// Писать можно только при records:all или records:taught: records:own — доступ на чтение своих записей.
+func authorizeRecord(db queryer, scope policy.Scope, studentID, subjectID int, date time.Time) error {
+	if scope.Access == policy.AccessAll {
+		return nil
+	}
+	if scope.Access != policy.AccessTaught {
+		return policy.ErrForbidden
+	}
+
+	filter, args := recordFilter(scope, "$1::int", "$2::int", "$3", 4)
+	var allowed bool
+	if err := db.QueryRow("SELECT "+filter, append([]interface{}{studentID, subjectID, date.Format(lessonDateLayout)}, args...)...).Scan(&allowed); err != nil {
+		return fmt.Errorf("failed to check access: %v", err)
+	}
+	if !allowed {
+		return policy.ErrForbidden
+	}
+
+	return nil
+}
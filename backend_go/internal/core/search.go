@@ -0,0 +1,127 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/search"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Выражения, по которым ищутся записи; совпадают с индексами миграции 0011_search
+const (
+	userSearchExpr    = `translate(lower(u.first_name || ' ' || u.middle_name || ' ' || u.last_name || ' ' || u.login), 'ё', 'е')`
+	groupSearchExpr   = `translate(lower(g.name), 'ё', 'е')`
+	subjectSearchExpr = `translate(lower(s.name), 'ё', 'е')`
+)
+
+// searchMatch добавляет к выборке q условие совпадения expr с одним из вариантов запроса и возвращает
+// выражение оценки. Запись подходит, если вариант входит в неё подстрокой (оценка 1) или похож на часть
+// её слов не меньше search.Threshold (оценка — word_similarity); оба условия используют триграммный индекс
+func searchMatch(q *listQuery, expr string, variants []string) string {
+	var conditions, scores []string
+	for _, variant := range variants {
+		text, pattern := q.arg(variant), q.arg("%"+escapeLike(variant)+"%")
+		conditions = append(conditions, fmt.Sprintf("%s <%% %s OR %s LIKE %s", text, expr, expr, pattern))
+		scores = append(scores, fmt.Sprintf("CASE WHEN %s LIKE %s THEN 1 ELSE word_similarity(%s, %s) END", expr, pattern, text, expr))
+	}
+	q.where = append(q.where, "("+strings.Join(conditions, " OR ")+")")
+	return "GREATEST(" + strings.Join(scores, ", ") + ")"
+}
+
+// escapeLike экранирует символы шаблона LIKE, чтобы «%» и «_» в запросе искались буквально
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text)
+}
+
+// Search ищет пользователей, которых видит scope, группы и предметы по вариантам запроса.
+// Порог похожести задаётся только на время транзакции поиска
+func Search(db *sql.DB, scope policy.Scope, query models.SearchQuery) (*models.SearchResult, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	threshold := strconv.FormatFloat(search.Threshold, 'f', -1, 64)
+	if _, err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)", threshold); err != nil {
+		return nil, fmt.Errorf("failed to set similarity threshold: %v", err)
+	}
+
+	result := &models.SearchResult{Users: []models.SearchHit{}, Groups: []models.SearchHit{}, Subjects: []models.SearchHit{}}
+	if slices.Contains(query.Types, models.SearchUsers) {
+		q := listQuery{from: "users u JOIN roles r ON r.id = u.role_id LEFT JOIN groups g ON g.id = u.group_id"}
+		score := searchMatch(&q, userSearchExpr, query.Variants)
+		q.scoped(func(arg int) (string, []interface{}) { return userFilter(scope, "u.id", arg) })
+		q.columns = "u.id, u.first_name, u.middle_name, u.last_name, u.login, r.value, g.name"
+		result.Users, err = searchHits(tx, q, score, "u.last_name, u.first_name, u.id", query.Limit, func(rows *sql.Rows, hit *models.SearchHit) error {
+			var user models.User
+			var group sql.NullString
+			if err := rows.Scan(&hit.ID, &user.FirstName, &user.MiddleName, &user.LastName, &hit.Login, &hit.Role, &group, &hit.Score); err != nil {
+				return err
+			}
+			hit.Name, hit.Group = user.FullName(), group.String
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if slices.Contains(query.Types, models.SearchGroups) {
+		q := listQuery{from: "groups g", columns: "g.id, g.name"}
+		score := searchMatch(&q, groupSearchExpr, query.Variants)
+		result.Groups, err = searchHits(tx, q, score, "g.name, g.id", query.Limit, func(rows *sql.Rows, hit *models.SearchHit) error {
+			return rows.Scan(&hit.ID, &hit.Name, &hit.Score)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if slices.Contains(query.Types, models.SearchSubjects) {
+		q := listQuery{from: "subjects s", columns: "s.id, s.name"}
+		score := searchMatch(&q, subjectSearchExpr, query.Variants)
+		result.Subjects, err = searchHits(tx, q, score, "s.name, s.id", query.Limit, func(rows *sql.Rows, hit *models.SearchHit) error {
+			return rows.Scan(&hit.ID, &hit.Name, &hit.Score)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return result, nil
+}
+
+// searchHits читает не больше limit записей выборки q от лучших совпадений к худшим; равные — в порядке order.
+// Оценка score читается после колонок q.columns
+func searchHits(tx *sql.Tx, q listQuery, score, order string, limit int, scan func(rows *sql.Rows, hit *models.SearchHit) error) ([]models.SearchHit, error) {
+	query := "SELECT " + q.columns + ", " + score + " AS score FROM " + q.from + " WHERE " + strings.Join(q.where, " AND ") +
+		" ORDER BY score DESC, " + order + " LIMIT " + q.arg(limit)
+
+	rows, err := tx.Query(query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %v", err)
+	}
+	defer rows.Close()
+
+	hits := []models.SearchHit{}
+	for rows.Next() {
+		var hit models.SearchHit
+		if err := scan(rows, &hit); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %v", err)
+		}
+		hits = append(hits, hit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %v", err)
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"time"
+)
+
+// CreateSession создаёт сессию пользователя вместе с первым refresh-токеном
+func CreateSession(db *sql.DB, sessionID string, userID int, refreshHash string, ttl time.Duration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	expiresAt := time.Now().Add(ttl)
+
+	_, err = tx.Exec(`
+        INSERT INTO auth_sessions (id, user_id, created_at, expires_at)
+        VALUES ($1, $2, NOW(), $3)
+    `, sessionID, userID, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %v", err)
+	}
+
+	_, err = tx.Exec(`
+        INSERT INTO refresh_tokens (session_id, token_hash, expires_at, created_at)
+        VALUES ($1, $2, $3, NOW())
+    `, sessionID, refreshHash, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit session: %v", err)
+	}
+
+	return nil
+}
+
+// RotateRefreshToken погашает refresh-токен и выпускает вместо него новый в той же сессии.
+// Повторное предъявление уже использованного токена считается кражей: сессия отзывается целиком.
+func RotateRefreshToken(db *sql.DB, oldHash, newHash string, ttl time.Duration) (*models.Session, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var token models.RefreshToken
+	var session models.Session
+	err = tx.QueryRow(`
+        SELECT rt.id, rt.session_id, rt.expires_at, rt.used_at,
+               s.user_id, s.created_at, s.expires_at, s.revoked_at
+        FROM refresh_tokens rt
+        JOIN auth_sessions s ON rt.session_id = s.id
+        WHERE rt.token_hash = $1
+        FOR UPDATE OF rt, s
+    `, oldHash).Scan(
+		&token.ID, &token.SessionID, &token.ExpiresAt, &token.UsedAt,
+		&session.UserID, &session.CreatedAt, &session.ExpiresAt, &session.RevokedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrInvalidRefreshToken
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to fetch refresh token: %v", err)
+	}
+	session.ID = token.SessionID
+
+	if session.RevokedAt != nil {
+		return nil, repository.ErrInvalidRefreshToken
+	}
+
+	if token.UsedAt != nil {
+		if _, err := tx.Exec("UPDATE auth_sessions SET revoked_at = NOW() WHERE id = $1", session.ID); err != nil {
+			return nil, fmt.Errorf("failed to revoke session: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit session revocation: %v", err)
+		}
+		return nil, repository.ErrRefreshTokenReused
+	}
+
+	now := time.Now()
+	if now.After(token.ExpiresAt) || now.After(session.ExpiresAt) {
+		return nil, repository.ErrInvalidRefreshToken
+	}
+
+	if _, err := tx.Exec("UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1", token.ID); err != nil {
+		return nil, fmt.Errorf("failed to mark refresh token as used: %v", err)
+	}
+
+	// Скользящее окно: каждая ротация продлевает сессию
+	session.ExpiresAt = now.Add(ttl)
+	if _, err := tx.Exec("UPDATE auth_sessions SET expires_at = $1 WHERE id = $2", session.ExpiresAt, session.ID); err != nil {
+		return nil, fmt.Errorf("failed to extend session: %v", err)
+	}
+
+	_, err = tx.Exec(`
+        INSERT INTO refresh_tokens (session_id, token_hash, expires_at, created_at)
+        VALUES ($1, $2, $3, NOW())
+    `, session.ID, newHash, session.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create refresh token: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit refresh token rotation: %v", err)
+	}
+
+	return &session, nil
+}
+
+// IsSessionActive сообщает, можно ли ещё доверять токенам этой сессии
+func IsSessionActive(db *sql.DB, sessionID string) (bool, error) {
+	var active bool
+	err := db.QueryRow(`
+        SELECT revoked_at IS NULL AND expires_at > NOW()
+        FROM auth_sessions
+        WHERE id = $1
+    `, sessionID).Scan(&active)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to check session: %v", err)
+	}
+
+	return active, nil
+}
+
+func RevokeSession(db *sql.DB, sessionID string) error {
+	_, err := db.Exec("UPDATE auth_sessions SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL", sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %v", err)
+	}
+
+	return nil
+}
+
+func RevokeUserSessions(db *sql.DB, userID int) error {
+	_, err := db.Exec("UPDATE auth_sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL", userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %v", err)
+	}
+
+	return nil
+}
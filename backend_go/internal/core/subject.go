@@ -0,0 +1,152 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+)
+
+func GetSubjects(db *sql.DB) ([]models.Subject, error) {
+	var subjects []models.Subject
+
+	rows, err := db.Query("SELECT id, name, created_at, updated_at FROM subjects ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch subjects: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var subject models.Subject
+		if err := rows.Scan(&subject.ID, &subject.Name, &subject.CreatedAt, &subject.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan subject: %v", err)
+		}
+		subjects = append(subjects, subject)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over subjects: %v", err)
+	}
+
+	return subjects, nil
+}
+
+func GetSubjectByID(db *sql.DB, subjectID int) (*models.Subject, error) {
+	var subject models.Subject
+
+	err := db.QueryRow("SELECT id, name, created_at, updated_at FROM subjects WHERE id = $1", subjectID).
+		Scan(&subject.ID, &subject.Name, &subject.CreatedAt, &subject.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("subject not found")
+		}
+		return nil, fmt.Errorf("failed to fetch subject: %v", err)
+	}
+
+	return &subject, nil
+}
+
+// subjectNameTaken проверяет уникальность имени так же, как индекс subjects_name_key (без учёта регистра)
+func subjectNameTaken(db *sql.DB, subjectID int, name string) (bool, error) {
+	var exists bool
+	err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM subjects WHERE LOWER(name) = LOWER($1) AND id <> $2)", name, subjectID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("database error: %v", err)
+	}
+	return exists, nil
+}
+
+func CreateSubject(db *sql.DB, subject models.Subject) (int, error) {
+	taken, err := subjectNameTaken(db, 0, subject.Name)
+	if err != nil {
+		return 0, err
+	}
+	if taken {
+		return 0, repository.ErrSubjectExists
+	}
+
+	var subjectID int
+	err = db.QueryRow(`
+        INSERT INTO subjects (name, created_at, updated_at)
+        VALUES ($1, NOW(), NOW())
+        RETURNING id
+    `, subject.Name).Scan(&subjectID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create subject: %v", err)
+	}
+
+	return subjectID, nil
+}
+
+func UpdateSubject(db *sql.DB, subject models.Subject) error {
+	taken, err := subjectNameTaken(db, subject.ID, subject.Name)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return repository.ErrSubjectExists
+	}
+
+	result, err := db.Exec("UPDATE subjects SET name = $1, updated_at = NOW() WHERE id = $2", subject.Name, subject.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update subject: %v", err)
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to check updated rows: %v", err)
+	} else if rows == 0 {
+		return fmt.Errorf("subject not found")
+	}
+
+	return nil
+}
+
+// DeleteSubject удаляет предмет. Внешние ключи на subjects не каскадные, поэтому
+// при наличии строк расписания, занятий, оценок, итоговых оценок или посещаемости возвращается *repository.InUseError с их количеством.
+func DeleteSubject(db *sql.DB, subjectID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow("SELECT id FROM subjects WHERE id = $1 FOR UPDATE", subjectID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("subject not found")
+	} else if err != nil {
+		return fmt.Errorf("failed to fetch subject: %v", err)
+	}
+
+	var schedules, lessons, grades, finalGrades, attendance int
+	err = tx.QueryRow(`
+        SELECT (SELECT COUNT(*) FROM schedules WHERE subject_id = $1),
+               (SELECT COUNT(*) FROM lessons WHERE subject_id = $1),
+               (SELECT COUNT(*) FROM grades WHERE subject_id = $1),
+               (SELECT COUNT(*) FROM final_grades WHERE subject_id = $1),
+               (SELECT COUNT(*) FROM attendance WHERE subject_id = $1)
+    `, subjectID).Scan(&schedules, &lessons, &grades, &finalGrades, &attendance)
+	if err != nil {
+		return fmt.Errorf("failed to count subject references: %v", err)
+	}
+
+	counts := map[string]int{
+		"schedules":    schedules,
+		"lessons":      lessons,
+		"grades":       grades,
+		"final_grades": finalGrades,
+		"attendance":   attendance,
+	}
+	if err := repository.CheckInUse("subject", counts); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM subjects WHERE id = $1", subjectID); err != nil {
+		return fmt.Errorf("failed to delete subject: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit subject deletion: %v", err)
+	}
+
+	return nil
+}
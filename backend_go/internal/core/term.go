@@ -0,0 +1,373 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"time"
+)
+
+func GetAcademicYears(db *sql.DB) ([]models.AcademicYear, error) {
+	var years []models.AcademicYear
+
+	rows, err := db.Query("SELECT id, name, start_date, end_date, created_at, updated_at FROM academic_years ORDER BY start_date, id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch academic years: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var year models.AcademicYear
+		if err := rows.Scan(&year.ID, &year.Name, &year.StartDate, &year.EndDate, &year.CreatedAt, &year.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan academic year: %v", err)
+		}
+		years = append(years, year)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over academic years: %v", err)
+	}
+
+	return years, nil
+}
+
+func GetAcademicYearByID(db *sql.DB, yearID int) (*models.AcademicYear, error) {
+	var year models.AcademicYear
+
+	err := db.QueryRow("SELECT id, name, start_date, end_date, created_at, updated_at FROM academic_years WHERE id = $1", yearID).
+		Scan(&year.ID, &year.Name, &year.StartDate, &year.EndDate, &year.CreatedAt, &year.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("academic year not found")
+		}
+		return nil, fmt.Errorf("failed to fetch academic year: %v", err)
+	}
+
+	return &year, nil
+}
+
+// checkAcademicYear проверяет даты и UNIQUE (name) года до записи
+func checkAcademicYear(q queryer, year models.AcademicYear) error {
+	if !year.EndDate.After(year.StartDate) {
+		return repository.ErrInvalidDateRange
+	}
+
+	var taken bool
+	err := q.QueryRow("SELECT EXISTS (SELECT 1 FROM academic_years WHERE name = $1 AND id <> $2)", year.Name, year.ID).Scan(&taken)
+	if err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+	if taken {
+		return repository.ErrAcademicYearExists
+	}
+
+	return nil
+}
+
+func CreateAcademicYear(db *sql.DB, year models.AcademicYear) (int, error) {
+	if err := checkAcademicYear(db, year); err != nil {
+		return 0, err
+	}
+
+	var yearID int
+	err := db.QueryRow(`
+        INSERT INTO academic_years (name, start_date, end_date, created_at, updated_at)
+        VALUES ($1, $2, $3, NOW(), NOW())
+        RETURNING id
+    `, year.Name, year.StartDate.Format(lessonDateLayout), year.EndDate.Format(lessonDateLayout)).Scan(&yearID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create academic year: %v", err)
+	}
+
+	return yearID, nil
+}
+
+// UpdateAcademicYear меняет год; новые границы должны вмещать все его периоды, иначе ErrTermOutsideYear
+func UpdateAcademicYear(db *sql.DB, year models.AcademicYear) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow("SELECT id FROM academic_years WHERE id = $1 FOR UPDATE", year.ID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("academic year not found")
+	} else if err != nil {
+		return fmt.Errorf("failed to fetch academic year: %v", err)
+	}
+
+	if err := checkAcademicYear(tx, year); err != nil {
+		return err
+	}
+
+	start, end := year.StartDate.Format(lessonDateLayout), year.EndDate.Format(lessonDateLayout)
+	var outside bool
+	err = tx.QueryRow(`
+        SELECT EXISTS (
+            SELECT 1 FROM terms WHERE academic_year_id = $1 AND (start_date < $2::date OR end_date > $3::date)
+        )
+    `, year.ID, start, end).Scan(&outside)
+	if err != nil {
+		return fmt.Errorf("failed to check academic year terms: %v", err)
+	}
+	if outside {
+		return repository.ErrTermOutsideYear
+	}
+
+	_, err = tx.Exec(`
+        UPDATE academic_years SET name = $1, start_date = $2, end_date = $3, updated_at = NOW() WHERE id = $4
+    `, year.Name, start, end, year.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update academic year: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit academic year update: %v", err)
+	}
+
+	return nil
+}
+
+// DeleteAcademicYear удаляет год; пока в нём есть периоды, возвращается *repository.InUseError
+func DeleteAcademicYear(db *sql.DB, yearID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow("SELECT id FROM academic_years WHERE id = $1 FOR UPDATE", yearID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("academic year not found")
+	} else if err != nil {
+		return fmt.Errorf("failed to fetch academic year: %v", err)
+	}
+
+	var terms int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM terms WHERE academic_year_id = $1", yearID).Scan(&terms); err != nil {
+		return fmt.Errorf("failed to count academic year references: %v", err)
+	}
+	if err := repository.CheckInUse("academic year", map[string]int{"terms": terms}); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM academic_years WHERE id = $1", yearID); err != nil {
+		return fmt.Errorf("failed to delete academic year: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit academic year deletion: %v", err)
+	}
+
+	return nil
+}
+
+const termSelect = `
+        SELECT t.id, t.academic_year_id, y.name, t.name, t.start_date, t.end_date, t.created_at, t.updated_at
+        FROM terms t
+        JOIN academic_years y ON t.academic_year_id = y.id`
+
+func scanTerm(row interface{ Scan(...interface{}) error }, term *models.Term) error {
+	return row.Scan(&term.ID, &term.AcademicYearID, &term.AcademicYearName, &term.Name,
+		&term.StartDate, &term.EndDate, &term.CreatedAt, &term.UpdatedAt)
+}
+
+// GetTerms возвращает периоды года yearID, а при yearID = 0 — все периоды
+func GetTerms(db *sql.DB, yearID int) ([]models.Term, error) {
+	var terms []models.Term
+
+	rows, err := db.Query(termSelect+`
+        WHERE $1 = 0 OR t.academic_year_id = $1
+        ORDER BY t.start_date, t.id`, yearID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch terms: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var term models.Term
+		if err := scanTerm(rows, &term); err != nil {
+			return nil, fmt.Errorf("failed to scan term: %v", err)
+		}
+		terms = append(terms, term)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over terms: %v", err)
+	}
+
+	return terms, nil
+}
+
+func GetTermByID(db queryer, termID int) (*models.Term, error) {
+	var term models.Term
+
+	if err := scanTerm(db.QueryRow(termSelect+` WHERE t.id = $1`, termID), &term); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("term not found")
+		}
+		return nil, fmt.Errorf("failed to fetch term: %v", err)
+	}
+
+	return &term, nil
+}
+
+// GetCurrentTerm возвращает период, в который попадает день date
+func GetCurrentTerm(db *sql.DB, date time.Time) (*models.Term, error) {
+	var term models.Term
+
+	err := scanTerm(db.QueryRow(termSelect+`
+        WHERE $1::date BETWEEN t.start_date AND t.end_date
+        ORDER BY t.start_date
+        LIMIT 1`, date.Format(lessonDateLayout)), &term)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("term not found")
+		}
+		return nil, fmt.Errorf("failed to fetch term: %v", err)
+	}
+
+	return &term, nil
+}
+
+// checkTerm проверяет период до записи: год существует и вмещает период, название в году свободно,
+// другие периоды года с ним не пересекаются. Строка года блокируется, чтобы проверки не разошлись с записью.
+func checkTerm(tx *sql.Tx, term models.Term) error {
+	if !term.EndDate.After(term.StartDate) {
+		return repository.ErrInvalidDateRange
+	}
+
+	var yearStart, yearEnd time.Time
+	err := tx.QueryRow("SELECT start_date, end_date FROM academic_years WHERE id = $1 FOR UPDATE", term.AcademicYearID).
+		Scan(&yearStart, &yearEnd)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("academic year not found")
+	} else if err != nil {
+		return fmt.Errorf("failed to fetch academic year: %v", err)
+	}
+
+	start, end := term.StartDate.Format(lessonDateLayout), term.EndDate.Format(lessonDateLayout)
+	if start < yearStart.Format(lessonDateLayout) || end > yearEnd.Format(lessonDateLayout) {
+		return repository.ErrTermOutsideYear
+	}
+
+	var taken, overlaps bool
+	err = tx.QueryRow(`
+        SELECT EXISTS (SELECT 1 FROM terms WHERE academic_year_id = $1 AND name = $2 AND id <> $3),
+               EXISTS (SELECT 1 FROM terms WHERE academic_year_id = $1 AND id <> $3
+                                             AND start_date <= $5::date AND end_date >= $4::date)
+    `, term.AcademicYearID, term.Name, term.ID, start, end).Scan(&taken, &overlaps)
+	if err != nil {
+		return fmt.Errorf("failed to check term: %v", err)
+	}
+	if taken {
+		return repository.ErrTermExists
+	}
+	if overlaps {
+		return repository.ErrTermOverlap
+	}
+
+	return nil
+}
+
+func CreateTerm(db *sql.DB, term models.Term) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	term.ID = 0
+	if err := checkTerm(tx, term); err != nil {
+		return 0, err
+	}
+
+	var termID int
+	err = tx.QueryRow(`
+        INSERT INTO terms (academic_year_id, name, start_date, end_date, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, NOW(), NOW())
+        RETURNING id
+    `, term.AcademicYearID, term.Name, term.StartDate.Format(lessonDateLayout), term.EndDate.Format(lessonDateLayout)).Scan(&termID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create term: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit term creation: %v", err)
+	}
+
+	return termID, nil
+}
+
+func UpdateTerm(db *sql.DB, term models.Term) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow("SELECT id FROM terms WHERE id = $1 FOR UPDATE", term.ID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("term not found")
+	} else if err != nil {
+		return fmt.Errorf("failed to fetch term: %v", err)
+	}
+
+	if err := checkTerm(tx, term); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+        UPDATE terms SET academic_year_id = $1, name = $2, start_date = $3, end_date = $4, updated_at = NOW()
+        WHERE id = $5
+    `, term.AcademicYearID, term.Name, term.StartDate.Format(lessonDateLayout), term.EndDate.Format(lessonDateLayout), term.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update term: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit term update: %v", err)
+	}
+
+	return nil
+}
+
+// DeleteTerm удаляет период; пока за него выставлены итоговые оценки, возвращается *repository.InUseError
+func DeleteTerm(db *sql.DB, termID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow("SELECT id FROM terms WHERE id = $1 FOR UPDATE", termID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("term not found")
+	} else if err != nil {
+		return fmt.Errorf("failed to fetch term: %v", err)
+	}
+
+	var finalGrades int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM final_grades WHERE term_id = $1", termID).Scan(&finalGrades); err != nil {
+		return fmt.Errorf("failed to count term references: %v", err)
+	}
+	if err := repository.CheckInUse("term", map[string]int{"final_grades": finalGrades}); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM terms WHERE id = $1", termID); err != nil {
+		return fmt.Errorf("failed to delete term: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit term deletion: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/VladislavSCV/internal/patch"
+	"sort"
+	"strings"
+)
+
+// execer — *sql.DB или *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// updateColumns обновляет строку таблицы по id; в запрос попадают только колонки из схемы,
+// поэтому имена колонок никогда не приходят от клиента напрямую
+func updateColumns(db execer, table string, schema patch.Schema, id int, updates map[string]interface{}) (bool, error) {
+	columns := make([]string, 0, len(updates))
+	for column := range updates {
+		if !schema.Allows(column) {
+			return false, fmt.Errorf("column %q cannot be updated", column)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	var set []string
+	var args []interface{}
+	for i, column := range columns {
+		set = append(set, fmt.Sprintf("%s = $%d", column, i+1))
+		args = append(args, updates[column])
+	}
+	set = append(set, "updated_at = NOW()")
+	args = append(args, id)
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d", table, strings.Join(set, ", "), len(args))
+	res, err := db.Exec(query, args...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
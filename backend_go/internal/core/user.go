@@ -4,69 +4,57 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/patch"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
 )
 
-func GetAllUsers(db *sql.DB) ([]models.User, error) {
-	var users []models.User
-
-	if db == nil {
-		return nil, fmt.Errorf("database connection is nil")
-	}
-
-	rows, err := db.Query(`
-        SELECT u.id, u.first_name, u.middle_name, u.last_name, r.value AS role, g.name AS group_name, u.login, u.created_at, u.updated_at
-        FROM users u
+// userListQuery — выборка пользователей под фильтром, которых видит scope; общая для списка и выгрузки
+func userListQuery(scope policy.Scope, filter models.UserFilter) listQuery {
+	q := listQuery{
+		columns: "u.id, u.first_name, u.middle_name, u.last_name, r.value AS role, g.name AS group_name, u.login, u.created_at, u.updated_at",
+		from: `users u
         JOIN roles r ON u.role_id = r.id
-        LEFT JOIN groups g ON u.group_id = g.id
-    `)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch users: %v", err)
+        LEFT JOIN groups g ON u.group_id = g.id`,
+		sorts: map[string]string{
+			"id":         "u.id",
+			"last_name":  "u.last_name",
+			"first_name": "u.first_name",
+			"login":      "u.login",
+			"role":       "r.value",
+			"group":      "COALESCE(g.name, '')",
+			"created_at": "u.created_at",
+		},
+		id: "u.id",
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var user models.User
-		if err := rows.Scan(&user.ID, &user.FirstName, &user.MiddleName, &user.LastName, &user.Role, &user.Group, &user.Login, &user.CreatedAt, &user.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan user: %v", err)
-		}
-		users = append(users, user)
+	q.scoped(func(arg int) (string, []interface{}) { return userFilter(scope, "u.id", arg) })
+	if filter.Role != "" {
+		q.where = append(q.where, "r.value = "+q.arg(filter.Role))
 	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating over users: %v", err)
+	if filter.GroupID != 0 {
+		q.where = append(q.where, "u.group_id = "+q.arg(filter.GroupID))
 	}
-
-	return users, nil
+	return q
 }
 
-func GetStudents(db *sql.DB) ([]models.User, error) {
-	var students []models.User
-
-	rows, err := db.Query(`
-        SELECT u.id, u.first_name, u.middle_name, u.last_name, r.value AS role, g.name AS group_name, u.login, u.created_at, u.updated_at
-        FROM users u
-        JOIN roles r ON u.role_id = r.id
-        LEFT JOIN groups g ON u.group_id = g.id
-        WHERE r.value = 'student'
-    `)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch students: %v", err)
+// ListUsers возвращает страницу пользователей, которых видит scope
+func ListUsers(db *sql.DB, scope policy.Scope, filter models.UserFilter, page models.ListQuery) ([]models.User, models.PageInfo, error) {
+	if db == nil {
+		return nil, models.PageInfo{}, fmt.Errorf("database connection is nil")
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var student models.User
-		if err := rows.Scan(&student.ID, &student.FirstName, &student.MiddleName, &student.LastName, &student.Role, &student.Group, &student.Login, &student.CreatedAt, &student.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan student: %v", err)
+	users, info, err := listPage(db, userListQuery(scope, filter), page, func(rows *sql.Rows, sortValue *string) (models.User, int, error) {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.FirstName, &user.MiddleName, &user.LastName, &user.Role, &user.Group, &user.Login, &user.CreatedAt, &user.UpdatedAt, sortValue); err != nil {
+			return user, 0, fmt.Errorf("failed to scan user: %v", err)
 		}
-		students = append(students, student)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating over students: %v", err)
+		return user, user.ID, nil
+	})
+	if err != nil {
+		return nil, info, fmt.Errorf("failed to fetch users: %v", err)
 	}
 
-	return students, nil
+	return users, info, nil
 }
 
 func GetTeachers(db *sql.DB) ([]models.User, error) {
@@ -99,16 +87,16 @@ func GetTeachers(db *sql.DB) ([]models.User, error) {
 	return teachers, nil
 }
 
-func GetUserByID(db *sql.DB, userID int) (*models.User, error) {
+func GetUserByID(db *sql.DB, scope policy.Scope, userID int) (*models.User, error) {
 	var user models.User
 
+	filter, args := userFilter(scope, "u.id", 2)
 	err := db.QueryRow(`
         SELECT u.id, u.first_name, u.middle_name, u.last_name, r.value AS role, g.name AS group_name, u.login, u.created_at, u.updated_at
         FROM users u
         JOIN roles r ON u.role_id = r.id
         LEFT JOIN groups g ON u.group_id = g.id
-        WHERE u.id = $1
-    `, userID).Scan(&user.ID, &user.FirstName, &user.MiddleName, &user.LastName, &user.Role, &user.Group, &user.Login, &user.CreatedAt, &user.UpdatedAt)
+        WHERE u.id = $1 AND `+filter, append([]interface{}{userID}, args...)...).Scan(&user.ID, &user.FirstName, &user.MiddleName, &user.LastName, &user.Role, &user.Group, &user.Login, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("user not found")
@@ -119,34 +107,39 @@ func GetUserByID(db *sql.DB, userID int) (*models.User, error) {
 	return &user, nil
 }
 
-func UpdateUser(db *sql.DB, updates map[string]interface{}) error {
-	//log.Printf("Updating user with ID: %d, FirstName: %s, Role: %s, Group: %s", user.ID, user.FirstName, user.RoleID, user.Group)
-
-	// Формирование запроса
-	query := "UPDATE users SET "
-	var args []interface{}
-	i := 1
-
-	for k, v := range updates {
-		query += fmt.Sprintf("%s = $%d, ", k, i) // Используем $ для подстановки
-		args = append(args, v)
-		i++
+// UpdateUser меняет только поля из patch.UserSchema; остальные ключи отклоняются
+func UpdateUser(db *sql.DB, userID int, updates map[string]interface{}) error {
+	if login, ok := updates["login"].(string); ok {
+		taken, err := loginTaken(db, userID, login)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return repository.ErrLoginExists
+		}
 	}
 
-	// Удаляем последнюю запятую
-	query = query[:len(query)-2]
-	query += fmt.Sprintf(" WHERE id = $%d", i)
-	args = append(args, updates["id"])
-
-	// Выполнение запроса
-	_, err := db.Exec(query, args...)
+	found, err := updateColumns(db, "users", patch.UserSchema, userID, updates)
 	if err != nil {
 		return fmt.Errorf("failed to update user: %v", err)
 	}
+	if !found {
+		return fmt.Errorf("user not found")
+	}
 
 	return nil
 }
 
+// loginTaken проверяет, занят ли логин другим пользователем (индекс users_login_key)
+func loginTaken(db *sql.DB, userID int, login string) (bool, error) {
+	var exists bool
+	err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM users WHERE login = $1 AND id <> $2)", login, userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("database error: %v", err)
+	}
+	return exists, nil
+}
+
 func DeleteUser(db *sql.DB, userID int) error {
 	_, err := db.Exec("DELETE FROM users WHERE id = $1", userID)
 	if err != nil {
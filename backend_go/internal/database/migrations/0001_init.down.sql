@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS attendance;
+DROP TABLE IF EXISTS grades;
+DROP TABLE IF EXISTS schedules;
+DROP TABLE IF EXISTS subjects;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS groups;
+DROP TABLE IF EXISTS roles;
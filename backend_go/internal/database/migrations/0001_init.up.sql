@@ -0,0 +1,81 @@
+-- Базовая схема: роли, группы, пользователи, предметы, расписание, оценки, посещаемость
+
+CREATE TABLE roles (
+    id    SERIAL PRIMARY KEY,
+    value VARCHAR(50) NOT NULL UNIQUE
+);
+
+-- ID ролей зафиксированы, чтобы совпадать с ролями хранилища в памяти (repository/memory); код и миграции находят роли по value
+INSERT INTO roles (id, value) VALUES (1, 'admin'), (2, 'teacher'), (3, 'student');
+SELECT setval(pg_get_serial_sequence('roles', 'id'), (SELECT MAX(id) FROM roles));
+
+CREATE TABLE groups (
+    id         SERIAL PRIMARY KEY,
+    name       VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE users (
+    id          SERIAL PRIMARY KEY,
+    first_name  VARCHAR(100) NOT NULL,
+    middle_name VARCHAR(100) NOT NULL DEFAULT '',
+    last_name   VARCHAR(100) NOT NULL,
+    role_id     INTEGER      NOT NULL REFERENCES roles (id),
+    group_id    INTEGER      REFERENCES groups (id) ON DELETE SET NULL,
+    login       VARCHAR(100) NOT NULL UNIQUE,
+    password    VARCHAR(255) NOT NULL,
+    salt        VARCHAR(255) NOT NULL,
+    created_at  TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_users_role_id ON users (role_id);
+CREATE INDEX idx_users_group_id ON users (group_id);
+
+CREATE TABLE subjects (
+    id         SERIAL PRIMARY KEY,
+    name       VARCHAR(150) NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE schedules (
+    id          SERIAL PRIMARY KEY,
+    group_id    INTEGER      NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    subject_id  INTEGER      NOT NULL REFERENCES subjects (id),
+    teacher_id  INTEGER      NOT NULL REFERENCES users (id),
+    day_of_week SMALLINT     NOT NULL CHECK (day_of_week BETWEEN 1 AND 7),
+    start_time  TIME         NOT NULL,
+    end_time    TIME         NOT NULL,
+    location    VARCHAR(100) NOT NULL DEFAULT '',
+    created_at  TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_schedules_group_id ON schedules (group_id);
+CREATE INDEX idx_schedules_teacher_id ON schedules (teacher_id);
+
+CREATE TABLE grades (
+    id         SERIAL PRIMARY KEY,
+    student_id INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    subject_id INTEGER   NOT NULL REFERENCES subjects (id),
+    value      SMALLINT  NOT NULL CHECK (value BETWEEN 2 AND 5),
+    date       DATE      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_grades_student_id ON grades (student_id);
+
+CREATE TABLE attendance (
+    id         SERIAL PRIMARY KEY,
+    student_id INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    subject_id INTEGER     NOT NULL REFERENCES subjects (id),
+    date       DATE        NOT NULL,
+    status     VARCHAR(20) NOT NULL CHECK (status IN ('present', 'absent', 'excused')),
+    created_at TIMESTAMP   NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_attendance_student_id ON attendance (student_id);
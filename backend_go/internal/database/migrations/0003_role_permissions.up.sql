@@ -0,0 +1,44 @@
+-- Права ролей: роли владеют именованными правами, middleware.RequirePermission проверяет их по базе
+ALTER TABLE roles ADD COLUMN description TEXT    NOT NULL DEFAULT '';
+ALTER TABLE roles ADD COLUMN builtin     BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE roles SET builtin = TRUE, description = 'Администратор' WHERE value = 'admin';
+UPDATE roles SET builtin = TRUE, description = 'Преподаватель' WHERE value = 'teacher';
+UPDATE roles SET builtin = TRUE, description = 'Студент' WHERE value = 'student';
+
+-- Каталог прав задаётся миграциями: каждое право проверяется где-то в коде
+CREATE TABLE permissions (
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT         NOT NULL DEFAULT ''
+);
+
+CREATE TABLE role_permissions (
+    role_id       INTEGER NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    permission_id INTEGER NOT NULL REFERENCES permissions (id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:read', 'Просмотр списка всех пользователей'),
+    ('students:read', 'Просмотр списка студентов'),
+    ('users:manage', 'Изменение и удаление пользователей'),
+    ('roles:manage', 'Управление ролями, правами и назначение ролей'),
+    ('groups:manage', 'Создание, изменение и удаление групп'),
+    ('schedule:manage', 'Создание, изменение и удаление занятий'),
+    ('grades:read', 'Просмотр оценок'),
+    ('grades:write', 'Выставление, изменение и удаление оценок'),
+    ('attendance:read', 'Просмотр посещаемости'),
+    ('attendance:write', 'Отметка посещаемости'),
+    ('records:all', 'Доступ к оценкам и посещаемости всех студентов'),
+    ('records:taught', 'Доступ к оценкам и посещаемости по своим группам и предметам из расписания'),
+    ('records:own', 'Доступ только к своим оценкам и посещаемости');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON
+    (r.value = 'admin' AND p.name NOT IN ('records:taught', 'records:own'))
+    OR (r.value = 'teacher' AND p.name IN ('students:read', 'grades:read', 'grades:write',
+                                           'attendance:read', 'attendance:write', 'records:taught'))
+    OR (r.value = 'student' AND p.name IN ('grades:read', 'attendance:read', 'records:own'));
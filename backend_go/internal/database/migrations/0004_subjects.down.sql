@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'subjects:manage';
+
+DROP INDEX IF EXISTS subjects_name_key;
@@ -0,0 +1,11 @@
+-- Справочник предметов: имена уникальны без учёта регистра, управление — по праву subjects:manage
+CREATE UNIQUE INDEX subjects_name_key ON subjects (LOWER(name));
+
+INSERT INTO permissions (name, description) VALUES
+    ('subjects:manage', 'Создание, изменение и удаление предметов');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name = 'subjects:manage'
+WHERE r.value = 'admin';
@@ -0,0 +1,7 @@
+-- Секреты подписки на ленты расписания (.ics): календари опрашивают ленту без JWT.
+-- Хранится только хеш; у пользователя не больше одного действующего токена.
+CREATE TABLE calendar_tokens (
+    user_id    INTEGER     PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+ALTER TABLE attendance DROP CONSTRAINT IF EXISTS attendance_lesson_student_key;
+ALTER TABLE grades DROP COLUMN IF EXISTS lesson_id;
+ALTER TABLE attendance DROP COLUMN IF EXISTS lesson_id;
+
+DROP TABLE IF EXISTS lessons;
@@ -0,0 +1,28 @@
+-- Конкретные занятия: недельное расписание, развёрнутое в даты.
+-- Поля занятия копируются из schedules, чтобы правка расписания не переписывала прошлое.
+CREATE TABLE lessons (
+    id          SERIAL PRIMARY KEY,
+    schedule_id INTEGER      REFERENCES schedules (id) ON DELETE SET NULL,
+    group_id    INTEGER      NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    subject_id  INTEGER      NOT NULL REFERENCES subjects (id),
+    teacher_id  INTEGER      NOT NULL REFERENCES users (id),
+    date        DATE         NOT NULL,
+    start_time  TIME         NOT NULL,
+    end_time    TIME         NOT NULL,
+    location    VARCHAR(100) NOT NULL DEFAULT '',
+    created_at  TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMP    NOT NULL DEFAULT NOW(),
+    -- Повторная генерация за тот же период не создаёт дублей
+    CONSTRAINT lessons_schedule_date_key UNIQUE (schedule_id, date)
+);
+
+CREATE INDEX idx_lessons_group_date ON lessons (group_id, date);
+CREATE INDEX idx_lessons_teacher_date ON lessons (teacher_id, date);
+
+-- Оценки и посещаемость могут ссылаться на конкретное занятие
+ALTER TABLE attendance ADD COLUMN lesson_id INTEGER REFERENCES lessons (id) ON DELETE SET NULL;
+ALTER TABLE grades ADD COLUMN lesson_id INTEGER REFERENCES lessons (id) ON DELETE SET NULL;
+
+-- Не больше одной отметки студента на занятии (записи без lesson_id не ограничиваются)
+ALTER TABLE attendance ADD CONSTRAINT attendance_lesson_student_key UNIQUE (lesson_id, student_id);
+CREATE INDEX idx_grades_lesson_id ON grades (lesson_id);
@@ -0,0 +1,7 @@
+DELETE FROM permissions WHERE name = 'grade_types:manage';
+
+ALTER TABLE grades DROP COLUMN IF EXISTS comment;
+ALTER TABLE grades DROP COLUMN IF EXISTS grade_type_id;
+
+DROP TABLE IF EXISTS subject_grade_weights;
+DROP TABLE IF EXISTS grade_types;
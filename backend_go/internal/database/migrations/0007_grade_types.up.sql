@@ -0,0 +1,36 @@
+-- Виды оценок с весом по умолчанию и веса, переопределённые для отдельных предметов.
+-- Оценка без вида весит 1.
+CREATE TABLE grade_types (
+    id             SERIAL PRIMARY KEY,
+    code           VARCHAR(50)  NOT NULL UNIQUE,
+    name           VARCHAR(100) NOT NULL,
+    default_weight NUMERIC(5, 2) NOT NULL DEFAULT 1 CHECK (default_weight > 0),
+    created_at     TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO grade_types (code, name, default_weight) VALUES
+    ('oral', 'Устный ответ', 1),
+    ('homework', 'Домашняя работа', 1),
+    ('lab', 'Лабораторная работа', 1.5),
+    ('test', 'Контрольная работа', 2),
+    ('exam', 'Экзамен', 3);
+
+CREATE TABLE subject_grade_weights (
+    subject_id    INTEGER       NOT NULL REFERENCES subjects (id) ON DELETE CASCADE,
+    grade_type_id INTEGER       NOT NULL REFERENCES grade_types (id) ON DELETE CASCADE,
+    weight        NUMERIC(5, 2) NOT NULL CHECK (weight > 0),
+    PRIMARY KEY (subject_id, grade_type_id)
+);
+
+ALTER TABLE grades ADD COLUMN grade_type_id INTEGER REFERENCES grade_types (id);
+ALTER TABLE grades ADD COLUMN comment VARCHAR(500) NOT NULL DEFAULT '';
+
+INSERT INTO permissions (name, description) VALUES
+    ('grade_types:manage', 'Настройка видов оценок и их весов');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name = 'grade_types:manage'
+WHERE r.value = 'admin';
@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE name IN ('terms:manage', 'grades:override');
+
+DROP TABLE IF EXISTS final_grades;
+DROP TABLE IF EXISTS terms;
+DROP TABLE IF EXISTS academic_years;
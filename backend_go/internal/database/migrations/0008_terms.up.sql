@@ -0,0 +1,52 @@
+-- Учебные годы и периоды (семестры, четверти) внутри них.
+-- Что периоды одного года не пересекаются и лежат внутри года, проверяет приложение.
+CREATE TABLE academic_years (
+    id         SERIAL PRIMARY KEY,
+    name       VARCHAR(50) NOT NULL UNIQUE,
+    start_date DATE        NOT NULL,
+    end_date   DATE        NOT NULL,
+    created_at TIMESTAMP   NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP   NOT NULL DEFAULT NOW(),
+    CHECK (end_date > start_date)
+);
+
+CREATE TABLE terms (
+    id               SERIAL PRIMARY KEY,
+    academic_year_id INTEGER     NOT NULL REFERENCES academic_years (id),
+    name             VARCHAR(50) NOT NULL,
+    start_date       DATE        NOT NULL,
+    end_date         DATE        NOT NULL,
+    created_at       TIMESTAMP   NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMP   NOT NULL DEFAULT NOW(),
+    CHECK (end_date > start_date),
+    CONSTRAINT terms_year_name_key UNIQUE (academic_year_id, name)
+);
+
+CREATE INDEX idx_terms_dates ON terms (start_date, end_date);
+
+-- Итоговые оценки за период. suggested — средневзвешенный балл на момент расчёта;
+-- после закрытия (locked) оценки студента по предмету за период меняются только с правом grades:override.
+CREATE TABLE final_grades (
+    id         SERIAL PRIMARY KEY,
+    term_id    INTEGER       NOT NULL REFERENCES terms (id),
+    student_id INTEGER       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    subject_id INTEGER       NOT NULL REFERENCES subjects (id),
+    suggested  NUMERIC(4, 2),
+    value      SMALLINT      NOT NULL CHECK (value BETWEEN 2 AND 5),
+    locked     BOOLEAN       NOT NULL DEFAULT FALSE,
+    locked_at  TIMESTAMP,
+    locked_by  INTEGER       REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMP     NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP     NOT NULL DEFAULT NOW(),
+    CONSTRAINT final_grades_term_student_subject_key UNIQUE (term_id, student_id, subject_id)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('terms:manage', 'Создание, изменение и удаление учебных годов и периодов'),
+    ('grades:override', 'Изменение оценок и итоговых оценок в закрытых периодах');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name IN ('terms:manage', 'grades:override')
+WHERE r.value = 'admin';
@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE name = 'history:read';
+
+DROP TABLE IF EXISTS record_history;
+DROP FUNCTION IF EXISTS record_history_append_only();
@@ -0,0 +1,35 @@
+-- История изменений оценок и посещаемости. Записи только добавляются: триггер запрещает UPDATE и DELETE.
+-- record_id и actor_id без внешних ключей, чтобы история переживала удаление записи и пользователя.
+-- old_value и new_value — строка записи до и после изменения (to_jsonb), NULL при создании и удалении соответственно.
+CREATE TABLE record_history (
+    id          BIGSERIAL PRIMARY KEY,
+    record_type VARCHAR(20)  NOT NULL CHECK (record_type IN ('grade', 'attendance')),
+    record_id   INTEGER      NOT NULL,
+    action      VARCHAR(10)  NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    actor_id    INTEGER,
+    old_value   JSONB,
+    new_value   JSONB,
+    reason      VARCHAR(500) NOT NULL DEFAULT '',
+    created_at  TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_record_history_record ON record_history (record_type, record_id, id);
+
+CREATE FUNCTION record_history_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'record_history is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER record_history_append_only
+    BEFORE UPDATE OR DELETE ON record_history
+    FOR EACH ROW EXECUTE FUNCTION record_history_append_only();
+
+INSERT INTO permissions (name, description) VALUES
+    ('history:read', 'Просмотр истории изменений оценок и посещаемости');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name = 'history:read'
+WHERE r.value = 'admin';
@@ -0,0 +1,41 @@
+-- Журнал аудита: каждый изменяющий запрос к API и каждая попытка входа. Записи только добавляются.
+-- actor_id без внешнего ключа, а actor_login хранит логин на момент запроса, чтобы журнал переживал удаление пользователя;
+-- при неудачном входе actor_id пуст, а actor_login — логин, под которым пытались войти.
+-- changes — поля сущности, изменённые запросом: {"поле": {"old": ..., "new": ...}}.
+CREATE TABLE audit_log (
+    id          BIGSERIAL PRIMARY KEY,
+    actor_id    INTEGER,
+    actor_login VARCHAR(50)  NOT NULL DEFAULT '',
+    ip          VARCHAR(45)  NOT NULL DEFAULT '',
+    method      VARCHAR(10)  NOT NULL,
+    route       VARCHAR(255) NOT NULL,
+    action      VARCHAR(50)  NOT NULL,
+    status      SMALLINT     NOT NULL,
+    entity_type VARCHAR(50)  NOT NULL DEFAULT '',
+    entity_id   INTEGER,
+    changes     JSONB,
+    created_at  TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_log_created_at ON audit_log (created_at);
+CREATE INDEX idx_audit_log_actor ON audit_log (actor_id);
+CREATE INDEX idx_audit_log_entity ON audit_log (entity_type, entity_id);
+
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+INSERT INTO permissions (name, description) VALUES
+    ('audit:read', 'Просмотр и выгрузка журнала аудита');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name = 'audit:read'
+WHERE r.value = 'admin';
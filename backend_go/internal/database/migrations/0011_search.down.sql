@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_users_search;
+DROP INDEX IF EXISTS idx_groups_search;
+DROP INDEX IF EXISTS idx_subjects_search;
+
+DROP EXTENSION IF EXISTS pg_trgm;
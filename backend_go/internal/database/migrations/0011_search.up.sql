@@ -0,0 +1,9 @@
+-- Поиск по ФИО и логинам пользователей, названиям групп и предметов (GET /api/search).
+-- Триграммные индексы pg_trgm находят и подстроки, и слова с опечатками.
+-- Выражения индексов должны совпадать с выражениями в internal/core/search.go, иначе индексы не используются
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_users_search ON users
+    USING GIN ((translate(lower(first_name || ' ' || middle_name || ' ' || last_name || ' ' || login), 'ё', 'е')) gin_trgm_ops);
+CREATE INDEX idx_groups_search ON groups USING GIN ((translate(lower(name), 'ё', 'е')) gin_trgm_ops);
+CREATE INDEX idx_subjects_search ON subjects USING GIN ((translate(lower(name), 'ё', 'е')) gin_trgm_ops);
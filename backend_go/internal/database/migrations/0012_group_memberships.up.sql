@@ -0,0 +1,23 @@
+-- История групп студентов: в какой группе студент состоял в какие дни. users.group_id остаётся
+-- текущей группой и меняется только вместе с историей (переводом POST /api/group/{id}/transfer).
+-- valid_from пуст у первой группы студента — «с самого начала», valid_to не включается и пуст у текущей группы.
+-- Групповые выборки оценок и посещаемости берут студентов, состоявших в группе в день записи.
+-- Группу с историей удалить нельзя, чтобы история не терялась.
+CREATE TABLE group_memberships (
+    id         SERIAL PRIMARY KEY,
+    student_id INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    group_id   INTEGER   NOT NULL REFERENCES groups (id) ON DELETE RESTRICT,
+    valid_from DATE,
+    valid_to   DATE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (valid_to IS NULL OR valid_from IS NULL OR valid_to > valid_from)
+);
+
+CREATE INDEX idx_group_memberships_student_id ON group_memberships (student_id);
+CREATE INDEX idx_group_memberships_group_id ON group_memberships (group_id);
+-- Текущая группа у студента одна
+CREATE UNIQUE INDEX idx_group_memberships_current ON group_memberships (student_id) WHERE valid_to IS NULL;
+
+-- Текущие группы становятся первыми записями истории
+INSERT INTO group_memberships (student_id, group_id)
+SELECT id, group_id FROM users WHERE group_id IS NOT NULL;
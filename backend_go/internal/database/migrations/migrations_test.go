@@ -0,0 +1,148 @@
+package migrations
+
+import (
+	"database/sql"
+	_ "github.com/lib/pq" // Драйвер для PostgreSQL
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var createTableRe = regexp.MustCompile(`(?i)CREATE TABLE (?:IF NOT EXISTS )?([a-z_]+)`)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := load(files)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			t.Errorf("migration %d_%s: version %d, want %d (versions must go without gaps)", migration.Version, migration.Name, migration.Version, i+1)
+		}
+		if len(migration.Checksum) != 64 {
+			t.Errorf("migration %d_%s: checksum %q", migration.Version, migration.Name, migration.Checksum)
+		}
+		// Откат должен удалять все таблицы, созданные миграцией
+		for _, m := range createTableRe.FindAllStringSubmatch(migration.Up, -1) {
+			if !strings.Contains(migration.Down, "DROP TABLE IF EXISTS "+m[1]) {
+				t.Errorf("migration %d_%s: down does not drop table %s", migration.Version, migration.Name, m[1])
+			}
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_second.up.sql":   {Data: []byte("CREATE TABLE b (id INT);")},
+		"0002_second.down.sql": {Data: []byte("DROP TABLE IF EXISTS b;")},
+		"0001_first.up.sql":    {Data: []byte("CREATE TABLE a (id INT);")},
+		"0001_first.down.sql":  {Data: []byte("DROP TABLE IF EXISTS a;")},
+	}
+	migrations, err := load(fsys)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(migrations) != 2 || migrations[0].Name != "first" || migrations[1].Name != "second" {
+		t.Fatalf("migrations = %+v, want first and second in order", migrations)
+	}
+	if migrations[0].Down != "DROP TABLE IF EXISTS a;" {
+		t.Errorf("down = %q", migrations[0].Down)
+	}
+
+	// Контрольная сумма зависит только от up-файла
+	before := migrations[0].Checksum
+	fsys["0001_first.down.sql"] = &fstest.MapFile{Data: []byte("DROP TABLE a;")}
+	if migrations, err = load(fsys); err != nil || migrations[0].Checksum != before {
+		t.Errorf("checksum changed with the down file: %v", err)
+	}
+	fsys["0001_first.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE a (id BIGINT);")}
+	if migrations, err = load(fsys); err != nil || migrations[0].Checksum == before {
+		t.Errorf("checksum did not change with the up file: %v", err)
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{"invalid name", []string{"0001_First.up.sql", "0001_First.down.sql"}, "invalid migration file name"},
+		{"no up file", []string{"0001_first.down.sql"}, "has no up file"},
+		{"no down file", []string{"0001_first.up.sql"}, "has no down file"},
+		{"conflicting names", []string{"0001_first.up.sql", "0001_other.down.sql"}, "conflicting names"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			for _, name := range tt.files {
+				fsys[name] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+			}
+			_, err := load(fsys)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("load() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestUpDown применяет и откатывает все миграции на пустой базе из TEST_DATABASE_URL
+func TestUpDown(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+
+	m, err := New(db)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	applied, err := m.Up(0)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(applied) != len(m.migrations) {
+		t.Fatalf("Up applied %d migrations, want %d (is the database empty?)", len(applied), len(m.migrations))
+	}
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	for _, status := range statuses {
+		if !status.Applied || status.Modified {
+			t.Errorf("status %+v, want applied and not modified", status)
+		}
+	}
+
+	if _, err := m.Redo(); err != nil {
+		t.Fatalf("Redo: %v", err)
+	}
+
+	rolledBack, err := m.Down(len(m.migrations))
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if len(rolledBack) != len(m.migrations) || rolledBack[0].Version != len(m.migrations) {
+		t.Fatalf("Down rolled back %d migrations, want all from the last one", len(rolledBack))
+	}
+
+	// После полного отката схему можно поднять заново
+	if _, err := m.Up(0); err != nil {
+		t.Fatalf("Up after Down: %v", err)
+	}
+	if _, err := m.Down(len(m.migrations)); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+}
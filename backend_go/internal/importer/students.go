@@ -0,0 +1,250 @@
+// Package importer загружает студентов из CSV и XLSX: общий код эндпоинта импорта и команды CLI
+package importer
+
+import (
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/VladislavSCV/internal/tabular"
+	"github.com/VladislavSCV/pkg"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ограничения импорта: строк в файле, длина полей (как в таблицах users и groups) и длина пароля
+const (
+	MaxRows        = 5000
+	maxFieldLength = 100
+	passwordLength = 12
+)
+
+// Колонки файла импорта
+const (
+	columnName       = "name"
+	columnLastName   = "last_name"
+	columnFirstName  = "first_name"
+	columnMiddleName = "middle_name"
+	columnGroup      = "group"
+	columnLogin      = "login"
+)
+
+// columnAliases сопоставляет заголовки файла (без учёта регистра) колонкам импорта.
+// Имя задаётся либо одной колонкой name («Фамилия Имя Отчество»), либо раздельно
+var columnAliases = map[string]string{
+	"name":        columnName,
+	"full_name":   columnName,
+	"фио":         columnName,
+	"last_name":   columnLastName,
+	"фамилия":     columnLastName,
+	"first_name":  columnFirstName,
+	"имя":         columnFirstName,
+	"middle_name": columnMiddleName,
+	"отчество":    columnMiddleName,
+	"group":       columnGroup,
+	"group_name":  columnGroup,
+	"группа":      columnGroup,
+	"login":       columnLogin,
+	"логин":       columnLogin,
+}
+
+// Students разбирает файл формата format (tabular.FormatCSV или tabular.FormatXLSX), проверяет каждую строку,
+// генерирует пароли и создаёт студентов с недостающими группами через users. Ошибки файла и строк
+// возвращаются в результате; error — только отказ хранилища. При dryRun ничего не сохраняется
+func Students(users repository.UserRepository, data []byte, format string, dryRun bool) (*models.ImportResult, error) {
+	result := models.NewImportResult(dryRun)
+
+	rows, err := tabular.Read(data, format)
+	if err != nil {
+		result.AddError(0, "", err.Error())
+		return result, nil
+	}
+	students := parseStudents(rows, result)
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	passwords := make(map[int]string)
+	if !dryRun {
+		if err := hashPasswords(students, passwords); err != nil {
+			return nil, fmt.Errorf("failed to generate passwords: %v", err)
+		}
+	}
+
+	imported, err := users.ImportStudents(students, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	if imported.Committed {
+		for i := range imported.Students {
+			imported.Students[i].Password = passwords[imported.Students[i].Line]
+		}
+	}
+	return imported, nil
+}
+
+// parseStudents сопоставляет колонки по заголовку и проверяет строки; ошибки пишет в result.
+// Пустые строки пропускаются
+func parseStudents(rows [][]string, result *models.ImportResult) []models.StudentImport {
+	if len(rows) == 0 {
+		result.AddError(0, "", "file is empty")
+		return nil
+	}
+	if len(rows)-1 > MaxRows {
+		result.AddError(0, "", fmt.Sprintf("file must contain at most %d rows", MaxRows))
+		return nil
+	}
+
+	columns := make(map[string]int)
+	for i, header := range rows[0] {
+		column, ok := columnAliases[strings.ToLower(strings.TrimSpace(header))]
+		if !ok {
+			continue
+		}
+		if _, duplicate := columns[column]; duplicate {
+			result.AddError(1, header, "duplicate column")
+			continue
+		}
+		columns[column] = i
+	}
+	_, hasName := columns[columnName]
+	_, hasFirst := columns[columnFirstName]
+	_, hasLast := columns[columnLastName]
+	if !hasName && !(hasFirst && hasLast) {
+		result.AddError(1, columnName, "name or first_name and last_name columns are required")
+	}
+	for _, column := range []string{columnGroup, columnLogin} {
+		if _, ok := columns[column]; !ok {
+			result.AddError(1, column, "column is required")
+		}
+	}
+	if len(result.Errors) > 0 {
+		return nil
+	}
+
+	var students []models.StudentImport
+	logins := make(map[string]int)
+	for i, row := range rows[1:] {
+		line := i + 2
+		cell := func(column string) string {
+			index, ok := columns[column]
+			if !ok || index >= len(row) {
+				return ""
+			}
+			return strings.Join(strings.Fields(row[index]), " ")
+		}
+
+		if blankRow(row) {
+			continue
+		}
+
+		student := models.StudentImport{
+			Line:       line,
+			FirstName:  cell(columnFirstName),
+			MiddleName: cell(columnMiddleName),
+			LastName:   cell(columnLastName),
+			GroupName:  cell(columnGroup),
+			Login:      cell(columnLogin),
+		}
+		valid := true
+		if name := cell(columnName); name != "" && student.FirstName == "" && student.LastName == "" {
+			parts := strings.SplitN(name, " ", 3)
+			switch len(parts) {
+			case 1:
+				result.AddError(line, columnName, "name must contain last name and first name")
+				valid = false
+			case 3:
+				student.MiddleName = parts[2]
+				fallthrough
+			default:
+				student.LastName, student.FirstName = parts[0], parts[1]
+			}
+		}
+		for _, field := range []struct {
+			column, value string
+			required      bool
+		}{
+			{columnLastName, student.LastName, true},
+			{columnFirstName, student.FirstName, true},
+			{columnMiddleName, student.MiddleName, false},
+			{columnGroup, student.GroupName, true},
+			{columnLogin, student.Login, true},
+		} {
+			switch {
+			case field.required && field.value == "":
+				result.AddError(line, field.column, "value is required")
+				valid = false
+			case utf8.RuneCountInString(field.value) > maxFieldLength:
+				result.AddError(line, field.column, fmt.Sprintf("value must be at most %d characters", maxFieldLength))
+				valid = false
+			}
+		}
+		if strings.IndexFunc(student.Login, unicode.IsSpace) >= 0 {
+			result.AddError(line, columnLogin, "login must not contain spaces")
+			valid = false
+		}
+		if first, ok := logins[student.Login]; ok && student.Login != "" {
+			result.AddError(line, columnLogin, fmt.Sprintf("duplicate login, first used on line %d", first))
+			valid = false
+		} else {
+			logins[student.Login] = line
+		}
+
+		if valid {
+			students = append(students, student)
+		}
+	}
+	if len(students) == 0 && len(result.Errors) == 0 {
+		result.AddError(0, "", "file has no students")
+	}
+	return students
+}
+
+func blankRow(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// hashPasswords генерирует пароли студентам и хеширует их параллельно: argon2 медленный намеренно,
+// а в наборе бывают сотни студентов. Пароли в открытом виде складываются в passwords по номеру строки
+func hashPasswords(students []models.StudentImport, passwords map[int]string) error {
+	for i := range students {
+		password, err := pkg.GeneratePassword(passwordLength)
+		if err != nil {
+			return err
+		}
+		passwords[students[i].Line] = password
+	}
+
+	jobs := make(chan int)
+	errs := make(chan error, len(students))
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				hash, err := pkg.CreateHashWithSalt(passwords[students[i].Line])
+				if err != nil {
+					errs <- err
+					continue
+				}
+				students[i].Password, students[i].Salt = hash.Hash, hash.Salt
+			}
+		}()
+	}
+	for i := range students {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
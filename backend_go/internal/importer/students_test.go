@@ -0,0 +1,153 @@
+package importer
+
+import (
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/VladislavSCV/internal/repository/memory"
+	"github.com/VladislavSCV/internal/tabular"
+	"reflect"
+	"testing"
+)
+
+const studentsCSV = "ФИО;Группа;Логин\n" +
+	"Иванов Иван Иванович;ИС-21;iivanov\n" +
+	";;\n" +
+	"Петрова Анна;ПИ-23;apetrova\n"
+
+// countUsers возвращает число пользователей в хранилище
+func countUsers(t *testing.T, users repository.UserRepository) int {
+	t.Helper()
+	_, info, err := users.List(policy.System(), models.UserFilter{}, models.ListQuery{Sort: "id"})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	return info.Total
+}
+
+func TestStudents(t *testing.T) {
+	store := memory.New()
+	if err := store.SeedDemo(); err != nil {
+		t.Fatalf("SeedDemo: %v", err)
+	}
+	users := store.Repositories().Users
+	before := countUsers(t, users)
+
+	// Пробный импорт показывает студентов и новые группы, но ничего не сохраняет
+	result, err := Students(users, []byte(studentsCSV), tabular.FormatCSV, true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if result.Committed || len(result.Errors) != 0 || len(result.Students) != 2 {
+		t.Fatalf("dry run: %+v", result)
+	}
+	if want := []models.ImportedGroup{{Name: "ПИ-23"}}; !reflect.DeepEqual(result.CreatedGroups, want) {
+		t.Errorf("dry run: created groups %+v, want %+v", result.CreatedGroups, want)
+	}
+	if result.Students[0].Password != "" {
+		t.Errorf("dry run returned a password")
+	}
+	if countUsers(t, users) != before {
+		t.Fatalf("dry run created users")
+	}
+
+	result, err = Students(users, []byte(studentsCSV), tabular.FormatCSV, false)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if !result.Committed || len(result.Students) != 2 || len(result.CreatedGroups) != 1 || result.CreatedGroups[0].ID == 0 {
+		t.Fatalf("import: %+v", result)
+	}
+	// Пустая строка 3 пропущена, но номера строк остаются номерами строк файла
+	want := []struct {
+		line          int
+		login, name   string
+		group         string
+		first, middle string
+	}{
+		{2, "iivanov", "Иванов Иван Иванович", "ИС-21", "Иван", "Иванович"},
+		{4, "apetrova", "Петрова Анна", "ПИ-23", "Анна", ""},
+	}
+	for i, w := range want {
+		student := result.Students[i]
+		if student.Line != w.line || student.Login != w.login || student.Name != w.name || student.Group != w.group || student.GroupID == 0 {
+			t.Errorf("student %d: %+v", i, student)
+		}
+		// С паролем из ответа можно войти
+		authenticated, err := users.Authenticate(w.login, student.Password)
+		if err != nil {
+			t.Fatalf("Authenticate %s: %v", w.login, err)
+		}
+		user, err := users.GetCurrent(authenticated.ID)
+		if err != nil {
+			t.Fatalf("GetCurrent %s: %v", w.login, err)
+		}
+		if user.FirstName != w.first || user.MiddleName != w.middle || user.GroupID == nil || *user.GroupID != student.GroupID {
+			t.Errorf("user %s: %+v", w.login, user)
+		}
+	}
+
+	// Повторный импорт упирается в занятые логины и ничего не создаёт
+	result, err = Students(users, []byte(studentsCSV), tabular.FormatCSV, false)
+	if err != nil {
+		t.Fatalf("repeated import: %v", err)
+	}
+	if result.Committed || len(result.Errors) != 2 || result.Errors[0].Error != "login already exists" {
+		t.Errorf("repeated import: %+v", result)
+	}
+}
+
+func TestStudentsRejectsRows(t *testing.T) {
+	data := "last_name,first_name,group,login\n" +
+		"Иванов,Иван,ИС-21,iivanov\n" +
+		"Петров,,ИС-21,ppetrov\n" +
+		"Сидоров,Олег,ИС-21,iivanov\n" +
+		"Козлов,Павел,ИС-21,p kozlov\n"
+
+	result, err := Students(memory.New().Repositories().Users, []byte(data), tabular.FormatCSV, true)
+	if err != nil {
+		t.Fatalf("Students: %v", err)
+	}
+	want := []models.ImportError{
+		{Line: 3, Column: "first_name", Error: "value is required"},
+		{Line: 4, Column: "login", Error: "duplicate login, first used on line 2"},
+		{Line: 5, Column: "login", Error: "login must not contain spaces"},
+	}
+	if !reflect.DeepEqual(result.Errors, want) || len(result.Students) != 0 {
+		t.Errorf("Students() = %+v, want errors %+v", result, want)
+	}
+}
+
+func TestStudentsRejectsFile(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []models.ImportError
+	}{
+		{"empty", "", []models.ImportError{{Error: "file is empty"}}},
+		{"no columns", "ФИО;Класс\nИванов Иван;1А\n", []models.ImportError{
+			{Line: 1, Column: "group", Error: "column is required"},
+			{Line: 1, Column: "login", Error: "column is required"},
+		}},
+		{"no name columns", "group,login\nИС-21,iivanov\n", []models.ImportError{
+			{Line: 1, Column: "name", Error: "name or first_name and last_name columns are required"},
+		}},
+		{"only header", "name,group,login\n", []models.ImportError{{Error: "file has no students"}}},
+		{"single word name", "name,group,login\nИванов,ИС-21,iivanov\n", []models.ImportError{
+			{Line: 2, Column: "name", Error: "name must contain last name and first name"},
+			{Line: 2, Column: "last_name", Error: "value is required"},
+			{Line: 2, Column: "first_name", Error: "value is required"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Students(memory.New().Repositories().Users, []byte(tt.data), tabular.FormatCSV, true)
+			if err != nil {
+				t.Fatalf("Students: %v", err)
+			}
+			if !reflect.DeepEqual(result.Errors, tt.want) {
+				t.Errorf("errors = %+v, want %+v", result.Errors, tt.want)
+			}
+		})
+	}
+}
@@ -1,13 +1,17 @@
 package models
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 type Attendance struct {
 	ID        int       `json:"id"`
 	StudentID int       `json:"student_id"`
 	SubjectID int       `json:"subject_id"`
 	Date      time.Time `json:"date"`
-	Status    string    `json:"status"` // present, absent, excused
+	Status    string    `json:"status"`              // present, absent, excused
+	LessonID  *int      `json:"lesson_id,omitempty"` // Занятие, на котором сделана отметка
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -19,6 +23,81 @@ type AttendanceDetail struct {
 	SubjectName string    `json:"subject_name"`
 	Date        time.Time `json:"date"` // Используем time.Time вместо string
 	Status      string    `json:"status"`
+	LessonID    *int      `json:"lesson_id,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
+
+// RollCall — отметки всей группы на одном занятии. Если указан LessonID, группа, предмет
+// и дата берутся из занятия (переданные значения должны с ним совпадать)
+type RollCall struct {
+	GroupID        int            `json:"group_id"`
+	SubjectID      int            `json:"subject_id"`
+	LessonID       *int           `json:"lesson_id,omitempty"`
+	Date           time.Time      `json:"date"`
+	Statuses       map[int]string `json:"statuses"`        // Статус по ID студента
+	DefaultPresent bool           `json:"default_present"` // Отметить неуказанных студентов группы как present
+}
+
+// RollCallRejection — отметка, которую не удалось сохранить
+type RollCallRejection struct {
+	StudentID int    `json:"student_id"`
+	Reason    string `json:"reason"`
+}
+
+// RollCallResult — итог переклички: ID студентов с созданными и обновлёнными отметками и отклонённые
+type RollCallResult struct {
+	Created  []int               `json:"created"`
+	Updated  []int               `json:"updated"`
+	Rejected []RollCallRejection `json:"rejected"`
+}
+
+// AttendanceStatsFilter сужает выборку для статистики посещаемости; нулевые поля не фильтруют
+type AttendanceStatsFilter struct {
+	GroupID   int
+	StudentID int
+	SubjectID int
+	From      time.Time
+	To        time.Time
+}
+
+// AttendanceCounts — число отметок по статусам и доли в процентах от общего числа
+type AttendanceCounts struct {
+	Present       int     `json:"present"`
+	Absent        int     `json:"absent"`
+	Excused       int     `json:"excused"`
+	Total         int     `json:"total"`
+	Rate          float64 `json:"rate"`           // Доля присутствий, %
+	UnexcusedRate float64 `json:"unexcused_rate"` // Доля пропусков без уважительной причины, %
+}
+
+// NewAttendanceCounts считает итог и доли, округлённые до сотых
+func NewAttendanceCounts(present, absent, excused int) AttendanceCounts {
+	counts := AttendanceCounts{Present: present, Absent: absent, Excused: excused, Total: present + absent + excused}
+	if counts.Total > 0 {
+		counts.Rate = math.Round(float64(present)*10000/float64(counts.Total)) / 100
+		counts.UnexcusedRate = math.Round(float64(absent)*10000/float64(counts.Total)) / 100
+	}
+	return counts
+}
+
+// AttendanceStat — посещаемость одного студента, предмета или группы
+type AttendanceStat struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	AttendanceCounts
+}
+
+// AttendanceMonth — посещаемость за календарный месяц
+type AttendanceMonth struct {
+	Month string `json:"month"` // ГГГГ-ММ
+	AttendanceCounts
+}
+
+// Разрезы статистики посещаемости и оценок
+const (
+	StatsByStudent = "student"
+	StatsBySubject = "subject"
+	StatsByGroup   = "group"   // по текущей группе студента
+	StatsByTeacher = "teacher" // только для оценок: по преподавателю занятия или расписания
+)
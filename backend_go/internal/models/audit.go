@@ -0,0 +1,42 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Действия в журнале аудита, кроме производных от маршрута (compute, lock, roll-call и т.п.)
+const (
+	AuditCreate      = "create"
+	AuditUpdate      = "update"
+	AuditDelete      = "delete"
+	AuditLogin       = "login"
+	AuditLoginFailed = "login_failed"
+)
+
+// AuditEntry — один изменяющий запрос к API или попытка входа
+type AuditEntry struct {
+	ID         int             `json:"id"`
+	ActorID    *int            `json:"actor_id"` // nil — запрос без аутентификации
+	ActorLogin string          `json:"actor_login,omitempty"`
+	IP         string          `json:"ip"`
+	Method     string          `json:"method"`
+	Route      string          `json:"route"` // Шаблон маршрута, например /api/user/:id
+	Action     string          `json:"action"`
+	Status     int             `json:"status"`
+	EntityType string          `json:"entity_type,omitempty"`
+	EntityID   *int            `json:"entity_id,omitempty"`
+	Changes    json.RawMessage `json:"changes,omitempty" swaggertype:"object"` // {"поле": {"old": ..., "new": ...}}
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditFilter сужает выборку журнала аудита; нулевые поля не фильтруют
+type AuditFilter struct {
+	ActorID    int
+	EntityType string
+	EntityID   int
+	Action     string
+	From       time.Time // Включительно
+	To         time.Time // Не включительно
+	Limit      int       // 0 — без ограничения
+}
@@ -1,15 +1,22 @@
 package models
 
-import "time"
+import (
+	"math"
+	"sort"
+	"time"
+)
 
 type Grade struct {
-	ID        int       `json:"id"`
-	StudentID int       `json:"student_id"`
-	SubjectID int       `json:"subject_id"`
-	Value     int       `json:"value"` // Оценка (2-5)
-	Date      time.Time `json:"date"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          int       `json:"id"`
+	StudentID   int       `json:"student_id"`
+	SubjectID   int       `json:"subject_id"`
+	Value       int       `json:"value"` // Оценка (2-5)
+	Date        time.Time `json:"date"`
+	LessonID    *int      `json:"lesson_id,omitempty"`     // Занятие, на котором поставлена оценка
+	GradeTypeID *int      `json:"grade_type_id,omitempty"` // Вид оценки; без вида оценка весит 1
+	Comment     string    `json:"comment,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 type GradeDetail struct {
@@ -19,6 +26,96 @@ type GradeDetail struct {
 	SubjectName string    `json:"subject_name"`
 	Value       int       `json:"value"`
 	Date        string    `json:"date"`
+	LessonID    *int      `json:"lesson_id,omitempty"`
+	GradeTypeID *int      `json:"grade_type_id,omitempty"`
+	GradeType   string    `json:"grade_type,omitempty"` // Название вида оценки
+	Weight      float64   `json:"weight"`               // Вес оценки в предмете
+	Comment     string    `json:"comment,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
+
+// Допустимые значения оценок
+const (
+	MinGradeValue = 2
+	MaxGradeValue = 5
+)
+
+// GradeStatsFilter сужает выборку для аналитики оценок; нулевые поля не фильтруют
+type GradeStatsFilter struct {
+	GroupID   int
+	StudentID int
+	SubjectID int
+	TeacherID int
+	From      time.Time
+	To        time.Time
+}
+
+// GradeSummary — сводка по набору оценок
+type GradeSummary struct {
+	Count        int         `json:"count"`
+	Average      float64     `json:"average"`      // Средний балл с учётом весов видов оценок, округлён до сотых
+	Median       float64     `json:"median"`       // Медиана значений; для чётного числа оценок — среднее двух средних
+	Distribution map[int]int `json:"distribution"` // Число оценок каждого значения от 2 до 5
+}
+
+// NewGradeSummary считает сводку по значениям оценок и их весам (weights[i] — вес values[i]).
+// Все хранилища считают её здесь, чтобы средние и медианы совпадали независимо от источника
+func NewGradeSummary(values []int, weights []float64) GradeSummary {
+	summary := GradeSummary{Count: len(values), Distribution: make(map[int]int)}
+	for value := MinGradeValue; value <= MaxGradeValue; value++ {
+		summary.Distribution[value] = 0
+	}
+	if len(values) == 0 {
+		return summary
+	}
+
+	var sum, totalWeight float64
+	for i, value := range values {
+		sum += float64(value) * weights[i]
+		totalWeight += weights[i]
+		summary.Distribution[value]++
+	}
+	summary.Average = math.Round(sum*100/totalWeight) / 100
+
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		summary.Median = float64(sorted[middle])
+	} else {
+		summary.Median = float64(sorted[middle-1]+sorted[middle]) / 2
+	}
+
+	return summary
+}
+
+// GradeStat — сводка по оценкам одного студента, предмета, группы или преподавателя
+type GradeStat struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	GradeSummary
+}
+
+// GradeRank — место студента в рейтинге группы по среднему баллу.
+// Студенты с одинаковым средним делят место, следующее место пропускается (1, 1, 3)
+type GradeRank struct {
+	Rank int `json:"rank"`
+	GradeStat
+}
+
+// RankStudents упорядочивает сводки студентов по среднему баллу и расставляет места
+func RankStudents(stats []GradeStat) []GradeRank {
+	sorted := append([]GradeStat(nil), stats...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Average > sorted[j].Average })
+
+	ranking := make([]GradeRank, 0, len(sorted))
+	for i, stat := range sorted {
+		rank := i + 1
+		if i > 0 && stat.Average == sorted[i-1].Average {
+			rank = ranking[i-1].Rank
+		}
+		ranking = append(ranking, GradeRank{Rank: rank, GradeStat: stat})
+	}
+	return ranking
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// GradeType — вид оценки (устный ответ, контрольная, экзамен...) с весом по умолчанию
+type GradeType struct {
+	ID            int       `json:"id"`
+	Code          string    `json:"code" example:"exam"`
+	Name          string    `json:"name" example:"Экзамен"`
+	DefaultWeight float64   `json:"default_weight" example:"3"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// GradeWeight — вес вида оценки в предмете: переопределённый для предмета или по умолчанию
+type GradeWeight struct {
+	GradeTypeID int     `json:"grade_type_id"`
+	Code        string  `json:"code"`
+	Name        string  `json:"name"`
+	Weight      float64 `json:"weight"`
+	Overridden  bool    `json:"overridden"` // true — вес задан для предмета
+}
+
+// DefaultGradeWeight — вес оценки без вида
+const DefaultGradeWeight = 1.0
@@ -18,3 +18,33 @@ type GroupDetail struct {
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 }
+
+// GroupMembership — период, когда студент состоял в группе. ValidFrom пуст у первой группы студента
+// («с самого начала»), ValidTo — день перевода в другую группу (не включается), пуст у текущей группы
+type GroupMembership struct {
+	ID        int        `json:"id"`
+	StudentID int        `json:"student_id"`
+	GroupID   int        `json:"group_id"`
+	GroupName string     `json:"group_name"`
+	ValidFrom *time.Time `json:"valid_from"`
+	ValidTo   *time.Time `json:"valid_to"`
+}
+
+// Covers сообщает, состоял ли студент в группе в день date
+func (m GroupMembership) Covers(date time.Time) bool {
+	day := date.Format("2006-01-02")
+	return (m.ValidFrom == nil || m.ValidFrom.Format("2006-01-02") <= day) &&
+		(m.ValidTo == nil || day < m.ValidTo.Format("2006-01-02"))
+}
+
+// Overlaps сообщает, состоял ли студент в группе хотя бы один день из [from, to]
+func (m GroupMembership) Overlaps(from, to time.Time) bool {
+	return (m.ValidFrom == nil || m.ValidFrom.Format("2006-01-02") <= to.Format("2006-01-02")) &&
+		(m.ValidTo == nil || from.Format("2006-01-02") < m.ValidTo.Format("2006-01-02"))
+}
+
+// Transfer — перевод студента в группу с дня Date; пустая дата — сегодня
+type Transfer struct {
+	StudentID int
+	Date      time.Time
+}
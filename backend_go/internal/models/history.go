@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Типы записей с историей изменений
+const (
+	RecordGrade      = "grade"
+	RecordAttendance = "attendance"
+)
+
+// Действия в истории изменений
+const (
+	ChangeCreate = "create"
+	ChangeUpdate = "update"
+	ChangeDelete = "delete"
+)
+
+// RecordChange — одно изменение оценки или отметки посещаемости. OldValue и NewValue — строка записи
+// до и после изменения с именами колонок в качестве ключей; при создании OldValue пуст, при удалении — NewValue.
+type RecordChange struct {
+	ID         int             `json:"id"`
+	RecordType string          `json:"record_type"` // grade, attendance
+	RecordID   int             `json:"record_id"`
+	Action     string          `json:"action"`   // create, update, delete
+	ActorID    *int            `json:"actor_id"` // nil — изменение без пользователя (CLI, внутренние задачи)
+	ActorName  string          `json:"actor_name,omitempty"`
+	OldValue   json.RawMessage `json:"old_value" swaggertype:"object"`
+	NewValue   json.RawMessage `json:"new_value" swaggertype:"object"`
+	Reason     string          `json:"reason,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import "strings"
+
+// StudentImport — студент из строки файла импорта. Password и Salt — хеш и соль
+// сгенерированного пароля; при пробном импорте пустые
+type StudentImport struct {
+	Line       int
+	FirstName  string
+	MiddleName string
+	LastName   string
+	GroupName  string
+	Login      string
+	Password   string
+	Salt       string
+}
+
+// FullName — ФИО студента через пробел
+func (student StudentImport) FullName() string {
+	return strings.TrimSpace(student.LastName + " " + student.FirstName + " " + student.MiddleName)
+}
+
+// ImportResult — итог импорта студентов. Студенты и группы создаются все или ни одного:
+// при ошибках в строках или пробном импорте (DryRun) ничего не сохраняется и ID новых записей пусты
+type ImportResult struct {
+	DryRun        bool              `json:"dry_run"`
+	Committed     bool              `json:"committed"`
+	Students      []ImportedStudent `json:"students"`
+	CreatedGroups []ImportedGroup   `json:"created_groups"`
+	Errors        []ImportError     `json:"errors"`
+}
+
+// ImportedStudent — студент, созданный импортом (или который был бы создан при пробном импорте).
+// Password — сгенерированный пароль; показывается один раз, в ответе на сохранённый импорт
+type ImportedStudent struct {
+	Line     int    `json:"line"`
+	UserID   int    `json:"user_id,omitempty"`
+	Login    string `json:"login"`
+	Name     string `json:"name"`
+	Group    string `json:"group"`
+	GroupID  int    `json:"group_id,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// ImportedGroup — группа, созданная импортом, потому что её ещё не было
+type ImportedGroup struct {
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+// ImportError — ошибка в строке файла (Line с 1, считая заголовок); Line 0 — ошибка файла целиком
+type ImportError struct {
+	Line   int    `json:"line"`
+	Column string `json:"column,omitempty"`
+	Error  string `json:"error"`
+}
+
+// NewImportResult возвращает результат с пустыми, а не nil списками
+func NewImportResult(dryRun bool) *ImportResult {
+	return &ImportResult{
+		DryRun:        dryRun,
+		Students:      []ImportedStudent{},
+		CreatedGroups: []ImportedGroup{},
+		Errors:        []ImportError{},
+	}
+}
+
+// ClearIDs убирает из несохранённого импорта ID студентов и созданных групп: в хранилище их нет.
+// ID существующих групп остаются
+func (result *ImportResult) ClearIDs() {
+	created := make(map[string]bool)
+	for i := range result.CreatedGroups {
+		created[result.CreatedGroups[i].Name] = true
+		result.CreatedGroups[i].ID = 0
+	}
+	for i := range result.Students {
+		result.Students[i].UserID = 0
+		if created[result.Students[i].Group] {
+			result.Students[i].GroupID = 0
+		}
+	}
+}
+
+// AddError добавляет ошибку строки
+func (result *ImportResult) AddError(line int, column, message string) {
+	result.Errors = append(result.Errors, ImportError{Line: line, Column: column, Error: message})
+}
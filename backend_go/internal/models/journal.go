@@ -0,0 +1,130 @@
+package models
+
+import "time"
+
+// JournalFilter — журнал группы по предмету за период [From, To]
+type JournalFilter struct {
+	GroupID   int
+	SubjectID int
+	From      time.Time
+	To        time.Time
+}
+
+// Journal — классический журнал: студенты по строкам, дни по столбцам
+type Journal struct {
+	GroupID   int          `json:"group_id"`
+	SubjectID int          `json:"subject_id"`
+	From      string       `json:"from"`
+	To        string       `json:"to"`
+	Dates     []string     `json:"dates"` // Дни с занятиями, оценками или отметками (ГГГГ-ММ-ДД) по возрастанию
+	Students  []JournalRow `json:"students"`
+}
+
+// JournalRow — строка журнала: ячейки по столбцам Dates и итоги студента за период
+type JournalRow struct {
+	StudentID   int           `json:"student_id"`
+	StudentName string        `json:"student_name"`
+	Cells       []JournalCell `json:"cells"`   // Cells[i] — день Dates[i]
+	Average     *float64      `json:"average"` // Средний балл с учётом весов; nil — оценок нет
+	Absences    int           `json:"absences"`
+	Excused     int           `json:"excused"`
+}
+
+// JournalCell — оценки и отметки студента за день
+type JournalCell struct {
+	Grades     []JournalGrade      `json:"grades"`
+	Attendance []JournalAttendance `json:"attendance"`
+}
+
+// JournalGrade — оценка в ячейке журнала
+type JournalGrade struct {
+	ID          int    `json:"id"`
+	Value       int    `json:"value"`
+	GradeTypeID *int   `json:"grade_type_id,omitempty"`
+	GradeType   string `json:"grade_type,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+// JournalAttendance — отметка посещаемости в ячейке журнала
+type JournalAttendance struct {
+	ID       int    `json:"id"`
+	Status   string `json:"status"`
+	LessonID *int   `json:"lesson_id,omitempty"`
+}
+
+// Fill раскладывает оценки и отметки (по студенту и дню) по ячейкам строк журнала и считает итоги студентов.
+// Все хранилища собирают журнал здесь, чтобы он выглядел одинаково независимо от источника
+func (journal *Journal) Fill(grades map[int]map[string][]JournalGrade,
+	attendance map[int]map[string][]JournalAttendance, averages map[int]float64) {
+	for i := range journal.Students {
+		row := &journal.Students[i]
+		row.Cells = make([]JournalCell, len(journal.Dates))
+		for j, day := range journal.Dates {
+			cell := JournalCell{Grades: grades[row.StudentID][day], Attendance: attendance[row.StudentID][day]}
+			if cell.Grades == nil {
+				cell.Grades = []JournalGrade{}
+			}
+			if cell.Attendance == nil {
+				cell.Attendance = []JournalAttendance{}
+			}
+			for _, mark := range cell.Attendance {
+				switch mark.Status {
+				case "absent":
+					row.Absences++
+				case "excused":
+					row.Excused++
+				}
+			}
+			row.Cells[j] = cell
+		}
+		if average, ok := averages[row.StudentID]; ok {
+			row.Average = &average
+		}
+	}
+}
+
+// JournalSave — правки многих ячеек журнала группы по предмету; применяются все или ни одной
+type JournalSave struct {
+	GroupID    int
+	SubjectID  int
+	Grades     []JournalGradeEdit
+	Attendance []JournalAttendanceEdit
+}
+
+// JournalGradeEdit — новая оценка (без ID), изменение значения, вида и комментария оценки ID или её удаление
+type JournalGradeEdit struct {
+	ID          *int
+	StudentID   int
+	Date        time.Time
+	Value       int
+	GradeTypeID *int
+	Comment     string
+	Delete      bool
+}
+
+// JournalAttendanceEdit — статус студента за день: меняет его отметку по предмету или создаёт новую
+type JournalAttendanceEdit struct {
+	StudentID int
+	Date      time.Time
+	Status    string
+}
+
+// JournalSaveResult — ID оценок и отметок, затронутых сохранением журнала
+type JournalSaveResult struct {
+	CreatedGrades     []int `json:"created_grades"`
+	UpdatedGrades     []int `json:"updated_grades"`
+	DeletedGrades     []int `json:"deleted_grades"`
+	CreatedAttendance []int `json:"created_attendance"`
+	UpdatedAttendance []int `json:"updated_attendance"`
+}
+
+// NewJournalSaveResult возвращает результат с пустыми, а не nil списками
+func NewJournalSaveResult() *JournalSaveResult {
+	return &JournalSaveResult{
+		CreatedGrades:     []int{},
+		UpdatedGrades:     []int{},
+		DeletedGrades:     []int{},
+		CreatedAttendance: []int{},
+		UpdatedAttendance: []int{},
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Lesson — занятие в конкретный день, созданное из строки недельного расписания
+type Lesson struct {
+	ID          int       `json:"id"`
+	ScheduleID  *int      `json:"schedule_id"` // nil, если строку расписания уже удалили
+	GroupID     int       `json:"group_id"`
+	GroupName   string    `json:"group_name"`
+	SubjectID   int       `json:"subject_id"`
+	SubjectName string    `json:"subject_name"`
+	TeacherID   int       `json:"teacher_id"`
+	TeacherName string    `json:"teacher_name"`
+	Date        time.Time `json:"date"`
+	Period      int       `json:"period"` // Номер пары у группы в этот день, по времени начала
+	StartTime   string    `json:"start_time"`
+	EndTime     string    `json:"end_time"`
+	Location    string    `json:"location"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// LessonFilter сужает выборку занятий; нулевые поля не фильтруют
+type LessonFilter struct {
+	GroupID   int
+	TeacherID int
+	Period    int // Номер пары у группы в день
+	From      time.Time
+	To        time.Time
+}
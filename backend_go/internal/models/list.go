@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// Поля, по которым можно сортировать списки; первое — сортировка по умолчанию
+var (
+	UserSortFields       = []string{"id", "last_name", "first_name", "login", "role", "group", "created_at"}
+	GroupSortFields      = []string{"name", "id", "student_count", "created_at"}
+	ScheduleSortFields   = []string{"day_of_week", "id", "start_time", "group_name", "subject_name", "teacher_name", "location"}
+	GradeSortFields      = []string{"date", "id", "value", "subject_name", "student_name", "created_at"}
+	AttendanceSortFields = []string{"date", "id", "status", "subject_name", "student_name", "created_at"}
+)
+
+// ListQuery — страница списка. Продолжить можно смещением Offset или курсором After;
+// курсор не сбивается, если между запросами добавились или удалились записи
+type ListQuery struct {
+	Sort   string // Поле из *SortFields
+	Desc   bool
+	Limit  int // 0 — без ограничения
+	Offset int
+	After  *Cursor // Начать после этой записи
+}
+
+// Cursor — позиция в списке: значение поля сортировки и ID последней выданной записи.
+// Значение хранится текстом в том виде, в каком его вернуло хранилище
+type Cursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d,omitempty"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+// PageInfo — сведения о выданной странице
+type PageInfo struct {
+	Total int     // Записей под фильтром без учёта страницы
+	Next  *Cursor // nil — страница последняя
+}
+
+// UserFilter сужает список пользователей; нулевые поля не фильтруют
+type UserFilter struct {
+	Role    string
+	GroupID int
+}
+
+// ScheduleFilter сужает список занятий расписания; нулевые поля не фильтруют
+type ScheduleFilter struct {
+	GroupID   int
+	SubjectID int
+	TeacherID int
+	DayOfWeek int
+}
+
+// RecordFilter сужает список или выгрузку оценок и посещаемости; нулевые поля не фильтруют
+type RecordFilter struct {
+	StudentID int
+	GroupID   int
+	SubjectID int
+	From      time.Time // Включительно
+	To        time.Time // Не включительно
+	Status    string    // Только для посещаемости
+}
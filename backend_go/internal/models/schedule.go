@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type Schedule struct {
 	ID          int       `json:"id"`
@@ -17,3 +20,25 @@ type Schedule struct {
 	SubjectID   int       `json:"subject_id"`
 	TeacherID   int       `json:"teacher_id"`
 }
+
+// ScheduleConflict — существующее занятие, пересекающееся по времени с проверяемым
+type ScheduleConflict struct {
+	Schedule
+	Reasons []string `json:"reasons"` // teacher, group и/или room
+}
+
+// ConflictReasons перечисляет, чем пересекающееся по времени занятие other мешает этому:
+// тот же преподаватель, та же группа или та же аудитория (без учёта регистра и пробелов)
+func (s Schedule) ConflictReasons(other Schedule) []string {
+	var reasons []string
+	if other.TeacherID == s.TeacherID {
+		reasons = append(reasons, "teacher")
+	}
+	if other.GroupID == s.GroupID {
+		reasons = append(reasons, "group")
+	}
+	if location := strings.TrimSpace(s.Location); location != "" && strings.EqualFold(strings.TrimSpace(other.Location), location) {
+		reasons = append(reasons, "room")
+	}
+	return reasons
+}
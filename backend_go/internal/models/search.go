@@ -0,0 +1,35 @@
+package models
+
+// Типы записей, среди которых идёт поиск
+const (
+	SearchUsers    = "users"
+	SearchGroups   = "groups"
+	SearchSubjects = "subjects"
+)
+
+// SearchTypes — все типы записей поиска
+var SearchTypes = []string{SearchUsers, SearchGroups, SearchSubjects}
+
+// SearchQuery — поисковый запрос
+type SearchQuery struct {
+	Variants []string // Текст запроса, его транслитерации и набор в другой раскладке (search.Variants)
+	Types    []string // Где искать, из SearchTypes
+	Limit    int      // Наибольшее число результатов каждого типа
+}
+
+// SearchHit — найденная запись. Score — похожесть от 0 до 1: 1 — запрос целиком входит в название
+type SearchHit struct {
+	ID    int     `json:"id"`
+	Name  string  `json:"name"` // ФИО пользователя, название группы или предмета
+	Login string  `json:"login,omitempty"`
+	Role  string  `json:"role,omitempty"`
+	Group string  `json:"group,omitempty"`
+	Score float64 `json:"score"`
+}
+
+// SearchResult — найденные записи по типам, в каждом — от лучших совпадений к худшим
+type SearchResult struct {
+	Users    []SearchHit `json:"users"`
+	Groups   []SearchHit `json:"groups"`
+	Subjects []SearchHit `json:"subjects"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+type Subject struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
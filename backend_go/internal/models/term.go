@@ -0,0 +1,71 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// AcademicYear — учебный год, например «2024/2025»
+type AcademicYear struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name" example:"2024/2025"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Term — учебный период (семестр, четверть) внутри учебного года; периоды одного года не пересекаются
+type Term struct {
+	ID               int       `json:"id"`
+	AcademicYearID   int       `json:"academic_year_id"`
+	AcademicYearName string    `json:"academic_year_name"`
+	Name             string    `json:"name" example:"1 семестр"`
+	StartDate        time.Time `json:"start_date"`
+	EndDate          time.Time `json:"end_date"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// Covers сообщает, попадает ли день date в период (границы включительно)
+func (t Term) Covers(date time.Time) bool {
+	day := date.Format("2006-01-02")
+	return day >= t.StartDate.Format("2006-01-02") && day <= t.EndDate.Format("2006-01-02")
+}
+
+// FinalGrade — итоговая оценка студента по предмету за период.
+// Suggested — средневзвешенный балл за период на момент расчёта, Value — выставленная оценка.
+// Закрытая (Locked) оценка фиксирует и себя, и оценки студента по предмету за период.
+type FinalGrade struct {
+	ID          int        `json:"id"`
+	TermID      int        `json:"term_id"`
+	StudentID   int        `json:"student_id"`
+	StudentName string     `json:"student_name"`
+	SubjectID   int        `json:"subject_id"`
+	SubjectName string     `json:"subject_name"`
+	Suggested   *float64   `json:"suggested"`
+	Value       int        `json:"value"`
+	Locked      bool       `json:"locked"`
+	LockedAt    *time.Time `json:"locked_at,omitempty"`
+	LockedBy    *int       `json:"locked_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// FinalGradeFilter сужает выборку итоговых оценок периода; нулевые поля не фильтруют
+type FinalGradeFilter struct {
+	GroupID   int
+	SubjectID int
+}
+
+// SuggestFinalGrade округляет средний балл до оценки от MinGradeValue до MaxGradeValue (3.5 → 4)
+func SuggestFinalGrade(average float64) int {
+	value := int(math.Round(average))
+	if value < MinGradeValue {
+		return MinGradeValue
+	}
+	if value > MaxGradeValue {
+		return MaxGradeValue
+	}
+	return value
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"database/sql"
+	"strings"
 	"time"
 )
 
@@ -20,3 +21,8 @@ type User struct {
 	Role       string         `json:"role,omitempty"`
 	Group      sql.NullString `json:"group,omitempty"`
 }
+
+// FullName — ФИО пользователя через пробел
+func (user User) FullName() string {
+	return strings.TrimSpace(user.LastName + " " + user.FirstName + " " + user.MiddleName)
+}
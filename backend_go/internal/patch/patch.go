@@ -0,0 +1,377 @@
+// Package patch реализует частичное обновление сущностей по белому списку полей.
+//
+// Поддерживаются два формата тела запроса:
+//   - JSON Merge Patch (RFC 7396), Content-Type application/merge-patch+json или application/json;
+//   - JSON Patch (RFC 6902), Content-Type application/json-patch+json.
+//
+// Результат — набор изменений «колонка → значение», где колонки берутся только из схемы,
+// а значения уже приведены к нужному типу и проверены.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	ContentTypeMergePatch = "application/merge-patch+json"
+	ContentTypeJSONPatch  = "application/json-patch+json"
+)
+
+// ErrTestFailed — операция test из JSON Patch не совпала с текущим значением
+var ErrTestFailed = errors.New("json patch test operation failed")
+
+// ErrUnsupportedMediaType — тело патча пришло в неизвестном формате
+var ErrUnsupportedMediaType = errors.New("unsupported patch media type")
+
+type Kind int
+
+const (
+	String Kind = iota
+	Int
+	NullableInt
+)
+
+// Field описывает одно изменяемое поле; имя поля в JSON совпадает с колонкой в базе
+type Field struct {
+	Kind Kind
+	// Required — поле нельзя очистить: null и remove для него — ошибка. Остальные строки
+	// очищаются до пустой строки, NullableInt — до null
+	Required  bool
+	Validate  func(value interface{}) error       // nil — без дополнительных проверок
+	Normalize func(value interface{}) interface{} // приведение к виду, в котором значение хранится в базе
+}
+
+// Schema — белый список полей сущности
+type Schema struct {
+	Fields map[string]Field
+	// Forbidden — известные поля, которые нельзя менять через патч (id, пароль, роль...)
+	Forbidden map[string]bool
+}
+
+// Columns возвращает отсортированный список изменяемых колонок
+func (s Schema) Columns() []string {
+	columns := make([]string, 0, len(s.Fields))
+	for name := range s.Fields {
+		columns = append(columns, name)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// Allows сообщает, можно ли менять колонку через патч
+func (s Schema) Allows(column string) bool {
+	_, ok := s.Fields[column]
+	return ok
+}
+
+// ValidationError перечисляет все проблемы патча сразу; отдаётся клиенту как 422
+type ValidationError struct {
+	Unknown   []string          `json:"unknown_fields,omitempty"`
+	Forbidden []string          `json:"forbidden_fields,omitempty"`
+	Invalid   map[string]string `json:"invalid_fields,omitempty"`
+	// Malformed — ошибка структуры самого патча (неверный op, path и т.п.)
+	Malformed []string `json:"malformed,omitempty"`
+}
+
+func (e *ValidationError) Error() string {
+	var parts []string
+	if len(e.Unknown) > 0 {
+		parts = append(parts, "unknown fields: "+strings.Join(e.Unknown, ", "))
+	}
+	if len(e.Forbidden) > 0 {
+		parts = append(parts, "forbidden fields: "+strings.Join(e.Forbidden, ", "))
+	}
+	if len(e.Invalid) > 0 {
+		names := make([]string, 0, len(e.Invalid))
+		for name := range e.Invalid {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			parts = append(parts, fmt.Sprintf("%s: %s", name, e.Invalid[name]))
+		}
+	}
+	parts = append(parts, e.Malformed...)
+	return "invalid patch: " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) empty() bool {
+	return len(e.Unknown) == 0 && len(e.Forbidden) == 0 && len(e.Invalid) == 0 && len(e.Malformed) == 0
+}
+
+func (e *ValidationError) invalid(field, reason string) {
+	if e.Invalid == nil {
+		e.Invalid = make(map[string]string)
+	}
+	if _, exists := e.Invalid[field]; !exists {
+		e.Invalid[field] = reason
+	}
+}
+
+// checkField раскладывает поле по спискам ошибок; true — поле можно менять
+func (s Schema) checkField(name string, verr *ValidationError) bool {
+	if _, ok := s.Fields[name]; ok {
+		return true
+	}
+	if s.Forbidden[name] {
+		if !contains(verr.Forbidden, name) {
+			verr.Forbidden = append(verr.Forbidden, name)
+		}
+	} else if !contains(verr.Unknown, name) {
+		verr.Unknown = append(verr.Unknown, name)
+	}
+	return false
+}
+
+// Apply применяет патч к текущему состоянию сущности и возвращает изменившиеся поля.
+// current должен содержать все поля схемы в нормализованном виде (string, int, nil).
+func (s Schema) Apply(contentType string, body []byte, current map[string]interface{}) (map[string]interface{}, error) {
+	switch contentType {
+	case ContentTypeJSONPatch:
+		return s.applyJSONPatch(body, current)
+	case ContentTypeMergePatch, "application/json", "":
+		return s.applyMergePatch(body, current)
+	default:
+		return nil, ErrUnsupportedMediaType
+	}
+}
+
+// applyMergePatch — RFC 7396. Документ плоский, поэтому null означает «очистить поле».
+func (s Schema) applyMergePatch(body []byte, current map[string]interface{}) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	if err := decode(body, &doc); err != nil || doc == nil {
+		return nil, &ValidationError{Malformed: []string{"merge patch must be a JSON object"}}
+	}
+
+	verr := &ValidationError{}
+	changes := make(map[string]interface{})
+
+	for _, name := range sortedNames(doc) {
+		if !s.checkField(name, verr) {
+			continue
+		}
+		value, err := s.convert(name, doc[name])
+		if err != nil {
+			verr.invalid(name, err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(current[name], value) {
+			changes[name] = value
+		}
+	}
+
+	sort.Strings(verr.Unknown)
+	sort.Strings(verr.Forbidden)
+	if !verr.empty() {
+		return nil, verr
+	}
+
+	return changes, nil
+}
+
+type operation struct {
+	Op    string           `json:"op"`
+	Path  string           `json:"path"`
+	From  string           `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// applyJSONPatch — RFC 6902 над плоским документом из полей схемы.
+// Операции применяются по порядку к копии документа; при любой ошибке ничего не меняется.
+func (s Schema) applyJSONPatch(body []byte, current map[string]interface{}) (map[string]interface{}, error) {
+	var ops []operation
+	if err := decode(body, &ops); err != nil || ops == nil {
+		return nil, &ValidationError{Malformed: []string{"json patch must be a JSON array of operations"}}
+	}
+
+	verr := &ValidationError{}
+	doc := make(map[string]interface{}, len(current))
+	for k, v := range current {
+		doc[k] = v
+	}
+
+	for i, op := range ops {
+		name, ok := pointerField(op.Path)
+		if !ok {
+			verr.Malformed = append(verr.Malformed, fmt.Sprintf("operation %d: unsupported path %q", i, op.Path))
+			continue
+		}
+		if !s.checkField(name, verr) {
+			continue
+		}
+
+		var value interface{}
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				verr.Malformed = append(verr.Malformed, fmt.Sprintf("operation %d: %s requires a value", i, op.Op))
+				continue
+			}
+			var raw interface{}
+			if err := decode(*op.Value, &raw); err != nil {
+				verr.Malformed = append(verr.Malformed, fmt.Sprintf("operation %d: invalid value", i))
+				continue
+			}
+			converted, err := s.convert(name, raw)
+			if err != nil {
+				verr.invalid(name, err.Error())
+				continue
+			}
+			value = converted
+		case "remove":
+			// Удалить поле плоской записи — значит очистить его
+			converted, err := s.convert(name, nil)
+			if err != nil {
+				verr.invalid(name, err.Error())
+				continue
+			}
+			value = converted
+		case "move", "copy":
+			from, ok := pointerField(op.From)
+			if !ok {
+				verr.Malformed = append(verr.Malformed, fmt.Sprintf("operation %d: unsupported from %q", i, op.From))
+				continue
+			}
+			if !s.checkField(from, verr) {
+				continue
+			}
+			converted, err := s.convert(name, doc[from])
+			if err != nil {
+				verr.invalid(name, err.Error())
+				continue
+			}
+			value = converted
+			if op.Op == "move" && from != name {
+				cleared, err := s.convert(from, nil)
+				if err != nil {
+					verr.invalid(from, err.Error())
+					continue
+				}
+				doc[from] = cleared
+			}
+		default:
+			verr.Malformed = append(verr.Malformed, fmt.Sprintf("operation %d: unsupported op %q", i, op.Op))
+			continue
+		}
+
+		if op.Op == "test" {
+			if verr.empty() && !reflect.DeepEqual(doc[name], value) {
+				return nil, fmt.Errorf("%w: %s", ErrTestFailed, op.Path)
+			}
+			continue
+		}
+		doc[name] = value
+	}
+
+	sort.Strings(verr.Unknown)
+	sort.Strings(verr.Forbidden)
+	if !verr.empty() {
+		return nil, verr
+	}
+
+	changes := make(map[string]interface{})
+	for name, value := range doc {
+		if !reflect.DeepEqual(current[name], value) {
+			changes[name] = value
+		}
+	}
+
+	return changes, nil
+}
+
+// convert приводит JSON-значение или значение документа к типу поля и запускает его проверку
+func (s Schema) convert(name string, raw interface{}) (interface{}, error) {
+	field := s.Fields[name]
+
+	var value interface{}
+	switch field.Kind {
+	case String:
+		if raw == nil {
+			if field.Required {
+				return nil, errors.New("is required and cannot be cleared")
+			}
+			return "", nil
+		}
+		str, ok := raw.(string)
+		if !ok {
+			return nil, errors.New("must be a string")
+		}
+		value = str
+	case Int, NullableInt:
+		if raw == nil {
+			if field.Kind == Int {
+				return nil, errors.New("must not be null")
+			}
+			return nil, nil
+		}
+		// json.Number — из тела запроса; int — уже приведённое значение документа (move и copy)
+		switch v := raw.(type) {
+		case json.Number:
+			n, err := v.Int64()
+			if err != nil {
+				return nil, errors.New("must be an integer")
+			}
+			value = int(n)
+		case int:
+			value = v
+		default:
+			return nil, errors.New("must be an integer")
+		}
+	}
+
+	if field.Validate != nil {
+		if err := field.Validate(value); err != nil {
+			return nil, err
+		}
+	}
+	if field.Normalize != nil {
+		value = field.Normalize(value)
+	}
+
+	return value, nil
+}
+
+// pointerField разбирает JSON Pointer вида /field (только верхний уровень)
+func pointerField(pointer string) (string, bool) {
+	if !strings.HasPrefix(pointer, "/") || strings.Count(pointer, "/") != 1 || len(pointer) < 2 {
+		return "", false
+	}
+	name := strings.ReplaceAll(strings.ReplaceAll(pointer[1:], "~1", "/"), "~0", "~")
+	return name, true
+}
+
+func decode(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return errors.New("unexpected data after JSON value")
+	}
+	return nil
+}
+
+func sortedNames(m map[string]interface{}) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
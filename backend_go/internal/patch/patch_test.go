@@ -0,0 +1,185 @@
+package patch
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func scheduleDocument() map[string]interface{} {
+	return map[string]interface{}{
+		"group_id":    1,
+		"subject_id":  2,
+		"teacher_id":  3,
+		"day_of_week": 1,
+		"start_time":  "09:00:00",
+		"end_time":    "10:30:00",
+		"location":    "101",
+	}
+}
+
+func userDocument() map[string]interface{} {
+	return map[string]interface{}{
+		"first_name":  "Мария",
+		"middle_name": "Ивановна",
+		"last_name":   "Иванова",
+		"login":       "student1",
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name        string
+		schema      Schema
+		current     map[string]interface{}
+		contentType string
+		body        string
+		want        map[string]interface{}
+		wantInvalid []string // поля из ValidationError.Invalid
+	}{
+		{
+			name: "merge patch", schema: ScheduleSchema, current: scheduleDocument(), contentType: ContentTypeMergePatch,
+			body: `{"day_of_week": 3, "start_time": "11:00", "location": "101"}`,
+			want: map[string]interface{}{"day_of_week": 3, "start_time": "11:00:00"},
+		},
+		{
+			name: "merge patch clears optional string", schema: UserSchema, current: userDocument(), contentType: "application/json",
+			body: `{"middle_name": null}`,
+			want: map[string]interface{}{"middle_name": ""},
+		},
+		{
+			name: "merge patch rejects null in required field", schema: UserSchema, current: userDocument(), contentType: ContentTypeMergePatch,
+			body: `{"first_name": null}`, wantInvalid: []string{"first_name"},
+		},
+		{
+			name: "add", schema: ScheduleSchema, current: scheduleDocument(), contentType: ContentTypeJSONPatch,
+			body: `[{"op": "add", "path": "/location", "value": "205"}]`,
+			want: map[string]interface{}{"location": "205"},
+		},
+		{
+			name: "replace", schema: ScheduleSchema, current: scheduleDocument(), contentType: ContentTypeJSONPatch,
+			body: `[{"op": "replace", "path": "/teacher_id", "value": 7}]`,
+			want: map[string]interface{}{"teacher_id": 7},
+		},
+		{
+			name: "replace with invalid value", schema: ScheduleSchema, current: scheduleDocument(), contentType: ContentTypeJSONPatch,
+			body: `[{"op": "replace", "path": "/day_of_week", "value": 9}]`, wantInvalid: []string{"day_of_week"},
+		},
+		{
+			name: "remove", schema: UserSchema, current: userDocument(), contentType: ContentTypeJSONPatch,
+			body: `[{"op": "remove", "path": "/middle_name"}]`,
+			want: map[string]interface{}{"middle_name": ""},
+		},
+		{
+			name: "remove required field", schema: UserSchema, current: userDocument(), contentType: ContentTypeJSONPatch,
+			body: `[{"op": "remove", "path": "/login"}]`, wantInvalid: []string{"login"},
+		},
+		{
+			name: "test then replace", schema: ScheduleSchema, current: scheduleDocument(), contentType: ContentTypeJSONPatch,
+			body: `[{"op": "test", "path": "/group_id", "value": 1}, {"op": "replace", "path": "/group_id", "value": 4}]`,
+			want: map[string]interface{}{"group_id": 4},
+		},
+		{
+			name: "copy integer field", schema: ScheduleSchema, current: scheduleDocument(), contentType: ContentTypeJSONPatch,
+			body: `[{"op": "copy", "from": "/subject_id", "path": "/teacher_id"}]`,
+			want: map[string]interface{}{"teacher_id": 2},
+		},
+		{
+			name: "copy replaced integer field", schema: ScheduleSchema, current: scheduleDocument(), contentType: ContentTypeJSONPatch,
+			body: `[{"op": "replace", "path": "/group_id", "value": 5}, {"op": "copy", "from": "/group_id", "path": "/subject_id"}]`,
+			want: map[string]interface{}{"group_id": 5, "subject_id": 5},
+		},
+		{
+			name: "copy string field", schema: ScheduleSchema, current: scheduleDocument(), contentType: ContentTypeJSONPatch,
+			body: `[{"op": "copy", "from": "/start_time", "path": "/end_time"}]`,
+			want: map[string]interface{}{"end_time": "09:00:00"},
+		},
+		{
+			name: "move string field", schema: UserSchema, current: userDocument(), contentType: ContentTypeJSONPatch,
+			body: `[{"op": "move", "from": "/middle_name", "path": "/first_name"}]`,
+			want: map[string]interface{}{"first_name": "Ивановна", "middle_name": ""},
+		},
+		{
+			name: "move leaves non-nullable integer field empty", schema: ScheduleSchema, current: scheduleDocument(), contentType: ContentTypeJSONPatch,
+			body: `[{"op": "move", "from": "/teacher_id", "path": "/subject_id"}]`, wantInvalid: []string{"teacher_id"},
+		},
+		{
+			name: "move required string field", schema: UserSchema, current: userDocument(), contentType: ContentTypeJSONPatch,
+			body: `[{"op": "move", "from": "/login", "path": "/middle_name"}]`, wantInvalid: []string{"login"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.schema.Apply(tt.contentType, []byte(tt.body), tt.current)
+			if tt.wantInvalid != nil {
+				var verr *ValidationError
+				if !errors.As(err, &verr) {
+					t.Fatalf("Apply() error = %v, want ValidationError", err)
+				}
+				for _, name := range tt.wantInvalid {
+					if _, ok := verr.Invalid[name]; !ok {
+						t.Errorf("Invalid = %v, want %s", verr.Invalid, name)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want func(t *testing.T, err error)
+	}{
+		{"failed test", `[{"op": "test", "path": "/location", "value": "999"}, {"op": "replace", "path": "/location", "value": "205"}]`, func(t *testing.T, err error) {
+			if !errors.Is(err, ErrTestFailed) {
+				t.Errorf("error = %v, want ErrTestFailed", err)
+			}
+		}},
+		{"forbidden field", `[{"op": "replace", "path": "/id", "value": 2}]`, func(t *testing.T, err error) {
+			var verr *ValidationError
+			if !errors.As(err, &verr) || !reflect.DeepEqual(verr.Forbidden, []string{"id"}) {
+				t.Errorf("error = %v, want forbidden id", err)
+			}
+		}},
+		{"unknown field", `[{"op": "add", "path": "/room", "value": "1"}]`, func(t *testing.T, err error) {
+			var verr *ValidationError
+			if !errors.As(err, &verr) || !reflect.DeepEqual(verr.Unknown, []string{"room"}) {
+				t.Errorf("error = %v, want unknown room", err)
+			}
+		}},
+		{"unsupported op", `[{"op": "increment", "path": "/day_of_week", "value": 1}]`, func(t *testing.T, err error) {
+			var verr *ValidationError
+			if !errors.As(err, &verr) || len(verr.Malformed) != 1 {
+				t.Errorf("error = %v, want malformed operation", err)
+			}
+		}},
+		{"not an array", `{"op": "remove", "path": "/location"}`, func(t *testing.T, err error) {
+			var verr *ValidationError
+			if !errors.As(err, &verr) || len(verr.Malformed) != 1 {
+				t.Errorf("error = %v, want malformed patch", err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ScheduleSchema.Apply(ContentTypeJSONPatch, []byte(tt.body), scheduleDocument())
+			if err == nil {
+				t.Fatalf("Apply() = %v, want error", got)
+			}
+			tt.want(t, err)
+		})
+	}
+
+	if _, err := ScheduleSchema.Apply("text/plain", []byte(`{}`), scheduleDocument()); !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("text/plain: error = %v, want ErrUnsupportedMediaType", err)
+	}
+}
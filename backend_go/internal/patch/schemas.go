@@ -0,0 +1,130 @@
+package patch
+
+import (
+	"errors"
+	"github.com/VladislavSCV/internal/models"
+	"strings"
+	"time"
+)
+
+// UserSchema — поля пользователя, которые можно менять через PATCH /api/user/{id}.
+// Пароль, соль и роль меняются только отдельными операциями, группа — переводом с историей групп.
+var UserSchema = Schema{
+	Fields: map[string]Field{
+		"first_name":  {Kind: String, Required: true, Validate: notBlank},
+		"middle_name": {Kind: String},
+		"last_name":   {Kind: String, Required: true, Validate: notBlank},
+		"login":       {Kind: String, Required: true, Validate: notBlank},
+	},
+	Forbidden: map[string]bool{
+		"id":         true,
+		"password":   true,
+		"salt":       true,
+		"role_id":    true,
+		"role":       true,
+		"group_id":   true,
+		"group":      true,
+		"created_at": true,
+		"updated_at": true,
+	},
+}
+
+// ScheduleSchema — поля занятия, которые можно менять через PATCH /api/schedule/{id}
+var ScheduleSchema = Schema{
+	Fields: map[string]Field{
+		"group_id":    {Kind: Int, Validate: positiveID},
+		"subject_id":  {Kind: Int, Validate: positiveID},
+		"teacher_id":  {Kind: Int, Validate: positiveID},
+		"day_of_week": {Kind: Int, Validate: dayOfWeek},
+		"start_time":  {Kind: String, Required: true, Validate: timeOfDay, Normalize: normalizeTime},
+		"end_time":    {Kind: String, Required: true, Validate: timeOfDay, Normalize: normalizeTime},
+		"location":    {Kind: String, Required: true, Validate: notBlank},
+	},
+	Forbidden: map[string]bool{
+		"id":           true,
+		"group_name":   true,
+		"subject_name": true,
+		"teacher_name": true,
+		"created_at":   true,
+		"updated_at":   true,
+	},
+}
+
+// UserDocument — текущее состояние пользователя в виде документа для UserSchema
+func UserDocument(user models.User) map[string]interface{} {
+	return map[string]interface{}{
+		"first_name":  user.FirstName,
+		"middle_name": user.MiddleName,
+		"last_name":   user.LastName,
+		"login":       user.Login,
+	}
+}
+
+// ScheduleDocument — текущее состояние занятия в виде документа для ScheduleSchema
+func ScheduleDocument(schedule models.Schedule) map[string]interface{} {
+	return map[string]interface{}{
+		"group_id":    schedule.GroupID,
+		"subject_id":  schedule.SubjectID,
+		"teacher_id":  schedule.TeacherID,
+		"day_of_week": schedule.DayOfWeek,
+		"start_time":  NormalizeTime(schedule.StartTime),
+		"end_time":    NormalizeTime(schedule.EndTime),
+		"location":    schedule.Location,
+	}
+}
+
+// NormalizeTime приводит время занятия к виду HH:MM:SS; некорректное значение возвращается как есть
+func NormalizeTime(value string) string {
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format("15:04:05")
+		}
+	}
+	return value
+}
+
+func notBlank(value interface{}) error {
+	if s, _ := value.(string); strings.TrimSpace(s) == "" {
+		return errors.New("must not be empty")
+	}
+	return nil
+}
+
+func positiveID(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	if n, _ := value.(int); n <= 0 {
+		return errors.New("must be positive")
+	}
+	return nil
+}
+
+func dayOfWeek(value interface{}) error {
+	if n, _ := value.(int); n < 1 || n > 7 {
+		return errors.New("must be between 1 and 7")
+	}
+	return nil
+}
+
+// IsTime сообщает, записано ли время занятия в формате HH:MM или HH:MM:SS
+func IsTime(value string) bool {
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if _, err := time.Parse(layout, value); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func timeOfDay(value interface{}) error {
+	if s, _ := value.(string); !IsTime(s) {
+		return errors.New("must be a time in HH:MM or HH:MM:SS format")
+	}
+	return nil
+}
+
+func normalizeTime(value interface{}) interface{} {
+	s, _ := value.(string)
+	return NormalizeTime(s)
+}
@@ -0,0 +1,395 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// Font — разобранный шрифт TrueType. Разбирается один раз и используется многими документами:
+// в каждый документ встраивается только подмножество глифов, которые в нём встретились
+type Font struct {
+	name       string
+	tables     map[string][]byte
+	unitsPerEm int
+	bbox       [4]int
+	ascent     int
+	descent    int
+	capHeight  int
+	numGlyphs  int
+	longLoca   bool
+	advances   []int // ширина каждого глифа в единицах шрифта
+	cmap       map[rune]uint16
+}
+
+// ParseTrueType разбирает файл шрифта TrueType (.ttf); name — имя шрифта в PDF (без пробелов)
+func ParseTrueType(name string, data []byte) (*Font, error) {
+	if len(data) < 12 {
+		return nil, fmt.Errorf("invalid TrueType font: file is too short")
+	}
+	if version := binary.BigEndian.Uint32(data); version != 0x00010000 && version != 0x74727565 {
+		return nil, fmt.Errorf("invalid TrueType font: unsupported version %#x", version)
+	}
+
+	font := &Font{name: name, tables: make(map[string][]byte)}
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < numTables; i++ {
+		record := 12 + 16*i
+		if record+16 > len(data) {
+			return nil, fmt.Errorf("invalid TrueType font: truncated table directory")
+		}
+		tag := string(data[record : record+4])
+		offset := int(binary.BigEndian.Uint32(data[record+8:]))
+		length := int(binary.BigEndian.Uint32(data[record+12:]))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return nil, fmt.Errorf("invalid TrueType font: table %s is out of bounds", tag)
+		}
+		font.tables[tag] = data[offset : offset+length]
+	}
+	for _, tag := range []string{"head", "hhea", "maxp", "hmtx", "loca", "glyf", "cmap"} {
+		if _, ok := font.tables[tag]; !ok {
+			return nil, fmt.Errorf("invalid TrueType font: table %s not found", tag)
+		}
+	}
+
+	head, hhea, maxp := font.tables["head"], font.tables["hhea"], font.tables["maxp"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 {
+		return nil, fmt.Errorf("invalid TrueType font: truncated head, hhea or maxp")
+	}
+	font.unitsPerEm = int(binary.BigEndian.Uint16(head[18:]))
+	for i := range font.bbox {
+		font.bbox[i] = int(int16(binary.BigEndian.Uint16(head[36+2*i:])))
+	}
+	font.longLoca = binary.BigEndian.Uint16(head[50:]) == 1
+	font.ascent = int(int16(binary.BigEndian.Uint16(hhea[4:])))
+	font.descent = int(int16(binary.BigEndian.Uint16(hhea[6:])))
+	font.capHeight = font.ascent * 7 / 10
+	if os2 := font.tables["OS/2"]; len(os2) >= 90 && binary.BigEndian.Uint16(os2) >= 2 {
+		font.capHeight = int(int16(binary.BigEndian.Uint16(os2[88:])))
+	}
+	font.numGlyphs = int(binary.BigEndian.Uint16(maxp[4:]))
+	if font.unitsPerEm == 0 || font.numGlyphs == 0 {
+		return nil, fmt.Errorf("invalid TrueType font: empty head or maxp")
+	}
+
+	numMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	hmtx := font.tables["hmtx"]
+	if numMetrics == 0 || numMetrics > font.numGlyphs || len(hmtx) < 4*numMetrics {
+		return nil, fmt.Errorf("invalid TrueType font: truncated hmtx")
+	}
+	font.advances = make([]int, font.numGlyphs)
+	for gid := range font.advances {
+		// Глифы после numMetrics наследуют ширину последнего
+		metric := gid
+		if metric >= numMetrics {
+			metric = numMetrics - 1
+		}
+		font.advances[gid] = int(binary.BigEndian.Uint16(hmtx[4*metric:]))
+	}
+
+	cmap, err := parseCmap(font.tables["cmap"])
+	if err != nil {
+		return nil, err
+	}
+	for r, gid := range cmap {
+		if int(gid) >= font.numGlyphs {
+			delete(cmap, r)
+		}
+	}
+	font.cmap = cmap
+
+	if _, err := font.glyphRange(uint16(font.numGlyphs - 1)); err != nil {
+		return nil, err
+	}
+	return font, nil
+}
+
+// parseCmap читает таблицу символов Unicode: формат 12 (вся Unicode) или 4 (только BMP)
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, fmt.Errorf("invalid TrueType font: truncated cmap")
+	}
+
+	var format4, format12 []byte
+	numTables := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < numTables; i++ {
+		record := 4 + 8*i
+		if record+8 > len(cmap) {
+			break
+		}
+		platform := binary.BigEndian.Uint16(cmap[record:])
+		encoding := binary.BigEndian.Uint16(cmap[record+2:])
+		offset := int(binary.BigEndian.Uint32(cmap[record+4:]))
+		if offset+4 > len(cmap) || (platform != 0 && !(platform == 3 && (encoding == 1 || encoding == 10))) {
+			continue
+		}
+		switch binary.BigEndian.Uint16(cmap[offset:]) {
+		case 4:
+			format4 = cmap[offset:]
+		case 12:
+			format12 = cmap[offset:]
+		}
+	}
+
+	runes := make(map[rune]uint16)
+	switch {
+	case len(format12) >= 16:
+		groups := int(binary.BigEndian.Uint32(format12[12:]))
+		if 16+12*groups > len(format12) {
+			return nil, fmt.Errorf("invalid TrueType font: truncated cmap format 12")
+		}
+		for i := 0; i < groups; i++ {
+			group := format12[16+12*i:]
+			start := binary.BigEndian.Uint32(group)
+			end := binary.BigEndian.Uint32(group[4:])
+			glyph := binary.BigEndian.Uint32(group[8:])
+			for r := start; r <= end && r <= 0x10FFFF; r++ {
+				runes[rune(r)] = uint16(glyph + r - start)
+			}
+		}
+	case len(format4) >= 14:
+		segments := int(binary.BigEndian.Uint16(format4[6:])) / 2
+		ends, starts := 14, 16+2*segments
+		deltas, rangeOffsets := starts+2*segments, starts+4*segments
+		if rangeOffsets+2*segments > len(format4) {
+			return nil, fmt.Errorf("invalid TrueType font: truncated cmap format 4")
+		}
+		for i := 0; i < segments; i++ {
+			end := binary.BigEndian.Uint16(format4[ends+2*i:])
+			start := binary.BigEndian.Uint16(format4[starts+2*i:])
+			delta := binary.BigEndian.Uint16(format4[deltas+2*i:])
+			rangeOffset := int(binary.BigEndian.Uint16(format4[rangeOffsets+2*i:]))
+			for c := int(start); c <= int(end) && c != 0xFFFF; c++ {
+				glyph := uint16(c) + delta
+				if rangeOffset != 0 {
+					// Смещение считается от самого поля idRangeOffset этого сегмента
+					index := rangeOffsets + 2*i + rangeOffset + 2*(c-int(start))
+					if index+2 > len(format4) {
+						continue
+					}
+					glyph = binary.BigEndian.Uint16(format4[index:])
+					if glyph != 0 {
+						glyph += delta
+					}
+				}
+				if glyph != 0 {
+					runes[rune(c)] = glyph
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid TrueType font: no Unicode cmap")
+	}
+	return runes, nil
+}
+
+// Width возвращает ширину строки в пунктах при размере шрифта size
+func (f *Font) Width(text string, size float64) float64 {
+	units := 0
+	for _, r := range text {
+		units += f.advances[f.cmap[r]]
+	}
+	return float64(units) * size / float64(f.unitsPerEm)
+}
+
+// glyph возвращает номер глифа символа; символы, которых нет в шрифте, рисуются глифом 0 (.notdef)
+func (f *Font) glyph(r rune) uint16 {
+	return f.cmap[r]
+}
+
+// scaled переводит единицы шрифта в тысячные доли кегля, как принято в PDF
+func (f *Font) scaled(units int) int {
+	return units * 1000 / f.unitsPerEm
+}
+
+// glyphRange возвращает описание глифа в таблице glyf
+func (f *Font) glyphRange(gid uint16) ([]byte, error) {
+	loca, glyf := f.tables["loca"], f.tables["glyf"]
+	var start, end int
+	if f.longLoca {
+		if 4*int(gid)+8 > len(loca) {
+			return nil, fmt.Errorf("invalid TrueType font: truncated loca")
+		}
+		start = int(binary.BigEndian.Uint32(loca[4*int(gid):]))
+		end = int(binary.BigEndian.Uint32(loca[4*int(gid)+4:]))
+	} else {
+		if 2*int(gid)+4 > len(loca) {
+			return nil, fmt.Errorf("invalid TrueType font: truncated loca")
+		}
+		start = 2 * int(binary.BigEndian.Uint16(loca[2*int(gid):]))
+		end = 2 * int(binary.BigEndian.Uint16(loca[2*int(gid)+2:]))
+	}
+	if start > end || end > len(glyf) {
+		return nil, fmt.Errorf("invalid TrueType font: glyph %d is out of bounds", gid)
+	}
+	return glyf[start:end], nil
+}
+
+// Флаги компонентов составного глифа
+const (
+	compositeArgWords   = 0x0001
+	compositeScale      = 0x0008
+	compositeMore       = 0x0020
+	compositeXYScale    = 0x0040
+	compositeTwoByTwo   = 0x0080
+	compositeHeaderSize = 10
+)
+
+// components возвращает глифы, из которых собран составной глиф
+func components(glyph []byte) []uint16 {
+	if len(glyph) < compositeHeaderSize || int16(binary.BigEndian.Uint16(glyph)) >= 0 {
+		return nil
+	}
+
+	var gids []uint16
+	for offset := compositeHeaderSize; offset+4 <= len(glyph); {
+		flags := binary.BigEndian.Uint16(glyph[offset:])
+		gids = append(gids, binary.BigEndian.Uint16(glyph[offset+2:]))
+		offset += 4
+		if flags&compositeArgWords != 0 {
+			offset += 4
+		} else {
+			offset += 2
+		}
+		switch {
+		case flags&compositeScale != 0:
+			offset += 2
+		case flags&compositeXYScale != 0:
+			offset += 4
+		case flags&compositeTwoByTwo != 0:
+			offset += 8
+		}
+		if flags&compositeMore == 0 {
+			break
+		}
+	}
+	return gids
+}
+
+// subset собирает файл шрифта, в котором описаны только глифы used и их компоненты.
+// Номера глифов сохраняются (остальные глифы пустые), поэтому ширины и тексты документа не меняются
+func (f *Font) subset(used map[uint16]bool) ([]byte, error) {
+	keep := make(map[uint16]bool)
+	queue := []uint16{0}
+	for gid := range used {
+		queue = append(queue, gid)
+	}
+	for len(queue) > 0 {
+		gid := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if keep[gid] || int(gid) >= f.numGlyphs {
+			continue
+		}
+		keep[gid] = true
+		glyph, err := f.glyphRange(gid)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, components(glyph)...)
+	}
+
+	var glyf bytes.Buffer
+	loca := make([]byte, 4*(f.numGlyphs+1))
+	for gid := 0; gid < f.numGlyphs; gid++ {
+		binary.BigEndian.PutUint32(loca[4*gid:], uint32(glyf.Len()))
+		if !keep[uint16(gid)] {
+			continue
+		}
+		glyph, err := f.glyphRange(uint16(gid))
+		if err != nil {
+			return nil, err
+		}
+		glyf.Write(glyph)
+		for glyf.Len()%4 != 0 {
+			glyf.WriteByte(0)
+		}
+	}
+	binary.BigEndian.PutUint32(loca[4*f.numGlyphs:], uint32(glyf.Len()))
+
+	head := append([]byte(nil), f.tables["head"]...)
+	binary.BigEndian.PutUint32(head[8:], 0) // checksumAdjustment пересчитывается ниже
+	binary.BigEndian.PutUint16(head[50:], 1)
+
+	tables := map[string][]byte{
+		"head": head,
+		"hhea": f.tables["hhea"],
+		"maxp": f.tables["maxp"],
+		"hmtx": f.tables["hmtx"],
+		"loca": loca,
+		"glyf": glyf.Bytes(),
+	}
+	// Программы хинтинга нужны глифам, а cmap, OS/2 и name — растеризаторам, которые
+	// проверяют полноту шрифта; всё это копируется как есть
+	for _, tag := range []string{"cvt ", "fpgm", "prep", "cmap", "OS/2", "name"} {
+		if table, ok := f.tables[tag]; ok {
+			tables[tag] = table
+		}
+	}
+	// Имена глифов в post не нужны: версия 3.0 хранит только заголовок
+	if post := f.tables["post"]; len(post) >= 32 {
+		post = append([]byte(nil), post[:32]...)
+		binary.BigEndian.PutUint32(post, 0x00030000)
+		tables["post"] = post
+	}
+	return writeFontFile(tables), nil
+}
+
+// writeFontFile собирает файл TrueType из таблиц с контрольными суммами по спецификации
+func writeFontFile(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	searchRange, entrySelector := 1, 0
+	for searchRange*2 <= len(tags) {
+		searchRange *= 2
+		entrySelector++
+	}
+
+	var out bytes.Buffer
+	header := make([]byte, 12+16*len(tags))
+	binary.BigEndian.PutUint32(header, 0x00010000)
+	binary.BigEndian.PutUint16(header[4:], uint16(len(tags)))
+	binary.BigEndian.PutUint16(header[6:], uint16(searchRange*16))
+	binary.BigEndian.PutUint16(header[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(header[10:], uint16(len(tags)*16-searchRange*16))
+
+	offset := len(header)
+	headOffset := 0
+	for i, tag := range tags {
+		table := tables[tag]
+		record := header[12+16*i:]
+		copy(record, tag)
+		binary.BigEndian.PutUint32(record[4:], checksum(table))
+		binary.BigEndian.PutUint32(record[8:], uint32(offset))
+		binary.BigEndian.PutUint32(record[12:], uint32(len(table)))
+		if tag == "head" {
+			headOffset = offset
+		}
+		offset += (len(table) + 3) &^ 3
+	}
+	out.Write(header)
+	for _, tag := range tags {
+		out.Write(tables[tag])
+		for out.Len()%4 != 0 {
+			out.WriteByte(0)
+		}
+	}
+
+	file := out.Bytes()
+	binary.BigEndian.PutUint32(file[headOffset+8:], 0xB1B0AFBA-checksum(file))
+	return file
+}
+
+func checksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
@@ -0,0 +1,283 @@
+// Package pdf собирает простые PDF-документы (текст, линии, заливки) без внешних сервисов и библиотек.
+// Текст набирается встроенными шрифтами TrueType, поэтому кириллица отображается в любом просмотрщике;
+// в документ попадают только использованные глифы.
+//
+// Координаты страницы — пункты (1/72 дюйма) от левого верхнего угла, ось Y направлена вниз.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Размер страницы A4 в пунктах
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// Document — PDF-документ из страниц одинакового размера
+type Document struct {
+	title   string
+	created time.Time
+	pages   []*Page
+	fonts   []*fontUsage
+}
+
+// fontUsage — шрифт в документе: имя ресурса и глифы, которые нужно встроить
+type fontUsage struct {
+	font     *Font
+	resource string
+	glyphs   map[uint16]rune
+}
+
+// Page — страница документа; команды рисования копятся в потоке содержимого
+type Page struct {
+	doc     *Document
+	content bytes.Buffer
+}
+
+// New создаёт пустой документ; title попадает в свойства файла
+func New(title string) *Document {
+	return &Document{title: title, created: time.Now()}
+}
+
+// AddPage добавляет страницу A4
+func (d *Document) AddPage() *Page {
+	page := &Page{doc: d}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// PageCount возвращает число страниц
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+func (d *Document) usage(font *Font) *fontUsage {
+	for _, usage := range d.fonts {
+		if usage.font == font {
+			return usage
+		}
+	}
+	usage := &fontUsage{font: font, resource: fmt.Sprintf("F%d", len(d.fonts)+1), glyphs: make(map[uint16]rune)}
+	d.fonts = append(d.fonts, usage)
+	return usage
+}
+
+// Text пишет строку: x — левый край, y — базовая линия
+func (p *Page) Text(x, y float64, font *Font, size float64, text string) {
+	usage := p.doc.usage(font)
+	var hex strings.Builder
+	for _, r := range text {
+		gid := font.glyph(r)
+		if _, ok := usage.glyphs[gid]; !ok {
+			usage.glyphs[gid] = r
+		}
+		fmt.Fprintf(&hex, "%04X", gid)
+	}
+	fmt.Fprintf(&p.content, "BT /%s %s Tf %s %s Td <%s> Tj ET\n",
+		usage.resource, number(size), number(x), number(A4Height-y), hex.String())
+}
+
+// TextRight пишет строку, выровненную по правому краю right
+func (p *Page) TextRight(right, y float64, font *Font, size float64, text string) {
+	p.Text(right-font.Width(text, size), y, font, size, text)
+}
+
+// TextCenter пишет строку с центром в x
+func (p *Page) TextCenter(x, y float64, font *Font, size float64, text string) {
+	p.Text(x-font.Width(text, size)/2, y, font, size, text)
+}
+
+// Line рисует отрезок толщиной width
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n",
+		number(width), number(x1), number(A4Height-y1), number(x2), number(A4Height-y2))
+}
+
+// FillRect заливает прямоугольник с левым верхним углом (x, y) оттенком серого gray (0 — чёрный, 1 — белый)
+func (p *Page) FillRect(x, y, width, height, gray float64) {
+	fmt.Fprintf(&p.content, "q %s g %s %s %s %s re f Q\n",
+		number(gray), number(x), number(A4Height-y-height), number(width), number(height))
+}
+
+// number печатает число без лишних нулей, как принято в PDF
+func number(value float64) string {
+	s := strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", value), "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+// writer нумерует объекты и запоминает их смещения для таблицы xref
+type writer struct {
+	out     bytes.Buffer
+	offsets []int
+}
+
+// reserve выделяет номер объекта, который будет записан позже
+func (w *writer) reserve() int {
+	w.offsets = append(w.offsets, 0)
+	return len(w.offsets)
+}
+
+func (w *writer) object(id int, body string) {
+	w.offsets[id-1] = w.out.Len()
+	fmt.Fprintf(&w.out, "%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+// stream записывает поток, сжатый FlateDecode; extra — дополнительные ключи словаря
+func (w *writer) stream(id int, data []byte, extra string) error {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	w.offsets[id-1] = w.out.Len()
+	fmt.Fprintf(&w.out, "%d 0 obj\n<< /Length %d /Filter /FlateDecode%s >>\nstream\n", id, compressed.Len(), extra)
+	w.out.Write(compressed.Bytes())
+	w.out.WriteString("\nendstream\nendobj\n")
+	return nil
+}
+
+// WriteTo записывает документ; в каждый шрифт встраиваются только глифы, использованные на страницах
+func (d *Document) WriteTo(out io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	w := &writer{}
+	w.out.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	catalogID, pagesID, infoID := w.reserve(), w.reserve(), w.reserve()
+
+	var fontRefs strings.Builder
+	for i, usage := range d.fonts {
+		fontID, err := d.writeFont(w, usage, i)
+		if err != nil {
+			return 0, err
+		}
+		fmt.Fprintf(&fontRefs, "/%s %d 0 R ", usage.resource, fontID)
+	}
+
+	kids := make([]string, 0, len(d.pages))
+	for _, page := range d.pages {
+		pageID, contentID := w.reserve(), w.reserve()
+		if err := w.stream(contentID, page.content.Bytes(), ""); err != nil {
+			return 0, err
+		}
+		w.object(pageID, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s>> >> /Contents %d 0 R >>",
+			pagesID, number(A4Width), number(A4Height), fontRefs.String(), contentID))
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageID))
+	}
+
+	w.object(pagesID, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	w.object(catalogID, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesID))
+	w.object(infoID, fmt.Sprintf("<< /Title %s /Producer (backend_go) /CreationDate (D:%s) >>",
+		textString(d.title), d.created.UTC().Format("20060102150405Z")))
+
+	xref := w.out.Len()
+	fmt.Fprintf(&w.out, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, offset := range w.offsets {
+		fmt.Fprintf(&w.out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&w.out, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(w.offsets)+1, catalogID, infoID, xref)
+
+	n, err := out.Write(w.out.Bytes())
+	return int64(n), err
+}
+
+// writeFont встраивает подмножество шрифта как составной шрифт Type0 с кодировкой Identity-H:
+// коды в тексте — номера глифов, а ToUnicode позволяет копировать и искать текст
+func (d *Document) writeFont(w *writer, usage *fontUsage, index int) (int, error) {
+	font := usage.font
+	used := make(map[uint16]bool, len(usage.glyphs))
+	for gid := range usage.glyphs {
+		used[gid] = true
+	}
+	file, err := font.subset(used)
+	if err != nil {
+		return 0, err
+	}
+
+	// Подмножество помечается префиксом из шести заглавных букв, разным у шрифтов документа
+	baseName := fmt.Sprintf("%sAAAA%c+%s", string(rune('A'+index/26%26)), rune('A'+index%26), font.name)
+
+	fontID, descendantID, descriptorID, fileID, toUnicodeID := w.reserve(), w.reserve(), w.reserve(), w.reserve(), w.reserve()
+	if err := w.stream(fileID, file, fmt.Sprintf(" /Length1 %d", len(file))); err != nil {
+		return 0, err
+	}
+	w.object(descriptorID, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		baseName, font.scaled(font.bbox[0]), font.scaled(font.bbox[1]), font.scaled(font.bbox[2]), font.scaled(font.bbox[3]),
+		font.scaled(font.ascent), font.scaled(font.descent), font.scaled(font.capHeight), fileID))
+
+	gids := make([]int, 0, len(usage.glyphs))
+	for gid := range usage.glyphs {
+		gids = append(gids, int(gid))
+	}
+	sort.Ints(gids)
+
+	var widths, cmap strings.Builder
+	for _, gid := range gids {
+		fmt.Fprintf(&widths, "%d [%d] ", gid, font.scaled(font.advances[gid]))
+	}
+	w.object(descendantID, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /W [%s] /CIDToGIDMap /Identity >>",
+		baseName, descriptorID, widths.String()))
+
+	cmap.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	cmap.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	cmap.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	cmap.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	// В одном блоке bfchar допускается не больше 100 записей
+	for start := 0; start < len(gids); start += 100 {
+		end := start + 100
+		if end > len(gids) {
+			end = len(gids)
+		}
+		fmt.Fprintf(&cmap, "%d beginbfchar\n", end-start)
+		for _, gid := range gids[start:end] {
+			fmt.Fprintf(&cmap, "<%04X> <%s>\n", gid, utf16Hex(usage.glyphs[uint16(gid)]))
+		}
+		cmap.WriteString("endbfchar\n")
+	}
+	cmap.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	if err := w.stream(toUnicodeID, []byte(cmap.String()), ""); err != nil {
+		return 0, err
+	}
+
+	w.object(fontID, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		baseName, descendantID, toUnicodeID))
+	return fontID, nil
+}
+
+// utf16Hex кодирует символ в UTF-16BE для ToUnicode
+func utf16Hex(r rune) string {
+	if r >= 0x10000 {
+		r -= 0x10000
+		return fmt.Sprintf("%04X%04X", 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+	}
+	return fmt.Sprintf("%04X", r)
+}
+
+// textString кодирует строку свойств документа в UTF-16BE с меткой порядка байтов
+func textString(s string) string {
+	var hex strings.Builder
+	hex.WriteString("<FEFF")
+	for _, r := range s {
+		hex.WriteString(utf16Hex(r))
+	}
+	hex.WriteString(">")
+	return hex.String()
+}
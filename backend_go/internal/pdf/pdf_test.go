@@ -0,0 +1,216 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Шрифт отчётов используется и здесь: другого TrueType-шрифта в репозитории нет
+func loadFont(t *testing.T) (*Font, []byte) {
+	t.Helper()
+	data, err := os.ReadFile("../report/fonts/DejaVuSans.ttf")
+	if err != nil {
+		t.Fatalf("read font: %v", err)
+	}
+	font, err := ParseTrueType("DejaVuSans", data)
+	if err != nil {
+		t.Fatalf("ParseTrueType: %v", err)
+	}
+	return font, data
+}
+
+var (
+	objectRe = regexp.MustCompile(`(?m)^(\d+) 0 obj\n`)
+	streamRe = regexp.MustCompile(`(\d+) 0 obj\n<< /Length (\d+) /Filter /FlateDecode[^>]*>>\nstream\n`)
+)
+
+// streams распаковывает все потоки документа по номерам объектов
+func streams(t *testing.T, data []byte) map[int][]byte {
+	t.Helper()
+	result := make(map[int][]byte)
+	for _, m := range streamRe.FindAllSubmatchIndex(data, -1) {
+		id, _ := strconv.Atoi(string(data[m[2]:m[3]]))
+		length, _ := strconv.Atoi(string(data[m[4]:m[5]]))
+		reader, err := zlib.NewReader(bytes.NewReader(data[m[1] : m[1]+length]))
+		if err != nil {
+			t.Fatalf("stream %d: %v", id, err)
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("stream %d: %v", id, err)
+		}
+		result[id] = content
+	}
+	return result
+}
+
+// checkStructure сверяет таблицу xref и startxref со смещениями объектов в файле
+func checkStructure(t *testing.T, data []byte) {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatalf("no PDF header or trailer")
+	}
+
+	start := bytes.LastIndex(data, []byte("startxref\n"))
+	xref, err := strconv.Atoi(strings.Fields(string(data[start+len("startxref\n"):]))[0])
+	if err != nil || !bytes.HasPrefix(data[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref does not point to the xref table")
+	}
+
+	objects := objectRe.FindAllSubmatchIndex(data, -1)
+	lines := strings.Split(string(data[xref:]), "\n")
+	if lines[1] != fmt.Sprintf("0 %d", len(objects)+1) {
+		t.Fatalf("xref header %q, want %d objects", lines[1], len(objects)+1)
+	}
+	for i := 1; i <= len(objects); i++ {
+		offset, _ := strconv.Atoi(strings.Fields(lines[2+i])[0])
+		if !bytes.HasPrefix(data[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i))) {
+			t.Errorf("xref entry %d points to %q", i, data[offset:offset+10])
+		}
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	font, ttf := loadFont(t)
+	doc := New("Табель — Иванов")
+	doc.created = time.Date(2026, time.October, 18, 9, 30, 0, 0, time.UTC)
+	first := doc.AddPage()
+	first.Text(50, 60, font, 12, "Привет")
+	first.Line(50, 70, 200, 70, 0.5)
+	first.FillRect(50, 80, 100, 20, 0.9)
+	doc.AddPage().TextRight(545, 60, font, 8, "Стр. 2")
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	data := buf.Bytes()
+	checkStructure(t, data)
+
+	for _, want := range []string{
+		"/Type /Pages /Kids [",
+		"/Count 2 >>",
+		"/Subtype /Type0 /BaseFont /AAAAAA+DejaVuSans /Encoding /Identity-H",
+		"/Title " + textString("Табель — Иванов"),
+		"/CreationDate (D:20261018093000Z)",
+	} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("document has no %q", want)
+		}
+	}
+
+	// Первая страница: текст кодами глифов, координаты от нижнего края
+	all := streams(t, data)
+	var content, toUnicode, fontFile []byte
+	for _, stream := range all {
+		switch {
+		case bytes.Contains(stream, []byte(" Tj ET")) && bytes.Contains(stream, []byte(" re f ")):
+			content = stream
+		case bytes.Contains(stream, []byte("beginbfchar")):
+			toUnicode = stream
+		case bytes.HasPrefix(stream, []byte{0, 1, 0, 0}):
+			fontFile = stream
+		}
+	}
+	var hex strings.Builder
+	for _, r := range "Привет" {
+		fmt.Fprintf(&hex, "%04X", font.glyph(r))
+	}
+	wantContent := fmt.Sprintf("BT /F1 12 Tf 50 781.89 Td <%s> Tj ET\n0.5 w 50 771.89 m 200 771.89 l S\nq 0.9 g 50 741.89 100 20 re f Q\n", hex.String())
+	if string(content) != wantContent {
+		t.Errorf("page content = %q, want %q", content, wantContent)
+	}
+
+	// ToUnicode переводит глифы обратно в текст обеих страниц
+	for _, r := range "ПриветСтр. 2" {
+		entry := fmt.Sprintf("<%04X> <%04X>", font.glyph(r), r)
+		if !bytes.Contains(toUnicode, []byte(entry)) {
+			t.Errorf("ToUnicode has no %s for %q", entry, r)
+		}
+	}
+
+	// Встроено только подмножество, и оно остаётся корректным шрифтом
+	if len(fontFile) == 0 || len(fontFile) >= len(ttf)/4 {
+		t.Fatalf("embedded font of %d bytes, full font %d", len(fontFile), len(ttf))
+	}
+	subset, err := ParseTrueType("subset", fontFile)
+	if err != nil {
+		t.Fatalf("subset: %v", err)
+	}
+	for _, r := range "Привет" {
+		gid := font.glyph(r)
+		original, _ := font.glyphRange(gid)
+		embedded, err := subset.glyphRange(gid)
+		if err != nil || !bytes.Equal(original, embedded) {
+			t.Errorf("glyph %d of %q differs in the subset: %v", gid, r, err)
+		}
+	}
+	if unused, _ := subset.glyphRange(font.glyph('Ж')); len(unused) != 0 {
+		t.Errorf("unused glyph is embedded")
+	}
+}
+
+func TestWriteToEmptyDocument(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := New("").WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	checkStructure(t, buf.Bytes())
+	if !bytes.Contains(buf.Bytes(), []byte("/Count 1 >>")) {
+		t.Errorf("empty document must have one blank page")
+	}
+}
+
+func TestFontMetrics(t *testing.T) {
+	font, _ := loadFont(t)
+	if font.glyph('Я') == 0 || font.glyph('\uE000') != 0 {
+		t.Errorf("glyph('Я') = %d, glyph(U+E000) = %d", font.glyph('Я'), font.glyph('\uE000'))
+	}
+	if w := font.Width("ии", 10); w <= 0 || w != 2*font.Width("и", 10) || font.Width("и", 20) != 2*font.Width("и", 10) {
+		t.Errorf("Width is not additive or proportional to size: %v", w)
+	}
+}
+
+func TestParseTrueTypeRejects(t *testing.T) {
+	_, ttf := loadFont(t)
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"too short", []byte{0, 1, 0, 0}, "file is too short"},
+		{"OpenType CFF", append([]byte("OTTO"), ttf[4:]...), "unsupported version"},
+		{"truncated directory", ttf[:20], "truncated table directory"},
+		{"table out of bounds", ttf[:4096], "out of bounds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTrueType("broken", tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseTrueType() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNumber(t *testing.T) {
+	for value, want := range map[float64]string{0: "0", 12: "12", 0.5: "0.5", 781.886: "781.89", -0.001: "0", -3.1: "-3.1", 841.89: "841.89"} {
+		if got := number(value); got != want {
+			t.Errorf("number(%v) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestTextString(t *testing.T) {
+	if got, want := textString("Я😀"), "<FEFF042FD83DDE00>"; got != want {
+		t.Errorf("textString() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,50 @@
+package policy
+
+// Права из таблицы permissions. Каталог создаётся миграциями, роли собираются из него через API.
+const (
+	PermUsersRead        = "users:read"
+	PermStudentsRead     = "students:read"
+	PermUsersManage      = "users:manage"
+	PermRolesManage      = "roles:manage"
+	PermGroupsManage     = "groups:manage"
+	PermScheduleManage   = "schedule:manage"
+	PermSubjectsManage   = "subjects:manage"
+	PermGradeTypesManage = "grade_types:manage"
+	PermGradesRead       = "grades:read"
+	PermGradesWrite      = "grades:write"
+	PermGradesOverride   = "grades:override"
+	PermTermsManage      = "terms:manage"
+	PermHistoryRead      = "history:read"
+	PermAuditRead        = "audit:read"
+	PermAttendanceRead   = "attendance:read"
+	PermAttendanceWrite  = "attendance:write"
+	PermRecordsAll       = "records:all"
+	PermRecordsTaught    = "records:taught"
+	PermRecordsOwn       = "records:own"
+)
+
+// Permissions — каталог прав с описаниями, как в миграциях 0003_role_permissions, 0004_subjects, 0007_grade_types, 0008_terms, 0009_record_history и 0010_audit_log
+var Permissions = []struct {
+	Name        string
+	Description string
+}{
+	{PermUsersRead, "Просмотр списка всех пользователей"},
+	{PermStudentsRead, "Просмотр списка студентов"},
+	{PermUsersManage, "Изменение и удаление пользователей"},
+	{PermRolesManage, "Управление ролями, правами и назначение ролей"},
+	{PermGroupsManage, "Создание, изменение и удаление групп"},
+	{PermScheduleManage, "Создание, изменение и удаление занятий"},
+	{PermSubjectsManage, "Создание, изменение и удаление предметов"},
+	{PermGradeTypesManage, "Настройка видов оценок и их весов"},
+	{PermGradesRead, "Просмотр оценок"},
+	{PermGradesWrite, "Выставление, изменение и удаление оценок"},
+	{PermGradesOverride, "Изменение оценок и итоговых оценок в закрытых периодах"},
+	{PermTermsManage, "Создание, изменение и удаление учебных годов и периодов"},
+	{PermHistoryRead, "Просмотр истории изменений оценок и посещаемости"},
+	{PermAuditRead, "Просмотр и выгрузка журнала аудита"},
+	{PermAttendanceRead, "Просмотр посещаемости"},
+	{PermAttendanceWrite, "Отметка посещаемости"},
+	{PermRecordsAll, "Доступ к оценкам и посещаемости всех студентов"},
+	{PermRecordsTaught, "Доступ к оценкам и посещаемости по своим группам и предметам из расписания"},
+	{PermRecordsOwn, "Доступ только к своим оценкам и посещаемости"},
+}
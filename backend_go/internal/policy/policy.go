@@ -0,0 +1,73 @@
+// Package policy описывает, какие данные доступны пользователю.
+//
+// Что пользователь может делать, определяют права его роли (таблицы permissions и role_permissions),
+// а какие оценки и посещаемость он при этом видит — одно из прав records:*:
+//   - records:all — все записи (администратор);
+//   - records:taught — только по группам и предметам, которые он ведёт по расписанию (schedules.teacher_id);
+//   - records:own — только свои записи (студент).
+//
+// Scope передаётся в запросы internal/core и в хранилища, которые сами сужают выборку,
+// поэтому обработчик не может случайно отдать чужие данные.
+package policy
+
+import "errors"
+
+// ErrForbidden — запись существует, но пользователю недоступна
+var ErrForbidden = errors.New("access denied")
+
+// Access — какие записи об успеваемости видит пользователь
+type Access int
+
+const (
+	AccessNone Access = iota
+	AccessOwn
+	AccessTaught
+	AccessAll
+)
+
+// Scope — от чьего имени выполняется запрос
+type Scope struct {
+	UserID   int
+	Access   Access
+	Override bool // grades:override — может менять оценки за закрытые периоды
+}
+
+// System — полный доступ для CLI и внутренних задач, где нет пользователя
+func System() Scope {
+	return Scope{Access: AccessAll, Override: true}
+}
+
+// NewScope строит scope пользователя по правам его роли
+func NewScope(userID int, permissions []string) Scope {
+	scope := Scope{UserID: userID}
+	for _, permission := range permissions {
+		var access Access
+		switch permission {
+		case PermRecordsAll:
+			access = AccessAll
+		case PermRecordsTaught:
+			access = AccessTaught
+		case PermRecordsOwn:
+			access = AccessOwn
+		case PermGradesOverride:
+			scope.Override = true
+		}
+		if access > scope.Access {
+			scope.Access = access
+		}
+	}
+	return scope
+}
+
+// CanReadStudent — быстрая проверка без обращения к базе: при records:own виден только сам пользователь.
+// Для records:taught выборку дополнительно сужает запрос.
+func (s Scope) CanReadStudent(studentID int) bool {
+	switch s.Access {
+	case AccessAll, AccessTaught:
+		return true
+	case AccessOwn:
+		return s.UserID == studentID
+	default:
+		return false
+	}
+}
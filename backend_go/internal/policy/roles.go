@@ -0,0 +1,101 @@
+package policy
+
+import (
+	"github.com/VladislavSCV/internal/models"
+	"sync"
+	"time"
+)
+
+// RoleCacheTTL — как долго права ролей берутся из памяти без похода в базу.
+// Изменения через API ролей сбрасывают кэш сразу; TTL нужен для правок в обход API.
+const RoleCacheTTL = time.Minute
+
+// RoleLoader загружает все роли вместе с правами; его реализует repository.RoleRepository
+type RoleLoader interface {
+	GetAll() ([]models.Role, error)
+}
+
+// RoleCache хранит роли с правами в памяти процесса
+type RoleCache struct {
+	loader RoleLoader
+	ttl    time.Duration
+
+	mu       sync.RWMutex
+	roles    map[int]roleEntry
+	loadedAt time.Time
+}
+
+type roleEntry struct {
+	role        models.Role
+	permissions map[string]bool
+}
+
+func NewRoleCache(loader RoleLoader, ttl time.Duration) *RoleCache {
+	return &RoleCache{loader: loader, ttl: ttl}
+}
+
+// Permissions возвращает права роли; ok == false, если роли нет
+func (c *RoleCache) Permissions(roleID int) (permissions []string, ok bool, err error) {
+	entry, ok, err := c.entry(roleID)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	return entry.role.Permissions, true, nil
+}
+
+// HasPermission сообщает, есть ли у роли право
+func (c *RoleCache) HasPermission(roleID int, permission string) (bool, error) {
+	entry, ok, err := c.entry(roleID)
+	if err != nil || !ok {
+		return false, err
+	}
+	return entry.permissions[permission], nil
+}
+
+// Invalidate заставляет перечитать роли при следующем обращении
+func (c *RoleCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.roles = nil
+}
+
+func (c *RoleCache) entry(roleID int) (roleEntry, bool, error) {
+	c.mu.RLock()
+	if c.roles != nil && time.Since(c.loadedAt) < c.ttl {
+		entry, ok := c.roles[roleID]
+		c.mu.RUnlock()
+		return entry, ok, nil
+	}
+	c.mu.RUnlock()
+
+	if err := c.reload(); err != nil {
+		return roleEntry{}, false, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.roles[roleID]
+	return entry, ok, nil
+}
+
+func (c *RoleCache) reload() error {
+	roles, err := c.loader.GetAll()
+	if err != nil {
+		return err
+	}
+
+	entries := make(map[int]roleEntry, len(roles))
+	for _, role := range roles {
+		permissions := make(map[string]bool, len(role.Permissions))
+		for _, permission := range role.Permissions {
+			permissions[permission] = true
+		}
+		entries[role.ID] = roleEntry{role: role, permissions: permissions}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.roles = entries
+	c.loadedAt = time.Now()
+	return nil
+}
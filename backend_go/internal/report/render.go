@@ -0,0 +1,383 @@
+package report
+
+import (
+	"archive/zip"
+	_ "embed"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/pdf"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Шрифты DejaVu встраиваются в бинарник: у них есть кириллица, а лицензия лежит рядом в fonts/LICENSE
+var (
+	//go:embed fonts/DejaVuSans.ttf
+	regularTTF []byte
+	//go:embed fonts/DejaVuSans-Bold.ttf
+	boldTTF []byte
+
+	fontsOnce     sync.Once
+	regular, bold *pdf.Font
+	fontsErr      error
+)
+
+// loadFonts разбирает встроенные шрифты один раз на процесс
+func loadFonts() error {
+	fontsOnce.Do(func() {
+		if regular, fontsErr = pdf.ParseTrueType("DejaVuSans", regularTTF); fontsErr != nil {
+			return
+		}
+		bold, fontsErr = pdf.ParseTrueType("DejaVuSans-Bold", boldTTF)
+	})
+	if fontsErr != nil {
+		return fmt.Errorf("failed to load report fonts: %v", fontsErr)
+	}
+	return nil
+}
+
+// Разметка страницы в пунктах
+const (
+	margin     = 50.0
+	rowHeight  = 18.0
+	lineHeight = 12.0 // шаг строк внутри ячейки
+	bodySize   = 10.0
+	dateLayout = "02.01.2006"
+)
+
+// column — колонка таблицы предметов: левый край и ширина
+type column struct {
+	title string
+	x, w  float64
+}
+
+var columns = []column{
+	{"Предмет", margin, 215},
+	{"Оценок", margin + 215, 55},
+	{"Средний балл", margin + 270, 75},
+	{"Итоговая", margin + 345, 60},
+	{"Пропуски", margin + 405, pdf.A4Width - 2*margin - 405},
+}
+
+// layout ведёт текущую страницу и позицию по вертикали, перенося содержимое на новую страницу
+type layout struct {
+	doc  *pdf.Document
+	page *pdf.Page
+	y    float64
+	now  time.Time
+}
+
+func newLayout(title string, now time.Time) *layout {
+	l := &layout{doc: pdf.New(title), now: now}
+	l.newPage()
+	return l
+}
+
+func (l *layout) newPage() {
+	l.page = l.doc.AddPage()
+	l.y = margin
+	// Номер страницы в нижнем колонтитуле
+	l.page.TextRight(pdf.A4Width-margin, pdf.A4Height-margin/2, regular, 8, fmt.Sprintf("Стр. %d", l.doc.PageCount()))
+}
+
+// ensure начинает новую страницу, если блок высотой height не помещается на текущую
+func (l *layout) ensure(height float64) bool {
+	if l.y+height <= pdf.A4Height-margin {
+		return false
+	}
+	l.newPage()
+	return true
+}
+
+func (l *layout) line(font *pdf.Font, size float64, text string) {
+	l.ensure(size * 1.5)
+	l.y += size * 1.5
+	l.page.Text(margin, l.y, font, size, text)
+}
+
+func (l *layout) centered(font *pdf.Font, size float64, text string) {
+	l.ensure(size * 1.5)
+	l.y += size * 1.5
+	l.page.TextCenter(pdf.A4Width/2, l.y, font, size, text)
+}
+
+func (l *layout) space(height float64) {
+	l.y += height
+}
+
+// header рисует шапку учебного заведения и заголовок документа
+func (l *layout) header(title string, student Student) {
+	if settings.SchoolName != "" {
+		l.centered(bold, 13, settings.SchoolName)
+	}
+	for _, text := range settings.HeaderLines {
+		l.centered(regular, 8, text)
+	}
+	if settings.SchoolName != "" || len(settings.HeaderLines) > 0 {
+		l.space(6)
+		l.page.Line(margin, l.y, pdf.A4Width-margin, l.y, 0.8)
+	}
+
+	l.space(14)
+	l.centered(bold, 15, title)
+	l.space(8)
+	l.line(regular, 11, "Студент: "+student.Name)
+	group := student.Group
+	if group == "" {
+		group = "—"
+	}
+	l.line(regular, 11, "Группа: "+group)
+}
+
+// table рисует таблицу предметов периода; шапка повторяется на каждой новой странице
+func (l *layout) table(subjects []SubjectLine) (provisional bool) {
+	tableHeader := func() {
+		l.page.FillRect(margin, l.y, pdf.A4Width-2*margin, rowHeight, 0.88)
+		for i, col := range columns {
+			if i == 0 {
+				l.page.Text(col.x+4, l.y+12.5, bold, 9, col.title)
+			} else {
+				l.page.TextCenter(col.x+col.w/2, l.y+12.5, bold, 9, col.title)
+			}
+		}
+		l.y += rowHeight
+	}
+
+	l.space(8)
+	l.ensure(2 * rowHeight)
+	tableHeader()
+	for _, subject := range subjects {
+		// Длинное название предмета переносится на следующие строки ячейки
+		names := wrap(regular, bodySize, subject.Name, columns[0].w-8)
+		height := rowHeight + float64(len(names)-1)*lineHeight
+		if l.ensure(height) {
+			tableHeader()
+		}
+		final := "—"
+		if subject.FinalGrade != nil {
+			final = fmt.Sprint(*subject.FinalGrade)
+			if !subject.FinalLocked {
+				final += "*"
+				provisional = true
+			}
+		}
+		cells := []string{
+			"",
+			fmt.Sprint(subject.GradeCount),
+			formatAverage(subject.Average),
+			final,
+			formatAbsences(subject.Attendance.Absent, subject.Attendance.Excused),
+		}
+		for i, name := range names {
+			l.page.Text(columns[0].x+4, l.y+12.5+float64(i)*lineHeight, regular, bodySize, name)
+		}
+		for i, col := range columns[1:] {
+			l.page.TextCenter(col.x+col.w/2, l.y+12.5, regular, bodySize, cells[i+1])
+		}
+		l.y += height
+		l.page.Line(margin, l.y, pdf.A4Width-margin, l.y, 0.3)
+	}
+	return provisional
+}
+
+// summary пишет средний балл и посещаемость периода под таблицей
+func (l *layout) summary(card *Card) {
+	l.space(6)
+	l.line(regular, bodySize, "Средний балл за период: "+formatAverage(card.Average))
+	for _, text := range attendanceLines(card.Attendance) {
+		l.line(regular, bodySize, text)
+	}
+}
+
+// footer пишет примечание о предварительных оценках, дату выдачи и подпись
+func (l *layout) footer(provisional bool) {
+	l.space(10)
+	if provisional {
+		l.line(regular, 8, "* Предварительная итоговая оценка: период ещё не закрыт.")
+	}
+	l.space(16)
+	l.line(regular, bodySize, "Дата выдачи: "+l.now.Format(dateLayout))
+	if settings.Signatory != "" {
+		l.space(20)
+		l.line(regular, bodySize, settings.Signatory)
+		l.page.Line(pdf.A4Width-margin-150, l.y, pdf.A4Width-margin, l.y, 0.5)
+	}
+}
+
+// WriteCard пишет табель успеваемости за период в PDF
+func WriteCard(w io.Writer, card *Card, now time.Time) error {
+	if err := loadFonts(); err != nil {
+		return err
+	}
+
+	l := newLayout("Табель успеваемости — "+card.Student.Name, now)
+	l.header("ТАБЕЛЬ УСПЕВАЕМОСТИ", card.Student)
+	l.line(regular, 11, "Период: "+termTitle(card))
+
+	provisional := false
+	if card.Empty() {
+		l.space(8)
+		l.line(regular, bodySize, "За период нет оценок и отметок посещаемости.")
+	} else {
+		provisional = l.table(card.Subjects)
+		l.summary(card)
+	}
+	l.footer(provisional)
+
+	_, err := l.doc.WriteTo(w)
+	return err
+}
+
+// WriteTranscript пишет справку об успеваемости за все периоды в PDF
+func WriteTranscript(w io.Writer, transcript *Transcript, now time.Time) error {
+	if err := loadFonts(); err != nil {
+		return err
+	}
+
+	l := newLayout("Справка об успеваемости — "+transcript.Student.Name, now)
+	l.header("СПРАВКА ОБ УСПЕВАЕМОСТИ", transcript.Student)
+
+	provisional := false
+	if len(transcript.Terms) == 0 {
+		l.space(8)
+		l.line(regular, bodySize, "Записей об успеваемости нет.")
+	}
+	for i := range transcript.Terms {
+		card := &transcript.Terms[i]
+		l.space(12)
+		// Заголовок периода не должен остаться внизу страницы без таблицы
+		l.ensure(20 + 3*rowHeight)
+		l.line(bold, 11, termTitle(card))
+		if l.table(card.Subjects) {
+			provisional = true
+		}
+		l.summary(card)
+	}
+
+	if len(transcript.Terms) > 0 {
+		l.space(12)
+		l.line(bold, 11, "Итого за всё обучение")
+		l.line(regular, bodySize, "Средний итоговый балл: "+formatAverage(transcript.FinalAverage))
+		for _, text := range attendanceLines(transcript.Attendance) {
+			l.line(regular, bodySize, text)
+		}
+	}
+	l.footer(provisional)
+
+	_, err := l.doc.WriteTo(w)
+	return err
+}
+
+func termTitle(card *Card) string {
+	title := card.Term.Name
+	if card.Term.AcademicYearName != "" {
+		title += ", " + card.Term.AcademicYearName + " уч. год"
+	}
+	return fmt.Sprintf("%s (%s — %s)", title, card.Term.StartDate.Format(dateLayout), card.Term.EndDate.Format(dateLayout))
+}
+
+// formatAverage печатает средний балл с десятичной запятой; nil — прочерк
+func formatAverage(average *float64) string {
+	if average == nil {
+		return "—"
+	}
+	return strings.Replace(fmt.Sprintf("%.2f", *average), ".", ",", 1)
+}
+
+// formatAbsences печатает число пропусков и сколько из них по уважительной причине
+func formatAbsences(absent, excused int) string {
+	if excused == 0 {
+		return fmt.Sprint(absent)
+	}
+	return fmt.Sprintf("%d (уваж. %d)", absent+excused, excused)
+}
+
+// attendanceLines описывает посещаемость двумя строками: присутствия и пропуски
+func attendanceLines(a models.AttendanceCounts) []string {
+	if a.Total == 0 {
+		return []string{"Посещаемость: отметок нет"}
+	}
+	return []string{
+		fmt.Sprintf("Посещаемость: присутствий %d из %d (%s%%)", a.Present, a.Total, strings.Replace(fmt.Sprintf("%.1f", a.Rate), ".", ",", 1)),
+		fmt.Sprintf("Пропусков: %d, из них по уважительной причине %d", a.Absent+a.Excused, a.Excused),
+	}
+}
+
+// wrap разбивает текст по словам на строки не шире width; слово длиннее строки режется по символам
+func wrap(font *pdf.Font, size float64, text string, width float64) []string {
+	var lines []string
+	current := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if font.Width(candidate, size) <= width {
+			current = candidate
+			continue
+		}
+		if current != "" {
+			lines = append(lines, current)
+		}
+		for font.Width(word, size) > width {
+			cut := len(word)
+			for cut > 0 && font.Width(word[:cut], size) > width {
+				_, n := utf8.DecodeLastRuneInString(word[:cut])
+				cut -= n
+			}
+			if cut == 0 {
+				break
+			}
+			lines = append(lines, word[:cut])
+			word = word[cut:]
+		}
+		current = word
+	}
+	if current != "" || len(lines) == 0 {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+// WriteCardsZip пишет ZIP-архив с табелем каждого студента в отдельном PDF
+func WriteCardsZip(w io.Writer, cards []Card, now time.Time) error {
+	return writeZip(w, now, len(cards), func(i int) Student { return cards[i].Student }, func(i int, out io.Writer) error {
+		return WriteCard(out, &cards[i], now)
+	})
+}
+
+// WriteTranscriptsZip пишет ZIP-архив со справкой каждого студента в отдельном PDF
+func WriteTranscriptsZip(w io.Writer, transcripts []Transcript, now time.Time) error {
+	return writeZip(w, now, len(transcripts), func(i int) Student { return transcripts[i].Student }, func(i int, out io.Writer) error {
+		return WriteTranscript(out, &transcripts[i], now)
+	})
+}
+
+// writeZip складывает n документов в архив; файлы называются по студенту, ID делает имена уникальными
+func writeZip(w io.Writer, now time.Time, n int, student func(int) Student, write func(int, io.Writer) error) error {
+	archive := zip.NewWriter(w)
+	for i := 0; i < n; i++ {
+		s := student(i)
+		name := strings.Map(func(r rune) rune {
+			if r == ' ' || r == '/' || r == '\\' {
+				return '_'
+			}
+			return r
+		}, s.Name)
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     fmt.Sprintf("%s_%d.pdf", name, s.ID),
+			Method:   zip.Deflate,
+			Modified: now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add report to archive: %v", err)
+		}
+		if err := write(i, file); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
@@ -0,0 +1,305 @@
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"github.com/VladislavSCV/internal/models"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+var (
+	streamRe  = regexp.MustCompile(`(\d+) 0 obj\n<< /Length (\d+) /Filter /FlateDecode[^>]*>>\nstream\n`)
+	fontRe    = regexp.MustCompile(`(\d+) 0 obj\n<< /Type /Font /Subtype /Type0 [^\n]*/ToUnicode (\d+) 0 R >>`)
+	resRe     = regexp.MustCompile(`/(F\d+) (\d+) 0 R`)
+	contentRe = regexp.MustCompile(`/Type /Page /Parent [^\n]*/Font << ([^>]*)>> >> /Contents (\d+) 0 R`)
+	bfcharRe  = regexp.MustCompile(`<([0-9A-F]{4})> <([0-9A-F]+)>`)
+	textRe    = regexp.MustCompile(`BT /(F\d+) \S+ Tf \S+ \S+ Td <([0-9A-F]*)> Tj ET`)
+)
+
+// pageTexts достаёт из PDF строки текста каждой страницы, переводя коды глифов обратно через ToUnicode
+func pageTexts(t *testing.T, data []byte) [][]string {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatalf("not a PDF document")
+	}
+
+	streams := make(map[string]string)
+	for _, m := range streamRe.FindAllSubmatchIndex(data, -1) {
+		length, _ := strconv.Atoi(string(data[m[4]:m[5]]))
+		reader, err := zlib.NewReader(bytes.NewReader(data[m[1] : m[1]+length]))
+		if err != nil {
+			t.Fatalf("stream %s: %v", data[m[2]:m[3]], err)
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("stream %s: %v", data[m[2]:m[3]], err)
+		}
+		streams[string(data[m[2]:m[3]])] = string(content)
+	}
+
+	// Объект шрифта → таблица глиф → текст
+	cmaps := make(map[string]map[string]string)
+	for _, m := range fontRe.FindAllSubmatch(data, -1) {
+		cmap := make(map[string]string)
+		for _, entry := range bfcharRe.FindAllStringSubmatch(streams[string(m[2])], -1) {
+			var units []uint16
+			for i := 0; i < len(entry[2]); i += 4 {
+				unit, _ := strconv.ParseUint(entry[2][i:i+4], 16, 16)
+				units = append(units, uint16(unit))
+			}
+			cmap[entry[1]] = string(utf16.Decode(units))
+		}
+		cmaps[string(m[1])] = cmap
+	}
+
+	var pages [][]string
+	for _, page := range contentRe.FindAllSubmatch(data, -1) {
+		resources := make(map[string]string)
+		for _, res := range resRe.FindAllSubmatch(page[1], -1) {
+			resources[string(res[1])] = string(res[2])
+		}
+		var lines []string
+		for _, text := range textRe.FindAllStringSubmatch(streams[string(page[2])], -1) {
+			cmap := cmaps[resources[text[1]]]
+			var line strings.Builder
+			for i := 0; i < len(text[2]); i += 4 {
+				line.WriteString(cmap[text[2][i:i+4]])
+			}
+			lines = append(lines, line.String())
+		}
+		pages = append(pages, lines)
+	}
+	return pages
+}
+
+// configure подменяет шапку и подпись на время теста
+func configure(t *testing.T, s Settings) {
+	previous := settings
+	settings = s
+	t.Cleanup(func() { settings = previous })
+}
+
+var (
+	issued    = time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	firstTerm = models.Term{
+		Name:             "1 семестр",
+		AcademicYearName: "2026/2027",
+		StartDate:        time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:          time.Date(2026, time.December, 31, 0, 0, 0, 0, time.UTC),
+	}
+)
+
+func float(v float64) *float64 { return &v }
+
+func grade(v int) *int { return &v }
+
+func TestWriteCard(t *testing.T) {
+	configure(t, Settings{SchoolName: "Колледж связи", HeaderLines: []string{"г. Москва"}, Signatory: "Директор Петров П. П."})
+	card := &Card{
+		Student: Student{ID: 3, Name: "Иванов Иван Иванович", Group: "ИС-21"},
+		Term:    firstTerm,
+		Subjects: []SubjectLine{
+			{Name: "Математика", GradeCount: 4, Average: float(4.5), FinalGrade: grade(5), FinalLocked: true,
+				Attendance: models.NewAttendanceCounts(10, 2, 1)},
+			{Name: "Основы алгоритмизации и программирования на языке высокого уровня", GradeCount: 0,
+				FinalGrade: grade(4)},
+		},
+		Average:    float(4.5),
+		Attendance: models.NewAttendanceCounts(10, 2, 1),
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCard(&buf, card, issued); err != nil {
+		t.Fatalf("WriteCard: %v", err)
+	}
+	pages := pageTexts(t, buf.Bytes())
+	if len(pages) != 1 {
+		t.Fatalf("%d pages, want 1", len(pages))
+	}
+	// Длинное название предмета переносится на несколько строк ячейки
+	names := wrap(regular, bodySize, card.Subjects[1].Name, columns[0].w-8)
+	if len(names) < 2 {
+		t.Fatalf("subject name is not wrapped: %q", names)
+	}
+	for _, want := range append(names,
+		"Стр. 1",
+		"Колледж связи",
+		"г. Москва",
+		"ТАБЕЛЬ УСПЕВАЕМОСТИ",
+		"Студент: Иванов Иван Иванович",
+		"Группа: ИС-21",
+		"Период: 1 семестр, 2026/2027 уч. год (01.09.2026 — 31.12.2026)",
+		"Предмет", "Средний балл", "Пропуски",
+		"Математика", "4,50", "5", "3 (уваж. 1)",
+		// Предварительная оценка помечена звёздочкой
+		"4*", "—",
+		"Средний балл за период: 4,50",
+		"Посещаемость: присутствий 10 из 13 (76,9%)",
+		"Пропусков: 3, из них по уважительной причине 1",
+		"* Предварительная итоговая оценка: период ещё не закрыт.",
+		"Дата выдачи: 18.10.2026",
+		"Директор Петров П. П.",
+	) {
+		if !slices.Contains(pages[0], want) {
+			t.Errorf("card has no line %q", want)
+		}
+	}
+}
+
+func TestWriteCardEmpty(t *testing.T) {
+	configure(t, Settings{})
+	card := &Card{Student: Student{ID: 4, Name: "Петрова Анна"}, Term: models.Term{Name: "2 семестр", StartDate: firstTerm.StartDate, EndDate: firstTerm.EndDate}}
+
+	var buf bytes.Buffer
+	if err := WriteCard(&buf, card, issued); err != nil {
+		t.Fatalf("WriteCard: %v", err)
+	}
+	lines := pageTexts(t, buf.Bytes())[0]
+	for _, want := range []string{"Группа: —", "Период: 2 семестр (01.09.2026 — 31.12.2026)", "За период нет оценок и отметок посещаемости."} {
+		if !slices.Contains(lines, want) {
+			t.Errorf("card has no line %q", want)
+		}
+	}
+	for _, unwanted := range []string{"Предмет", "* Предварительная итоговая оценка: период ещё не закрыт."} {
+		if slices.Contains(lines, unwanted) {
+			t.Errorf("empty card has line %q", unwanted)
+		}
+	}
+}
+
+func TestWriteTranscriptPages(t *testing.T) {
+	configure(t, Settings{})
+	var subjects []SubjectLine
+	for i := 1; i <= 12; i++ {
+		subjects = append(subjects, SubjectLine{Name: "Предмет " + strconv.Itoa(i), GradeCount: i, FinalGrade: grade(4), FinalLocked: true})
+	}
+	transcript := &Transcript{Student: Student{ID: 3, Name: "Иванов Иван", Group: "ИС-21"}, FinalAverage: float(4)}
+	for i := 0; i < 4; i++ {
+		term := firstTerm
+		term.Name = strconv.Itoa(i+1) + " семестр"
+		transcript.Terms = append(transcript.Terms, Card{Term: term, Subjects: subjects})
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTranscript(&buf, transcript, issued); err != nil {
+		t.Fatalf("WriteTranscript: %v", err)
+	}
+	pages := pageTexts(t, buf.Bytes())
+	if len(pages) < 2 {
+		t.Fatalf("%d pages, want the transcript to continue on the next page", len(pages))
+	}
+	rows := 0
+	for i, lines := range pages {
+		if !slices.Contains(lines, "Стр. "+strconv.Itoa(i+1)) {
+			t.Errorf("page %d has no page number", i+1)
+		}
+		pageRows := 0
+		for _, line := range lines {
+			if strings.HasPrefix(line, "Предмет ") {
+				pageRows++
+			}
+		}
+		// Строки предметов без шапки таблицы на странице означают, что шапка не повторилась
+		if pageRows > 0 && !slices.Contains(lines, "Предмет") {
+			t.Errorf("page %d: table rows without the table header", i+1)
+		}
+		rows += pageRows
+	}
+	if rows != 4*len(subjects) {
+		t.Errorf("%d subject rows, want %d", rows, 4*len(subjects))
+	}
+	last := pages[len(pages)-1]
+	for _, want := range []string{"Итого за всё обучение", "Средний итоговый балл: 4,00", "Посещаемость: отметок нет"} {
+		if !slices.Contains(last, want) {
+			t.Errorf("last page has no line %q", want)
+		}
+	}
+	if slices.Contains(last, "* Предварительная итоговая оценка: период ещё не закрыт.") {
+		t.Errorf("locked final grades are marked as provisional")
+	}
+}
+
+func TestWrap(t *testing.T) {
+	if err := loadFonts(); err != nil {
+		t.Fatal(err)
+	}
+	width := regular.Width("Основы алгоритмизации", bodySize)
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{""}},
+		{"  Математика  ", []string{"Математика"}},
+		{"Основы алгоритмизации и программирования", []string{"Основы алгоритмизации", "и программирования"}},
+		{"Электроэнергетикаиэлектротехникаоченьдлинно", []string{"Электроэнергетикаиэле", "ктротехникаоченьдлин", "но"}},
+	}
+	for _, tt := range tests {
+		got := wrap(regular, bodySize, tt.text, width)
+		for _, line := range got {
+			if regular.Width(line, bodySize) > width {
+				t.Errorf("wrap(%q): line %q is wider than %v", tt.text, line, width)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("wrap(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFormatting(t *testing.T) {
+	if got := formatAverage(nil); got != "—" {
+		t.Errorf("formatAverage(nil) = %q", got)
+	}
+	if got := formatAverage(float(4.666)); got != "4,67" {
+		t.Errorf("formatAverage(4.666) = %q", got)
+	}
+	if got := formatAbsences(2, 0); got != "2" {
+		t.Errorf("formatAbsences(2, 0) = %q", got)
+	}
+	if got := formatAbsences(2, 3); got != "5 (уваж. 3)" {
+		t.Errorf("formatAbsences(2, 3) = %q", got)
+	}
+}
+
+func TestWriteCardsZip(t *testing.T) {
+	configure(t, Settings{})
+	cards := []Card{
+		{Student: Student{ID: 3, Name: "Иванов Иван", Group: "ИС-21"}, Term: firstTerm},
+		{Student: Student{ID: 7, Name: "Иванов Иван", Group: "ИС/22"}, Term: firstTerm},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCardsZip(&buf, cards, issued); err != nil {
+		t.Fatalf("WriteCardsZip: %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("open archive: %v", err)
+	}
+	// Тёзки различаются по ID
+	want := []string{"Иванов_Иван_3.pdf", "Иванов_Иван_7.pdf"}
+	if len(archive.File) != len(want) {
+		t.Fatalf("%d files, want %d", len(archive.File), len(want))
+	}
+	for i, file := range archive.File {
+		if file.Name != want[i] || !file.Modified.Equal(issued) {
+			t.Errorf("file %d: %s modified %v", i, file.Name, file.Modified)
+		}
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", file.Name, err)
+		}
+		data, _ := io.ReadAll(reader)
+		if lines := pageTexts(t, data)[0]; !slices.Contains(lines, "Группа: "+cards[i].Student.Group) {
+			t.Errorf("%s is not the card of student %d", file.Name, cards[i].Student.ID)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+// Package report собирает печатные табели успеваемости за период и справки об успеваемости
+// за всё обучение: данные берутся из оценок, посещаемости и итоговых оценок, документ — PDF
+// со встроенными шрифтами, без внешних сервисов.
+package report
+
+import (
+	"fmt"
+	"github.com/VladislavSCV/internal/config"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Settings — шапка и подпись документов
+type Settings struct {
+	SchoolName  string
+	HeaderLines []string
+	Signatory   string
+}
+
+var settings Settings
+
+// Configure задаёт шапку и подпись из конфигурации сервера
+func Configure(cfg config.ReportConfig) {
+	settings = Settings{
+		SchoolName:  strings.TrimSpace(cfg.SchoolName),
+		HeaderLines: cfg.HeaderLines,
+		Signatory:   strings.TrimSpace(cfg.Signatory),
+	}
+}
+
+// Student — студент, на которого выписан документ
+type Student struct {
+	ID    int
+	Name  string // Фамилия Имя Отчество
+	Group string // пустая строка — студент вне группы
+}
+
+// SubjectLine — строка табеля по предмету
+type SubjectLine struct {
+	SubjectID   int
+	Name        string
+	GradeCount  int
+	Average     *float64 // nil — оценок нет
+	FinalGrade  *int     // nil — итоговая оценка не выставлена
+	FinalLocked bool     // false — итоговая оценка предварительная, период не закрыт
+	Attendance  models.AttendanceCounts
+}
+
+// Card — табель студента за период
+type Card struct {
+	Student    Student
+	Term       models.Term
+	Subjects   []SubjectLine
+	Average    *float64 // средний балл по всем оценкам периода с учётом весов
+	Attendance models.AttendanceCounts
+}
+
+// Empty сообщает, что за период нет ни оценок, ни посещаемости, ни итоговых оценок
+func (c *Card) Empty() bool {
+	return len(c.Subjects) == 0
+}
+
+// Transcript — справка об успеваемости за все периоды, в которых у студента есть записи
+type Transcript struct {
+	Student    Student
+	Terms      []Card
+	Attendance models.AttendanceCounts
+	// FinalAverage — среднее итоговых оценок за все периоды; nil — итоговых оценок нет
+	FinalAverage *float64
+}
+
+// Sources — хранилища, из которых собираются документы. Все выборки идут от имени scope,
+// поэтому преподаватель видит в табеле только свои предметы
+type Sources struct {
+	Users       repository.UserRepository
+	Groups      repository.GroupRepository
+	Terms       repository.TermRepository
+	Grades      repository.GradeRepository
+	Attendance  repository.AttendanceRepository
+	FinalGrades repository.FinalGradeRepository
+}
+
+// student находит студента по ID; пользователи других ролей считаются ненайденными
+func (s Sources) student(scope policy.Scope, studentID int) (Student, error) {
+	user, err := s.Users.GetByID(scope, studentID)
+	if err != nil {
+		return Student{}, err
+	}
+	if user.Role != "student" {
+		return Student{}, fmt.Errorf("student not found")
+	}
+	return newStudent(*user), nil
+}
+
+func newStudent(user models.User) Student {
+	name := user.LastName + " " + user.FirstName
+	if user.MiddleName != "" {
+		name += " " + user.MiddleName
+	}
+	return Student{ID: user.ID, Name: name, Group: user.Group.String}
+}
+
+// Card собирает табель студента за период termID
+func (s Sources) Card(scope policy.Scope, studentID, termID int) (*Card, error) {
+	if !scope.CanReadStudent(studentID) {
+		return nil, policy.ErrForbidden
+	}
+	student, err := s.student(scope, studentID)
+	if err != nil {
+		return nil, err
+	}
+	term, err := s.Terms.GetByID(termID)
+	if err != nil {
+		return nil, err
+	}
+	finals, err := s.FinalGrades.GetByTerm(scope, term.ID, models.FinalGradeFilter{})
+	if err != nil {
+		return nil, err
+	}
+	return s.card(scope, student, *term, finals)
+}
+
+// Transcript собирает справку об успеваемости студента по всем периодам
+func (s Sources) Transcript(scope policy.Scope, studentID int) (*Transcript, error) {
+	if !scope.CanReadStudent(studentID) {
+		return nil, policy.ErrForbidden
+	}
+	student, err := s.student(scope, studentID)
+	if err != nil {
+		return nil, err
+	}
+	terms, err := s.Terms.GetAll(0)
+	if err != nil {
+		return nil, err
+	}
+	finals := make(map[int][]models.FinalGrade, len(terms))
+	for _, term := range terms {
+		if finals[term.ID], err = s.FinalGrades.GetByTerm(scope, term.ID, models.FinalGradeFilter{}); err != nil {
+			return nil, err
+		}
+	}
+	return s.transcript(scope, student, terms, finals)
+}
+
+// GroupCards собирает табели всех студентов группы, доступных scope, за период termID
+func (s Sources) GroupCards(scope policy.Scope, groupID, termID int) (string, []Card, error) {
+	group, students, err := s.groupStudents(scope, groupID)
+	if err != nil {
+		return "", nil, err
+	}
+	term, err := s.Terms.GetByID(termID)
+	if err != nil {
+		return "", nil, err
+	}
+	finals, err := s.FinalGrades.GetByTerm(scope, term.ID, models.FinalGradeFilter{GroupID: groupID})
+	if err != nil {
+		return "", nil, err
+	}
+
+	cards := make([]Card, 0, len(students))
+	for _, student := range students {
+		card, err := s.card(scope, student, *term, finals)
+		if err != nil {
+			return "", nil, err
+		}
+		cards = append(cards, *card)
+	}
+	return group, cards, nil
+}
+
+// GroupTranscripts собирает справки всех студентов группы, доступных scope
+func (s Sources) GroupTranscripts(scope policy.Scope, groupID int) (string, []Transcript, error) {
+	group, students, err := s.groupStudents(scope, groupID)
+	if err != nil {
+		return "", nil, err
+	}
+	terms, err := s.Terms.GetAll(0)
+	if err != nil {
+		return "", nil, err
+	}
+	finals := make(map[int][]models.FinalGrade, len(terms))
+	for _, term := range terms {
+		if finals[term.ID], err = s.FinalGrades.GetByTerm(scope, term.ID, models.FinalGradeFilter{GroupID: groupID}); err != nil {
+			return "", nil, err
+		}
+	}
+
+	transcripts := make([]Transcript, 0, len(students))
+	for _, student := range students {
+		transcript, err := s.transcript(scope, student, terms, finals)
+		if err != nil {
+			return "", nil, err
+		}
+		transcripts = append(transcripts, *transcript)
+	}
+	return group, transcripts, nil
+}
+
+// groupStudents возвращает название группы и её студентов, доступных scope, по алфавиту
+func (s Sources) groupStudents(scope policy.Scope, groupID int) (string, []Student, error) {
+	group, err := s.Groups.GetByID(groupID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var students []Student
+	for _, user := range group.Students {
+		if !scope.CanReadStudent(user.ID) {
+			continue
+		}
+		student := newStudent(user)
+		student.Group = group.Name
+		students = append(students, student)
+	}
+	if len(students) == 0 && len(group.Students) > 0 {
+		return "", nil, policy.ErrForbidden
+	}
+	sort.SliceStable(students, func(i, j int) bool { return students[i].Name < students[j].Name })
+	return group.Name, students, nil
+}
+
+func (s Sources) transcript(scope policy.Scope, student Student, terms []models.Term, finals map[int][]models.FinalGrade) (*Transcript, error) {
+	// Периоды идут в хронологическом порядке, независимо от порядка в хранилище
+	terms = append([]models.Term(nil), terms...)
+	sort.SliceStable(terms, func(i, j int) bool { return terms[i].StartDate.Before(terms[j].StartDate) })
+
+	transcript := &Transcript{Student: student}
+	var present, absent, excused, finalSum, finalCount int
+	for _, term := range terms {
+		card, err := s.card(scope, student, term, finals[term.ID])
+		if err != nil {
+			return nil, err
+		}
+		if card.Empty() {
+			continue
+		}
+		transcript.Terms = append(transcript.Terms, *card)
+		present += card.Attendance.Present
+		absent += card.Attendance.Absent
+		excused += card.Attendance.Excused
+		for _, line := range card.Subjects {
+			if line.FinalGrade != nil {
+				finalSum += *line.FinalGrade
+				finalCount++
+			}
+		}
+	}
+	transcript.Attendance = models.NewAttendanceCounts(present, absent, excused)
+	if finalCount > 0 {
+		average := roundAverage(float64(finalSum) / float64(finalCount))
+		transcript.FinalAverage = &average
+	}
+	return transcript, nil
+}
+
+// card собирает табель из статистики оценок и посещаемости за границы периода; finals — итоговые
+// оценки периода (можно для нескольких студентов — лишние отбрасываются)
+func (s Sources) card(scope policy.Scope, student Student, term models.Term, finals []models.FinalGrade) (*Card, error) {
+	gradeFilter := models.GradeStatsFilter{StudentID: student.ID, From: term.StartDate, To: term.EndDate}
+	gradeStats, err := s.Grades.Stats(scope, gradeFilter, models.StatsBySubject)
+	if err != nil {
+		return nil, err
+	}
+	attendanceFilter := models.AttendanceStatsFilter{StudentID: student.ID, From: term.StartDate, To: term.EndDate}
+	attendanceStats, err := s.Attendance.Stats(scope, attendanceFilter, models.StatsBySubject)
+	if err != nil {
+		return nil, err
+	}
+
+	card := &Card{Student: student, Term: term}
+	lines := make(map[int]*SubjectLine)
+	line := func(subjectID int, name string) *SubjectLine {
+		if l, ok := lines[subjectID]; ok {
+			return l
+		}
+		l := &SubjectLine{SubjectID: subjectID, Name: name}
+		lines[subjectID] = l
+		return l
+	}
+	for _, stat := range gradeStats {
+		l := line(stat.ID, stat.Name)
+		l.GradeCount = stat.Count
+		if stat.Count > 0 {
+			average := stat.Average
+			l.Average = &average
+		}
+	}
+	var present, absent, excused int
+	for _, stat := range attendanceStats {
+		line(stat.ID, stat.Name).Attendance = stat.AttendanceCounts
+		present += stat.Present
+		absent += stat.Absent
+		excused += stat.Excused
+	}
+	for _, final := range finals {
+		if final.StudentID != student.ID {
+			continue
+		}
+		l := line(final.SubjectID, final.SubjectName)
+		value := final.Value
+		l.FinalGrade, l.FinalLocked = &value, final.Locked
+	}
+
+	for _, l := range lines {
+		card.Subjects = append(card.Subjects, *l)
+	}
+	sort.Slice(card.Subjects, func(i, j int) bool {
+		if card.Subjects[i].Name != card.Subjects[j].Name {
+			return card.Subjects[i].Name < card.Subjects[j].Name
+		}
+		return card.Subjects[i].SubjectID < card.Subjects[j].SubjectID
+	})
+	card.Attendance = models.NewAttendanceCounts(present, absent, excused)
+
+	if len(gradeStats) > 0 {
+		overall, err := s.Grades.Stats(scope, gradeFilter, models.StatsByStudent)
+		if err != nil {
+			return nil, err
+		}
+		if len(overall) > 0 && overall[0].Count > 0 {
+			average := overall[0].Average
+			card.Average = &average
+		}
+	}
+	return card, nil
+}
+
+// roundAverage округляет средний балл до сотых, как в статистике оценок
+func roundAverage(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"sort"
+	"strings"
+)
+
+// Ошибки, общие для всех реализаций хранилищ
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+
+	ErrLoginExists = errors.New("login already exists")
+
+	ErrRoleExists        = errors.New("role already exists")
+	ErrRoleInUse         = errors.New("role is assigned to users")
+	ErrBuiltinRole       = errors.New("built-in role cannot be renamed or deleted")
+	ErrUnknownPermission = errors.New("unknown permission")
+
+	ErrSubjectExists   = errors.New("subject already exists")
+	ErrGradeTypeExists = errors.New("grade type already exists")
+
+	ErrInvalidTimeRange = errors.New("end_time must be after start_time")
+
+	ErrLessonMismatch = errors.New("lesson does not match the record's subject, date or student group")
+
+	ErrAcademicYearExists = errors.New("academic year already exists")
+	ErrTermExists         = errors.New("term already exists in this academic year")
+	ErrInvalidDateRange   = errors.New("end_date must be after start_date")
+	ErrTermOutsideYear    = errors.New("term must lie within its academic year")
+	ErrTermOverlap        = errors.New("term overlaps another term of the academic year")
+	ErrGradeLocked        = errors.New("final grade for this term is locked")
+
+	ErrNotGroupMember          = errors.New("student is not a member of the group")
+	ErrNotStudent              = errors.New("only students can be transferred between groups")
+	ErrAlreadyInGroup          = errors.New("student is already in this group")
+	ErrInvalidTransferDate     = errors.New("transfer date must be after the start of the current group membership and not in the future")
+	ErrInvalidAttendanceStatus = errors.New("status must be one of present, absent, excused")
+)
+
+// ScheduleConflictError — занятие пересекается по времени с другими занятиями
+// того же преподавателя, той же группы или в той же аудитории
+type ScheduleConflictError struct {
+	Conflicts []models.ScheduleConflict
+}
+
+func (e *ScheduleConflictError) Error() string {
+	return fmt.Sprintf("schedule conflicts with %d existing entries", len(e.Conflicts))
+}
+
+// JournalEditError — правка журнала, из-за которой сохранение отменено целиком.
+// Field — список правок (grades или attendance), Index — номер правки в нём
+type JournalEditError struct {
+	Field string
+	Index int
+	Err   error
+}
+
+func (e *JournalEditError) Error() string {
+	return fmt.Sprintf("%s[%d]: %v", e.Field, e.Index, e.Err)
+}
+
+func (e *JournalEditError) Unwrap() error {
+	return e.Err
+}
+
+// InUseError — запись нельзя удалить, пока на неё ссылаются другие таблицы.
+// Dependents содержит число ссылающихся строк по имени таблицы, только ненулевые.
+type InUseError struct {
+	Resource   string
+	Dependents map[string]int
+}
+
+// CheckInUse возвращает *InUseError с ненулевыми счётчиками или nil, если ссылок нет
+func CheckInUse(resource string, counts map[string]int) error {
+	dependents := make(map[string]int)
+	for table, count := range counts {
+		if count > 0 {
+			dependents[table] = count
+		}
+	}
+	if len(dependents) == 0 {
+		return nil
+	}
+	return &InUseError{Resource: resource, Dependents: dependents}
+}
+
+func (e *InUseError) Error() string {
+	tables := make([]string, 0, len(e.Dependents))
+	for table := range e.Dependents {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	parts := make([]string, len(tables))
+	for i, table := range tables {
+		parts[i] = fmt.Sprintf("%s: %d", table, e.Dependents[table])
+	}
+	return fmt.Sprintf("%s is still referenced (%s)", e.Resource, strings.Join(parts, ", "))
+}
@@ -0,0 +1,287 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"sort"
+	"time"
+)
+
+type attendanceRepository struct {
+	s *Store
+}
+
+// attendanceDetail повторяет выборку core.ListAttendance. Вызывать под s.mu
+func (s *Store) attendanceDetail(attendance models.Attendance) (models.AttendanceDetail, bool) {
+	student, ok := s.users[attendance.StudentID]
+	if !ok {
+		return models.AttendanceDetail{}, false
+	}
+	subject, ok := s.subjects[attendance.SubjectID]
+	if !ok {
+		return models.AttendanceDetail{}, false
+	}
+
+	return models.AttendanceDetail{
+		ID:          attendance.ID,
+		StudentID:   attendance.StudentID,
+		StudentName: student.FirstName + " " + student.LastName,
+		SubjectName: subject.Name,
+		Date:        attendance.Date,
+		Status:      attendance.Status,
+		LessonID:    attendance.LessonID,
+		CreatedAt:   attendance.CreatedAt,
+		UpdatedAt:   attendance.UpdatedAt,
+	}, true
+}
+
+var attendanceSorts = listSorts[models.AttendanceDetail]{
+	"date":         func(a models.AttendanceDetail) interface{} { return a.Date },
+	"id":           func(a models.AttendanceDetail) interface{} { return a.ID },
+	"status":       func(a models.AttendanceDetail) interface{} { return a.Status },
+	"subject_name": func(a models.AttendanceDetail) interface{} { return a.SubjectName },
+	"student_name": func(a models.AttendanceDetail) interface{} { return a.StudentName },
+	"created_at":   func(a models.AttendanceDetail) interface{} { return a.CreatedAt },
+}
+
+func (r *attendanceRepository) List(scope policy.Scope, filter models.RecordFilter, page models.ListQuery) ([]models.AttendanceDetail, models.PageInfo, error) {
+	r.s.mu.RLock()
+	var attendances []models.AttendanceDetail
+	for _, id := range sortedKeys(r.s.attendance) {
+		attendance := r.s.attendance[id]
+		if !r.s.recordMatches(scope, filter, attendance.StudentID, attendance.SubjectID, attendance.Date, attendance.Status) {
+			continue
+		}
+		if detail, ok := r.s.attendanceDetail(attendance); ok {
+			attendances = append(attendances, detail)
+		}
+	}
+	r.s.mu.RUnlock()
+
+	return listPage(attendances, attendanceSorts, func(a models.AttendanceDetail) int { return a.ID }, page)
+}
+
+func (r *attendanceRepository) GetByLessonID(scope policy.Scope, lessonID int) ([]models.AttendanceDetail, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var attendances []models.AttendanceDetail
+	for _, id := range sortedKeys(r.s.attendance) {
+		attendance := r.s.attendance[id]
+		if attendance.LessonID == nil || *attendance.LessonID != lessonID || !r.s.canAccessRecord(scope, attendance.StudentID, attendance.SubjectID, attendance.Date) {
+			continue
+		}
+		if detail, ok := r.s.attendanceDetail(attendance); ok {
+			attendances = append(attendances, detail)
+		}
+	}
+
+	// ORDER BY u.last_name, u.first_name, a.id
+	sort.SliceStable(attendances, func(i, j int) bool {
+		a, b := r.s.users[attendances[i].StudentID], r.s.users[attendances[j].StudentID]
+		if a.LastName != b.LastName {
+			return a.LastName < b.LastName
+		}
+		return a.FirstName < b.FirstName
+	})
+	return attendances, nil
+}
+
+func (r *attendanceRepository) Create(scope policy.Scope, attendance models.Attendance, reason string) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.createAttendance(scope, attendance, reason)
+}
+
+// createAttendance повторяет core.createAttendance. Вызывать под s.mu.Lock
+func (s *Store) createAttendance(scope policy.Scope, attendance models.Attendance, reason string) (int, error) {
+	if err := s.authorizeRecord(scope, attendance.StudentID, attendance.SubjectID, attendance.Date); err != nil {
+		return 0, err
+	}
+	if err := s.checkLesson(attendance.LessonID, attendance.StudentID, attendance.SubjectID, attendance.Date); err != nil {
+		return 0, err
+	}
+	attendance.ID = 0
+	if err := s.checkAttendance(attendance); err != nil {
+		return 0, fmt.Errorf("failed to create attendance: %v", err)
+	}
+
+	now := time.Now()
+	attendance.ID = s.nextID("attendance")
+	attendance.Date = truncateDate(attendance.Date)
+	attendance.CreatedAt = now
+	attendance.UpdatedAt = now
+	s.attendance[attendance.ID] = attendance
+	s.logChange(scope, models.RecordAttendance, attendance.ID, models.ChangeCreate, nil, attendanceRow(attendance), reason)
+
+	return attendance.ID, nil
+}
+
+func (r *attendanceRepository) Update(scope policy.Scope, attendance models.Attendance, reason string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.updateAttendance(scope, attendance, reason)
+}
+
+// updateAttendance повторяет core.updateAttendance. Вызывать под s.mu.Lock
+func (s *Store) updateAttendance(scope policy.Scope, attendance models.Attendance, reason string) error {
+	existing, ok := s.attendance[attendance.ID]
+	if !ok {
+		return fmt.Errorf("attendance not found")
+	}
+	if err := s.authorizeRecord(scope, existing.StudentID, existing.SubjectID, existing.Date); err != nil {
+		return err
+	}
+	if err := s.authorizeRecord(scope, attendance.StudentID, attendance.SubjectID, attendance.Date); err != nil {
+		return err
+	}
+	if err := s.checkLesson(attendance.LessonID, attendance.StudentID, attendance.SubjectID, attendance.Date); err != nil {
+		return err
+	}
+	if err := s.checkAttendance(attendance); err != nil {
+		return fmt.Errorf("failed to update attendance: %v", err)
+	}
+
+	old := attendanceRow(existing)
+	existing.StudentID = attendance.StudentID
+	existing.SubjectID = attendance.SubjectID
+	existing.LessonID = attendance.LessonID
+	existing.Date = truncateDate(attendance.Date)
+	existing.Status = attendance.Status
+	existing.UpdatedAt = time.Now()
+	s.attendance[attendance.ID] = existing
+	s.logChange(scope, models.RecordAttendance, attendance.ID, models.ChangeUpdate, old, attendanceRow(existing), reason)
+
+	return nil
+}
+
+// RollCall повторяет core.RollCall; всё выполняется под одной блокировкой, как в транзакции
+func (r *attendanceRepository) RollCall(scope policy.Scope, call models.RollCall, reason string) (*models.RollCallResult, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if call.LessonID != nil {
+		lesson, ok := r.s.lessons[*call.LessonID]
+		if !ok {
+			return nil, fmt.Errorf("lesson not found")
+		}
+		if (call.GroupID != 0 && call.GroupID != lesson.GroupID) || (call.SubjectID != 0 && call.SubjectID != lesson.SubjectID) ||
+			(!call.Date.IsZero() && !truncateDate(call.Date).Equal(lesson.Date)) {
+			return nil, repository.ErrLessonMismatch
+		}
+		call.GroupID, call.SubjectID, call.Date = lesson.GroupID, lesson.SubjectID, lesson.Date
+	}
+	if _, ok := r.s.groups[call.GroupID]; !ok {
+		return nil, fmt.Errorf("group not found")
+	}
+	if _, ok := r.s.subjects[call.SubjectID]; !ok {
+		return nil, fmt.Errorf("subject not found")
+	}
+
+	result := &models.RollCallResult{Created: []int{}, Updated: []int{}, Rejected: []models.RollCallRejection{}}
+	statuses := make(map[int]string)
+	for _, studentID := range sortedKeys(call.Statuses) {
+		status := call.Statuses[studentID]
+		_, ok := r.s.users[studentID]
+		switch {
+		case !ok || !r.s.memberAt(studentID, call.GroupID, call.Date):
+			result.Rejected = append(result.Rejected, models.RollCallRejection{StudentID: studentID, Reason: repository.ErrNotGroupMember.Error()})
+		case status != "present" && status != "absent" && status != "excused":
+			result.Rejected = append(result.Rejected, models.RollCallRejection{StudentID: studentID, Reason: repository.ErrInvalidAttendanceStatus.Error()})
+		default:
+			statuses[studentID] = status
+		}
+	}
+	if call.DefaultPresent {
+		for _, id := range sortedKeys(r.s.users) {
+			if _, listed := call.Statuses[id]; !listed && r.s.memberAt(id, call.GroupID, call.Date) {
+				statuses[id] = "present"
+			}
+		}
+	}
+
+	date := truncateDate(call.Date)
+	now := time.Now()
+	for _, studentID := range sortedKeys(statuses) {
+		if err := r.s.authorizeRecord(scope, studentID, call.SubjectID, call.Date); err != nil {
+			result.Rejected = append(result.Rejected, models.RollCallRejection{StudentID: studentID, Reason: err.Error()})
+			continue
+		}
+
+		// Отметка на этом занятии, а если её нет — отметка без занятия по предмету за день
+		existing, found := 0, false
+		for _, id := range sortedKeys(r.s.attendance) {
+			attendance := r.s.attendance[id]
+			if attendance.StudentID != studentID {
+				continue
+			}
+			if call.LessonID != nil && attendance.LessonID != nil && *attendance.LessonID == *call.LessonID {
+				existing, found = id, true
+				break
+			}
+			// Как ORDER BY lesson_id IS NULL, id: отметка на занятии важнее отметки без него
+			if (call.LessonID == nil || attendance.LessonID == nil) && attendance.SubjectID == call.SubjectID && attendance.Date.Equal(date) &&
+				(!found || r.s.attendance[existing].LessonID == nil && attendance.LessonID != nil) {
+				existing, found = id, true
+			}
+		}
+
+		if found {
+			attendance := r.s.attendance[existing]
+			old := attendanceRow(attendance)
+			attendance.Status = statuses[studentID]
+			if call.LessonID != nil {
+				attendance.LessonID = call.LessonID
+			}
+			attendance.UpdatedAt = now
+			r.s.attendance[existing] = attendance
+			r.s.logChange(scope, models.RecordAttendance, existing, models.ChangeUpdate, old, attendanceRow(attendance), reason)
+			result.Updated = append(result.Updated, studentID)
+			continue
+		}
+
+		attendance := models.Attendance{
+			ID:        r.s.nextID("attendance"),
+			StudentID: studentID,
+			SubjectID: call.SubjectID,
+			Date:      date,
+			Status:    statuses[studentID],
+			LessonID:  call.LessonID,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		r.s.attendance[attendance.ID] = attendance
+		r.s.logChange(scope, models.RecordAttendance, attendance.ID, models.ChangeCreate, nil, attendanceRow(attendance), reason)
+		result.Created = append(result.Created, studentID)
+	}
+
+	return result, nil
+}
+
+// checkAttendance повторяет внешние ключи, UNIQUE (lesson_id, student_id) и CHECK таблицы attendance. Вызывать под s.mu
+func (s *Store) checkAttendance(attendance models.Attendance) error {
+	if _, ok := s.users[attendance.StudentID]; !ok {
+		return fmt.Errorf("user %d does not exist", attendance.StudentID)
+	}
+	if _, ok := s.subjects[attendance.SubjectID]; !ok {
+		return fmt.Errorf("subject %d does not exist", attendance.SubjectID)
+	}
+	if attendance.LessonID != nil {
+		for _, other := range s.attendance {
+			if other.ID != attendance.ID && other.LessonID != nil && *other.LessonID == *attendance.LessonID &&
+				other.StudentID == attendance.StudentID {
+				return fmt.Errorf("duplicate key value violates unique constraint \"attendance_lesson_student_key\"")
+			}
+		}
+	}
+	switch attendance.Status {
+	case "present", "absent", "excused":
+	default:
+		return fmt.Errorf("status must be one of present, absent, excused")
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"sort"
+)
+
+// statsRecords возвращает отметки в пределах scope и фильтра, как core.attendanceStatsQuery. Вызывать под s.mu
+func (s *Store) statsRecords(scope policy.Scope, filter models.AttendanceStatsFilter) []models.Attendance {
+	var records []models.Attendance
+	for _, id := range sortedKeys(s.attendance) {
+		attendance := s.attendance[id]
+		_, ok := s.users[attendance.StudentID]
+		if !ok || !s.canAccessRecord(scope, attendance.StudentID, attendance.SubjectID, attendance.Date) {
+			continue
+		}
+		if (filter.GroupID != 0 && !s.memberAt(attendance.StudentID, filter.GroupID, attendance.Date)) ||
+			(filter.StudentID != 0 && attendance.StudentID != filter.StudentID) ||
+			(filter.SubjectID != 0 && attendance.SubjectID != filter.SubjectID) ||
+			(!filter.From.IsZero() && attendance.Date.Before(truncateDate(filter.From))) ||
+			(!filter.To.IsZero() && attendance.Date.After(truncateDate(filter.To))) {
+			continue
+		}
+		records = append(records, attendance)
+	}
+	return records
+}
+
+// statusCounts копит отметки по статусам
+type statusCounts struct {
+	present, absent, excused int
+}
+
+func (c *statusCounts) add(status string) {
+	switch status {
+	case "present":
+		c.present++
+	case "absent":
+		c.absent++
+	case "excused":
+		c.excused++
+	}
+}
+
+func (r *attendanceRepository) Stats(scope policy.Scope, filter models.AttendanceStatsFilter, by string) ([]models.AttendanceStat, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	return r.s.attendanceStats(scope, filter, by)
+}
+
+// attendanceStats повторяет core.GetAttendanceStats. Вызывать под s.mu
+func (s *Store) attendanceStats(scope policy.Scope, filter models.AttendanceStatsFilter, by string) ([]models.AttendanceStat, error) {
+	switch by {
+	case models.StatsByStudent, models.StatsBySubject, models.StatsByGroup:
+	default:
+		return nil, fmt.Errorf("unknown attendance stats grouping %q", by)
+	}
+
+	counts := make(map[int]*statusCounts)
+	names := make(map[int]string)
+	for _, attendance := range s.statsRecords(scope, filter) {
+		var key int
+		switch by {
+		case models.StatsByStudent:
+			student := s.users[attendance.StudentID]
+			key = student.ID
+			names[key] = student.FirstName + " " + student.LastName
+		case models.StatsBySubject:
+			key = attendance.SubjectID
+			names[key] = s.subjects[key].Name
+		case models.StatsByGroup:
+			groupID, ok := s.groupAt(attendance.StudentID, attendance.Date)
+			if !ok {
+				continue
+			}
+			group, ok := s.groups[groupID]
+			if !ok {
+				continue
+			}
+			key = group.ID
+			names[key] = group.Name
+		}
+		if counts[key] == nil {
+			counts[key] = &statusCounts{}
+		}
+		counts[key].add(attendance.Status)
+	}
+
+	stats := []models.AttendanceStat{}
+	for _, key := range sortedKeys(counts) {
+		c := counts[key]
+		stats = append(stats, models.AttendanceStat{
+			ID:               key,
+			Name:             names[key],
+			AttendanceCounts: models.NewAttendanceCounts(c.present, c.absent, c.excused),
+		})
+	}
+	// ORDER BY name, id
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats, nil
+}
+
+func (r *attendanceRepository) Monthly(scope policy.Scope, filter models.AttendanceStatsFilter) ([]models.AttendanceMonth, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	counts := make(map[string]*statusCounts)
+	for _, attendance := range r.s.statsRecords(scope, filter) {
+		month := attendance.Date.Format("2006-01")
+		if counts[month] == nil {
+			counts[month] = &statusCounts{}
+		}
+		counts[month].add(attendance.Status)
+	}
+
+	months := []models.AttendanceMonth{}
+	for month, c := range counts {
+		months = append(months, models.AttendanceMonth{
+			Month:            month,
+			AttendanceCounts: models.NewAttendanceCounts(c.present, c.absent, c.excused),
+		})
+	}
+	sort.Slice(months, func(i, j int) bool { return months[i].Month < months[j].Month })
+	return months, nil
+}
+
+func (r *attendanceRepository) Absentees(scope policy.Scope, filter models.AttendanceStatsFilter, threshold float64) ([]models.AttendanceStat, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	stats, err := r.s.attendanceStats(scope, filter, models.StatsByStudent)
+	if err != nil {
+		return nil, err
+	}
+	return repository.FilterAbsentees(stats, threshold), nil
+}
@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"errors"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"slices"
+	"testing"
+)
+
+// ID из SeedDemo
+const (
+	seedGroupID    = 1
+	seedMathID     = 1
+	seedStudent1ID = 3
+	seedStudent2ID = 4
+)
+
+// seedLesson возвращает занятие группы по математике, на которое в демо-данных ещё нет отметок
+func seedLesson(t *testing.T, store *Store) models.Lesson {
+	t.Helper()
+	for _, id := range sortedKeys(store.lessons) {
+		lesson := store.lessons[id]
+		if lesson.GroupID != seedGroupID || lesson.SubjectID != seedMathID {
+			continue
+		}
+		marked := false
+		for _, attendance := range store.attendance {
+			marked = marked || attendance.Date.Equal(lesson.Date)
+		}
+		if !marked {
+			return lesson
+		}
+	}
+	t.Fatal("no unmarked math lesson in the seed data")
+	return models.Lesson{}
+}
+
+// marks возвращает отметки студента по предмету за день
+func marks(store *Store, studentID, subjectID int, lesson models.Lesson) []models.Attendance {
+	var result []models.Attendance
+	for _, id := range sortedKeys(store.attendance) {
+		if a := store.attendance[id]; a.StudentID == studentID && a.SubjectID == subjectID && a.Date.Equal(lesson.Date) {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+func TestRollCall(t *testing.T) {
+	store := New()
+	if err := store.SeedDemo(); err != nil {
+		t.Fatalf("SeedDemo: %v", err)
+	}
+	repo := store.Repositories().Attendance
+	lesson := seedLesson(t, store)
+
+	// Перекличка по группе, предмету и дате: чужой студент и неверный статус отклоняются
+	result, err := repo.RollCall(policy.System(), models.RollCall{
+		GroupID: seedGroupID, SubjectID: seedMathID, Date: lesson.Date,
+		Statuses: map[int]string{seedStudent1ID: "absent", seedStudent2ID: "late", 99: "present"},
+	}, "")
+	if err != nil {
+		t.Fatalf("RollCall by date: %v", err)
+	}
+	if !slices.Equal(result.Created, []int{seedStudent1ID}) || len(result.Updated) != 0 {
+		t.Errorf("by date: created %v, updated %v, want created [3]", result.Created, result.Updated)
+	}
+	rejected := map[int]string{}
+	for _, r := range result.Rejected {
+		rejected[r.StudentID] = r.Reason
+	}
+	if rejected[seedStudent2ID] != repository.ErrInvalidAttendanceStatus.Error() || rejected[99] != repository.ErrNotGroupMember.Error() || len(rejected) != 2 {
+		t.Errorf("by date: rejected %v", result.Rejected)
+	}
+
+	// Та же перекличка по занятию обновляет отметку без занятия и привязывает её, а не создаёт вторую
+	call := models.RollCall{LessonID: &lesson.ID, Statuses: map[int]string{seedStudent1ID: "excused"}, DefaultPresent: true}
+	result, err = repo.RollCall(policy.System(), call, "")
+	if err != nil {
+		t.Fatalf("RollCall by lesson: %v", err)
+	}
+	if !slices.Equal(result.Updated, []int{seedStudent1ID}) || !slices.Equal(result.Created, []int{seedStudent2ID}) || len(result.Rejected) != 0 {
+		t.Errorf("by lesson: created %v, updated %v, rejected %v", result.Created, result.Updated, result.Rejected)
+	}
+	for studentID, status := range map[int]string{seedStudent1ID: "excused", seedStudent2ID: "present"} {
+		got := marks(store, studentID, seedMathID, lesson)
+		if len(got) != 1 || got[0].Status != status || got[0].LessonID == nil || *got[0].LessonID != lesson.ID {
+			t.Errorf("student %d: marks %+v, want one %s mark on lesson %d", studentID, got, status, lesson.ID)
+		}
+	}
+
+	// Повтор только обновляет
+	result, err = repo.RollCall(policy.System(), call, "")
+	if err != nil {
+		t.Fatalf("repeated RollCall: %v", err)
+	}
+	if !slices.Equal(result.Updated, []int{seedStudent1ID, seedStudent2ID}) || len(result.Created) != 0 {
+		t.Errorf("repeated: created %v, updated %v", result.Created, result.Updated)
+	}
+
+	// Перекличка по дате после привязки находит отметку на занятии и не теряет lesson_id
+	result, err = repo.RollCall(policy.System(), models.RollCall{
+		GroupID: seedGroupID, SubjectID: seedMathID, Date: lesson.Date, Statuses: map[int]string{seedStudent1ID: "present"},
+	}, "")
+	if err != nil {
+		t.Fatalf("RollCall by date after lesson: %v", err)
+	}
+	got := marks(store, seedStudent1ID, seedMathID, lesson)
+	if !slices.Equal(result.Updated, []int{seedStudent1ID}) || len(got) != 1 || got[0].Status != "present" || got[0].LessonID == nil {
+		t.Errorf("by date after lesson: updated %v, marks %+v", result.Updated, got)
+	}
+}
+
+func TestRollCallRejectsLesson(t *testing.T) {
+	store := New()
+	if err := store.SeedDemo(); err != nil {
+		t.Fatalf("SeedDemo: %v", err)
+	}
+	repo := store.Repositories().Attendance
+	lesson := seedLesson(t, store)
+	statuses := map[int]string{seedStudent1ID: "present"}
+
+	_, err := repo.RollCall(policy.System(), models.RollCall{LessonID: &lesson.ID, SubjectID: lesson.SubjectID + 1, Statuses: statuses}, "")
+	if !errors.Is(err, repository.ErrLessonMismatch) {
+		t.Errorf("other subject: error = %v, want ErrLessonMismatch", err)
+	}
+	_, err = repo.RollCall(policy.System(), models.RollCall{LessonID: &lesson.ID, Date: lesson.Date.AddDate(0, 0, 1), Statuses: statuses}, "")
+	if !errors.Is(err, repository.ErrLessonMismatch) {
+		t.Errorf("other date: error = %v, want ErrLessonMismatch", err)
+	}
+	missing := 1 << 20
+	if _, err := repo.RollCall(policy.System(), models.RollCall{LessonID: &missing, Statuses: statuses}, ""); err == nil || err.Error() != "lesson not found" {
+		t.Errorf("unknown lesson: error = %v, want lesson not found", err)
+	}
+	if len(marks(store, seedStudent1ID, lesson.SubjectID, lesson)) != 0 {
+		t.Errorf("rejected roll call saved marks")
+	}
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"github.com/VladislavSCV/internal/models"
+	"time"
+)
+
+type auditRepository struct {
+	s *Store
+}
+
+func (r *auditRepository) Record(entry models.AuditEntry) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	// Как core.CreateAuditEntry: без переданного логина берётся текущий логин пользователя
+	if entry.ActorLogin == "" && entry.ActorID != nil {
+		entry.ActorLogin = r.s.users[*entry.ActorID].Login
+	}
+	entry.ID = r.s.nextID("audit_log")
+	entry.CreatedAt = time.Now()
+	r.s.auditLog = append(r.s.auditLog, entry)
+
+	return nil
+}
+
+func (r *auditRepository) Get(filter models.AuditFilter) ([]models.AuditEntry, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	entries := []models.AuditEntry{}
+	for i := len(r.s.auditLog) - 1; i >= 0; i-- {
+		entry := r.s.auditLog[i]
+		if filter.ActorID != 0 && (entry.ActorID == nil || *entry.ActorID != filter.ActorID) {
+			continue
+		}
+		if filter.EntityType != "" && entry.EntityType != filter.EntityType {
+			continue
+		}
+		if filter.EntityID != 0 && (entry.EntityID == nil || *entry.EntityID != filter.EntityID) {
+			continue
+		}
+		if filter.Action != "" && entry.Action != filter.Action {
+			continue
+		}
+		if !filter.From.IsZero() && entry.CreatedAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !entry.CreatedAt.Before(filter.To) {
+			continue
+		}
+		entries = append(entries, entry)
+		if filter.Limit > 0 && len(entries) == filter.Limit {
+			break
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,44 @@
+package memory
+
+import "fmt"
+
+type calendarTokenRepository struct {
+	s *Store
+}
+
+func (r *calendarTokenRepository) Set(userID int, tokenHash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.users[userID]; !ok {
+		return fmt.Errorf("failed to save calendar token: user %d does not exist", userID)
+	}
+	for owner, hash := range r.s.calendarTokens {
+		if hash == tokenHash && owner != userID {
+			return fmt.Errorf("failed to save calendar token: duplicate key value violates unique constraint \"calendar_tokens_token_hash_key\"")
+		}
+	}
+
+	r.s.calendarTokens[userID] = tokenHash
+	return nil
+}
+
+func (r *calendarTokenRepository) Delete(userID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.calendarTokens, userID)
+	return nil
+}
+
+func (r *calendarTokenRepository) GetUserID(tokenHash string) (int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for userID, hash := range r.s.calendarTokens {
+		if hash == tokenHash {
+			return userID, nil
+		}
+	}
+	return 0, fmt.Errorf("calendar token not found")
+}
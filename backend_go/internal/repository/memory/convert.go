@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Значения в map-обновлениях приходят из JSON: числа как float64, строки, null
+
+func toInt(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		if v != float64(int(v)) {
+			return 0, fmt.Errorf("invalid input syntax for type integer: %v", v)
+		}
+		return int(v), nil
+	case string:
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("invalid input syntax for type integer: %q", v)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("invalid input syntax for type integer: %v", value)
+	}
+}
+
+func toString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", fmt.Errorf("null value violates not-null constraint")
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"sort"
+	"time"
+)
+
+// recordMatches проверяет доступ scope к оценке или отметке и условия filter, как core.recordWhere;
+// status пустой у оценок. Вызывать под s.mu
+func (s *Store) recordMatches(scope policy.Scope, filter models.RecordFilter, studentID, subjectID int, date time.Time, status string) bool {
+	if _, ok := s.users[studentID]; !ok || !s.canAccessRecord(scope, studentID, subjectID, date) {
+		return false
+	}
+	switch {
+	case filter.StudentID != 0 && studentID != filter.StudentID,
+		filter.GroupID != 0 && !s.memberAt(studentID, filter.GroupID, date),
+		filter.SubjectID != 0 && subjectID != filter.SubjectID,
+		!filter.From.IsZero() && date.Before(filter.From),
+		!filter.To.IsZero() && !date.Before(filter.To),
+		filter.Status != "" && status != "" && status != filter.Status:
+		return false
+	}
+	return true
+}
+
+// exportLess повторяет порядок core.ExportGrades: дата, фамилия и имя студента, ID. Вызывать под s.mu
+func (s *Store) exportLess(dateA, dateB string, studentA, studentB, idA, idB int) bool {
+	if dateA != dateB {
+		return dateA < dateB
+	}
+	a, b := s.users[studentA], s.users[studentB]
+	if a.LastName != b.LastName {
+		return a.LastName < b.LastName
+	}
+	if a.FirstName != b.FirstName {
+		return a.FirstName < b.FirstName
+	}
+	return idA < idB
+}
+
+// Export повторяет core.ExportGrades. Данные и так в памяти, поэтому выборка копируется под блокировкой,
+// а fn вызывается уже без неё, чтобы медленный клиент не держал хранилище
+func (r *gradeRepository) Export(scope policy.Scope, filter models.RecordFilter, fn func(models.GradeDetail) error) error {
+	r.s.mu.RLock()
+	var grades []models.GradeDetail
+	for _, id := range sortedKeys(r.s.grades) {
+		grade := r.s.grades[id]
+		if !r.s.recordMatches(scope, filter, grade.StudentID, grade.SubjectID, grade.Date, "") {
+			continue
+		}
+		if detail, ok := r.s.gradeDetail(grade); ok {
+			grades = append(grades, detail)
+		}
+	}
+	sort.SliceStable(grades, func(i, j int) bool {
+		return r.s.exportLess(grades[i].Date, grades[j].Date, grades[i].StudentID, grades[j].StudentID, grades[i].ID, grades[j].ID)
+	})
+	r.s.mu.RUnlock()
+
+	for _, grade := range grades {
+		if err := fn(grade); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Export повторяет core.ExportAttendance
+func (r *attendanceRepository) Export(scope policy.Scope, filter models.RecordFilter, fn func(models.AttendanceDetail) error) error {
+	r.s.mu.RLock()
+	var attendances []models.AttendanceDetail
+	for _, id := range sortedKeys(r.s.attendance) {
+		attendance := r.s.attendance[id]
+		if !r.s.recordMatches(scope, filter, attendance.StudentID, attendance.SubjectID, attendance.Date, attendance.Status) {
+			continue
+		}
+		if detail, ok := r.s.attendanceDetail(attendance); ok {
+			attendances = append(attendances, detail)
+		}
+	}
+	sort.SliceStable(attendances, func(i, j int) bool {
+		a, b := attendances[i], attendances[j]
+		return r.s.exportLess(a.Date.Format("2006-01-02"), b.Date.Format("2006-01-02"), a.StudentID, b.StudentID, a.ID, b.ID)
+	})
+	r.s.mu.RUnlock()
+
+	for _, attendance := range attendances {
+		if err := fn(attendance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Export повторяет core.ExportUsers; r.list отпускает блокировку до вызовов fn
+func (r *userRepository) Export(scope policy.Scope, filter models.UserFilter, fn func(models.User) error) error {
+	users := r.list(&scope, filter)
+	sort.SliceStable(users, func(i, j int) bool {
+		a, b := users[i], users[j]
+		if a.LastName != b.LastName {
+			return a.LastName < b.LastName
+		}
+		if a.FirstName != b.FirstName {
+			return a.FirstName < b.FirstName
+		}
+		return a.ID < b.ID
+	})
+
+	for _, user := range users {
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Export повторяет core.ExportSchedules
+func (r *scheduleRepository) Export(filter models.ScheduleFilter, fn func(models.Schedule) error) error {
+	schedules := r.list(scheduleMatches(filter))
+	sort.SliceStable(schedules, func(i, j int) bool {
+		a, b := schedules[i], schedules[j]
+		if a.GroupName != b.GroupName {
+			return a.GroupName < b.GroupName
+		}
+		if a.DayOfWeek != b.DayOfWeek {
+			return a.DayOfWeek < b.DayOfWeek
+		}
+		if a.StartTime != b.StartTime {
+			return a.StartTime < b.StartTime
+		}
+		return a.ID < b.ID
+	})
+
+	for _, schedule := range schedules {
+		if err := fn(schedule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
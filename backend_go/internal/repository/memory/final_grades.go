@@ -0,0 +1,260 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"slices"
+	"sort"
+	"time"
+)
+
+type finalGradeRepository struct {
+	s *Store
+}
+
+// finalGradeView дополняет итоговую оценку именами студента и предмета. Вызывать под s.mu
+func (s *Store) finalGradeView(grade models.FinalGrade) models.FinalGrade {
+	student := s.users[grade.StudentID]
+	grade.StudentName = student.FirstName + " " + student.LastName
+	grade.SubjectName = s.subjects[grade.SubjectID].Name
+	return grade
+}
+
+// listFinalGrades повторяет core.GetFinalGrades без проверки периода. Вызывать под s.mu
+func (s *Store) listFinalGrades(scope policy.Scope, termID int, filter models.FinalGradeFilter) []models.FinalGrade {
+	grades := []models.FinalGrade{}
+	for _, id := range sortedKeys(s.finalGrades) {
+		grade := s.finalGrades[id]
+		if grade.TermID != termID || !s.canAccessRecord(scope, grade.StudentID, grade.SubjectID, time.Now()) {
+			continue
+		}
+		if filter.SubjectID != 0 && grade.SubjectID != filter.SubjectID {
+			continue
+		}
+		if student := s.users[grade.StudentID]; filter.GroupID != 0 && (student.GroupID == nil || *student.GroupID != filter.GroupID) {
+			continue
+		}
+		grades = append(grades, s.finalGradeView(grade))
+	}
+
+	sort.SliceStable(grades, func(i, j int) bool {
+		a, b := grades[i], grades[j]
+		if a.SubjectName != b.SubjectName {
+			return a.SubjectName < b.SubjectName
+		}
+		studentA, studentB := s.users[a.StudentID], s.users[b.StudentID]
+		if studentA.LastName != studentB.LastName {
+			return studentA.LastName < studentB.LastName
+		}
+		return studentA.FirstName < studentB.FirstName
+	})
+	return grades
+}
+
+func (r *finalGradeRepository) GetByTerm(scope policy.Scope, termID int, filter models.FinalGradeFilter) ([]models.FinalGrade, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	if _, ok := r.s.terms[termID]; !ok {
+		return nil, fmt.Errorf("term not found")
+	}
+	return r.s.listFinalGrades(scope, termID, filter), nil
+}
+
+func (r *finalGradeRepository) GetByID(scope policy.Scope, finalGradeID int) (*models.FinalGrade, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	grade, ok := r.s.finalGrades[finalGradeID]
+	if !ok || !r.s.canAccessRecord(scope, grade.StudentID, grade.SubjectID, time.Now()) {
+		return nil, fmt.Errorf("final grade not found")
+	}
+	grade = r.s.finalGradeView(grade)
+	return &grade, nil
+}
+
+// authorizeGroupSubject повторяет core.authorizeGroupSubject. Вызывать под s.mu
+func (s *Store) authorizeGroupSubject(scope policy.Scope, groupID, subjectID int) ([]int, error) {
+	if err := s.checkGroupSubject(groupID, subjectID); err != nil {
+		return nil, err
+	}
+	var students []int
+	for _, id := range sortedKeys(s.users) {
+		if user := s.users[id]; user.GroupID != nil && *user.GroupID == groupID {
+			if err := s.authorizeRecord(scope, id, subjectID, time.Now()); err != nil {
+				return nil, err
+			}
+			students = append(students, id)
+		}
+	}
+	return students, nil
+}
+
+func (r *finalGradeRepository) Compute(scope policy.Scope, termID, groupID, subjectID int) ([]models.FinalGrade, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	term, ok := r.s.terms[termID]
+	if !ok {
+		return nil, fmt.Errorf("term not found")
+	}
+	students, err := r.s.authorizeGroupSubject(scope, groupID, subjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Итог считается по всем оценкам студента за период, в том числе поставленным до перевода в группу
+	stats, err := r.s.gradeStats(policy.System(), models.GradeStatsFilter{
+		SubjectID: subjectID,
+		From:      term.StartDate,
+		To:        term.EndDate,
+	}, models.StatsByStudent)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, stat := range stats {
+		if !slices.Contains(students, stat.ID) {
+			continue
+		}
+		suggested := stat.Average
+		grade := models.FinalGrade{
+			TermID:    termID,
+			StudentID: stat.ID,
+			SubjectID: subjectID,
+			Suggested: &suggested,
+			Value:     models.SuggestFinalGrade(stat.Average),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+
+		// ON CONFLICT (term_id, student_id, subject_id) DO UPDATE ... WHERE NOT locked
+		if existing, ok := r.s.findFinalGrade(termID, stat.ID, subjectID); ok {
+			if existing.Locked {
+				continue
+			}
+			existing.Suggested = grade.Suggested
+			existing.Value = grade.Value
+			existing.UpdatedAt = now
+			r.s.finalGrades[existing.ID] = existing
+			continue
+		}
+		grade.ID = r.s.nextID("final_grades")
+		r.s.finalGrades[grade.ID] = grade
+	}
+
+	return r.s.listFinalGrades(scope, termID, models.FinalGradeFilter{GroupID: groupID, SubjectID: subjectID}), nil
+}
+
+// findFinalGrade ищет итоговую оценку по ключу UNIQUE (term_id, student_id, subject_id). Вызывать под s.mu
+func (s *Store) findFinalGrade(termID, studentID, subjectID int) (models.FinalGrade, bool) {
+	for _, grade := range s.finalGrades {
+		if grade.TermID == termID && grade.StudentID == studentID && grade.SubjectID == subjectID {
+			return grade, true
+		}
+	}
+	return models.FinalGrade{}, false
+}
+
+func (r *finalGradeRepository) Lock(scope policy.Scope, termID, groupID, subjectID int) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.terms[termID]; !ok {
+		return 0, fmt.Errorf("term not found")
+	}
+	if _, err := r.s.authorizeGroupSubject(scope, groupID, subjectID); err != nil {
+		return 0, err
+	}
+
+	var lockedBy *int
+	if scope.UserID != 0 {
+		id := scope.UserID
+		lockedBy = &id
+	}
+
+	now := time.Now()
+	locked := 0
+	for _, id := range sortedKeys(r.s.finalGrades) {
+		grade := r.s.finalGrades[id]
+		student := r.s.users[grade.StudentID]
+		if grade.TermID != termID || grade.SubjectID != subjectID || grade.Locked ||
+			student.GroupID == nil || *student.GroupID != groupID {
+			continue
+		}
+		lockedAt := now
+		grade.Locked = true
+		grade.LockedAt = &lockedAt
+		grade.LockedBy = lockedBy
+		grade.UpdatedAt = now
+		r.s.finalGrades[id] = grade
+		locked++
+	}
+
+	return locked, nil
+}
+
+func (r *finalGradeRepository) Update(scope policy.Scope, finalGradeID, value int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	grade, ok := r.s.finalGrades[finalGradeID]
+	if !ok {
+		return fmt.Errorf("final grade not found")
+	}
+	if err := r.s.authorizeRecord(scope, grade.StudentID, grade.SubjectID, time.Now()); err != nil {
+		return err
+	}
+	if grade.Locked && !scope.Override {
+		return repository.ErrGradeLocked
+	}
+	if value < models.MinGradeValue || value > models.MaxGradeValue {
+		return fmt.Errorf("failed to update final grade: value must be between 2 and 5")
+	}
+
+	grade.Value = value
+	grade.UpdatedAt = time.Now()
+	r.s.finalGrades[finalGradeID] = grade
+
+	return nil
+}
+
+func (r *finalGradeRepository) Unlock(scope policy.Scope, finalGradeID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if !scope.Override {
+		return policy.ErrForbidden
+	}
+	grade, ok := r.s.finalGrades[finalGradeID]
+	if !ok {
+		return fmt.Errorf("final grade not found")
+	}
+
+	grade.Locked = false
+	grade.LockedAt = nil
+	grade.LockedBy = nil
+	grade.UpdatedAt = time.Now()
+	r.s.finalGrades[finalGradeID] = grade
+
+	return nil
+}
+
+// checkGradeUnlocked повторяет core.checkGradeUnlocked. Вызывать под s.mu
+func (s *Store) checkGradeUnlocked(scope policy.Scope, studentID, subjectID int, date time.Time) error {
+	if scope.Override {
+		return nil
+	}
+	for _, grade := range s.finalGrades {
+		if grade.StudentID != studentID || grade.SubjectID != subjectID || !grade.Locked {
+			continue
+		}
+		if term, ok := s.terms[grade.TermID]; ok && term.Covers(date) {
+			return repository.ErrGradeLocked
+		}
+	}
+	return nil
+}
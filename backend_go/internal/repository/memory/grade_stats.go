@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"sort"
+)
+
+// gradeTeachers повторяет core.gradeTeachers: преподаватель занятия или все преподаватели предмета
+// по расписанию группы, в которой студент был в день оценки. Вызывать под s.mu
+func (s *Store) gradeTeachers(grade models.Grade) []int {
+	if grade.LessonID != nil {
+		if lesson, ok := s.lessons[*grade.LessonID]; ok {
+			return []int{lesson.TeacherID}
+		}
+		return nil
+	}
+
+	groupID, ok := s.groupAt(grade.StudentID, grade.Date)
+	if !ok {
+		return nil
+	}
+	seen := make(map[int]bool)
+	var teachers []int
+	for _, id := range sortedKeys(s.schedules) {
+		schedule := s.schedules[id]
+		if schedule.GroupID == groupID && schedule.SubjectID == grade.SubjectID && !seen[schedule.TeacherID] {
+			seen[schedule.TeacherID] = true
+			teachers = append(teachers, schedule.TeacherID)
+		}
+	}
+	return teachers
+}
+
+func (r *gradeRepository) Stats(scope policy.Scope, filter models.GradeStatsFilter, by string) ([]models.GradeStat, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	return r.s.gradeStats(scope, filter, by)
+}
+
+// gradeStats повторяет core.GetGradeStats. Вызывать под s.mu
+func (s *Store) gradeStats(scope policy.Scope, filter models.GradeStatsFilter, by string) ([]models.GradeStat, error) {
+	switch by {
+	case models.StatsByStudent, models.StatsBySubject, models.StatsByGroup, models.StatsByTeacher:
+	default:
+		return nil, fmt.Errorf("unknown grade stats grouping %q", by)
+	}
+
+	values := make(map[int][]int)
+	weights := make(map[int][]float64)
+	names := make(map[int]string)
+	for _, id := range sortedKeys(s.grades) {
+		grade := s.grades[id]
+		student, ok := s.users[grade.StudentID]
+		if !ok || !s.canAccessRecord(scope, grade.StudentID, grade.SubjectID, grade.Date) {
+			continue
+		}
+		subject, ok := s.subjects[grade.SubjectID]
+		if !ok {
+			continue
+		}
+		teachers := s.gradeTeachers(grade)
+		if (filter.GroupID != 0 && !s.memberAt(grade.StudentID, filter.GroupID, grade.Date)) ||
+			(filter.StudentID != 0 && grade.StudentID != filter.StudentID) ||
+			(filter.SubjectID != 0 && grade.SubjectID != filter.SubjectID) ||
+			(filter.TeacherID != 0 && !containsInt(teachers, filter.TeacherID)) ||
+			(!filter.From.IsZero() && grade.Date.Before(truncateDate(filter.From))) ||
+			(!filter.To.IsZero() && grade.Date.After(truncateDate(filter.To))) {
+			continue
+		}
+
+		weight := s.gradeWeight(grade)
+		add := func(key int, name string) {
+			values[key] = append(values[key], grade.Value)
+			weights[key] = append(weights[key], weight)
+			names[key] = name
+		}
+		switch by {
+		case models.StatsByStudent:
+			add(student.ID, student.FirstName+" "+student.LastName)
+		case models.StatsBySubject:
+			add(subject.ID, subject.Name)
+		case models.StatsByGroup:
+			groupID, ok := s.groupAt(grade.StudentID, grade.Date)
+			if !ok {
+				continue
+			}
+			if group, ok := s.groups[groupID]; ok {
+				add(group.ID, group.Name)
+			}
+		case models.StatsByTeacher:
+			for _, teacherID := range teachers {
+				if teacher, ok := s.users[teacherID]; ok {
+					add(teacherID, teacher.FirstName+" "+teacher.LastName)
+				}
+			}
+		}
+	}
+
+	stats := []models.GradeStat{}
+	for _, key := range sortedKeys(values) {
+		stats = append(stats, models.GradeStat{ID: key, Name: names[key], GradeSummary: models.NewGradeSummary(values[key], weights[key])})
+	}
+	// ORDER BY name, id
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats, nil
+}
+
+func (r *gradeRepository) Ranking(scope policy.Scope, filter models.GradeStatsFilter) ([]models.GradeRank, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	stats, err := r.s.gradeStats(scope, filter, models.StatsByStudent)
+	if err != nil {
+		return nil, err
+	}
+	return models.RankStudents(stats), nil
+}
+
+func containsInt(values []int, target int) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
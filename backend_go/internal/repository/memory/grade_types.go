@@ -0,0 +1,186 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"time"
+)
+
+type gradeTypeRepository struct {
+	s *Store
+}
+
+// seedGradeTypes повторяет миграцию 0007_grade_types. Вызывать при создании Store
+func (s *Store) seedGradeTypes() {
+	now := time.Now()
+	for _, gradeType := range []models.GradeType{
+		{Code: "oral", Name: "Устный ответ", DefaultWeight: 1},
+		{Code: "homework", Name: "Домашняя работа", DefaultWeight: 1},
+		{Code: "lab", Name: "Лабораторная работа", DefaultWeight: 1.5},
+		{Code: "test", Name: "Контрольная работа", DefaultWeight: 2},
+		{Code: "exam", Name: "Экзамен", DefaultWeight: 3},
+	} {
+		gradeType.ID = s.nextID("grade_types")
+		gradeType.CreatedAt = now
+		gradeType.UpdatedAt = now
+		s.gradeTypes[gradeType.ID] = gradeType
+	}
+}
+
+// gradeWeight повторяет core.gradeWeight. Вызывать под s.mu
+func (s *Store) gradeWeight(grade models.Grade) float64 {
+	if grade.GradeTypeID == nil {
+		return models.DefaultGradeWeight
+	}
+	if weight, ok := s.gradeWeights[grade.SubjectID][*grade.GradeTypeID]; ok {
+		return weight
+	}
+	if gradeType, ok := s.gradeTypes[*grade.GradeTypeID]; ok {
+		return gradeType.DefaultWeight
+	}
+	return models.DefaultGradeWeight
+}
+
+func (r *gradeTypeRepository) GetAll() ([]models.GradeType, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var gradeTypes []models.GradeType
+	for _, id := range sortedKeys(r.s.gradeTypes) {
+		gradeTypes = append(gradeTypes, r.s.gradeTypes[id])
+	}
+	return gradeTypes, nil
+}
+
+func (r *gradeTypeRepository) GetByID(gradeTypeID int) (*models.GradeType, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	gradeType, ok := r.s.gradeTypes[gradeTypeID]
+	if !ok {
+		return nil, fmt.Errorf("grade type not found")
+	}
+	return &gradeType, nil
+}
+
+// gradeTypeCodeTaken повторяет ограничение UNIQUE (code). Вызывать под s.mu
+func (s *Store) gradeTypeCodeTaken(gradeTypeID int, code string) bool {
+	for _, gradeType := range s.gradeTypes {
+		if gradeType.ID != gradeTypeID && gradeType.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *gradeTypeRepository) Create(gradeType models.GradeType) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.gradeTypeCodeTaken(0, gradeType.Code) {
+		return 0, repository.ErrGradeTypeExists
+	}
+
+	now := time.Now()
+	gradeType.ID = r.s.nextID("grade_types")
+	gradeType.CreatedAt = now
+	gradeType.UpdatedAt = now
+	r.s.gradeTypes[gradeType.ID] = gradeType
+
+	return gradeType.ID, nil
+}
+
+func (r *gradeTypeRepository) Update(gradeType models.GradeType) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.gradeTypeCodeTaken(gradeType.ID, gradeType.Code) {
+		return repository.ErrGradeTypeExists
+	}
+	existing, ok := r.s.gradeTypes[gradeType.ID]
+	if !ok {
+		return fmt.Errorf("grade type not found")
+	}
+
+	existing.Code = gradeType.Code
+	existing.Name = gradeType.Name
+	existing.DefaultWeight = gradeType.DefaultWeight
+	existing.UpdatedAt = time.Now()
+	r.s.gradeTypes[gradeType.ID] = existing
+
+	return nil
+}
+
+func (r *gradeTypeRepository) Delete(gradeTypeID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.gradeTypes[gradeTypeID]; !ok {
+		return fmt.Errorf("grade type not found")
+	}
+
+	counts := make(map[string]int)
+	for _, grade := range r.s.grades {
+		if grade.GradeTypeID != nil && *grade.GradeTypeID == gradeTypeID {
+			counts["grades"]++
+		}
+	}
+	if err := repository.CheckInUse("grade type", counts); err != nil {
+		return err
+	}
+
+	delete(r.s.gradeTypes, gradeTypeID)
+	// subject_grade_weights.grade_type_id: ON DELETE CASCADE
+	for _, weights := range r.s.gradeWeights {
+		delete(weights, gradeTypeID)
+	}
+	return nil
+}
+
+func (r *gradeTypeRepository) GetWeights(subjectID int) ([]models.GradeWeight, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	if _, ok := r.s.subjects[subjectID]; !ok {
+		return nil, fmt.Errorf("subject not found")
+	}
+
+	weights := []models.GradeWeight{}
+	for _, id := range sortedKeys(r.s.gradeTypes) {
+		gradeType := r.s.gradeTypes[id]
+		weight, overridden := r.s.gradeWeights[subjectID][id]
+		if !overridden {
+			weight = gradeType.DefaultWeight
+		}
+		weights = append(weights, models.GradeWeight{
+			GradeTypeID: id,
+			Code:        gradeType.Code,
+			Name:        gradeType.Name,
+			Weight:      weight,
+			Overridden:  overridden,
+		})
+	}
+	return weights, nil
+}
+
+func (r *gradeTypeRepository) SetWeights(subjectID int, weights map[int]float64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.subjects[subjectID]; !ok {
+		return fmt.Errorf("subject not found")
+	}
+	for _, gradeTypeID := range sortedKeys(weights) {
+		if _, ok := r.s.gradeTypes[gradeTypeID]; !ok {
+			return fmt.Errorf("grade type %d not found", gradeTypeID)
+		}
+	}
+
+	overrides := make(map[int]float64, len(weights))
+	for gradeTypeID, weight := range weights {
+		overrides[gradeTypeID] = weight
+	}
+	r.s.gradeWeights[subjectID] = overrides
+	return nil
+}
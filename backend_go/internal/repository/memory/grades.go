@@ -0,0 +1,202 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"time"
+)
+
+type gradeRepository struct {
+	s *Store
+}
+
+// gradeDetail повторяет выборку core.ListGrades. Вызывать под s.mu
+func (s *Store) gradeDetail(grade models.Grade) (models.GradeDetail, bool) {
+	student, ok := s.users[grade.StudentID]
+	if !ok {
+		return models.GradeDetail{}, false
+	}
+	subject, ok := s.subjects[grade.SubjectID]
+	if !ok {
+		return models.GradeDetail{}, false
+	}
+	var gradeTypeName string
+	if grade.GradeTypeID != nil {
+		gradeTypeName = s.gradeTypes[*grade.GradeTypeID].Name
+	}
+
+	return models.GradeDetail{
+		ID:          grade.ID,
+		StudentID:   grade.StudentID,
+		StudentName: student.FirstName + " " + student.LastName,
+		SubjectName: subject.Name,
+		Value:       grade.Value,
+		Date:        grade.Date.Format(time.RFC3339),
+		LessonID:    grade.LessonID,
+		GradeTypeID: grade.GradeTypeID,
+		GradeType:   gradeTypeName,
+		Weight:      s.gradeWeight(grade),
+		Comment:     grade.Comment,
+		CreatedAt:   grade.CreatedAt,
+		UpdatedAt:   grade.UpdatedAt,
+	}, true
+}
+
+var gradeSorts = listSorts[models.GradeDetail]{
+	"date":         func(g models.GradeDetail) interface{} { return g.Date },
+	"id":           func(g models.GradeDetail) interface{} { return g.ID },
+	"value":        func(g models.GradeDetail) interface{} { return g.Value },
+	"subject_name": func(g models.GradeDetail) interface{} { return g.SubjectName },
+	"student_name": func(g models.GradeDetail) interface{} { return g.StudentName },
+	"created_at":   func(g models.GradeDetail) interface{} { return g.CreatedAt },
+}
+
+func (r *gradeRepository) List(scope policy.Scope, filter models.RecordFilter, page models.ListQuery) ([]models.GradeDetail, models.PageInfo, error) {
+	r.s.mu.RLock()
+	var grades []models.GradeDetail
+	for _, id := range sortedKeys(r.s.grades) {
+		grade := r.s.grades[id]
+		if !r.s.recordMatches(scope, filter, grade.StudentID, grade.SubjectID, grade.Date, "") {
+			continue
+		}
+		if detail, ok := r.s.gradeDetail(grade); ok {
+			grades = append(grades, detail)
+		}
+	}
+	r.s.mu.RUnlock()
+
+	return listPage(grades, gradeSorts, func(g models.GradeDetail) int { return g.ID }, page)
+}
+
+func (r *gradeRepository) Create(scope policy.Scope, grade models.Grade, reason string) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.createGrade(scope, grade, reason)
+}
+
+// createGrade повторяет core.createGrade. Вызывать под s.mu.Lock
+func (s *Store) createGrade(scope policy.Scope, grade models.Grade, reason string) (int, error) {
+	if err := s.authorizeRecord(scope, grade.StudentID, grade.SubjectID, grade.Date); err != nil {
+		return 0, err
+	}
+	if err := s.checkLesson(grade.LessonID, grade.StudentID, grade.SubjectID, grade.Date); err != nil {
+		return 0, err
+	}
+	if err := s.checkGradeUnlocked(scope, grade.StudentID, grade.SubjectID, grade.Date); err != nil {
+		return 0, err
+	}
+	if err := s.checkGrade(grade); err != nil {
+		return 0, fmt.Errorf("failed to create grade: %v", err)
+	}
+
+	now := time.Now()
+	grade.ID = s.nextID("grades")
+	grade.Date = truncateDate(grade.Date)
+	grade.CreatedAt = now
+	grade.UpdatedAt = now
+	s.grades[grade.ID] = grade
+	s.logChange(scope, models.RecordGrade, grade.ID, models.ChangeCreate, nil, gradeRow(grade), reason)
+
+	return grade.ID, nil
+}
+
+func (r *gradeRepository) Update(scope policy.Scope, grade models.Grade, reason string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.updateGrade(scope, grade, reason)
+}
+
+// updateGrade повторяет core.updateGrade. Вызывать под s.mu.Lock
+func (s *Store) updateGrade(scope policy.Scope, grade models.Grade, reason string) error {
+	existing, ok := s.grades[grade.ID]
+	if !ok {
+		return fmt.Errorf("grade not found")
+	}
+	if err := s.authorizeRecord(scope, existing.StudentID, existing.SubjectID, existing.Date); err != nil {
+		return err
+	}
+	if err := s.authorizeRecord(scope, grade.StudentID, grade.SubjectID, grade.Date); err != nil {
+		return err
+	}
+	if err := s.checkLesson(grade.LessonID, grade.StudentID, grade.SubjectID, grade.Date); err != nil {
+		return err
+	}
+	if err := s.checkGradeUnlocked(scope, existing.StudentID, existing.SubjectID, existing.Date); err != nil {
+		return err
+	}
+	if err := s.checkGradeUnlocked(scope, grade.StudentID, grade.SubjectID, grade.Date); err != nil {
+		return err
+	}
+	if err := s.checkGrade(grade); err != nil {
+		return fmt.Errorf("failed to update grade: %v", err)
+	}
+
+	old := gradeRow(existing)
+	existing.StudentID = grade.StudentID
+	existing.SubjectID = grade.SubjectID
+	existing.LessonID = grade.LessonID
+	existing.GradeTypeID = grade.GradeTypeID
+	existing.Comment = grade.Comment
+	existing.Value = grade.Value
+	existing.Date = truncateDate(grade.Date)
+	existing.UpdatedAt = time.Now()
+	s.grades[grade.ID] = existing
+	s.logChange(scope, models.RecordGrade, grade.ID, models.ChangeUpdate, old, gradeRow(existing), reason)
+
+	return nil
+}
+
+func (r *gradeRepository) Delete(scope policy.Scope, gradeID int, reason string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.deleteGrade(scope, gradeID, reason)
+}
+
+// deleteGrade повторяет core.deleteGrade. Вызывать под s.mu.Lock
+func (s *Store) deleteGrade(scope policy.Scope, gradeID int, reason string) error {
+	grade, ok := s.grades[gradeID]
+	if !ok {
+		return fmt.Errorf("grade not found")
+	}
+	if err := s.authorizeRecord(scope, grade.StudentID, grade.SubjectID, grade.Date); err != nil {
+		return err
+	}
+	if err := s.checkGradeUnlocked(scope, grade.StudentID, grade.SubjectID, grade.Date); err != nil {
+		return err
+	}
+
+	delete(s.grades, gradeID)
+	s.logChange(scope, models.RecordGrade, gradeID, models.ChangeDelete, gradeRow(grade), nil, reason)
+	return nil
+}
+
+// checkGrade повторяет внешние ключи и CHECK таблицы grades. Вызывать под s.mu
+func (s *Store) checkGrade(grade models.Grade) error {
+	if _, ok := s.users[grade.StudentID]; !ok {
+		return fmt.Errorf("user %d does not exist", grade.StudentID)
+	}
+	if _, ok := s.subjects[grade.SubjectID]; !ok {
+		return fmt.Errorf("subject %d does not exist", grade.SubjectID)
+	}
+	if grade.GradeTypeID != nil {
+		if _, ok := s.gradeTypes[*grade.GradeTypeID]; !ok {
+			return fmt.Errorf("grade type %d does not exist", *grade.GradeTypeID)
+		}
+	}
+	if grade.Value < 2 || grade.Value > 5 {
+		return fmt.Errorf("value must be between 2 and 5")
+	}
+	if len([]rune(grade.Comment)) > 500 {
+		return fmt.Errorf("value too long for type character varying(500)")
+	}
+	return nil
+}
+
+// truncateDate отбрасывает время, как колонка типа DATE
+func truncateDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"time"
+)
+
+type groupRepository struct {
+	s *Store
+}
+
+var groupSorts = listSorts[models.Group]{
+	"name":          func(g models.Group) interface{} { return g.Name },
+	"id":            func(g models.Group) interface{} { return g.ID },
+	"student_count": func(g models.Group) interface{} { return g.StudentCount },
+	"created_at":    func(g models.Group) interface{} { return g.CreatedAt },
+}
+
+func (r *groupRepository) List(page models.ListQuery) ([]models.Group, models.PageInfo, error) {
+	r.s.mu.RLock()
+
+	counts := make(map[int]int)
+	for _, user := range r.s.users {
+		if user.GroupID != nil {
+			counts[*user.GroupID]++
+		}
+	}
+
+	var groups []models.Group
+	for _, id := range sortedKeys(r.s.groups) {
+		group := r.s.groups[id]
+		group.StudentCount = counts[id]
+		groups = append(groups, group)
+	}
+	r.s.mu.RUnlock()
+
+	return listPage(groups, groupSorts, func(g models.Group) int { return g.ID }, page)
+}
+
+func (r *groupRepository) GetByID(groupID int) (*models.GroupDetail, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	group, ok := r.s.groups[groupID]
+	if !ok {
+		return nil, fmt.Errorf("group not found")
+	}
+
+	detail := models.GroupDetail{
+		ID:        group.ID,
+		Name:      group.Name,
+		CreatedAt: group.CreatedAt,
+		UpdatedAt: group.UpdatedAt,
+	}
+
+	for _, id := range sortedKeys(r.s.users) {
+		user := r.s.users[id]
+		if user.GroupID == nil || *user.GroupID != groupID {
+			continue
+		}
+		detail.Students = append(detail.Students, models.User{
+			ID:         user.ID,
+			FirstName:  user.FirstName,
+			MiddleName: user.MiddleName,
+			LastName:   user.LastName,
+			Login:      user.Login,
+			CreatedAt:  user.CreatedAt,
+			UpdatedAt:  user.UpdatedAt,
+		})
+	}
+
+	for _, id := range sortedKeys(r.s.schedules) {
+		schedule, ok := r.s.scheduleView(r.s.schedules[id])
+		if !ok || schedule.GroupID != groupID {
+			continue
+		}
+		detail.Schedule = append(detail.Schedule, models.Schedule{
+			ID:          schedule.ID,
+			SubjectName: schedule.SubjectName,
+			TeacherName: schedule.TeacherName,
+			DayOfWeek:   schedule.DayOfWeek,
+			StartTime:   schedule.StartTime,
+			EndTime:     schedule.EndTime,
+			Location:    schedule.Location,
+		})
+	}
+
+	return &detail, nil
+}
+
+func (r *groupRepository) Create(group models.Group) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if err := r.s.checkGroupName(0, group.Name); err != nil {
+		return 0, fmt.Errorf("failed to create group: %v", err)
+	}
+
+	now := time.Now()
+	group.ID = r.s.nextID("groups")
+	group.CreatedAt = now
+	group.UpdatedAt = now
+	group.StudentCount = 0
+	r.s.groups[group.ID] = group
+
+	return group.ID, nil
+}
+
+func (r *groupRepository) Update(group models.Group) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	existing, ok := r.s.groups[group.ID]
+	if !ok {
+		return nil
+	}
+	if err := r.s.checkGroupName(group.ID, group.Name); err != nil {
+		return fmt.Errorf("failed to update group: %v", err)
+	}
+
+	existing.Name = group.Name
+	existing.UpdatedAt = time.Now()
+	r.s.groups[group.ID] = existing
+
+	return nil
+}
+
+func (r *groupRepository) Delete(groupID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.groups[groupID]; !ok {
+		return fmt.Errorf("group not found")
+	}
+
+	// group_memberships.group_id: ON DELETE RESTRICT
+	counts := make(map[string]int)
+	for _, membership := range r.s.memberships {
+		if membership.GroupID == groupID {
+			counts["group_memberships"]++
+		}
+	}
+	if err := repository.CheckInUse("group", counts); err != nil {
+		return err
+	}
+
+	delete(r.s.groups, groupID)
+
+	// users.group_id: ON DELETE SET NULL, schedules.group_id и lessons.group_id: ON DELETE CASCADE
+	for id, user := range r.s.users {
+		if user.GroupID != nil && *user.GroupID == groupID {
+			user.GroupID = nil
+			r.s.users[id] = user
+		}
+	}
+	for id, schedule := range r.s.schedules {
+		if schedule.GroupID == groupID {
+			delete(r.s.schedules, id)
+		}
+	}
+	r.s.deleteGroupLessons(groupID)
+
+	return nil
+}
+
+// checkGroupName повторяет ограничение UNIQUE (name). Вызывать под s.mu
+func (s *Store) checkGroupName(groupID int, name string) error {
+	for _, group := range s.groups {
+		if group.ID != groupID && group.Name == name {
+			return fmt.Errorf("duplicate key value violates unique constraint \"groups_name_key\"")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"time"
+)
+
+// jsonbTimestamp — формат колонки TIMESTAMP в to_jsonb
+const jsonbTimestamp = "2006-01-02T15:04:05.999999"
+
+type historyRepository struct {
+	s *Store
+}
+
+// gradeRow повторяет to_jsonb строки grades: ключи — имена колонок, NULL — null
+func gradeRow(grade models.Grade) []byte {
+	row, _ := json.Marshal(map[string]interface{}{
+		"id":            grade.ID,
+		"student_id":    grade.StudentID,
+		"subject_id":    grade.SubjectID,
+		"value":         grade.Value,
+		"date":          grade.Date.Format("2006-01-02"),
+		"lesson_id":     grade.LessonID,
+		"grade_type_id": grade.GradeTypeID,
+		"comment":       grade.Comment,
+		"created_at":    grade.CreatedAt.Format(jsonbTimestamp),
+		"updated_at":    grade.UpdatedAt.Format(jsonbTimestamp),
+	})
+	return row
+}
+
+// attendanceRow повторяет to_jsonb строки attendance
+func attendanceRow(attendance models.Attendance) []byte {
+	row, _ := json.Marshal(map[string]interface{}{
+		"id":         attendance.ID,
+		"student_id": attendance.StudentID,
+		"subject_id": attendance.SubjectID,
+		"date":       attendance.Date.Format("2006-01-02"),
+		"status":     attendance.Status,
+		"lesson_id":  attendance.LessonID,
+		"created_at": attendance.CreatedAt.Format(jsonbTimestamp),
+		"updated_at": attendance.UpdatedAt.Format(jsonbTimestamp),
+	})
+	return row
+}
+
+// logChange повторяет core.logChange; снимки old и current передаются явно. Вызывать под s.mu.Lock
+func (s *Store) logChange(scope policy.Scope, recordType string, recordID int, action string, old, current []byte, reason string) {
+	var actorID *int
+	if scope.UserID != 0 {
+		id := scope.UserID
+		actorID = &id
+	}
+
+	s.history = append(s.history, models.RecordChange{
+		ID:         s.nextID("record_history"),
+		RecordType: recordType,
+		RecordID:   recordID,
+		Action:     action,
+		ActorID:    actorID,
+		OldValue:   old,
+		NewValue:   current,
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	})
+}
+
+func (r *historyRepository) Get(recordType string, recordID int) ([]models.RecordChange, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	if recordType != models.RecordGrade && recordType != models.RecordAttendance {
+		return nil, fmt.Errorf("unknown record type %q", recordType)
+	}
+
+	changes := []models.RecordChange{}
+	for _, change := range r.s.history {
+		if change.RecordType != recordType || change.RecordID != recordID {
+			continue
+		}
+		if change.ActorID != nil {
+			if actor, ok := r.s.users[*change.ActorID]; ok {
+				change.ActorName = actor.FirstName + " " + actor.LastName
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,266 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"sort"
+	"time"
+)
+
+type journalRepository struct {
+	s *Store
+}
+
+// checkGroupSubject повторяет core.checkGroupSubject. Вызывать под s.mu
+func (s *Store) checkGroupSubject(groupID, subjectID int) error {
+	if _, ok := s.groups[groupID]; !ok {
+		return fmt.Errorf("group not found")
+	}
+	if _, ok := s.subjects[subjectID]; !ok {
+		return fmt.Errorf("subject not found")
+	}
+	return nil
+}
+
+func (r *journalRepository) Get(scope policy.Scope, filter models.JournalFilter) (*models.Journal, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	if err := r.s.checkGroupSubject(filter.GroupID, filter.SubjectID); err != nil {
+		return nil, err
+	}
+
+	from, to := truncateDate(filter.From), truncateDate(filter.To)
+	inPeriod := func(studentID, subjectID int, date time.Time) bool {
+		return subjectID == filter.SubjectID && r.s.memberAt(studentID, filter.GroupID, date) &&
+			!date.Before(from) && !date.After(to) && r.s.canAccessRecord(scope, studentID, subjectID, date)
+	}
+
+	journal := &models.Journal{
+		GroupID:   filter.GroupID,
+		SubjectID: filter.SubjectID,
+		From:      from.Format("2006-01-02"),
+		To:        to.Format("2006-01-02"),
+		Dates:     []string{},
+		Students:  []models.JournalRow{},
+	}
+	for _, id := range sortedKeys(r.s.users) {
+		user := r.s.users[id]
+		if r.s.memberDuring(id, filter.GroupID, from, to) &&
+			r.s.scopedRecords(scope, id, filter.SubjectID, func(groupID int) bool { return r.s.memberDuring(id, groupID, from, to) }) {
+			journal.Students = append(journal.Students, models.JournalRow{StudentID: id, StudentName: user.FirstName + " " + user.LastName})
+		}
+	}
+	sort.SliceStable(journal.Students, func(i, j int) bool {
+		a, b := r.s.users[journal.Students[i].StudentID], r.s.users[journal.Students[j].StudentID]
+		if a.LastName != b.LastName {
+			return a.LastName < b.LastName
+		}
+		return a.FirstName < b.FirstName
+	})
+
+	dates := make(map[string]bool)
+	for _, lesson := range r.s.lessons {
+		if lesson.GroupID == filter.GroupID && lesson.SubjectID == filter.SubjectID &&
+			!lesson.Date.Before(from) && !lesson.Date.After(to) {
+			dates[lesson.Date.Format("2006-01-02")] = true
+		}
+	}
+
+	grades := make(map[int]map[string][]models.JournalGrade)
+	for _, id := range sortedKeys(r.s.grades) {
+		grade := r.s.grades[id]
+		if !inPeriod(grade.StudentID, grade.SubjectID, grade.Date) {
+			continue
+		}
+		journalGrade := models.JournalGrade{ID: id, Value: grade.Value, GradeTypeID: grade.GradeTypeID, Comment: grade.Comment}
+		if grade.GradeTypeID != nil {
+			journalGrade.GradeType = r.s.gradeTypes[*grade.GradeTypeID].Name
+		}
+		if grades[grade.StudentID] == nil {
+			grades[grade.StudentID] = make(map[string][]models.JournalGrade)
+		}
+		day := grade.Date.Format("2006-01-02")
+		grades[grade.StudentID][day] = append(grades[grade.StudentID][day], journalGrade)
+		dates[day] = true
+	}
+
+	attendance := make(map[int]map[string][]models.JournalAttendance)
+	for _, id := range sortedKeys(r.s.attendance) {
+		mark := r.s.attendance[id]
+		if !inPeriod(mark.StudentID, mark.SubjectID, mark.Date) {
+			continue
+		}
+		if attendance[mark.StudentID] == nil {
+			attendance[mark.StudentID] = make(map[string][]models.JournalAttendance)
+		}
+		day := mark.Date.Format("2006-01-02")
+		attendance[mark.StudentID][day] = append(attendance[mark.StudentID][day],
+			models.JournalAttendance{ID: id, Status: mark.Status, LessonID: mark.LessonID})
+		dates[day] = true
+	}
+	// ORDER BY a.lesson_id IS NULL, a.id: сначала отметки на занятиях
+	for _, days := range attendance {
+		for _, cell := range days {
+			sort.SliceStable(cell, func(i, j int) bool { return cell[i].LessonID != nil && cell[j].LessonID == nil })
+		}
+	}
+
+	stats, err := r.s.gradeStats(scope, models.GradeStatsFilter{
+		GroupID:   filter.GroupID,
+		SubjectID: filter.SubjectID,
+		From:      filter.From,
+		To:        filter.To,
+	}, models.StatsByStudent)
+	if err != nil {
+		return nil, err
+	}
+	averages := make(map[int]float64)
+	for _, stat := range stats {
+		averages[stat.ID] = stat.Average
+	}
+
+	for day := range dates {
+		journal.Dates = append(journal.Dates, day)
+	}
+	sort.Strings(journal.Dates)
+	journal.Fill(grades, attendance, averages)
+
+	return journal, nil
+}
+
+// Save повторяет core.SaveJournal. Вместо отката транзакции при ошибке восстанавливаются
+// оценки, отметки и история; счётчики ID, как последовательности PostgreSQL, не откатываются
+func (r *journalRepository) Save(scope policy.Scope, save models.JournalSave, reason string) (*models.JournalSaveResult, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if err := r.s.checkGroupSubject(save.GroupID, save.SubjectID); err != nil {
+		return nil, err
+	}
+
+	grades := make(map[int]models.Grade, len(r.s.grades))
+	for id, grade := range r.s.grades {
+		grades[id] = grade
+	}
+	attendance := make(map[int]models.Attendance, len(r.s.attendance))
+	for id, mark := range r.s.attendance {
+		attendance[id] = mark
+	}
+	history := len(r.s.history)
+	rollback := func(err error) (*models.JournalSaveResult, error) {
+		r.s.grades, r.s.attendance, r.s.history = grades, attendance, r.s.history[:history]
+		return nil, err
+	}
+
+	result := models.NewJournalSaveResult()
+	for i, edit := range save.Grades {
+		if err := r.s.saveJournalGrade(scope, save, edit, reason, result); err != nil {
+			return rollback(&repository.JournalEditError{Field: "grades", Index: i, Err: err})
+		}
+	}
+	for i, edit := range save.Attendance {
+		if err := r.s.saveJournalAttendance(scope, save, edit, reason, result); err != nil {
+			return rollback(&repository.JournalEditError{Field: "attendance", Index: i, Err: err})
+		}
+	}
+
+	return result, nil
+}
+
+// checkGroupMember повторяет core.checkGroupMember. Вызывать под s.mu
+func (s *Store) checkGroupMember(studentID, groupID int, date time.Time) error {
+	if _, ok := s.users[studentID]; !ok || !s.memberAt(studentID, groupID, date) {
+		return repository.ErrNotGroupMember
+	}
+	return nil
+}
+
+// saveJournalGrade повторяет core.saveJournalGrade. Вызывать под s.mu.Lock
+func (s *Store) saveJournalGrade(scope policy.Scope, save models.JournalSave, edit models.JournalGradeEdit,
+	reason string, result *models.JournalSaveResult) error {
+	if edit.ID == nil {
+		if err := s.checkGroupMember(edit.StudentID, save.GroupID, edit.Date); err != nil {
+			return err
+		}
+		gradeID, err := s.createGrade(scope, models.Grade{
+			StudentID:   edit.StudentID,
+			SubjectID:   save.SubjectID,
+			Value:       edit.Value,
+			Date:        edit.Date,
+			GradeTypeID: edit.GradeTypeID,
+			Comment:     edit.Comment,
+		}, reason)
+		if err != nil {
+			return err
+		}
+		result.CreatedGrades = append(result.CreatedGrades, gradeID)
+		return nil
+	}
+
+	grade, ok := s.grades[*edit.ID]
+	if !ok || grade.SubjectID != save.SubjectID {
+		return fmt.Errorf("grade not found")
+	}
+	if err := s.checkGroupMember(grade.StudentID, save.GroupID, grade.Date); err != nil {
+		return err
+	}
+
+	if edit.Delete {
+		if err := s.deleteGrade(scope, grade.ID, reason); err != nil {
+			return err
+		}
+		result.DeletedGrades = append(result.DeletedGrades, grade.ID)
+		return nil
+	}
+
+	grade.Value, grade.GradeTypeID, grade.Comment = edit.Value, edit.GradeTypeID, edit.Comment
+	if err := s.updateGrade(scope, grade, reason); err != nil {
+		return err
+	}
+	result.UpdatedGrades = append(result.UpdatedGrades, grade.ID)
+	return nil
+}
+
+// saveJournalAttendance повторяет core.saveJournalAttendance. Вызывать под s.mu.Lock
+func (s *Store) saveJournalAttendance(scope policy.Scope, save models.JournalSave, edit models.JournalAttendanceEdit,
+	reason string, result *models.JournalSaveResult) error {
+	if err := s.checkGroupMember(edit.StudentID, save.GroupID, edit.Date); err != nil {
+		return err
+	}
+
+	attendance := models.Attendance{
+		StudentID: edit.StudentID,
+		SubjectID: save.SubjectID,
+		Date:      edit.Date,
+		Status:    edit.Status,
+	}
+	// ORDER BY lesson_id IS NULL, id LIMIT 1
+	date := truncateDate(edit.Date)
+	for _, id := range sortedKeys(s.attendance) {
+		mark := s.attendance[id]
+		if mark.StudentID != edit.StudentID || mark.SubjectID != save.SubjectID || !mark.Date.Equal(date) {
+			continue
+		}
+		if attendance.ID == 0 || (attendance.LessonID == nil && mark.LessonID != nil) {
+			attendance.ID, attendance.LessonID = id, mark.LessonID
+		}
+	}
+
+	if attendance.ID == 0 {
+		attendanceID, err := s.createAttendance(scope, attendance, reason)
+		if err != nil {
+			return err
+		}
+		result.CreatedAttendance = append(result.CreatedAttendance, attendanceID)
+		return nil
+	}
+
+	if err := s.updateAttendance(scope, attendance, reason); err != nil {
+		return err
+	}
+	result.UpdatedAttendance = append(result.UpdatedAttendance, attendance.ID)
+	return nil
+}
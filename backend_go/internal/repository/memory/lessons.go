@@ -0,0 +1,186 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"sort"
+	"time"
+)
+
+type lessonRepository struct {
+	s *Store
+}
+
+// lessonViews дополняет занятия названиями и номером пары, как core.GetLessons.
+// Номер пары считается среди всех занятий группы за день. Вызывать под s.mu
+func (s *Store) lessonViews(match func(models.Lesson) bool) []models.Lesson {
+	var all []models.Lesson
+	for _, id := range sortedKeys(s.lessons) {
+		all = append(all, s.lessons[id])
+	}
+	// ORDER BY date, start_time, id
+	sort.SliceStable(all, func(i, j int) bool {
+		if !all[i].Date.Equal(all[j].Date) {
+			return all[i].Date.Before(all[j].Date)
+		}
+		return all[i].StartTime < all[j].StartTime
+	})
+
+	type groupDay struct {
+		groupID int
+		date    time.Time
+	}
+	periods := make(map[groupDay]int)
+
+	var lessons []models.Lesson
+	for _, lesson := range all {
+		key := groupDay{lesson.GroupID, lesson.Date}
+		periods[key]++
+		lesson.Period = periods[key]
+
+		group, ok := s.groups[lesson.GroupID]
+		if !ok {
+			continue
+		}
+		subject, ok := s.subjects[lesson.SubjectID]
+		if !ok {
+			continue
+		}
+		teacher, ok := s.users[lesson.TeacherID]
+		if !ok {
+			continue
+		}
+		lesson.GroupName = group.Name
+		lesson.SubjectName = subject.Name
+		lesson.TeacherName = teacher.FirstName + " " + teacher.LastName
+
+		if match == nil || match(lesson) {
+			lessons = append(lessons, lesson)
+		}
+	}
+
+	return lessons
+}
+
+func (r *lessonRepository) GetAll(filter models.LessonFilter) ([]models.Lesson, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	from, to := truncateDate(filter.From), truncateDate(filter.To)
+	return r.s.lessonViews(func(lesson models.Lesson) bool {
+		return (filter.From.IsZero() || !lesson.Date.Before(from)) &&
+			(filter.To.IsZero() || !lesson.Date.After(to)) &&
+			(filter.GroupID == 0 || lesson.GroupID == filter.GroupID) &&
+			(filter.TeacherID == 0 || lesson.TeacherID == filter.TeacherID) &&
+			(filter.Period == 0 || lesson.Period == filter.Period)
+	}), nil
+}
+
+func (r *lessonRepository) GetByID(lessonID int) (*models.Lesson, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	lessons := r.s.lessonViews(func(lesson models.Lesson) bool { return lesson.ID == lessonID })
+	if len(lessons) == 0 {
+		return nil, fmt.Errorf("lesson not found")
+	}
+
+	return &lessons[0], nil
+}
+
+func (r *lessonRepository) Generate(from, to time.Time, groupID int) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	// UNIQUE (schedule_id, date): ON CONFLICT DO NOTHING
+	type scheduleDay struct {
+		scheduleID int
+		date       time.Time
+	}
+	existing := make(map[scheduleDay]bool)
+	for _, lesson := range r.s.lessons {
+		if lesson.ScheduleID != nil {
+			existing[scheduleDay{*lesson.ScheduleID, lesson.Date}] = true
+		}
+	}
+
+	created := 0
+	now := time.Now()
+	for day := truncateDate(from); !day.After(truncateDate(to)); day = day.AddDate(0, 0, 1) {
+		weekday := int(day.Weekday())
+		if weekday == 0 {
+			weekday = 7 // ISODOW: воскресенье — 7
+		}
+		for _, id := range sortedKeys(r.s.schedules) {
+			schedule := r.s.schedules[id]
+			if schedule.DayOfWeek != weekday || (groupID != 0 && schedule.GroupID != groupID) ||
+				existing[scheduleDay{schedule.ID, day}] {
+				continue
+			}
+
+			scheduleID := schedule.ID
+			lesson := models.Lesson{
+				ID:         r.s.nextID("lessons"),
+				ScheduleID: &scheduleID,
+				GroupID:    schedule.GroupID,
+				SubjectID:  schedule.SubjectID,
+				TeacherID:  schedule.TeacherID,
+				Date:       day,
+				StartTime:  schedule.StartTime,
+				EndTime:    schedule.EndTime,
+				Location:   schedule.Location,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			}
+			r.s.lessons[lesson.ID] = lesson
+			existing[scheduleDay{schedule.ID, day}] = true
+			created++
+		}
+	}
+
+	return created, nil
+}
+
+// checkLesson повторяет проверку core.checkLesson: занятие по тому же предмету, в тот же день
+// и у группы, в которой студент был в день занятия. Вызывать под s.mu
+func (s *Store) checkLesson(lessonID *int, studentID, subjectID int, date time.Time) error {
+	if lessonID == nil {
+		return nil
+	}
+
+	lesson, ok := s.lessons[*lessonID]
+	_, found := s.users[studentID]
+	if !ok || !found || !s.memberAt(studentID, lesson.GroupID, lesson.Date) ||
+		lesson.SubjectID != subjectID || !lesson.Date.Equal(truncateDate(date)) {
+		return repository.ErrLessonMismatch
+	}
+
+	return nil
+}
+
+// deleteGroupLessons повторяет lessons.group_id ON DELETE CASCADE и lesson_id ON DELETE SET NULL
+// в оценках и посещаемости. Вызывать под s.mu.Lock
+func (s *Store) deleteGroupLessons(groupID int) {
+	for id, lesson := range s.lessons {
+		if lesson.GroupID == groupID {
+			delete(s.lessons, id)
+		}
+	}
+	for id, grade := range s.grades {
+		if grade.LessonID != nil {
+			if _, ok := s.lessons[*grade.LessonID]; !ok {
+				grade.LessonID = nil
+				s.grades[id] = grade
+			}
+		}
+	}
+	for id, attendance := range s.attendance {
+		if attendance.LessonID != nil {
+			if _, ok := s.lessons[*attendance.LessonID]; !ok {
+				attendance.LessonID = nil
+				s.attendance[id] = attendance
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"cmp"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// listSorts — поля сортировки списка и их значения у записи: int, float64, string или time.Time
+type listSorts[T any] map[string]func(T) interface{}
+
+// listPage повторяет core.listPage над уже отобранными записями: сортирует items по полю page.Sort
+// (равные — по ID), продолжает после курсора или смещения и отрезает страницу
+func listPage[T any](items []T, sorts listSorts[T], id func(T) int, page models.ListQuery) ([]T, models.PageInfo, error) {
+	info := models.PageInfo{Total: len(items)}
+	value, ok := sorts[page.Sort]
+	if !ok {
+		return nil, info, fmt.Errorf("unknown sort field: %s", page.Sort)
+	}
+
+	compare := func(a, b T) int {
+		if c := compareValues(value(a), value(b)); c != 0 {
+			return c
+		}
+		return cmp.Compare(id(a), id(b))
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if page.Desc {
+			return compare(items[i], items[j]) > 0
+		}
+		return compare(items[i], items[j]) < 0
+	})
+
+	start := page.Offset
+	if page.After != nil && len(items) > 0 {
+		after, err := parseValue(value(items[0]), page.After.Value)
+		if err != nil {
+			return nil, info, fmt.Errorf("invalid cursor: %v", err)
+		}
+		for start = 0; start < len(items); start++ {
+			c := compareValues(value(items[start]), after)
+			if c == 0 {
+				c = cmp.Compare(id(items[start]), page.After.ID)
+			}
+			if (page.Desc && c < 0) || (!page.Desc && c > 0) {
+				break
+			}
+		}
+	}
+	start = min(start, len(items))
+
+	end := len(items)
+	if page.Limit > 0 && start+page.Limit < end {
+		end = start + page.Limit
+		last := items[end-1]
+		info.Next = &models.Cursor{Sort: page.Sort, Desc: page.Desc, Value: formatValue(value(last)), ID: id(last)}
+	}
+
+	return append([]T{}, items[start:end]...), info, nil
+}
+
+func compareValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case int:
+		return cmp.Compare(a, b.(int))
+	case float64:
+		return cmp.Compare(a, b.(float64))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	default:
+		return cmp.Compare(a.(string), b.(string))
+	}
+}
+
+// formatValue и parseValue переводят значение поля сортировки в текст курсора и обратно
+func formatValue(value interface{}) string {
+	switch value := value.(type) {
+	case int:
+		return strconv.Itoa(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	default:
+		return value.(string)
+	}
+}
+
+// parseValue разбирает text как значение того же типа, что и sample
+func parseValue(sample interface{}, text string) (interface{}, error) {
+	switch sample.(type) {
+	case int:
+		return strconv.Atoi(text)
+	case float64:
+		return strconv.ParseFloat(text, 64)
+	case time.Time:
+		return time.Parse(time.RFC3339Nano, text)
+	default:
+		return text, nil
+	}
+}
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"github.com/VladislavSCV/internal/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+type listItem struct {
+	ID    int
+	Name  string
+	Score float64
+	At    time.Time
+}
+
+var listItemSorts = listSorts[listItem]{
+	"id":    func(i listItem) interface{} { return i.ID },
+	"name":  func(i listItem) interface{} { return i.Name },
+	"score": func(i listItem) interface{} { return i.Score },
+	"at":    func(i listItem) interface{} { return i.At },
+}
+
+func listItemID(i listItem) int { return i.ID }
+
+// listItems — записи с повторяющимися значениями полей, чтобы порядок решал ID
+func listItems() []listItem {
+	base := time.Date(2026, time.September, 1, 8, 0, 0, 0, time.UTC)
+	return []listItem{
+		{ID: 5, Name: "Петров", Score: 4.5, At: base.Add(time.Hour)},
+		{ID: 2, Name: "Иванов", Score: 3, At: base},
+		{ID: 7, Name: "Иванов", Score: 4.5, At: base.Add(time.Hour)},
+		{ID: 1, Name: "Сидоров", Score: 5, At: base.Add(time.Millisecond)},
+		{ID: 4, Name: "Иванов", Score: 3, At: base},
+	}
+}
+
+func ids(items []listItem) []int {
+	result := []int{}
+	for _, item := range items {
+		result = append(result, item.ID)
+	}
+	return result
+}
+
+// walk проходит список страницами по limit записей, продолжая курсором из предыдущей страницы
+func walk(t *testing.T, items func() []listItem, query models.ListQuery) []int {
+	t.Helper()
+	var result []int
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatalf("cursor walk does not end")
+		}
+		page, info, err := listPage(items(), listItemSorts, listItemID, query)
+		if err != nil {
+			t.Fatalf("listPage(%+v): %v", query, err)
+		}
+		if info.Total != len(items()) {
+			t.Errorf("Total = %d, want %d", info.Total, len(items()))
+		}
+		result = append(result, ids(page)...)
+		if info.Next == nil {
+			return result
+		}
+		if info.Next.Sort != query.Sort || info.Next.Desc != query.Desc {
+			t.Fatalf("cursor %+v does not keep the sort of %+v", info.Next, query)
+		}
+		query.After = info.Next
+	}
+}
+
+func TestListPageCursor(t *testing.T) {
+	tests := []struct {
+		sort string
+		desc bool
+		want []int
+	}{
+		{"id", false, []int{1, 2, 4, 5, 7}},
+		{"name", false, []int{2, 4, 7, 5, 1}},
+		{"name", true, []int{1, 5, 7, 4, 2}},
+		{"score", false, []int{2, 4, 5, 7, 1}},
+		{"at", true, []int{7, 5, 1, 4, 2}},
+	}
+	for _, tt := range tests {
+		for _, limit := range []int{1, 2, 5} {
+			got := walk(t, listItems, models.ListQuery{Sort: tt.sort, Desc: tt.desc, Limit: limit})
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("sort %s desc %v limit %d: %v, want %v", tt.sort, tt.desc, limit, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestListPageCursorSurvivesChanges(t *testing.T) {
+	query := models.ListQuery{Sort: "name", Limit: 2}
+	first, info, err := listPage(listItems(), listItemSorts, listItemID, query)
+	if err != nil || !slices.Equal(ids(first), []int{2, 4}) || info.Next == nil {
+		t.Fatalf("first page: %v, %+v, %v", ids(first), info, err)
+	}
+
+	// Между запросами удалили выданную запись и добавили новую перед курсором: смещение сдвинулось бы, курсор — нет
+	items := slices.DeleteFunc(listItems(), func(i listItem) bool { return i.ID == 2 })
+	items = append(items, listItem{ID: 9, Name: "Андреев"})
+	query.After = info.Next
+	second, _, err := listPage(items, listItemSorts, listItemID, query)
+	if err != nil || !slices.Equal(ids(second), []int{7, 5}) {
+		t.Errorf("second page: %v, %v, want [7 5]", ids(second), err)
+	}
+}
+
+func TestListPageOffset(t *testing.T) {
+	tests := []struct {
+		offset, limit int
+		want          []int
+		next          bool
+	}{
+		{0, 0, []int{1, 2, 4, 5, 7}, false},
+		{1, 2, []int{2, 4}, true},
+		{3, 2, []int{5, 7}, false},
+		{10, 2, []int{}, false},
+	}
+	for _, tt := range tests {
+		page, info, err := listPage(listItems(), listItemSorts, listItemID, models.ListQuery{Sort: "id", Offset: tt.offset, Limit: tt.limit})
+		if err != nil {
+			t.Fatalf("listPage: %v", err)
+		}
+		if !slices.Equal(ids(page), tt.want) || (info.Next != nil) != tt.next {
+			t.Errorf("offset %d limit %d: %v, next %+v", tt.offset, tt.limit, ids(page), info.Next)
+		}
+	}
+}
+
+func TestListPageRejects(t *testing.T) {
+	if _, _, err := listPage(listItems(), listItemSorts, listItemID, models.ListQuery{Sort: "age"}); err == nil {
+		t.Errorf("unknown sort field: no error")
+	}
+	after := &models.Cursor{Sort: "score", Value: "четыре", ID: 1}
+	if _, _, err := listPage(listItems(), listItemSorts, listItemID, models.ListQuery{Sort: "score", After: after}); err == nil {
+		t.Errorf("cursor with a non-numeric value: no error")
+	}
+}
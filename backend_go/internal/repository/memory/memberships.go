@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"sort"
+	"time"
+)
+
+// addMembership повторяет core.addMembership. Вызывать под s.mu.Lock
+func (s *Store) addMembership(studentID, groupID int, from *time.Time) int {
+	membership := models.GroupMembership{ID: s.nextID("group_memberships"), StudentID: studentID, GroupID: groupID}
+	if from != nil {
+		day := truncateDate(*from)
+		membership.ValidFrom = &day
+	}
+	s.memberships[membership.ID] = membership
+	return membership.ID
+}
+
+// groupAt возвращает группу, в которой студент состоял в день date. Вызывать под s.mu
+func (s *Store) groupAt(studentID int, date time.Time) (int, bool) {
+	for _, membership := range s.memberships {
+		if membership.StudentID == studentID && membership.Covers(date) {
+			return membership.GroupID, true
+		}
+	}
+	return 0, false
+}
+
+// memberAt повторяет core.memberAt. Вызывать под s.mu
+func (s *Store) memberAt(studentID, groupID int, date time.Time) bool {
+	current, ok := s.groupAt(studentID, date)
+	return ok && current == groupID
+}
+
+// memberDuring повторяет core.memberDuring. Вызывать под s.mu
+func (s *Store) memberDuring(studentID, groupID int, from, to time.Time) bool {
+	for _, membership := range s.memberships {
+		if membership.StudentID == studentID && membership.GroupID == groupID && membership.Overlaps(from, to) {
+			return true
+		}
+	}
+	return false
+}
+
+// Transfer повторяет core.TransferStudent
+func (r *groupRepository) Transfer(groupID int, transfer models.Transfer) (*models.GroupMembership, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	group, ok := r.s.groups[groupID]
+	if !ok {
+		return nil, fmt.Errorf("group not found")
+	}
+	student, ok := r.s.users[transfer.StudentID]
+	if !ok {
+		return nil, fmt.Errorf("student not found")
+	}
+	if r.s.roles[student.RoleID].Name != "student" {
+		return nil, repository.ErrNotStudent
+	}
+	if student.GroupID != nil && *student.GroupID == groupID {
+		return nil, repository.ErrAlreadyInGroup
+	}
+
+	today := truncateDate(time.Now())
+	date := today
+	if !transfer.Date.IsZero() {
+		date = truncateDate(transfer.Date)
+	}
+	if date.After(today) {
+		return nil, repository.ErrInvalidTransferDate
+	}
+	for _, membership := range r.s.memberships {
+		if membership.StudentID != transfer.StudentID {
+			continue
+		}
+		if (membership.ValidFrom != nil && !date.After(*membership.ValidFrom)) ||
+			(membership.ValidTo != nil && date.Before(*membership.ValidTo)) {
+			return nil, repository.ErrInvalidTransferDate
+		}
+	}
+
+	for id, membership := range r.s.memberships {
+		if membership.StudentID == transfer.StudentID && membership.ValidTo == nil {
+			validTo := date
+			membership.ValidTo = &validTo
+			r.s.memberships[id] = membership
+		}
+	}
+	membershipID := r.s.addMembership(transfer.StudentID, groupID, &date)
+	student.GroupID = &groupID
+	student.UpdatedAt = time.Now()
+	r.s.users[student.ID] = student
+
+	membership := r.s.memberships[membershipID]
+	membership.GroupName = group.Name
+	return &membership, nil
+}
+
+// GetMemberships повторяет core.GetGroupMemberships
+func (r *groupRepository) GetMemberships(studentID int) ([]models.GroupMembership, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	memberships := []models.GroupMembership{}
+	for _, id := range sortedKeys(r.s.memberships) {
+		membership := r.s.memberships[id]
+		if membership.StudentID != studentID {
+			continue
+		}
+		group, ok := r.s.groups[membership.GroupID]
+		if !ok {
+			continue
+		}
+		membership.GroupName = group.Name
+		memberships = append(memberships, membership)
+	}
+	// ORDER BY valid_from NULLS FIRST, id
+	sort.SliceStable(memberships, func(i, j int) bool {
+		a, b := memberships[i].ValidFrom, memberships[j].ValidFrom
+		return a == nil && b != nil || a != nil && b != nil && a.Before(*b)
+	})
+
+	return memberships, nil
+}
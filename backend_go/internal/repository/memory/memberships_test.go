@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"errors"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"testing"
+)
+
+// Роли из SeedDemo
+const (
+	seedTeacherRoleID = 2
+	seedStudentRoleID = 3
+)
+
+func TestRegisterMembership(t *testing.T) {
+	store := New()
+	if err := store.SeedDemo(); err != nil {
+		t.Fatalf("SeedDemo: %v", err)
+	}
+	repos := store.Repositories()
+	groupID := seedGroupID
+
+	// История групп открывается только студенту; куратор с группой в неё не попадает
+	tests := []struct {
+		login  string
+		roleID int
+		want   int
+	}{
+		{"curator", seedTeacherRoleID, 0},
+		{"newstudent", seedStudentRoleID, 1},
+	}
+	for _, tt := range tests {
+		userID, err := repos.Users.Register(models.User{FirstName: "Новый", LastName: "Пользователь", RoleID: tt.roleID, GroupID: &groupID, Login: tt.login})
+		if err != nil {
+			t.Fatalf("Register %s: %v", tt.login, err)
+		}
+		memberships, err := repos.Groups.GetMemberships(userID)
+		if err != nil {
+			t.Fatalf("GetMemberships %s: %v", tt.login, err)
+		}
+		if len(memberships) != tt.want {
+			t.Errorf("%s: %d memberships, want %d", tt.login, len(memberships), tt.want)
+		}
+		if tt.want == 1 && (memberships[0].GroupID != groupID || memberships[0].ValidFrom != nil || memberships[0].ValidTo != nil) {
+			t.Errorf("%s: membership %+v, want open group %d from the beginning", tt.login, memberships[0], groupID)
+		}
+
+		// Перевести можно только студента, так что у куратора история так и не появится
+		if tt.roleID != seedStudentRoleID {
+			if _, err := repos.Groups.Transfer(groupID, models.Transfer{StudentID: userID}); !errors.Is(err, repository.ErrNotStudent) {
+				t.Errorf("%s: transfer error = %v, want ErrNotStudent", tt.login, err)
+			}
+		}
+	}
+}
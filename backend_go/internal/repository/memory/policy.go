@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"time"
+)
+
+// canAccessRecord повторяет core.recordFilter для записи студента за день date. Вызывать под s.mu
+func (s *Store) canAccessRecord(scope policy.Scope, studentID, subjectID int, date time.Time) bool {
+	return s.scopedRecords(scope, studentID, subjectID, func(groupID int) bool { return s.memberAt(studentID, groupID, date) })
+}
+
+// scopedRecords повторяет core.scopedRecords: при records:taught студент связан с группой расписания
+// условием member. Вызывать под s.mu
+func (s *Store) scopedRecords(scope policy.Scope, studentID, subjectID int, member func(groupID int) bool) bool {
+	switch scope.Access {
+	case policy.AccessAll:
+		return true
+	case policy.AccessTaught:
+		if _, ok := s.users[studentID]; !ok {
+			return false
+		}
+		for _, schedule := range s.schedules {
+			if schedule.SubjectID == subjectID && schedule.TeacherID == scope.UserID && member(schedule.GroupID) {
+				return true
+			}
+		}
+		return false
+	case policy.AccessOwn:
+		return studentID == scope.UserID
+	default:
+		return false
+	}
+}
+
+// canSeeUser повторяет core.userFilter. Вызывать под s.mu
+func (s *Store) canSeeUser(scope policy.Scope, user models.User) bool {
+	switch scope.Access {
+	case policy.AccessAll:
+		return true
+	case policy.AccessTaught:
+		if user.ID == scope.UserID {
+			return true
+		}
+		for _, membership := range s.memberships {
+			if membership.StudentID != user.ID {
+				continue
+			}
+			for _, schedule := range s.schedules {
+				if schedule.GroupID == membership.GroupID && schedule.TeacherID == scope.UserID {
+					return true
+				}
+			}
+		}
+		return false
+	case policy.AccessOwn:
+		return user.ID == scope.UserID
+	default:
+		return false
+	}
+}
+
+// authorizeRecord повторяет core.authorizeRecord. Вызывать под s.mu
+func (s *Store) authorizeRecord(scope policy.Scope, studentID, subjectID int, date time.Time) error {
+	if scope.Access == policy.AccessAll {
+		return nil
+	}
+	if scope.Access != policy.AccessTaught || !s.canAccessRecord(scope, studentID, subjectID, date) {
+		return policy.ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"sort"
+)
+
+type roleRepository struct {
+	s *Store
+}
+
+// seedRoles повторяет миграцию 0003_role_permissions. Вызывать при создании Store
+func (s *Store) seedRoles() {
+	for _, permission := range policy.Permissions {
+		s.permissions = append(s.permissions, models.Permission{
+			ID:          s.nextID("permissions"),
+			Name:        permission.Name,
+			Description: permission.Description,
+		})
+	}
+
+	var admin, teacher, student []string
+	for _, permission := range policy.Permissions {
+		switch permission.Name {
+		case policy.PermRecordsTaught, policy.PermRecordsOwn:
+		default:
+			admin = append(admin, permission.Name)
+		}
+	}
+	teacher = []string{policy.PermStudentsRead, policy.PermGradesRead, policy.PermGradesWrite,
+		policy.PermAttendanceRead, policy.PermAttendanceWrite, policy.PermRecordsTaught}
+	student = []string{policy.PermGradesRead, policy.PermAttendanceRead, policy.PermRecordsOwn}
+
+	for _, role := range []models.Role{
+		{Name: "admin", Description: "Администратор", Permissions: admin},
+		{Name: "teacher", Description: "Преподаватель", Permissions: teacher},
+		{Name: "student", Description: "Студент", Permissions: student},
+	} {
+		role.ID = s.nextID("roles")
+		role.Builtin = true
+		sort.Strings(role.Permissions)
+		s.roles[role.ID] = role
+	}
+}
+
+func copyRole(role models.Role) models.Role {
+	role.Permissions = append([]string{}, role.Permissions...)
+	return role
+}
+
+func (r *roleRepository) GetAll() ([]models.Role, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var roles []models.Role
+	for _, id := range sortedKeys(r.s.roles) {
+		roles = append(roles, copyRole(r.s.roles[id]))
+	}
+
+	return roles, nil
+}
+
+func (r *roleRepository) GetByID(roleID int) (*models.Role, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	role, ok := r.s.roles[roleID]
+	if !ok {
+		return nil, fmt.Errorf("role not found")
+	}
+	role = copyRole(role)
+
+	return &role, nil
+}
+
+func (r *roleRepository) GetPermissions() ([]models.Permission, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	permissions := append([]models.Permission{}, r.s.permissions...)
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].Name < permissions[j].Name })
+
+	return permissions, nil
+}
+
+func (r *roleRepository) Create(role models.Role) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.roleNameTaken(role.Name, 0) {
+		return 0, repository.ErrRoleExists
+	}
+	permissions, err := r.s.checkPermissions(role.Permissions)
+	if err != nil {
+		return 0, err
+	}
+
+	role.ID = r.s.nextID("roles")
+	role.Builtin = false
+	role.Permissions = permissions
+	r.s.roles[role.ID] = role
+
+	return role.ID, nil
+}
+
+func (r *roleRepository) Update(role models.Role) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	existing, ok := r.s.roles[role.ID]
+	if !ok {
+		return fmt.Errorf("role not found")
+	}
+	if existing.Builtin && existing.Name != role.Name {
+		return repository.ErrBuiltinRole
+	}
+	if r.s.roleNameTaken(role.Name, role.ID) {
+		return repository.ErrRoleExists
+	}
+	permissions, err := r.s.checkPermissions(role.Permissions)
+	if err != nil {
+		return err
+	}
+
+	existing.Name = role.Name
+	existing.Description = role.Description
+	existing.Permissions = permissions
+	r.s.roles[role.ID] = existing
+
+	return nil
+}
+
+func (r *roleRepository) Delete(roleID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	role, ok := r.s.roles[roleID]
+	if !ok {
+		return fmt.Errorf("role not found")
+	}
+	if role.Builtin {
+		return repository.ErrBuiltinRole
+	}
+	for _, user := range r.s.users {
+		if user.RoleID == roleID {
+			return repository.ErrRoleInUse
+		}
+	}
+
+	delete(r.s.roles, roleID)
+	return nil
+}
+
+func (r *roleRepository) AssignToUser(userID, roleID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.roles[roleID]; !ok {
+		return fmt.Errorf("role not found")
+	}
+	user, ok := r.s.users[userID]
+	if !ok {
+		return fmt.Errorf("user not found")
+	}
+
+	user.RoleID = roleID
+	r.s.users[userID] = user
+	return nil
+}
+
+// roleNameTaken повторяет UNIQUE на roles.value. Вызывать под s.mu
+func (s *Store) roleNameTaken(name string, exceptID int) bool {
+	for id, role := range s.roles {
+		if id != exceptID && role.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPermissions проверяет имена по каталогу и убирает повторы. Вызывать под s.mu
+func (s *Store) checkPermissions(names []string) ([]string, error) {
+	known := make(map[string]bool, len(s.permissions))
+	for _, permission := range s.permissions {
+		known[permission.Name] = true
+	}
+
+	seen := make(map[string]bool, len(names))
+	var permissions []string
+	for _, name := range names {
+		if !known[name] {
+			return nil, fmt.Errorf("%w: %s", repository.ErrUnknownPermission, name)
+		}
+		if !seen[name] {
+			seen[name] = true
+			permissions = append(permissions, name)
+		}
+	}
+	sort.Strings(permissions)
+
+	return permissions, nil
+}
@@ -0,0 +1,275 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/patch"
+	"github.com/VladislavSCV/internal/repository"
+	"sort"
+	"time"
+)
+
+type scheduleRepository struct {
+	s *Store
+}
+
+// scheduleView дополняет занятие названиями группы, предмета и ФИО преподавателя,
+// как JOIN в core.ListSchedules. Вызывать под s.mu
+func (s *Store) scheduleView(schedule models.Schedule) (models.Schedule, bool) {
+	group, ok := s.groups[schedule.GroupID]
+	if !ok {
+		return models.Schedule{}, false
+	}
+	subject, ok := s.subjects[schedule.SubjectID]
+	if !ok {
+		return models.Schedule{}, false
+	}
+	teacher, ok := s.users[schedule.TeacherID]
+	if !ok {
+		return models.Schedule{}, false
+	}
+
+	schedule.GroupName = group.Name
+	schedule.SubjectName = subject.Name
+	schedule.TeacherName = teacher.FirstName + " " + teacher.LastName
+	return schedule, true
+}
+
+// list возвращает занятия, для которых match вернул true (nil — все)
+func (r *scheduleRepository) list(match func(models.Schedule) bool) []models.Schedule {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var schedules []models.Schedule
+	for _, id := range sortedKeys(r.s.schedules) {
+		schedule, ok := r.s.scheduleView(r.s.schedules[id])
+		if !ok || (match != nil && !match(schedule)) {
+			continue
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules
+}
+
+var scheduleSorts = listSorts[models.Schedule]{
+	"day_of_week":  func(s models.Schedule) interface{} { return s.DayOfWeek },
+	"id":           func(s models.Schedule) interface{} { return s.ID },
+	"start_time":   func(s models.Schedule) interface{} { return s.StartTime },
+	"group_name":   func(s models.Schedule) interface{} { return s.GroupName },
+	"subject_name": func(s models.Schedule) interface{} { return s.SubjectName },
+	"teacher_name": func(s models.Schedule) interface{} { return s.TeacherName },
+	"location":     func(s models.Schedule) interface{} { return s.Location },
+}
+
+// scheduleMatches повторяет условия core.scheduleListQuery
+func scheduleMatches(filter models.ScheduleFilter) func(models.Schedule) bool {
+	return func(schedule models.Schedule) bool {
+		return (filter.GroupID == 0 || schedule.GroupID == filter.GroupID) &&
+			(filter.SubjectID == 0 || schedule.SubjectID == filter.SubjectID) &&
+			(filter.TeacherID == 0 || schedule.TeacherID == filter.TeacherID) &&
+			(filter.DayOfWeek == 0 || schedule.DayOfWeek == filter.DayOfWeek)
+	}
+}
+
+func (r *scheduleRepository) List(filter models.ScheduleFilter, page models.ListQuery) ([]models.Schedule, models.PageInfo, error) {
+	return listPage(r.list(scheduleMatches(filter)), scheduleSorts, func(s models.Schedule) int { return s.ID }, page)
+}
+
+func (r *scheduleRepository) GetByGroupID(groupID int) ([]models.Schedule, error) {
+	return r.list(func(schedule models.Schedule) bool { return schedule.GroupID == groupID }), nil
+}
+
+func (r *scheduleRepository) GetByTeacherID(teacherID int) ([]models.Schedule, error) {
+	return r.list(func(schedule models.Schedule) bool { return schedule.TeacherID == teacherID }), nil
+}
+
+func (r *scheduleRepository) Create(schedule models.Schedule) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var err error
+	if schedule.StartTime, err = normalizeTime(schedule.StartTime); err != nil {
+		return 0, fmt.Errorf("failed to create schedule: %v", err)
+	}
+	if schedule.EndTime, err = normalizeTime(schedule.EndTime); err != nil {
+		return 0, fmt.Errorf("failed to create schedule: %v", err)
+	}
+	if err := r.s.checkScheduleRefs(schedule); err != nil {
+		return 0, fmt.Errorf("failed to create schedule: %v", err)
+	}
+	schedule.ID = 0
+	if err := r.s.checkSchedule(schedule); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	schedule.ID = r.s.nextID("schedules")
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+	schedule.GroupName, schedule.SubjectName, schedule.TeacherName = "", "", ""
+	r.s.schedules[schedule.ID] = schedule
+
+	return schedule.ID, nil
+}
+
+func (r *scheduleRepository) GetByID(scheduleID int) (*models.Schedule, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	schedule, ok := r.s.schedules[scheduleID]
+	if !ok {
+		return nil, fmt.Errorf("schedule not found")
+	}
+
+	return &schedule, nil
+}
+
+func (r *scheduleRepository) Update(scheduleID int, updates map[string]interface{}) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	schedule, ok := r.s.schedules[scheduleID]
+	if !ok {
+		return fmt.Errorf("schedule not found")
+	}
+
+	for column, value := range updates {
+		if !patch.ScheduleSchema.Allows(column) {
+			return fmt.Errorf("failed to update schedule: column %q cannot be updated", column)
+		}
+		if err := setScheduleColumn(&schedule, column, value); err != nil {
+			return fmt.Errorf("failed to update schedule: %v", err)
+		}
+	}
+	if err := r.s.checkScheduleRefs(schedule); err != nil {
+		return fmt.Errorf("failed to update schedule: %v", err)
+	}
+	if err := r.s.checkSchedule(schedule); err != nil {
+		return err
+	}
+
+	schedule.UpdatedAt = time.Now()
+	r.s.schedules[scheduleID] = schedule
+	return nil
+}
+
+func setScheduleColumn(schedule *models.Schedule, column string, value interface{}) error {
+	var err error
+
+	switch column {
+	case "group_id":
+		schedule.GroupID, err = toInt(value)
+	case "subject_id":
+		schedule.SubjectID, err = toInt(value)
+	case "teacher_id":
+		schedule.TeacherID, err = toInt(value)
+	case "day_of_week":
+		schedule.DayOfWeek, err = toInt(value)
+	case "start_time", "end_time":
+		var s string
+		if s, err = toString(value); err == nil {
+			if s, err = normalizeTime(s); err == nil {
+				if column == "start_time" {
+					schedule.StartTime = s
+				} else {
+					schedule.EndTime = s
+				}
+			}
+		}
+	case "location":
+		schedule.Location, err = toString(value)
+	default:
+		return fmt.Errorf("column %q does not exist", column)
+	}
+
+	return err
+}
+
+func (r *scheduleRepository) Delete(scheduleID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.schedules, scheduleID)
+
+	// lessons.schedule_id: ON DELETE SET NULL
+	for id, lesson := range r.s.lessons {
+		if lesson.ScheduleID != nil && *lesson.ScheduleID == scheduleID {
+			lesson.ScheduleID = nil
+			r.s.lessons[id] = lesson
+		}
+	}
+	return nil
+}
+
+func (r *scheduleRepository) Validate(schedule models.Schedule) error {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var err error
+	if schedule.StartTime, err = normalizeTime(schedule.StartTime); err != nil {
+		return fmt.Errorf("failed to check schedule conflicts: %v", err)
+	}
+	if schedule.EndTime, err = normalizeTime(schedule.EndTime); err != nil {
+		return fmt.Errorf("failed to check schedule conflicts: %v", err)
+	}
+	return r.s.checkSchedule(schedule)
+}
+
+// checkSchedule повторяет проверки core.CreateSchedule и core.UpdateSchedule: время окончания
+// позже начала и нет пересечений по преподавателю, группе или аудитории. Вызывать под s.mu
+func (s *Store) checkSchedule(schedule models.Schedule) error {
+	if schedule.EndTime <= schedule.StartTime {
+		return repository.ErrInvalidTimeRange
+	}
+
+	var conflicts []models.ScheduleConflict
+	for _, id := range sortedKeys(s.schedules) {
+		other := s.schedules[id]
+		if other.ID == schedule.ID || other.DayOfWeek != schedule.DayOfWeek ||
+			other.StartTime >= schedule.EndTime || other.EndTime <= schedule.StartTime {
+			continue
+		}
+		reasons := schedule.ConflictReasons(other)
+		if len(reasons) == 0 {
+			continue
+		}
+		if view, ok := s.scheduleView(other); ok {
+			conflicts = append(conflicts, models.ScheduleConflict{Schedule: view, Reasons: reasons})
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	// ORDER BY s.start_time, s.id
+	sort.SliceStable(conflicts, func(i, j int) bool { return conflicts[i].StartTime < conflicts[j].StartTime })
+	return &repository.ScheduleConflictError{Conflicts: conflicts}
+}
+
+// checkScheduleRefs повторяет внешние ключи и CHECK таблицы schedules. Вызывать под s.mu
+func (s *Store) checkScheduleRefs(schedule models.Schedule) error {
+	if _, ok := s.groups[schedule.GroupID]; !ok {
+		return fmt.Errorf("group %d does not exist", schedule.GroupID)
+	}
+	if _, ok := s.subjects[schedule.SubjectID]; !ok {
+		return fmt.Errorf("subject %d does not exist", schedule.SubjectID)
+	}
+	if _, ok := s.users[schedule.TeacherID]; !ok {
+		return fmt.Errorf("user %d does not exist", schedule.TeacherID)
+	}
+	if schedule.DayOfWeek < 1 || schedule.DayOfWeek > 7 {
+		return fmt.Errorf("day_of_week must be between 1 and 7")
+	}
+	return nil
+}
+
+// normalizeTime приводит время к виду, в котором PostgreSQL возвращает тип TIME
+func normalizeTime(value string) (string, error) {
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format("15:04:05"), nil
+		}
+	}
+	return "", fmt.Errorf("invalid input syntax for type time: %q", value)
+}
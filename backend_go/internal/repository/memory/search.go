@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"cmp"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/search"
+	"slices"
+	"strings"
+)
+
+type searchRepository struct {
+	s *Store
+}
+
+// Search повторяет core.Search, но похожесть считает search.Score вместо pg_trgm
+func (r *searchRepository) Search(scope policy.Scope, query models.SearchQuery) (*models.SearchResult, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	result := &models.SearchResult{Users: []models.SearchHit{}, Groups: []models.SearchHit{}, Subjects: []models.SearchHit{}}
+	if slices.Contains(query.Types, models.SearchUsers) {
+		var hits []models.SearchHit
+		var order []string
+		for _, id := range sortedKeys(r.s.users) {
+			user := r.s.users[id]
+			if !r.s.canSeeUser(scope, user) {
+				continue
+			}
+			view, ok := r.s.userView(user)
+			if !ok {
+				continue
+			}
+			text := strings.Join([]string{user.FirstName, user.MiddleName, user.LastName, user.Login}, " ")
+			if score, ok := searchScore(query.Variants, text); ok {
+				hits = append(hits, models.SearchHit{ID: id, Name: view.FullName(), Login: view.Login, Role: view.Role, Group: view.Group.String, Score: score})
+				order = append(order, user.LastName+" "+user.FirstName)
+			}
+		}
+		result.Users = searchTop(hits, order, query.Limit)
+	}
+	if slices.Contains(query.Types, models.SearchGroups) {
+		var hits []models.SearchHit
+		var order []string
+		for _, id := range sortedKeys(r.s.groups) {
+			group := r.s.groups[id]
+			if score, ok := searchScore(query.Variants, group.Name); ok {
+				hits = append(hits, models.SearchHit{ID: id, Name: group.Name, Score: score})
+				order = append(order, group.Name)
+			}
+		}
+		result.Groups = searchTop(hits, order, query.Limit)
+	}
+	if slices.Contains(query.Types, models.SearchSubjects) {
+		var hits []models.SearchHit
+		var order []string
+		for _, id := range sortedKeys(r.s.subjects) {
+			subject := r.s.subjects[id]
+			if score, ok := searchScore(query.Variants, subject.Name); ok {
+				hits = append(hits, models.SearchHit{ID: id, Name: subject.Name, Score: score})
+				order = append(order, subject.Name)
+			}
+		}
+		result.Subjects = searchTop(hits, order, query.Limit)
+	}
+
+	return result, nil
+}
+
+// searchScore — лучшая похожесть text на варианты запроса; ok, если она не ниже search.Threshold
+func searchScore(variants []string, text string) (float64, bool) {
+	text = search.Normalize(text)
+	best := 0.0
+	for _, variant := range variants {
+		best = max(best, search.Score(variant, text))
+	}
+	return best, best >= search.Threshold
+}
+
+// searchTop сортирует найденное от лучших совпадений к худшим (равные — по order[i], затем по ID)
+// и оставляет первые limit; hits идут по возрастанию ID
+func searchTop(hits []models.SearchHit, order []string, limit int) []models.SearchHit {
+	index := make([]int, len(hits))
+	for i := range index {
+		index[i] = i
+	}
+	slices.SortStableFunc(index, func(a, b int) int {
+		if c := cmp.Compare(hits[b].Score, hits[a].Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(order[a], order[b])
+	})
+
+	top := []models.SearchHit{}
+	for _, i := range index {
+		if len(top) == limit {
+			break
+		}
+		top = append(top, hits[i])
+	}
+	return top
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/search"
+	"slices"
+	"testing"
+)
+
+func TestSearchRanking(t *testing.T) {
+	store := New()
+	repos := store.Repositories()
+	for _, user := range []models.User{
+		{FirstName: "Пётр", LastName: "Ивнов", Login: "pivnov"},
+		{FirstName: "Мария", LastName: "Иванова", Login: "mivanova"},
+		{FirstName: "Сергей", LastName: "Петров", Login: "spetrov"},
+		{FirstName: "Иван", LastName: "Иванов", Login: "iivanov"},
+	} {
+		user.RoleID = 3
+		if _, err := repos.Users.Register(user); err != nil {
+			t.Fatalf("Register %s: %v", user.Login, err)
+		}
+	}
+	for _, name := range []string{"ПИ-21", "ИС-22", "ИС-21"} {
+		if _, err := repos.Groups.Create(models.Group{Name: name}); err != nil {
+			t.Fatalf("Create group %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		types  []string
+		limit  int
+		users  []string
+		groups []string
+	}{
+		// Полные совпадения — по фамилии и имени, за ними опечатки
+		{"cyrillic", "иванов", models.SearchTypes, 10, []string{"iivanov", "mivanova", "pivnov"}, []string{}},
+		{"transliteration", "Ivanov", models.SearchTypes, 10, []string{"iivanov", "mivanova", "pivnov"}, []string{}},
+		{"wrong layout", "bdfyjd", models.SearchTypes, 10, []string{"iivanov", "mivanova", "pivnov"}, []string{}},
+		{"typo in the query", "ивнов", models.SearchTypes, 10, []string{"pivnov", "iivanov", "mivanova"}, []string{}},
+		{"limit", "иванов", models.SearchTypes, 2, []string{"iivanov", "mivanova"}, []string{}},
+		{"login", "spetrov", models.SearchTypes, 10, []string{"spetrov"}, []string{}},
+		{"groups by name", "ис-2", []string{models.SearchGroups}, 10, []string{}, []string{"ИС-21", "ИС-22"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := repos.Search.Search(policy.System(), models.SearchQuery{Variants: search.Variants(tt.query), Types: tt.types, Limit: tt.limit})
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+
+			users := []string{}
+			for i, hit := range result.Users {
+				users = append(users, hit.Login)
+				if i > 0 && hit.Score > result.Users[i-1].Score {
+					t.Errorf("user %s scored %v above the previous hit", hit.Login, hit.Score)
+				}
+			}
+			groups := []string{}
+			for _, hit := range result.Groups {
+				groups = append(groups, hit.Name)
+			}
+			if !slices.Equal(users, tt.users) {
+				t.Errorf("users = %q, want %q", users, tt.users)
+			}
+			if !slices.Equal(groups, tt.groups) {
+				t.Errorf("groups = %q, want %q", groups, tt.groups)
+			}
+		})
+	}
+}
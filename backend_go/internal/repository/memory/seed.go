@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/pkg"
+	"time"
+)
+
+// DemoPassword — пароль всех пользователей, которых создаёт SeedDemo
+const DemoPassword = "demo12345"
+
+// SeedDemo наполняет хранилище небольшим набором данных для демо-режима:
+// пользователи admin, teacher, student1 и student2 с паролем DemoPassword,
+// одна группа, два предмета, расписание, занятия по нему, текущий учебный год с двумя семестрами,
+// оценки и посещаемость.
+func (s *Store) SeedDemo() error {
+	repos := s.Repositories()
+
+	groupID, err := repos.Groups.Create(models.Group{Name: "ИС-21"})
+	if err != nil {
+		return err
+	}
+	mathID, err := repos.Subjects.Create(models.Subject{Name: "Математика"})
+	if err != nil {
+		return err
+	}
+	programmingID, err := repos.Subjects.Create(models.Subject{Name: "Программирование"})
+	if err != nil {
+		return err
+	}
+
+	hash, err := pkg.CreateHashWithSalt(DemoPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash demo password: %v", err)
+	}
+
+	register := func(login, firstName, lastName string, roleID int, groupID *int) (int, error) {
+		return repos.Users.Register(models.User{
+			FirstName: firstName,
+			LastName:  lastName,
+			RoleID:    roleID,
+			GroupID:   groupID,
+			Login:     login,
+			Password:  hash.Hash,
+			Salt:      hash.Salt,
+		})
+	}
+
+	if _, err := register("admin", "Анна", "Смирнова", 1, nil); err != nil {
+		return err
+	}
+	teacherID, err := register("teacher", "Иван", "Петров", 2, nil)
+	if err != nil {
+		return err
+	}
+	student1ID, err := register("student1", "Мария", "Иванова", 3, &groupID)
+	if err != nil {
+		return err
+	}
+	student2ID, err := register("student2", "Алексей", "Кузнецов", 3, &groupID)
+	if err != nil {
+		return err
+	}
+
+	schedules := []models.Schedule{
+		{GroupID: groupID, SubjectID: mathID, TeacherID: teacherID, DayOfWeek: 1, StartTime: "09:00", EndTime: "10:30", Location: "101"},
+		{GroupID: groupID, SubjectID: programmingID, TeacherID: teacherID, DayOfWeek: 2, StartTime: "10:40", EndTime: "12:10", Location: "205"},
+	}
+	for _, schedule := range schedules {
+		if _, err := repos.Schedules.Create(schedule); err != nil {
+			return err
+		}
+	}
+
+	today := truncateDate(time.Now())
+	// Занятия за четыре недели вокруг текущей даты, чтобы было к чему привязывать отметки
+	if _, err := repos.Lessons.Generate(today.AddDate(0, 0, -14), today.AddDate(0, 0, 14), 0); err != nil {
+		return err
+	}
+
+	// Учебный год с 1 сентября по 30 июня, в который попадает сегодняшний день, и два семестра с границей 1 января
+	year := today.Year()
+	if today.Month() < time.August {
+		year--
+	}
+	yearStart := time.Date(year, time.September, 1, 0, 0, 0, 0, time.UTC)
+	yearEnd := time.Date(year+1, time.June, 30, 0, 0, 0, 0, time.UTC)
+	yearID, err := repos.Terms.CreateYear(models.AcademicYear{
+		Name:      fmt.Sprintf("%d/%d", yearStart.Year(), yearEnd.Year()),
+		StartDate: yearStart,
+		EndDate:   yearEnd,
+	})
+	if err != nil {
+		return err
+	}
+	terms := []models.Term{
+		{AcademicYearID: yearID, Name: "1 семестр", StartDate: yearStart, EndDate: time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)},
+		{AcademicYearID: yearID, Name: "2 семестр", StartDate: time.Date(year+1, time.January, 1, 0, 0, 0, 0, time.UTC), EndDate: yearEnd},
+	}
+	for _, term := range terms {
+		if _, err := repos.Terms.Create(term); err != nil {
+			return err
+		}
+	}
+
+	grades := []models.Grade{
+		{StudentID: student1ID, SubjectID: mathID, Value: 5, Date: today.AddDate(0, 0, -7)},
+		{StudentID: student1ID, SubjectID: programmingID, Value: 4, Date: today.AddDate(0, 0, -6)},
+		{StudentID: student2ID, SubjectID: mathID, Value: 3, Date: today.AddDate(0, 0, -7)},
+	}
+	for _, grade := range grades {
+		if _, err := repos.Grades.Create(policy.System(), grade, ""); err != nil {
+			return err
+		}
+	}
+
+	attendance := []models.Attendance{
+		{StudentID: student1ID, SubjectID: mathID, Date: today.AddDate(0, 0, -7), Status: "present"},
+		{StudentID: student2ID, SubjectID: mathID, Date: today.AddDate(0, 0, -7), Status: "absent"},
+	}
+	for _, a := range attendance {
+		if _, err := repos.Attendance.Create(policy.System(), a, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
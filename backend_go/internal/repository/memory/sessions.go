@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"time"
+)
+
+type sessionRepository struct {
+	s *Store
+}
+
+func (r *sessionRepository) Create(sessionID string, userID int, refreshHash string, ttl time.Duration) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	r.s.sessions[sessionID] = models.Session{
+		ID:        sessionID,
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	r.s.refreshTokens[refreshHash] = models.RefreshToken{
+		ID:        r.s.nextID("refresh_tokens"),
+		SessionID: sessionID,
+		TokenHash: refreshHash,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) RotateRefreshToken(oldHash, newHash string, ttl time.Duration) (*models.Session, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	token, ok := r.s.refreshTokens[oldHash]
+	if !ok {
+		return nil, repository.ErrInvalidRefreshToken
+	}
+	session, ok := r.s.sessions[token.SessionID]
+	if !ok || session.RevokedAt != nil {
+		return nil, repository.ErrInvalidRefreshToken
+	}
+
+	now := time.Now()
+	if token.UsedAt != nil {
+		session.RevokedAt = &now
+		r.s.sessions[session.ID] = session
+		return nil, repository.ErrRefreshTokenReused
+	}
+	if now.After(token.ExpiresAt) || now.After(session.ExpiresAt) {
+		return nil, repository.ErrInvalidRefreshToken
+	}
+
+	token.UsedAt = &now
+	r.s.refreshTokens[oldHash] = token
+
+	session.ExpiresAt = now.Add(ttl)
+	r.s.sessions[session.ID] = session
+
+	r.s.refreshTokens[newHash] = models.RefreshToken{
+		ID:        r.s.nextID("refresh_tokens"),
+		SessionID: session.ID,
+		TokenHash: newHash,
+		ExpiresAt: session.ExpiresAt,
+		CreatedAt: now,
+	}
+
+	return &session, nil
+}
+
+func (r *sessionRepository) IsActive(sessionID string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	session, ok := r.s.sessions[sessionID]
+	if !ok {
+		return false, nil
+	}
+
+	return session.RevokedAt == nil && time.Now().Before(session.ExpiresAt), nil
+}
+
+func (r *sessionRepository) Revoke(sessionID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if session, ok := r.s.sessions[sessionID]; ok && session.RevokedAt == nil {
+		now := time.Now()
+		session.RevokedAt = &now
+		r.s.sessions[sessionID] = session
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) RevokeAllForUser(userID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	for id, session := range r.s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			r.s.sessions[id] = session
+		}
+	}
+
+	return nil
+}
+
+// deleteUserSessions удаляет сессии и их refresh-токены (каскад при удалении пользователя). Вызывать под s.mu.Lock
+func (s *Store) deleteUserSessions(userID int) {
+	for id, session := range s.sessions {
+		if session.UserID == userID {
+			delete(s.sessions, id)
+		}
+	}
+	for hash, token := range s.refreshTokens {
+		if _, ok := s.sessions[token.SessionID]; !ok {
+			delete(s.refreshTokens, hash)
+		}
+	}
+}
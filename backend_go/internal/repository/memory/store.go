@@ -0,0 +1,107 @@
+// Package memory реализует хранилища из internal/repository в памяти процесса.
+//
+// Поведение повторяет PostgreSQL-реализацию: те же тексты ошибок («... not found»),
+// те же правила каскадного удаления, что и внешние ключи в миграциях.
+// Используется в тестах и в демо-режиме сервера (STORAGE=memory).
+package memory
+
+import (
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"sort"
+	"sync"
+)
+
+// Store — общее состояние всех хранилищ; все методы безопасны для конкурентного вызова
+type Store struct {
+	mu sync.RWMutex
+
+	roles          map[int]models.Role
+	permissions    []models.Permission
+	users          map[int]models.User
+	groups         map[int]models.Group
+	subjects       map[int]models.Subject
+	gradeTypes     map[int]models.GradeType
+	gradeWeights   map[int]map[int]float64 // вес вида оценки в предмете: subject_id -> grade_type_id -> вес
+	schedules      map[int]models.Schedule
+	lessons        map[int]models.Lesson
+	academicYears  map[int]models.AcademicYear
+	terms          map[int]models.Term
+	grades         map[int]models.Grade
+	finalGrades    map[int]models.FinalGrade
+	attendance     map[int]models.Attendance
+	memberships    map[int]models.GroupMembership // история групп студентов, как group_memberships
+	history        []models.RecordChange          // только добавляется, как record_history
+	auditLog       []models.AuditEntry            // только добавляется, как audit_log
+	sessions       map[string]models.Session
+	refreshTokens  map[string]models.RefreshToken // по хешу токена
+	calendarTokens map[int]string                 // хеш токена подписки по ID пользователя
+
+	lastID map[string]int
+}
+
+// New создаёт пустое хранилище с ролями admin (1), teacher (2) и student (3), каталогом прав
+// и видами оценок, как в миграциях
+func New() *Store {
+	s := &Store{
+		roles:          make(map[int]models.Role),
+		users:          make(map[int]models.User),
+		groups:         make(map[int]models.Group),
+		subjects:       make(map[int]models.Subject),
+		gradeTypes:     make(map[int]models.GradeType),
+		gradeWeights:   make(map[int]map[int]float64),
+		schedules:      make(map[int]models.Schedule),
+		lessons:        make(map[int]models.Lesson),
+		academicYears:  make(map[int]models.AcademicYear),
+		terms:          make(map[int]models.Term),
+		grades:         make(map[int]models.Grade),
+		finalGrades:    make(map[int]models.FinalGrade),
+		attendance:     make(map[int]models.Attendance),
+		memberships:    make(map[int]models.GroupMembership),
+		sessions:       make(map[string]models.Session),
+		refreshTokens:  make(map[string]models.RefreshToken),
+		calendarTokens: make(map[int]string),
+		lastID:         make(map[string]int),
+	}
+	s.seedRoles()
+	s.seedGradeTypes()
+	return s
+}
+
+// Repositories возвращает хранилища, работающие с этим Store
+func (s *Store) Repositories() *repository.Repositories {
+	return &repository.Repositories{
+		Users:       &userRepository{s: s},
+		Sessions:    &sessionRepository{s: s},
+		Calendar:    &calendarTokenRepository{s: s},
+		Roles:       &roleRepository{s: s},
+		Groups:      &groupRepository{s: s},
+		Subjects:    &subjectRepository{s: s},
+		GradeTypes:  &gradeTypeRepository{s: s},
+		Schedules:   &scheduleRepository{s: s},
+		Lessons:     &lessonRepository{s: s},
+		Terms:       &termRepository{s: s},
+		Grades:      &gradeRepository{s: s},
+		FinalGrades: &finalGradeRepository{s: s},
+		Attendance:  &attendanceRepository{s: s},
+		Journal:     &journalRepository{s: s},
+		History:     &historyRepository{s: s},
+		Audit:       &auditRepository{s: s},
+		Search:      &searchRepository{s: s},
+	}
+}
+
+// nextID выдаёт следующий идентификатор таблицы, как SERIAL. Вызывать под s.mu.Lock
+func (s *Store) nextID(table string) int {
+	s.lastID[table]++
+	return s.lastID[table]
+}
+
+func sortedKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"sort"
+	"strings"
+	"time"
+)
+
+type subjectRepository struct {
+	s *Store
+}
+
+func (r *subjectRepository) GetAll() ([]models.Subject, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var subjects []models.Subject
+	for _, id := range sortedKeys(r.s.subjects) {
+		subjects = append(subjects, r.s.subjects[id])
+	}
+	// ORDER BY name
+	sort.SliceStable(subjects, func(i, j int) bool { return subjects[i].Name < subjects[j].Name })
+
+	return subjects, nil
+}
+
+func (r *subjectRepository) GetByID(subjectID int) (*models.Subject, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	subject, ok := r.s.subjects[subjectID]
+	if !ok {
+		return nil, fmt.Errorf("subject not found")
+	}
+	return &subject, nil
+}
+
+func (r *subjectRepository) Create(subject models.Subject) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.subjectNameTaken(0, subject.Name) {
+		return 0, repository.ErrSubjectExists
+	}
+
+	now := time.Now()
+	subject.ID = r.s.nextID("subjects")
+	subject.CreatedAt = now
+	subject.UpdatedAt = now
+	r.s.subjects[subject.ID] = subject
+
+	return subject.ID, nil
+}
+
+func (r *subjectRepository) Update(subject models.Subject) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	existing, ok := r.s.subjects[subject.ID]
+	if !ok {
+		return fmt.Errorf("subject not found")
+	}
+	if r.s.subjectNameTaken(subject.ID, subject.Name) {
+		return repository.ErrSubjectExists
+	}
+
+	existing.Name = subject.Name
+	existing.UpdatedAt = time.Now()
+	r.s.subjects[subject.ID] = existing
+
+	return nil
+}
+
+func (r *subjectRepository) Delete(subjectID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.subjects[subjectID]; !ok {
+		return fmt.Errorf("subject not found")
+	}
+
+	// Внешние ключи на subjects без ON DELETE: удаление запрещено, пока есть ссылки
+	counts := make(map[string]int)
+	for _, schedule := range r.s.schedules {
+		if schedule.SubjectID == subjectID {
+			counts["schedules"]++
+		}
+	}
+	for _, lesson := range r.s.lessons {
+		if lesson.SubjectID == subjectID {
+			counts["lessons"]++
+		}
+	}
+	for _, grade := range r.s.grades {
+		if grade.SubjectID == subjectID {
+			counts["grades"]++
+		}
+	}
+	for _, finalGrade := range r.s.finalGrades {
+		if finalGrade.SubjectID == subjectID {
+			counts["final_grades"]++
+		}
+	}
+	for _, attendance := range r.s.attendance {
+		if attendance.SubjectID == subjectID {
+			counts["attendance"]++
+		}
+	}
+	if err := repository.CheckInUse("subject", counts); err != nil {
+		return err
+	}
+
+	delete(r.s.subjects, subjectID)
+	// subject_grade_weights.subject_id: ON DELETE CASCADE
+	delete(r.s.gradeWeights, subjectID)
+	return nil
+}
+
+// subjectNameTaken повторяет уникальный индекс subjects_name_key по LOWER(name). Вызывать под s.mu
+func (s *Store) subjectNameTaken(subjectID int, name string) bool {
+	for _, subject := range s.subjects {
+		if subject.ID != subjectID && strings.EqualFold(subject.Name, name) {
+			return true
+		}
+	}
+	return false
+}
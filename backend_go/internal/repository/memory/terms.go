@@ -0,0 +1,276 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/repository"
+	"sort"
+	"time"
+)
+
+type termRepository struct {
+	s *Store
+}
+
+func (r *termRepository) GetYears() ([]models.AcademicYear, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var years []models.AcademicYear
+	for _, id := range sortedKeys(r.s.academicYears) {
+		years = append(years, r.s.academicYears[id])
+	}
+	sort.SliceStable(years, func(i, j int) bool { return years[i].StartDate.Before(years[j].StartDate) })
+
+	return years, nil
+}
+
+func (r *termRepository) GetYearByID(yearID int) (*models.AcademicYear, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	year, ok := r.s.academicYears[yearID]
+	if !ok {
+		return nil, fmt.Errorf("academic year not found")
+	}
+	return &year, nil
+}
+
+// checkAcademicYear повторяет core.checkAcademicYear. Вызывать под s.mu
+func (s *Store) checkAcademicYear(year models.AcademicYear) error {
+	if !year.EndDate.After(year.StartDate) {
+		return repository.ErrInvalidDateRange
+	}
+	for id, other := range s.academicYears {
+		if id != year.ID && other.Name == year.Name {
+			return repository.ErrAcademicYearExists
+		}
+	}
+	return nil
+}
+
+func (r *termRepository) CreateYear(year models.AcademicYear) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	year.ID = 0
+	if err := r.s.checkAcademicYear(year); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	year.ID = r.s.nextID("academic_years")
+	year.StartDate = truncateDate(year.StartDate)
+	year.EndDate = truncateDate(year.EndDate)
+	year.CreatedAt = now
+	year.UpdatedAt = now
+	r.s.academicYears[year.ID] = year
+
+	return year.ID, nil
+}
+
+func (r *termRepository) UpdateYear(year models.AcademicYear) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	existing, ok := r.s.academicYears[year.ID]
+	if !ok {
+		return fmt.Errorf("academic year not found")
+	}
+	if err := r.s.checkAcademicYear(year); err != nil {
+		return err
+	}
+
+	start, end := truncateDate(year.StartDate), truncateDate(year.EndDate)
+	for _, term := range r.s.terms {
+		if term.AcademicYearID == year.ID && (term.StartDate.Before(start) || term.EndDate.After(end)) {
+			return repository.ErrTermOutsideYear
+		}
+	}
+
+	existing.Name = year.Name
+	existing.StartDate = start
+	existing.EndDate = end
+	existing.UpdatedAt = time.Now()
+	r.s.academicYears[year.ID] = existing
+
+	return nil
+}
+
+func (r *termRepository) DeleteYear(yearID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.academicYears[yearID]; !ok {
+		return fmt.Errorf("academic year not found")
+	}
+
+	counts := make(map[string]int)
+	for _, term := range r.s.terms {
+		if term.AcademicYearID == yearID {
+			counts["terms"]++
+		}
+	}
+	if err := repository.CheckInUse("academic year", counts); err != nil {
+		return err
+	}
+
+	delete(r.s.academicYears, yearID)
+	return nil
+}
+
+// termView дополняет период названием года, как core.GetTerms. Вызывать под s.mu
+func (s *Store) termView(term models.Term) models.Term {
+	term.AcademicYearName = s.academicYears[term.AcademicYearID].Name
+	return term
+}
+
+// sortTerms упорядочивает периоды как core.GetTerms: по началу, затем по ID
+func sortTerms(terms []models.Term) {
+	sort.SliceStable(terms, func(i, j int) bool { return terms[i].StartDate.Before(terms[j].StartDate) })
+}
+
+func (r *termRepository) GetAll(yearID int) ([]models.Term, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var terms []models.Term
+	for _, id := range sortedKeys(r.s.terms) {
+		term := r.s.terms[id]
+		if yearID == 0 || term.AcademicYearID == yearID {
+			terms = append(terms, r.s.termView(term))
+		}
+	}
+	sortTerms(terms)
+
+	return terms, nil
+}
+
+func (r *termRepository) GetByID(termID int) (*models.Term, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	term, ok := r.s.terms[termID]
+	if !ok {
+		return nil, fmt.Errorf("term not found")
+	}
+	term = r.s.termView(term)
+	return &term, nil
+}
+
+func (r *termRepository) GetCurrent(date time.Time) (*models.Term, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var terms []models.Term
+	for _, id := range sortedKeys(r.s.terms) {
+		if term := r.s.terms[id]; term.Covers(date) {
+			terms = append(terms, r.s.termView(term))
+		}
+	}
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("term not found")
+	}
+	sortTerms(terms)
+
+	return &terms[0], nil
+}
+
+// checkTerm повторяет core.checkTerm. Вызывать под s.mu
+func (s *Store) checkTerm(term models.Term) error {
+	if !term.EndDate.After(term.StartDate) {
+		return repository.ErrInvalidDateRange
+	}
+
+	year, ok := s.academicYears[term.AcademicYearID]
+	if !ok {
+		return fmt.Errorf("academic year not found")
+	}
+	start, end := truncateDate(term.StartDate), truncateDate(term.EndDate)
+	if start.Before(year.StartDate) || end.After(year.EndDate) {
+		return repository.ErrTermOutsideYear
+	}
+
+	overlaps := false
+	for id, other := range s.terms {
+		if id == term.ID || other.AcademicYearID != term.AcademicYearID {
+			continue
+		}
+		if other.Name == term.Name {
+			return repository.ErrTermExists
+		}
+		if !other.StartDate.After(end) && !other.EndDate.Before(start) {
+			overlaps = true
+		}
+	}
+	if overlaps {
+		return repository.ErrTermOverlap
+	}
+
+	return nil
+}
+
+func (r *termRepository) Create(term models.Term) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	term.ID = 0
+	if err := r.s.checkTerm(term); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	term.ID = r.s.nextID("terms")
+	term.AcademicYearName = ""
+	term.StartDate = truncateDate(term.StartDate)
+	term.EndDate = truncateDate(term.EndDate)
+	term.CreatedAt = now
+	term.UpdatedAt = now
+	r.s.terms[term.ID] = term
+
+	return term.ID, nil
+}
+
+func (r *termRepository) Update(term models.Term) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	existing, ok := r.s.terms[term.ID]
+	if !ok {
+		return fmt.Errorf("term not found")
+	}
+	if err := r.s.checkTerm(term); err != nil {
+		return err
+	}
+
+	existing.AcademicYearID = term.AcademicYearID
+	existing.Name = term.Name
+	existing.StartDate = truncateDate(term.StartDate)
+	existing.EndDate = truncateDate(term.EndDate)
+	existing.UpdatedAt = time.Now()
+	r.s.terms[term.ID] = existing
+
+	return nil
+}
+
+func (r *termRepository) Delete(termID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.terms[termID]; !ok {
+		return fmt.Errorf("term not found")
+	}
+
+	counts := make(map[string]int)
+	for _, finalGrade := range r.s.finalGrades {
+		if finalGrade.TermID == termID {
+			counts["final_grades"]++
+		}
+	}
+	if err := repository.CheckInUse("term", counts); err != nil {
+		return err
+	}
+
+	delete(r.s.terms, termID)
+	return nil
+}
@@ -0,0 +1,346 @@
+package memory
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/patch"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"github.com/VladislavSCV/pkg"
+	"time"
+)
+
+type userRepository struct {
+	s *Store
+}
+
+// userView повторяет выборку core.ListUsers: роль и группа по названию, без пароля. Вызывать под s.mu
+func (s *Store) userView(u models.User) (models.User, bool) {
+	role, ok := s.roles[u.RoleID]
+	if !ok {
+		return models.User{}, false
+	}
+
+	view := models.User{
+		ID:         u.ID,
+		FirstName:  u.FirstName,
+		MiddleName: u.MiddleName,
+		LastName:   u.LastName,
+		Role:       role.Name,
+		Login:      u.Login,
+		CreatedAt:  u.CreatedAt,
+		UpdatedAt:  u.UpdatedAt,
+	}
+	if u.GroupID != nil {
+		if group, ok := s.groups[*u.GroupID]; ok {
+			view.Group = sql.NullString{String: group.Name, Valid: true}
+		}
+	}
+
+	return view, true
+}
+
+var userSorts = listSorts[models.User]{
+	"id":         func(u models.User) interface{} { return u.ID },
+	"last_name":  func(u models.User) interface{} { return u.LastName },
+	"first_name": func(u models.User) interface{} { return u.FirstName },
+	"login":      func(u models.User) interface{} { return u.Login },
+	"role":       func(u models.User) interface{} { return u.Role },
+	"group":      func(u models.User) interface{} { return u.Group.String },
+	"created_at": func(u models.User) interface{} { return u.CreatedAt },
+}
+
+// list возвращает пользователей под фильтром, которых видит scope (nil — все)
+func (r *userRepository) list(scope *policy.Scope, filter models.UserFilter) []models.User {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var users []models.User
+	for _, id := range sortedKeys(r.s.users) {
+		user := r.s.users[id]
+		if scope != nil && !r.s.canSeeUser(*scope, user) {
+			continue
+		}
+		if filter.GroupID != 0 && (user.GroupID == nil || *user.GroupID != filter.GroupID) {
+			continue
+		}
+		view, ok := r.s.userView(user)
+		if !ok || (filter.Role != "" && view.Role != filter.Role) {
+			continue
+		}
+		users = append(users, view)
+	}
+
+	return users
+}
+
+func (r *userRepository) List(scope policy.Scope, filter models.UserFilter, page models.ListQuery) ([]models.User, models.PageInfo, error) {
+	return listPage(r.list(&scope, filter), userSorts, func(u models.User) int { return u.ID }, page)
+}
+
+func (r *userRepository) GetTeachers() ([]models.User, error) {
+	return r.list(nil, models.UserFilter{Role: "teacher"}), nil
+}
+
+func (r *userRepository) GetByID(scope policy.Scope, userID int) (*models.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	user, ok := r.s.users[userID]
+	if !ok || !r.s.canSeeUser(scope, user) {
+		return nil, fmt.Errorf("user not found")
+	}
+	view, ok := r.s.userView(user)
+	if !ok {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	return &view, nil
+}
+
+func (r *userRepository) Update(userID int, updates map[string]interface{}) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user, ok := r.s.users[userID]
+	if !ok {
+		return fmt.Errorf("user not found")
+	}
+
+	for column, value := range updates {
+		if !patch.UserSchema.Allows(column) {
+			return fmt.Errorf("failed to update user: column %q cannot be updated", column)
+		}
+		if err := setUserColumn(&user, column, value); err != nil {
+			return fmt.Errorf("failed to update user: %v", err)
+		}
+	}
+	for id, existing := range r.s.users {
+		if id != userID && existing.Login == user.Login {
+			return repository.ErrLoginExists
+		}
+	}
+
+	user.UpdatedAt = time.Now()
+	r.s.users[userID] = user
+	return nil
+}
+
+func setUserColumn(user *models.User, column string, value interface{}) error {
+	var err error
+
+	switch column {
+	case "first_name":
+		user.FirstName, err = toString(value)
+	case "middle_name":
+		user.MiddleName, err = toString(value)
+	case "last_name":
+		user.LastName, err = toString(value)
+	case "login":
+		user.Login, err = toString(value)
+	default:
+		return fmt.Errorf("column %q does not exist", column)
+	}
+
+	return err
+}
+
+func (r *userRepository) Delete(userID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, schedule := range r.s.schedules {
+		if schedule.TeacherID == userID {
+			return fmt.Errorf("failed to delete user: user is referenced by schedules")
+		}
+	}
+	for _, lesson := range r.s.lessons {
+		if lesson.TeacherID == userID {
+			return fmt.Errorf("failed to delete user: user is referenced by lessons")
+		}
+	}
+
+	delete(r.s.users, userID)
+
+	// ON DELETE CASCADE
+	for id, grade := range r.s.grades {
+		if grade.StudentID == userID {
+			delete(r.s.grades, id)
+		}
+	}
+	for id, attendance := range r.s.attendance {
+		if attendance.StudentID == userID {
+			delete(r.s.attendance, id)
+		}
+	}
+	for id, finalGrade := range r.s.finalGrades {
+		if finalGrade.StudentID == userID {
+			delete(r.s.finalGrades, id)
+		} else if finalGrade.LockedBy != nil && *finalGrade.LockedBy == userID {
+			// final_grades.locked_by: ON DELETE SET NULL
+			finalGrade.LockedBy = nil
+			r.s.finalGrades[id] = finalGrade
+		}
+	}
+	for id, membership := range r.s.memberships {
+		if membership.StudentID == userID {
+			delete(r.s.memberships, id)
+		}
+	}
+	r.s.deleteUserSessions(userID)
+	delete(r.s.calendarTokens, userID)
+
+	return nil
+}
+
+func (r *userRepository) Register(user models.User) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, existing := range r.s.users {
+		if existing.Login == user.Login {
+			return 0, repository.ErrLoginExists
+		}
+	}
+	if _, ok := r.s.roles[user.RoleID]; !ok {
+		return 0, fmt.Errorf("failed to register user: role %d does not exist", user.RoleID)
+	}
+	if user.GroupID != nil {
+		if _, ok := r.s.groups[*user.GroupID]; !ok {
+			return 0, fmt.Errorf("failed to register user: group %d does not exist", *user.GroupID)
+		}
+	}
+
+	now := time.Now()
+	user.ID = r.s.nextID("users")
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	user.Role = ""
+	user.Group = sql.NullString{}
+	r.s.users[user.ID] = user
+	if user.GroupID != nil && r.s.roles[user.RoleID].Name == "student" {
+		r.s.addMembership(user.ID, *user.GroupID, nil)
+	}
+
+	return user.ID, nil
+}
+
+// ImportStudents повторяет core.ImportStudents. Сначала проверяются все строки, и только потом
+// создаются группы и студенты, поэтому откатывать нечего
+func (r *userRepository) ImportStudents(students []models.StudentImport, dryRun bool) (*models.ImportResult, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	roleID := 0
+	for id, role := range r.s.roles {
+		if role.Name == "student" {
+			roleID = id
+		}
+	}
+	if roleID == 0 {
+		return nil, fmt.Errorf("failed to get student role: %v", sql.ErrNoRows)
+	}
+
+	logins := make(map[string]bool, len(r.s.users))
+	for _, user := range r.s.users {
+		logins[user.Login] = true
+	}
+	groups := make(map[string]int)
+	for id, group := range r.s.groups {
+		groups[group.Name] = id
+	}
+
+	result := models.NewImportResult(dryRun)
+	newGroups := make(map[string]bool)
+	for _, student := range students {
+		if logins[student.Login] {
+			result.AddError(student.Line, "login", "login already exists")
+			continue
+		}
+		logins[student.Login] = true
+		if _, ok := groups[student.GroupName]; !ok && !newGroups[student.GroupName] {
+			newGroups[student.GroupName] = true
+			result.CreatedGroups = append(result.CreatedGroups, models.ImportedGroup{Name: student.GroupName})
+		}
+		result.Students = append(result.Students, models.ImportedStudent{
+			Line:    student.Line,
+			Login:   student.Login,
+			Name:    student.FullName(),
+			Group:   student.GroupName,
+			GroupID: groups[student.GroupName],
+		})
+	}
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	now := time.Now()
+	for i, created := range result.CreatedGroups {
+		group := models.Group{ID: r.s.nextID("groups"), Name: created.Name, CreatedAt: now, UpdatedAt: now}
+		r.s.groups[group.ID] = group
+		groups[group.Name] = group.ID
+		result.CreatedGroups[i].ID = group.ID
+	}
+	for i, student := range students {
+		groupID := groups[student.GroupName]
+		user := models.User{
+			ID:         r.s.nextID("users"),
+			FirstName:  student.FirstName,
+			MiddleName: student.MiddleName,
+			LastName:   student.LastName,
+			RoleID:     roleID,
+			GroupID:    &groupID,
+			Login:      student.Login,
+			Password:   student.Password,
+			Salt:       student.Salt,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		r.s.users[user.ID] = user
+		r.s.addMembership(user.ID, groupID, nil)
+		result.Students[i].UserID, result.Students[i].GroupID = user.ID, groupID
+	}
+	result.Committed = true
+
+	return result, nil
+}
+
+func (r *userRepository) Authenticate(login, password string) (*models.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, user := range r.s.users {
+		if user.Login != login {
+			continue
+		}
+		if isValid, err := pkg.VerifyPassword(password, user.Salt, user.Password); err != nil || !isValid {
+			return nil, fmt.Errorf("invalid password")
+		}
+		return &user, nil
+	}
+
+	return nil, fmt.Errorf("user not found")
+}
+
+func (r *userRepository) GetCurrent(userID int) (*models.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	user, ok := r.s.users[userID]
+	if !ok {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	return &models.User{
+		ID:         user.ID,
+		FirstName:  user.FirstName,
+		MiddleName: user.MiddleName,
+		LastName:   user.LastName,
+		RoleID:     user.RoleID,
+		GroupID:    user.GroupID,
+		Login:      user.Login,
+		CreatedAt:  user.CreatedAt,
+		UpdatedAt:  user.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,483 @@
+// Package postgres реализует хранилища из internal/repository поверх PostgreSQL.
+// Сами запросы живут в internal/core; здесь только привязка к *sql.DB.
+package postgres
+
+import (
+	"database/sql"
+	"github.com/VladislavSCV/internal/core"
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"github.com/VladislavSCV/internal/repository"
+	"time"
+)
+
+// New возвращает все хранилища, работающие с переданным пулом соединений
+func New(db *sql.DB) *repository.Repositories {
+	return &repository.Repositories{
+		Users:       &userRepository{db: db},
+		Sessions:    &sessionRepository{db: db},
+		Calendar:    &calendarTokenRepository{db: db},
+		Roles:       &roleRepository{db: db},
+		Groups:      &groupRepository{db: db},
+		Subjects:    &subjectRepository{db: db},
+		GradeTypes:  &gradeTypeRepository{db: db},
+		Schedules:   &scheduleRepository{db: db},
+		Lessons:     &lessonRepository{db: db},
+		Terms:       &termRepository{db: db},
+		Grades:      &gradeRepository{db: db},
+		FinalGrades: &finalGradeRepository{db: db},
+		Attendance:  &attendanceRepository{db: db},
+		Journal:     &journalRepository{db: db},
+		History:     &historyRepository{db: db},
+		Audit:       &auditRepository{db: db},
+		Search:      &searchRepository{db: db},
+	}
+}
+
+type userRepository struct {
+	db *sql.DB
+}
+
+func (r *userRepository) List(scope policy.Scope, filter models.UserFilter, page models.ListQuery) ([]models.User, models.PageInfo, error) {
+	return core.ListUsers(r.db, scope, filter, page)
+}
+
+func (r *userRepository) Export(scope policy.Scope, filter models.UserFilter, fn func(models.User) error) error {
+	return core.ExportUsers(r.db, scope, filter, fn)
+}
+
+func (r *userRepository) GetTeachers() ([]models.User, error) {
+	return core.GetTeachers(r.db)
+}
+
+func (r *userRepository) GetByID(scope policy.Scope, userID int) (*models.User, error) {
+	return core.GetUserByID(r.db, scope, userID)
+}
+
+func (r *userRepository) Update(userID int, updates map[string]interface{}) error {
+	return core.UpdateUser(r.db, userID, updates)
+}
+
+func (r *userRepository) Delete(userID int) error {
+	return core.DeleteUser(r.db, userID)
+}
+
+func (r *userRepository) Register(user models.User) (int, error) {
+	return core.RegisterUser(r.db, user)
+}
+
+func (r *userRepository) ImportStudents(students []models.StudentImport, dryRun bool) (*models.ImportResult, error) {
+	return core.ImportStudents(r.db, students, dryRun)
+}
+
+func (r *userRepository) Authenticate(login, password string) (*models.User, error) {
+	return core.AuthenticateUser(r.db, login, password)
+}
+
+func (r *userRepository) GetCurrent(userID int) (*models.User, error) {
+	return core.GetCurrentUser(r.db, userID)
+}
+
+type sessionRepository struct {
+	db *sql.DB
+}
+
+func (r *sessionRepository) Create(sessionID string, userID int, refreshHash string, ttl time.Duration) error {
+	return core.CreateSession(r.db, sessionID, userID, refreshHash, ttl)
+}
+
+func (r *sessionRepository) RotateRefreshToken(oldHash, newHash string, ttl time.Duration) (*models.Session, error) {
+	return core.RotateRefreshToken(r.db, oldHash, newHash, ttl)
+}
+
+func (r *sessionRepository) IsActive(sessionID string) (bool, error) {
+	return core.IsSessionActive(r.db, sessionID)
+}
+
+func (r *sessionRepository) Revoke(sessionID string) error {
+	return core.RevokeSession(r.db, sessionID)
+}
+
+func (r *sessionRepository) RevokeAllForUser(userID int) error {
+	return core.RevokeUserSessions(r.db, userID)
+}
+
+type calendarTokenRepository struct {
+	db *sql.DB
+}
+
+func (r *calendarTokenRepository) Set(userID int, tokenHash string) error {
+	return core.SetCalendarToken(r.db, userID, tokenHash)
+}
+
+func (r *calendarTokenRepository) Delete(userID int) error {
+	return core.DeleteCalendarToken(r.db, userID)
+}
+
+func (r *calendarTokenRepository) GetUserID(tokenHash string) (int, error) {
+	return core.GetCalendarTokenUser(r.db, tokenHash)
+}
+
+type roleRepository struct {
+	db *sql.DB
+}
+
+func (r *roleRepository) GetAll() ([]models.Role, error) {
+	return core.GetRoles(r.db)
+}
+
+func (r *roleRepository) GetByID(roleID int) (*models.Role, error) {
+	return core.GetRoleByID(r.db, roleID)
+}
+
+func (r *roleRepository) GetPermissions() ([]models.Permission, error) {
+	return core.GetPermissions(r.db)
+}
+
+func (r *roleRepository) Create(role models.Role) (int, error) {
+	return core.CreateRole(r.db, role)
+}
+
+func (r *roleRepository) Update(role models.Role) error {
+	return core.UpdateRole(r.db, role)
+}
+
+func (r *roleRepository) Delete(roleID int) error {
+	return core.DeleteRole(r.db, roleID)
+}
+
+func (r *roleRepository) AssignToUser(userID, roleID int) error {
+	return core.AssignRole(r.db, userID, roleID)
+}
+
+type groupRepository struct {
+	db *sql.DB
+}
+
+func (r *groupRepository) List(page models.ListQuery) ([]models.Group, models.PageInfo, error) {
+	return core.ListGroups(r.db, page)
+}
+
+func (r *groupRepository) GetByID(groupID int) (*models.GroupDetail, error) {
+	return core.GetGroupByID(r.db, groupID)
+}
+
+func (r *groupRepository) Create(group models.Group) (int, error) {
+	return core.CreateGroup(r.db, group)
+}
+
+func (r *groupRepository) Update(group models.Group) error {
+	return core.UpdateGroup(r.db, group)
+}
+
+func (r *groupRepository) Delete(groupID int) error {
+	return core.DeleteGroup(r.db, groupID)
+}
+
+func (r *groupRepository) Transfer(groupID int, transfer models.Transfer) (*models.GroupMembership, error) {
+	return core.TransferStudent(r.db, groupID, transfer)
+}
+
+func (r *groupRepository) GetMemberships(studentID int) ([]models.GroupMembership, error) {
+	return core.GetGroupMemberships(r.db, studentID)
+}
+
+type subjectRepository struct {
+	db *sql.DB
+}
+
+func (r *subjectRepository) GetAll() ([]models.Subject, error) {
+	return core.GetSubjects(r.db)
+}
+
+func (r *subjectRepository) GetByID(subjectID int) (*models.Subject, error) {
+	return core.GetSubjectByID(r.db, subjectID)
+}
+
+func (r *subjectRepository) Create(subject models.Subject) (int, error) {
+	return core.CreateSubject(r.db, subject)
+}
+
+func (r *subjectRepository) Update(subject models.Subject) error {
+	return core.UpdateSubject(r.db, subject)
+}
+
+func (r *subjectRepository) Delete(subjectID int) error {
+	return core.DeleteSubject(r.db, subjectID)
+}
+
+type gradeTypeRepository struct {
+	db *sql.DB
+}
+
+func (r *gradeTypeRepository) GetAll() ([]models.GradeType, error) {
+	return core.GetGradeTypes(r.db)
+}
+
+func (r *gradeTypeRepository) GetByID(gradeTypeID int) (*models.GradeType, error) {
+	return core.GetGradeTypeByID(r.db, gradeTypeID)
+}
+
+func (r *gradeTypeRepository) Create(gradeType models.GradeType) (int, error) {
+	return core.CreateGradeType(r.db, gradeType)
+}
+
+func (r *gradeTypeRepository) Update(gradeType models.GradeType) error {
+	return core.UpdateGradeType(r.db, gradeType)
+}
+
+func (r *gradeTypeRepository) Delete(gradeTypeID int) error {
+	return core.DeleteGradeType(r.db, gradeTypeID)
+}
+
+func (r *gradeTypeRepository) GetWeights(subjectID int) ([]models.GradeWeight, error) {
+	return core.GetGradeWeights(r.db, subjectID)
+}
+
+func (r *gradeTypeRepository) SetWeights(subjectID int, weights map[int]float64) error {
+	return core.SetGradeWeights(r.db, subjectID, weights)
+}
+
+type scheduleRepository struct {
+	db *sql.DB
+}
+
+func (r *scheduleRepository) List(filter models.ScheduleFilter, page models.ListQuery) ([]models.Schedule, models.PageInfo, error) {
+	return core.ListSchedules(r.db, filter, page)
+}
+
+func (r *scheduleRepository) Export(filter models.ScheduleFilter, fn func(models.Schedule) error) error {
+	return core.ExportSchedules(r.db, filter, fn)
+}
+
+func (r *scheduleRepository) GetByGroupID(groupID int) ([]models.Schedule, error) {
+	return core.GetScheduleByID(r.db, groupID)
+}
+
+func (r *scheduleRepository) Create(schedule models.Schedule) (int, error) {
+	return core.CreateSchedule(r.db, schedule)
+}
+
+func (r *scheduleRepository) GetByID(scheduleID int) (*models.Schedule, error) {
+	return core.GetScheduleEntry(r.db, scheduleID)
+}
+
+func (r *scheduleRepository) Update(scheduleID int, updates map[string]interface{}) error {
+	return core.UpdateSchedule(r.db, scheduleID, updates)
+}
+
+func (r *scheduleRepository) GetByTeacherID(teacherID int) ([]models.Schedule, error) {
+	return core.GetSchedulesByTeacherID(r.db, teacherID)
+}
+
+func (r *scheduleRepository) Delete(scheduleID int) error {
+	return core.DeleteSchedule(r.db, scheduleID)
+}
+
+func (r *scheduleRepository) Validate(schedule models.Schedule) error {
+	return core.ValidateSchedule(r.db, schedule)
+}
+
+type lessonRepository struct {
+	db *sql.DB
+}
+
+func (r *lessonRepository) GetAll(filter models.LessonFilter) ([]models.Lesson, error) {
+	return core.GetLessons(r.db, filter)
+}
+
+func (r *lessonRepository) GetByID(lessonID int) (*models.Lesson, error) {
+	return core.GetLessonByID(r.db, lessonID)
+}
+
+func (r *lessonRepository) Generate(from, to time.Time, groupID int) (int, error) {
+	return core.GenerateLessons(r.db, from, to, groupID)
+}
+
+type termRepository struct {
+	db *sql.DB
+}
+
+func (r *termRepository) GetYears() ([]models.AcademicYear, error) {
+	return core.GetAcademicYears(r.db)
+}
+
+func (r *termRepository) GetYearByID(yearID int) (*models.AcademicYear, error) {
+	return core.GetAcademicYearByID(r.db, yearID)
+}
+
+func (r *termRepository) CreateYear(year models.AcademicYear) (int, error) {
+	return core.CreateAcademicYear(r.db, year)
+}
+
+func (r *termRepository) UpdateYear(year models.AcademicYear) error {
+	return core.UpdateAcademicYear(r.db, year)
+}
+
+func (r *termRepository) DeleteYear(yearID int) error {
+	return core.DeleteAcademicYear(r.db, yearID)
+}
+
+func (r *termRepository) GetAll(yearID int) ([]models.Term, error) {
+	return core.GetTerms(r.db, yearID)
+}
+
+func (r *termRepository) GetByID(termID int) (*models.Term, error) {
+	return core.GetTermByID(r.db, termID)
+}
+
+func (r *termRepository) GetCurrent(date time.Time) (*models.Term, error) {
+	return core.GetCurrentTerm(r.db, date)
+}
+
+func (r *termRepository) Create(term models.Term) (int, error) {
+	return core.CreateTerm(r.db, term)
+}
+
+func (r *termRepository) Update(term models.Term) error {
+	return core.UpdateTerm(r.db, term)
+}
+
+func (r *termRepository) Delete(termID int) error {
+	return core.DeleteTerm(r.db, termID)
+}
+
+type finalGradeRepository struct {
+	db *sql.DB
+}
+
+func (r *finalGradeRepository) GetByTerm(scope policy.Scope, termID int, filter models.FinalGradeFilter) ([]models.FinalGrade, error) {
+	return core.GetFinalGrades(r.db, scope, termID, filter)
+}
+
+func (r *finalGradeRepository) GetByID(scope policy.Scope, finalGradeID int) (*models.FinalGrade, error) {
+	return core.GetFinalGradeByID(r.db, scope, finalGradeID)
+}
+
+func (r *finalGradeRepository) Compute(scope policy.Scope, termID, groupID, subjectID int) ([]models.FinalGrade, error) {
+	return core.ComputeFinalGrades(r.db, scope, termID, groupID, subjectID)
+}
+
+func (r *finalGradeRepository) Lock(scope policy.Scope, termID, groupID, subjectID int) (int, error) {
+	return core.LockFinalGrades(r.db, scope, termID, groupID, subjectID)
+}
+
+func (r *finalGradeRepository) Update(scope policy.Scope, finalGradeID, value int) error {
+	return core.UpdateFinalGrade(r.db, scope, finalGradeID, value)
+}
+
+func (r *finalGradeRepository) Unlock(scope policy.Scope, finalGradeID int) error {
+	return core.UnlockFinalGrade(r.db, scope, finalGradeID)
+}
+
+type gradeRepository struct {
+	db *sql.DB
+}
+
+func (r *gradeRepository) List(scope policy.Scope, filter models.RecordFilter, page models.ListQuery) ([]models.GradeDetail, models.PageInfo, error) {
+	return core.ListGrades(r.db, scope, filter, page)
+}
+
+func (r *gradeRepository) Create(scope policy.Scope, grade models.Grade, reason string) (int, error) {
+	return core.CreateGrade(r.db, scope, grade, reason)
+}
+
+func (r *gradeRepository) Update(scope policy.Scope, grade models.Grade, reason string) error {
+	return core.UpdateGrade(r.db, scope, grade, reason)
+}
+
+func (r *gradeRepository) Delete(scope policy.Scope, gradeID int, reason string) error {
+	return core.DeleteGrade(r.db, scope, gradeID, reason)
+}
+
+func (r *gradeRepository) Stats(scope policy.Scope, filter models.GradeStatsFilter, by string) ([]models.GradeStat, error) {
+	return core.GetGradeStats(r.db, scope, filter, by)
+}
+
+func (r *gradeRepository) Ranking(scope policy.Scope, filter models.GradeStatsFilter) ([]models.GradeRank, error) {
+	return core.GetGroupRanking(r.db, scope, filter)
+}
+
+func (r *gradeRepository) Export(scope policy.Scope, filter models.RecordFilter, fn func(models.GradeDetail) error) error {
+	return core.ExportGrades(r.db, scope, filter, fn)
+}
+
+type attendanceRepository struct {
+	db *sql.DB
+}
+
+func (r *attendanceRepository) List(scope policy.Scope, filter models.RecordFilter, page models.ListQuery) ([]models.AttendanceDetail, models.PageInfo, error) {
+	return core.ListAttendance(r.db, scope, filter, page)
+}
+
+func (r *attendanceRepository) GetByLessonID(scope policy.Scope, lessonID int) ([]models.AttendanceDetail, error) {
+	return core.GetAttendanceByLessonID(r.db, scope, lessonID)
+}
+
+func (r *attendanceRepository) RollCall(scope policy.Scope, call models.RollCall, reason string) (*models.RollCallResult, error) {
+	return core.RollCall(r.db, scope, call, reason)
+}
+
+func (r *attendanceRepository) Stats(scope policy.Scope, filter models.AttendanceStatsFilter, by string) ([]models.AttendanceStat, error) {
+	return core.GetAttendanceStats(r.db, scope, filter, by)
+}
+
+func (r *attendanceRepository) Monthly(scope policy.Scope, filter models.AttendanceStatsFilter) ([]models.AttendanceMonth, error) {
+	return core.GetAttendanceByMonth(r.db, scope, filter)
+}
+
+func (r *attendanceRepository) Absentees(scope policy.Scope, filter models.AttendanceStatsFilter, threshold float64) ([]models.AttendanceStat, error) {
+	return core.GetAbsentees(r.db, scope, filter, threshold)
+}
+
+func (r *attendanceRepository) Create(scope policy.Scope, attendance models.Attendance, reason string) (int, error) {
+	return core.CreateAttendance(r.db, scope, attendance, reason)
+}
+
+func (r *attendanceRepository) Update(scope policy.Scope, attendance models.Attendance, reason string) error {
+	return core.UpdateAttendance(r.db, scope, attendance, reason)
+}
+
+func (r *attendanceRepository) Export(scope policy.Scope, filter models.RecordFilter, fn func(models.AttendanceDetail) error) error {
+	return core.ExportAttendance(r.db, scope, filter, fn)
+}
+
+type journalRepository struct {
+	db *sql.DB
+}
+
+func (r *journalRepository) Get(scope policy.Scope, filter models.JournalFilter) (*models.Journal, error) {
+	return core.GetJournal(r.db, scope, filter)
+}
+
+func (r *journalRepository) Save(scope policy.Scope, save models.JournalSave, reason string) (*models.JournalSaveResult, error) {
+	return core.SaveJournal(r.db, scope, save, reason)
+}
+
+type historyRepository struct {
+	db *sql.DB
+}
+
+func (r *historyRepository) Get(recordType string, recordID int) ([]models.RecordChange, error) {
+	return core.GetRecordHistory(r.db, recordType, recordID)
+}
+
+type auditRepository struct {
+	db *sql.DB
+}
+
+func (r *auditRepository) Record(entry models.AuditEntry) error {
+	return core.CreateAuditEntry(r.db, entry)
+}
+
+func (r *auditRepository) Get(filter models.AuditFilter) ([]models.AuditEntry, error) {
+	return core.GetAuditLog(r.db, filter)
+}
+
+type searchRepository struct {
+	db *sql.DB
+}
+
+func (r *searchRepository) Search(scope policy.Scope, query models.SearchQuery) (*models.SearchResult, error) {
+	return core.Search(r.db, scope, query)
+}
@@ -0,0 +1,242 @@
+// Package repository описывает хранилища агрегатов, с которыми работают обработчики API.
+//
+// Есть две реализации: repository/postgres (поверх SQL из internal/core)
+// и repository/memory (всё в памяти процесса, для тестов и демо-режима).
+package repository
+
+import (
+	"github.com/VladislavSCV/internal/models"
+	"github.com/VladislavSCV/internal/policy"
+	"time"
+)
+
+// Методы с policy.Scope возвращают только то, что доступно пользователю (см. internal/policy).
+// Недоступная запись выглядит как отсутствующая, а запись чужих данных — policy.ErrForbidden.
+//
+// Методы List возвращают страницу списка по models.ListQuery и сведения о ней; поле сортировки —
+// из соответствующего models.*SortFields.
+
+type UserRepository interface {
+	List(scope policy.Scope, filter models.UserFilter, page models.ListQuery) ([]models.User, models.PageInfo, error)
+	// Export передаёт пользователей, которых видит scope, в fn по одному (по фамилии и имени) без деления на страницы;
+	// ошибка fn прерывает выборку
+	Export(scope policy.Scope, filter models.UserFilter, fn func(models.User) error) error
+	GetTeachers() ([]models.User, error)
+	GetByID(scope policy.Scope, userID int) (*models.User, error)
+	// Update меняет только колонки из patch.UserSchema
+	Update(userID int, updates map[string]interface{}) error
+	Delete(userID int) error
+
+	// Register сохраняет пользователя с уже захешированным паролем
+	Register(user models.User) (int, error)
+	// ImportStudents создаёт студентов с уже захешированными паролями и недостающие группы;
+	// всех или никого (при занятых логинах или dryRun)
+	ImportStudents(students []models.StudentImport, dryRun bool) (*models.ImportResult, error)
+	// Authenticate проверяет логин и пароль
+	Authenticate(login, password string) (*models.User, error)
+	// GetCurrent возвращает пользователя вместе с role_id и group_id
+	GetCurrent(userID int) (*models.User, error)
+}
+
+type SessionRepository interface {
+	Create(sessionID string, userID int, refreshHash string, ttl time.Duration) error
+	RotateRefreshToken(oldHash, newHash string, ttl time.Duration) (*models.Session, error)
+	IsActive(sessionID string) (bool, error)
+	Revoke(sessionID string) error
+	RevokeAllForUser(userID int) error
+}
+
+// CalendarTokenRepository хранит хеши токенов подписки на ленты расписания, по одному на пользователя
+type CalendarTokenRepository interface {
+	// Set заменяет действующий токен пользователя
+	Set(userID int, tokenHash string) error
+	Delete(userID int) error
+	// GetUserID возвращает владельца токена или ошибку «calendar token not found»
+	GetUserID(tokenHash string) (int, error)
+}
+
+type RoleRepository interface {
+	// GetAll возвращает роли вместе с правами; на нём строится кэш policy.RoleCache
+	GetAll() ([]models.Role, error)
+	GetByID(roleID int) (*models.Role, error)
+	GetPermissions() ([]models.Permission, error)
+	// Create и Update возвращают ErrRoleExists, ErrBuiltinRole или ErrUnknownPermission
+	Create(role models.Role) (int, error)
+	Update(role models.Role) error
+	// Delete возвращает ErrBuiltinRole или ErrRoleInUse
+	Delete(roleID int) error
+	AssignToUser(userID, roleID int) error
+}
+
+type GroupRepository interface {
+	List(page models.ListQuery) ([]models.Group, models.PageInfo, error)
+	GetByID(groupID int) (*models.GroupDetail, error)
+	Create(group models.Group) (int, error)
+	Update(group models.Group) error
+	// Delete возвращает *InUseError, пока у группы есть история студентов
+	Delete(groupID int) error
+	// Transfer переводит студента в группу с даты перевода, сохраняя прежнее членство в истории.
+	// Возвращает ErrNotStudent, ErrAlreadyInGroup или ErrInvalidTransferDate
+	Transfer(groupID int, transfer models.Transfer) (*models.GroupMembership, error)
+	// GetMemberships возвращает историю групп студента от старых записей к новым
+	GetMemberships(studentID int) ([]models.GroupMembership, error)
+}
+
+type SubjectRepository interface {
+	GetAll() ([]models.Subject, error)
+	GetByID(subjectID int) (*models.Subject, error)
+	Create(subject models.Subject) (int, error)
+	Update(subject models.Subject) error
+	Delete(subjectID int) error
+}
+
+// GradeTypeRepository — виды оценок и их веса в предметах. Delete возвращает *InUseError,
+// пока вид указан у оценок
+type GradeTypeRepository interface {
+	GetAll() ([]models.GradeType, error)
+	GetByID(gradeTypeID int) (*models.GradeType, error)
+	Create(gradeType models.GradeType) (int, error)
+	Update(gradeType models.GradeType) error
+	Delete(gradeTypeID int) error
+	GetWeights(subjectID int) ([]models.GradeWeight, error)
+	// SetWeights заменяет веса в предмете; неуказанные виды возвращаются к весу по умолчанию
+	SetWeights(subjectID int, weights map[int]float64) error
+}
+
+type ScheduleRepository interface {
+	List(filter models.ScheduleFilter, page models.ListQuery) ([]models.Schedule, models.PageInfo, error)
+	// Export передаёт занятия в fn по одному (по группе, дню недели и времени начала); ошибка fn прерывает выборку
+	Export(filter models.ScheduleFilter, fn func(models.Schedule) error) error
+	GetByGroupID(groupID int) ([]models.Schedule, error)
+	GetByTeacherID(teacherID int) ([]models.Schedule, error)
+	GetByID(scheduleID int) (*models.Schedule, error)
+	// Create и Update отклоняют занятие с ErrInvalidTimeRange или *ScheduleConflictError
+	Create(schedule models.Schedule) (int, error)
+	// Update меняет только колонки из patch.ScheduleSchema
+	Update(scheduleID int, updates map[string]interface{}) error
+	Delete(scheduleID int) error
+	// Validate проверяет занятие как Create, ничего не сохраняя; ненулевой ID исключает само занятие
+	Validate(schedule models.Schedule) error
+}
+
+type LessonRepository interface {
+	GetAll(filter models.LessonFilter) ([]models.Lesson, error)
+	GetByID(lessonID int) (*models.Lesson, error)
+	// Generate создаёт занятия по расписанию на каждый день из [from, to] (groupID = 0 — для всех групп)
+	// и возвращает число новых; уже созданные занятия не меняются
+	Generate(from, to time.Time, groupID int) (int, error)
+}
+
+// TermRepository — учебные годы и периоды. Год и период с end_date не позже start_date отклоняются
+// с ErrInvalidDateRange, период вне своего года — с ErrTermOutsideYear, пересекающийся с другим
+// периодом года — с ErrTermOverlap. Delete возвращает *InUseError, пока на запись ссылаются
+type TermRepository interface {
+	GetYears() ([]models.AcademicYear, error)
+	GetYearByID(yearID int) (*models.AcademicYear, error)
+	// CreateYear и UpdateYear возвращают ErrAcademicYearExists при занятом названии
+	CreateYear(year models.AcademicYear) (int, error)
+	UpdateYear(year models.AcademicYear) error
+	DeleteYear(yearID int) error
+	// GetAll возвращает периоды года (yearID = 0 — все периоды)
+	GetAll(yearID int) ([]models.Term, error)
+	GetByID(termID int) (*models.Term, error)
+	// GetCurrent возвращает период, в который попадает день date, или ошибку «term not found»
+	GetCurrent(date time.Time) (*models.Term, error)
+	// Create и Update возвращают ErrTermExists при занятом в году названии
+	Create(term models.Term) (int, error)
+	Update(term models.Term) error
+	Delete(termID int) error
+}
+
+// FinalGradeRepository — итоговые оценки за период. Compute и Lock работают с группой по предмету целиком
+// и возвращают policy.ErrForbidden, если хотя бы один студент группы недоступен scope.
+// Закрытую оценку меняет только scope с Override, иначе — ErrGradeLocked
+type FinalGradeRepository interface {
+	GetByTerm(scope policy.Scope, termID int, filter models.FinalGradeFilter) ([]models.FinalGrade, error)
+	GetByID(scope policy.Scope, finalGradeID int) (*models.FinalGrade, error)
+	// Compute пересчитывает незакрытые оценки по средневзвешенному баллу за период
+	Compute(scope policy.Scope, termID, groupID, subjectID int) ([]models.FinalGrade, error)
+	// Lock закрывает оценки и возвращает число закрытых
+	Lock(scope policy.Scope, termID, groupID, subjectID int) (int, error)
+	Update(scope policy.Scope, finalGradeID, value int) error
+	Unlock(scope policy.Scope, finalGradeID int) error
+}
+
+// GradeRepository и AttendanceRepository возвращают ErrLessonMismatch, если запись ссылается
+// на занятие по другому предмету, в другой день или у другой группы.
+// GradeRepository возвращает ErrGradeLocked при изменении оценки за закрытый период без права Override.
+// Каждое изменение записывается в историю от имени scope.UserID с причиной reason (может быть пустой)
+type GradeRepository interface {
+	List(scope policy.Scope, filter models.RecordFilter, page models.ListQuery) ([]models.GradeDetail, models.PageInfo, error)
+	Create(scope policy.Scope, grade models.Grade, reason string) (int, error)
+	Update(scope policy.Scope, grade models.Grade, reason string) error
+	Delete(scope policy.Scope, gradeID int, reason string) error
+	// Stats и Ranking считают только оценки, доступные scope
+	Stats(scope policy.Scope, filter models.GradeStatsFilter, by string) ([]models.GradeStat, error)
+	Ranking(scope policy.Scope, filter models.GradeStatsFilter) ([]models.GradeRank, error)
+	// Export передаёт оценки, доступные scope, в fn по одной (по дате) без деления на страницы;
+	// ошибка fn прерывает выборку
+	Export(scope policy.Scope, filter models.RecordFilter, fn func(models.GradeDetail) error) error
+}
+
+type AttendanceRepository interface {
+	List(scope policy.Scope, filter models.RecordFilter, page models.ListQuery) ([]models.AttendanceDetail, models.PageInfo, error)
+	GetByLessonID(scope policy.Scope, lessonID int) ([]models.AttendanceDetail, error)
+	// RollCall сохраняет отметки группы одной транзакцией; ошибки отдельных студентов — в Rejected
+	RollCall(scope policy.Scope, call models.RollCall, reason string) (*models.RollCallResult, error)
+	// Stats, Monthly и Absentees считают только записи, доступные scope
+	Stats(scope policy.Scope, filter models.AttendanceStatsFilter, by string) ([]models.AttendanceStat, error)
+	Monthly(scope policy.Scope, filter models.AttendanceStatsFilter) ([]models.AttendanceMonth, error)
+	Absentees(scope policy.Scope, filter models.AttendanceStatsFilter, threshold float64) ([]models.AttendanceStat, error)
+	Create(scope policy.Scope, attendance models.Attendance, reason string) (int, error)
+	Update(scope policy.Scope, attendance models.Attendance, reason string) error
+	// Export передаёт отметки, доступные scope, в fn по одной (по дате); ошибка fn прерывает выборку
+	Export(scope policy.Scope, filter models.RecordFilter, fn func(models.AttendanceDetail) error) error
+}
+
+// JournalRepository — журнал группы по предмету: матрица студенты × дни и сохранение многих ячеек сразу
+type JournalRepository interface {
+	Get(scope policy.Scope, filter models.JournalFilter) (*models.Journal, error)
+	// Save применяет все правки или ни одной; ошибка правки — *JournalEditError
+	Save(scope policy.Scope, save models.JournalSave, reason string) (*models.JournalSaveResult, error)
+}
+
+// HistoryRepository — журнал изменений оценок и посещаемости; записи в него только добавляются
+type HistoryRepository interface {
+	// Get возвращает изменения записи (models.RecordGrade или models.RecordAttendance) от старых к новым
+	Get(recordType string, recordID int) ([]models.RecordChange, error)
+}
+
+// AuditRepository — журнал аудита изменяющих запросов и входов; записи в него только добавляются
+type AuditRepository interface {
+	Record(entry models.AuditEntry) error
+	// Get возвращает записи от новых к старым
+	Get(filter models.AuditFilter) ([]models.AuditEntry, error)
+}
+
+// SearchRepository — поиск по ФИО и логинам пользователей, названиям групп и предметов
+type SearchRepository interface {
+	// Search находит записи, похожие на один из вариантов запроса; пользователей — только видимых scope
+	Search(scope policy.Scope, query models.SearchQuery) (*models.SearchResult, error)
+}
+
+// Repositories — набор хранилищ, который передаётся в маршруты
+type Repositories struct {
+	Users       UserRepository
+	Sessions    SessionRepository
+	Calendar    CalendarTokenRepository
+	Roles       RoleRepository
+	Groups      GroupRepository
+	Subjects    SubjectRepository
+	GradeTypes  GradeTypeRepository
+	Schedules   ScheduleRepository
+	Lessons     LessonRepository
+	Terms       TermRepository
+	Grades      GradeRepository
+	FinalGrades FinalGradeRepository
+	Attendance  AttendanceRepository
+	Journal     JournalRepository
+	History     HistoryRepository
+	Audit       AuditRepository
+	Search      SearchRepository
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"github.com/VladislavSCV/internal/models"
+	"sort"
+)
+
+// FilterAbsentees оставляет студентов, у которых доля пропусков без уважительной причины больше threshold
+// процентов, и сортирует их по этой доле по убыванию. Общая часть отчёта для всех реализаций хранилищ
+func FilterAbsentees(stats []models.AttendanceStat, threshold float64) []models.AttendanceStat {
+	absentees := []models.AttendanceStat{}
+	for _, stat := range stats {
+		if stat.UnexcusedRate > threshold {
+			absentees = append(absentees, stat)
+		}
+	}
+	sort.SliceStable(absentees, func(i, j int) bool { return absentees[i].UnexcusedRate > absentees[j].UnexcusedRate })
+	return absentees
+}
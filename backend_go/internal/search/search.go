@@ -0,0 +1,198 @@
+// Package search готовит текст поискового запроса: нормализует его, добавляет транслитерацию
+// (кириллица ↔ латиница) и набор в другой раскладке клавиатуры, и оценивает похожесть строк по триграммам с допуском опечаток.
+//
+// В PostgreSQL поиск идёт по триграммным индексам pg_trgm (миграция 0011_search), а Score —
+// его замена для хранилища в памяти: близкая, но не точная копия word_similarity.
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Threshold — наименьшая похожесть, с которой запись попадает в результаты поиска
+const Threshold = 0.3
+
+// Normalize приводит текст к виду, в котором он сравнивается: нижний регистр, «ё» как «е»
+// и одиночные пробелы между словами
+func Normalize(text string) string {
+	text = strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Variants возвращает нормализованный запрос, его транслитерации — латиницу в кириллицу
+// («ivanov» → «иванов») и кириллицу в латиницу («студент» → «student») — и его же, набранный
+// не в той раскладке («bdfyjd» → «иванов», «шмфтщм» → «ivanov»), без повторов
+func Variants(query string) []string {
+	query = Normalize(query)
+	variants := []string{query}
+	for _, variant := range []string{toCyrillic(query), toLatin(query), switchLayout(query, latinKeys), switchLayout(query, cyrillicKeys)} {
+		seen := false
+		for _, v := range variants {
+			seen = seen || v == variant
+		}
+		if !seen {
+			variants = append(variants, variant)
+		}
+	}
+	return variants
+}
+
+// latinToCyrillic — сочетания латинских букв в порядке разбора: сначала длинные
+var latinToCyrillic = []struct{ latin, cyrillic string }{
+	{"shch", "щ"}, {"sch", "щ"},
+	{"zh", "ж"}, {"kh", "х"}, {"ts", "ц"}, {"tz", "ц"}, {"ch", "ч"}, {"sh", "ш"},
+	{"yu", "ю"}, {"ju", "ю"}, {"ya", "я"}, {"ja", "я"}, {"yo", "е"}, {"jo", "е"}, {"ye", "е"}, {"x", "кс"},
+	{"a", "а"}, {"b", "б"}, {"c", "к"}, {"d", "д"}, {"e", "е"}, {"f", "ф"}, {"g", "г"}, {"h", "х"},
+	{"i", "и"}, {"j", "й"}, {"k", "к"}, {"l", "л"}, {"m", "м"}, {"n", "н"}, {"o", "о"}, {"p", "п"},
+	{"q", "к"}, {"r", "р"}, {"s", "с"}, {"t", "т"}, {"u", "у"}, {"v", "в"}, {"w", "в"}, {"z", "з"},
+}
+
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p",
+	'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch",
+	'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// latinKeys — буквы русской раскладки ЙЦУКЕН на клавишах латинской QWERTY
+var latinKeys = map[rune]rune{
+	'q': 'й', 'w': 'ц', 'e': 'у', 'r': 'к', 't': 'е', 'y': 'н', 'u': 'г', 'i': 'ш', 'o': 'щ', 'p': 'з', '[': 'х', ']': 'ъ',
+	'a': 'ф', 's': 'ы', 'd': 'в', 'f': 'а', 'g': 'п', 'h': 'р', 'j': 'о', 'k': 'л', 'l': 'д', ';': 'ж', '\'': 'э',
+	'z': 'я', 'x': 'ч', 'c': 'с', 'v': 'м', 'b': 'и', 'n': 'т', 'm': 'ь', ',': 'б', '.': 'ю', '`': 'е',
+}
+
+// cyrillicKeys — обратная latinKeys: латинские буквы на клавишах русских
+var cyrillicKeys = func() map[rune]rune {
+	keys := make(map[rune]rune, len(latinKeys))
+	for latin, cyrillic := range latinKeys {
+		if latin != '`' {
+			keys[cyrillic] = latin
+		}
+	}
+	return keys
+}()
+
+// switchLayout перенабирает нормализованный текст в другой раскладке по таблице keys; остальные символы не меняются
+func switchLayout(text string, keys map[rune]rune) string {
+	return strings.Map(func(r rune) rune {
+		if switched, ok := keys[r]; ok {
+			return switched
+		}
+		return r
+	}, text)
+}
+
+// toCyrillic переводит латиницу нормализованного текста в кириллицу. «y» после согласной читается
+// как «ы» («bykov»), а в конце слова — как «ий» («vasily»); после гласной — как «й» («dmitriy»)
+func toCyrillic(text string) string {
+	var out strings.Builder
+	var prev rune
+	for i := 0; i < len(text); {
+		if text[i] == 'y' && !strings.HasPrefix(text[i:], "yu") && !strings.HasPrefix(text[i:], "ya") &&
+			!strings.HasPrefix(text[i:], "yo") && !strings.HasPrefix(text[i:], "ye") {
+			consonant := prev >= 'a' && prev <= 'z' && !strings.ContainsRune("aeiouy", prev)
+			switch {
+			case consonant && (i+1 == len(text) || text[i+1] < 'a' || text[i+1] > 'z'):
+				out.WriteString("ий")
+			case consonant:
+				out.WriteString("ы")
+			default:
+				out.WriteString("й")
+			}
+			prev = 'y'
+			i++
+			continue
+		}
+		matched := false
+		for _, pair := range latinToCyrillic {
+			if strings.HasPrefix(text[i:], pair.latin) {
+				out.WriteString(pair.cyrillic)
+				prev = rune(pair.latin[len(pair.latin)-1])
+				i += len(pair.latin)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			r := []rune(text[i:])[0]
+			out.WriteRune(r)
+			prev = r
+			i += len(string(r))
+		}
+	}
+	return out.String()
+}
+
+// toLatin переводит кириллицу нормализованного текста в латиницу, как в логинах и документах
+func toLatin(text string) string {
+	var out strings.Builder
+	for _, r := range text {
+		if latin, ok := cyrillicToLatin[r]; ok {
+			out.WriteString(latin)
+		} else {
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+// Score оценивает от 0 до 1, насколько нормализованный text подходит под нормализованный query:
+// 1 — query входит в text целиком, иначе — средняя по словам запроса похожесть на ближайшее слово text.
+// Похожесть слова считается как в word_similarity: доля его триграмм, найденных в слове text,
+// поэтому пропущенная в запросе буква («ивнов» → «иванова») штрафуется мягче, чем в similarity()
+func Score(query, text string) float64 {
+	if query == "" {
+		return 0
+	}
+	if strings.Contains(text, query) {
+		return 1
+	}
+
+	textWords := words(text)
+	queryWords := words(query)
+	if len(textWords) == 0 || len(queryWords) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, queryWord := range queryWords {
+		best := 0.0
+		for _, textWord := range textWords {
+			best = max(best, wordSimilarity(queryWord, textWord))
+		}
+		total += best
+	}
+	return total / float64(len(queryWords))
+}
+
+// words делит текст на слова из букв и цифр, как pg_trgm
+func words(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// wordSimilarity — доля триграмм слова запроса, которые есть и в слове текста, как word_similarity() в pg_trgm
+func wordSimilarity(queryWord, textWord string) float64 {
+	tq, tt := trigrams(queryWord), trigrams(textWord)
+	if len(tq) == 0 {
+		return 0
+	}
+	shared := 0
+	for trigram := range tq {
+		if tt[trigram] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(tq))
+}
+
+// trigrams возвращает триграммы слова, дополненного двумя пробелами в начале и одним в конце
+func trigrams(word string) map[string]bool {
+	runes := []rune("  " + word + " ")
+	set := make(map[string]bool, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = true
+	}
+	return set
+}
@@ -0,0 +1,57 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestVariants(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"latin to cyrillic", "Ivanov", []string{"ivanov", "иванов", "шмфтщм"}},
+		{"cyrillic to latin", "Студент", []string{"студент", "student", "cneltyn"}},
+		{"normalized", "  Ёлкин  Пётр ", []string{"елкин петр", "elkin petr", "tkrby gtnh"}},
+		{"y after vowel", "Dmitriy", []string{"dmitriy", "дмитрий", "вьшекшн"}},
+		{"russian typed on latin layout", "bdfyjd", []string{"bdfyjd", "бдфыйд", "иванов"}},
+		{"latin typed on russian layout", "шмфтщм", []string{"шмфтщм", "shmftshchm", "ivanov"}},
+		{"layout switch of a long word", "ghjuhfvvbhjdfybt", []string{"ghjuhfvvbhjdfybt", "гхюхфввбхйдфыбт", "программирование"}},
+		{"no duplicates", "123", []string{"123"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Variants(tt.query); !slices.Equal(got, tt.want) {
+				t.Errorf("Variants(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		text      string
+		want      float64 // -1 — проверяется только порог
+		wantMatch bool
+	}{
+		{"substring", "иванов", "иванова мария", 1, true},
+		{"typo", "ивнов", "мария иванова", 0.5, true},
+		{"typo in the text", "иванов", "петр ивнов", -1, true},
+		{"unrelated", "иванов", "петров", -1, false},
+		{"empty query", "", "иванов", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Score(tt.query, tt.text)
+			if tt.want >= 0 && got != tt.want {
+				t.Errorf("Score(%q, %q) = %v, want %v", tt.query, tt.text, got, tt.want)
+			}
+			if match := got >= Threshold; match != tt.wantMatch {
+				t.Errorf("Score(%q, %q) = %v: match %v, want %v", tt.query, tt.text, got, match, tt.wantMatch)
+			}
+		})
+	}
+}
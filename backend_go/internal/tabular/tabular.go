@@ -0,0 +1,63 @@
+// Package tabular читает таблицы из CSV и XLSX в строки ячеек и пишет их построчно,
+// чтобы импорт и выгрузки не зависели от формата файла
+package tabular
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// Форматы файлов с таблицами
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// utf8BOM — метка порядка байтов, с которой Excel сохраняет CSV в UTF-8
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Format определяет формат по расширению имени файла; пустая строка — формат неизвестен
+func Format(filename string) string {
+	switch {
+	case strings.HasSuffix(strings.ToLower(filename), ".csv"):
+		return FormatCSV
+	case strings.HasSuffix(strings.ToLower(filename), ".xlsx"):
+		return FormatXLSX
+	default:
+		return ""
+	}
+}
+
+// Read читает таблицу формата format. Строки возвращаются как есть, включая заголовок;
+// у строк XLSX столько ячеек, сколько до последней непустой
+func Read(data []byte, format string) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(data)
+	case FormatXLSX:
+		return readXLSX(data)
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// readCSV читает CSV с разделителем «,» или «;» (русский Excel сохраняет с «;»)
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,142 @@
+package tabular
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// buildXLSX собирает книгу из частей path → содержимое
+func buildXLSX(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for path, body := range parts {
+		file, err := archive.Create(path)
+		if err != nil {
+			t.Fatalf("create %s: %v", path, err)
+		}
+		file.Write([]byte(body))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("close archive: %v", err)
+	}
+	return buf.Bytes()
+}
+
+const (
+	workbookXML = `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Студенты" sheetId="1" r:id="rId3"/><sheet name="Лист2" sheetId="2" r:id="rId1"/></sheets></workbook>`
+	relsXML = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Target="worksheets/sheet2.xml"/><Relationship Id="rId3" Target="/xl/worksheets/students.xml"/></Relationships>`
+	sharedXML = `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<si><t>ФИО</t></si><si><t>Группа</t></si><si><r><t>Иванов </t></r><r><t>Иван</t></r></si></sst>`
+	// Ячейки B1 и C2 пропущены, D1 — inline-строка, A3 — число
+	studentsXML = `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+		`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c><c r="D1" t="inlineStr"><is><t>Логин</t></is></c></row>` +
+		`<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2"><v></v></c><c r="D2" t="inlineStr"><is><t>iivanov</t></is></c></row>` +
+		`<row r="3"><c r="A3"><v>42</v></c></row>` +
+		`</sheetData></worksheet>`
+)
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want [][]string
+	}{
+		{"comma", "name,group\nИванов Иван,ИС-21\n", [][]string{{"name", "group"}, {"Иванов Иван", "ИС-21"}}},
+		{"semicolon with BOM", "\xEF\xBB\xBFФИО;Группа;Логин\r\n\"Петров; Пётр\";ИС-22\r\n", [][]string{{"ФИО", "Группа", "Логин"}, {"Петров; Пётр", "ИС-22"}}},
+		{"leading spaces", "a, b\n1, 2\n", [][]string{{"a", "b"}, {"1", "2"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read([]byte(tt.data), FormatCSV)
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Read() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	data := buildXLSX(t, map[string]string{
+		"xl/workbook.xml":            workbookXML,
+		"xl/_rels/workbook.xml.rels": relsXML,
+		"xl/sharedStrings.xml":       sharedXML,
+		"xl/worksheets/students.xml": studentsXML,
+		"xl/worksheets/sheet2.xml":   `<worksheet><sheetData><row><c t="inlineStr"><is><t>второй лист</t></is></c></row></sheetData></worksheet>`,
+	})
+
+	got, err := Read(data, FormatXLSX)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	want := [][]string{
+		{"ФИО", "", "Группа", "Логин"},
+		{"Иванов Иван", "", "", "iivanov"},
+		{"42"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %q, want %q", got, want)
+	}
+}
+
+func TestReadXLSXWithoutRelationships(t *testing.T) {
+	// Без связей книги берётся стандартный путь первого листа, ячейки без r идут по порядку
+	data := buildXLSX(t, map[string]string{
+		"xl/workbook.xml":          workbookXML,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row><c t="inlineStr"><is><t>a</t></is></c><c><v>1</v></c></row></sheetData></worksheet>`,
+	})
+
+	got, err := Read(data, FormatXLSX)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if want := [][]string{{"a", "1"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %q, want %q", got, want)
+	}
+}
+
+func TestReadRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		format string
+		want   string
+	}{
+		{"unknown format", []byte("a"), "ods", "unsupported format"},
+		{"broken CSV", []byte("a,\"b\n"), FormatCSV, "invalid CSV"},
+		{"not a zip", []byte("name,group"), FormatXLSX, "invalid XLSX"},
+		{"no workbook", buildXLSX(t, map[string]string{"xl/worksheets/sheet1.xml": studentsXML}), FormatXLSX, "xl/workbook.xml not found"},
+		{"missing shared string", buildXLSX(t, map[string]string{
+			"xl/workbook.xml":          workbookXML,
+			"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row><c r="A1" t="s"><v>5</v></c></row></sheetData></worksheet>`,
+		}), FormatXLSX, "missing shared string"},
+		{"bad cell reference", buildXLSX(t, map[string]string{
+			"xl/workbook.xml":          workbookXML,
+			"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row><c r="1A"><v>5</v></c></row></sheetData></worksheet>`,
+		}), FormatXLSX, "bad cell reference"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(tt.data, tt.format)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Read() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	for filename, want := range map[string]string{"students.CSV": FormatCSV, "Список.xlsx": FormatXLSX, "students.xls": "", "csv": ""} {
+		if got := Format(filename); got != want {
+			t.Errorf("Format(%q) = %q, want %q", filename, got, want)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package tabular
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Типы колонок: от типа зависит, как значение попадёт в ячейку XLSX
+const (
+	KindText   = iota
+	KindNumber // число в записи «1234.5»; пустое значение — пустая ячейка
+	KindDate   // дата ГГГГ-ММ-ДД; в XLSX — настоящая дата Excel
+)
+
+// Column — колонка выгружаемой таблицы
+type Column struct {
+	Title string
+	Kind  int
+}
+
+// Writer пишет таблицу построчно, не накапливая её в памяти. Заголовок пишется при создании
+type Writer interface {
+	Write(row []string) error
+	// Close дописывает файл; без него CSV может остаться в буфере, а XLSX будет повреждён
+	Close() error
+}
+
+// ContentType возвращает MIME-тип файла формата format
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// NewWriter начинает таблицу формата format с колонками columns; sheet — название листа XLSX
+func NewWriter(w io.Writer, format, sheet string, columns []Column) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatXLSX:
+		return newXLSXWriter(w, sheet, columns)
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// csvWriter пишет CSV для Excel: UTF-8 с BOM (иначе кириллица откроется кракозябрами)
+// и разделитель «;», который русский Excel ожидает при открытии файла двойным щелчком
+type csvWriter struct {
+	buf     *bufio.Writer
+	out     *csv.Writer
+	columns []Column
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	// BOM идёт через тот же буфер, что и строки: до первого сброса в w ничего не попадает
+	buf := bufio.NewWriter(w)
+	buf.Write(utf8BOM)
+	out := csv.NewWriter(buf)
+	out.Comma = ';'
+	out.UseCRLF = true
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Title
+	}
+	if err := out.Write(header); err != nil {
+		return nil, err
+	}
+	return &csvWriter{buf: buf, out: out, columns: columns}, nil
+}
+
+func (cw *csvWriter) Write(row []string) error {
+	record := make([]string, len(row))
+	for i, value := range row {
+		// Текст, начинающийся с «=», «+», «-» или «@», Excel принял бы за формулу
+		if i < len(cw.columns) && cw.columns[i].Kind == KindText && value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+			value = "'" + value
+		}
+		record[i] = value
+	}
+	return cw.out.Write(record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.out.Flush()
+	if err := cw.out.Error(); err != nil {
+		return err
+	}
+	return cw.buf.Flush()
+}
+
+// maxXLSXRows — предел строк на листе Excel
+const maxXLSXRows = 1 << 20
+
+// Стили ячеек из xl/styles.xml
+const (
+	styleHeader = 1
+	styleDate   = 2
+)
+
+// excelEpoch — нулевой день дат Excel (с учётом ошибки 1900 года, которую Excel сохраняет)
+var excelEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxWriter пишет книгу с одним листом: служебные части записываются сразу, а строки листа
+// сжимаются в архив по мере поступления
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   *bufio.Writer
+	columns []Column
+	rows    int
+}
+
+func newXLSXWriter(w io.Writer, sheet string, columns []Column) (*xlsxWriter, error) {
+	archive := zip.NewWriter(w)
+	var name strings.Builder
+	xml.EscapeText(&name, []byte(sheetName(sheet)))
+
+	parts := []struct{ path, body string }{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + name.String() + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+			`</Relationships>`},
+		// Стили: 0 — обычная ячейка, 1 — полужирный заголовок, 2 — дата ДД.ММ.ГГГГ (встроенный формат 14)
+		{"xl/styles.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+			`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+			`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+			`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+			`<cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+			`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+			`<xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>` +
+			`</styleSheet>`},
+	}
+	for _, part := range parts {
+		file, err := archive.Create(part.path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(file, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	xw := &xlsxWriter{archive: archive, sheet: bufio.NewWriter(file), columns: columns}
+	// Первая строка с заголовком закреплена, чтобы не уезжала при прокрутке
+	xw.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>` +
+		`<sheetData>`)
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Title
+	}
+	if err := xw.row(header, true); err != nil {
+		return nil, err
+	}
+	return xw, nil
+}
+
+func (xw *xlsxWriter) Write(row []string) error {
+	return xw.row(row, false)
+}
+
+func (xw *xlsxWriter) row(values []string, header bool) error {
+	if xw.rows == maxXLSXRows {
+		return fmt.Errorf("xlsx sheet is limited to %d rows", maxXLSXRows)
+	}
+	xw.rows++
+
+	fmt.Fprintf(xw.sheet, `<row r="%d">`, xw.rows)
+	for i, value := range values {
+		if value == "" {
+			continue
+		}
+		ref := columnName(i) + strconv.Itoa(xw.rows)
+		kind := KindText
+		if !header && i < len(xw.columns) {
+			kind = xw.columns[i].Kind
+		}
+		switch kind {
+		case KindNumber:
+			if _, err := strconv.ParseFloat(value, 64); err == nil {
+				fmt.Fprintf(xw.sheet, `<c r="%s"><v>%s</v></c>`, ref, value)
+				continue
+			}
+		case KindDate:
+			if date, err := time.Parse("2006-01-02", value); err == nil {
+				fmt.Fprintf(xw.sheet, `<c r="%s" s="%d"><v>%d</v></c>`, ref, styleDate, int(date.Sub(excelEpoch).Hours()/24))
+				continue
+			}
+		}
+		// Значения, которые не разобрались как число или дата, остаются текстом
+		style := ""
+		if header {
+			style = fmt.Sprintf(` s="%d"`, styleHeader)
+		}
+		fmt.Fprintf(xw.sheet, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">`, ref, style)
+		xml.EscapeText(xw.sheet, []byte(value))
+		xw.sheet.WriteString(`</t></is></c>`)
+	}
+	_, err := xw.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (xw *xlsxWriter) Close() error {
+	xw.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := xw.sheet.Flush(); err != nil {
+		return err
+	}
+	return xw.archive.Close()
+}
+
+// columnName переводит номер колонки с нуля в буквенное обозначение Excel: 0 → A, 26 → AA
+func columnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// sheetName убирает из названия листа символы, запрещённые Excel, и обрезает его до 31 символа
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	if name == "" {
+		return "Sheet1"
+	}
+	return name
+}
@@ -0,0 +1,179 @@
+package tabular
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var writerColumns = []Column{
+	{Title: "ФИО", Kind: KindText},
+	{Title: "Средний балл", Kind: KindNumber},
+	{Title: "Дата", Kind: KindDate},
+}
+
+// write пишет строки в таблицу формата format и возвращает готовый файл
+func write(t *testing.T, format, sheet string, rows [][]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, format, sheet, writerColumns)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatalf("Write(%q): %v", row, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// part возвращает содержимое части книги XLSX
+func part(t *testing.T, data []byte, path string) string {
+	t.Helper()
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("open xlsx: %v", err)
+	}
+	file, err := archive.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer file.Close()
+	body, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(body)
+}
+
+var writerRows = [][]string{
+	{"Иванов; Иван", "4.5", "2026-09-01"},
+	{"=СУММ(A1)", "", ""},
+	{"-Петров", "не число", "01.09.2026"},
+	{"Сидоров \"Сид\"", "5", "2026-10-18", "лишняя"},
+}
+
+func TestCSVWriter(t *testing.T) {
+	got := string(write(t, FormatCSV, "", writerRows))
+	want := "\xEF\xBB\xBFФИО;Средний балл;Дата\r\n" +
+		"\"Иванов; Иван\";4.5;2026-09-01\r\n" +
+		"'=СУММ(A1);;\r\n" +
+		"'-Петров;не число;01.09.2026\r\n" +
+		"\"Сидоров \"\"Сид\"\"\";5;2026-10-18;лишняя\r\n"
+	if got != want {
+		t.Errorf("CSV = %q, want %q", got, want)
+	}
+
+	// Файл читается обратно тем же Read, которым идёт импорт
+	rows, err := Read([]byte(got), FormatCSV)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if rows[1][0] != "Иванов; Иван" || rows[4][0] != "Сидоров \"Сид\"" {
+		t.Errorf("Read() = %q", rows)
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	data := write(t, FormatXLSX, "Ведомость: ИС-21/22", writerRows)
+
+	// Даты и числа стали значениями ячеек, а не текстом; нераспознанное осталось текстом
+	rows, err := Read(data, FormatXLSX)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	want := [][]string{
+		{"ФИО", "Средний балл", "Дата"},
+		{"Иванов; Иван", "4.5", "46266"},
+		{"=СУММ(A1)"},
+		{"-Петров", "не число", "01.09.2026"},
+		{"Сидоров \"Сид\"", "5", "46313", "лишняя"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Read() = %q, want %q", rows, want)
+	}
+
+	sheet := part(t, data, "xl/worksheets/sheet1.xml")
+	for _, cell := range []string{
+		`<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">ФИО</t></is></c>`,
+		`<c r="B2"><v>4.5</v></c>`,
+		`<c r="C2" s="2"><v>46266</v></c>`,
+		`<c r="B4" t="inlineStr">`,
+		`<t xml:space="preserve">Сидоров &#34;Сид&#34;</t>`,
+		`<row r="3"><c r="A3" t="inlineStr">`,
+		`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`,
+	} {
+		if !strings.Contains(sheet, cell) {
+			t.Errorf("sheet has no %s", cell)
+		}
+	}
+	if workbook := part(t, data, "xl/workbook.xml"); !strings.Contains(workbook, `<sheet name="Ведомость_ ИС-21_22" sheetId="1" r:id="rId1"/>`) {
+		t.Errorf("workbook: %s", workbook)
+	}
+	if styles := part(t, data, "xl/styles.xml"); !strings.Contains(styles, `<cellXfs count="3">`) {
+		t.Errorf("styles: %s", styles)
+	}
+}
+
+func TestXLSXWriterRowLimit(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, FormatXLSX, "", writerColumns)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	w.(*xlsxWriter).rows = maxXLSXRows - 1
+	if err := w.Write([]string{"последняя"}); err != nil {
+		t.Fatalf("last row: %v", err)
+	}
+	if err := w.Write([]string{"лишняя"}); err == nil || !strings.Contains(err.Error(), "limited to") {
+		t.Errorf("row over the limit: error = %v", err)
+	}
+}
+
+func TestNewWriterRejects(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := NewWriter(&buf, "ods", "", writerColumns); err == nil || err.Error() != "unsupported format: ods" {
+		t.Errorf("NewWriter() error = %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("rejected writer wrote %d bytes", buf.Len())
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for index, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(index); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", index, got, want)
+		}
+	}
+}
+
+func TestSheetName(t *testing.T) {
+	tests := map[string]string{
+		"":              "Sheet1",
+		"Ведомость":     "Ведомость",
+		`[a]:b*c?d/e\f`: "_a__b_c_d_e_f",
+		"Очень длинное название листа книги": "Очень длинное название листа кн",
+	}
+	for name, want := range tests {
+		if got := sheetName(name); got != want {
+			t.Errorf("sheetName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestContentType(t *testing.T) {
+	if got := ContentType(FormatXLSX); got != "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" {
+		t.Errorf("ContentType(xlsx) = %q", got)
+	}
+	if got := ContentType(FormatCSV); got != "text/csv; charset=utf-8" {
+		t.Errorf("ContentType(csv) = %q", got)
+	}
+}
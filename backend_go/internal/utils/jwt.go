@@ -1,45 +1,123 @@
-package utils
-
-import (
-    "time"
-    "github.com/dgrijalva/jwt-go"
-)
-
-var jwtKey = []byte("your_secret_key")
-
-type Claims struct {
-    UserID int `json:"user_id"`
-    RoleID int `json:"role_id"`
-    jwt.StandardClaims
-}
-
-func GenerateToken(userID int, roleID int) (string, error) {
-    expirationTime := time.Now().Add(24 * time.Hour)
-    claims := &Claims{
-        UserID: userID,
-        RoleID: roleID,
-        StandardClaims: jwt.StandardClaims{
-            ExpiresAt: expirationTime.Unix(),
-        },
-    }
-
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    return token.SignedString(jwtKey)
-}
-
-func ValidateToken(tokenString string) (*Claims, error) {
-    claims := &Claims{}
-    token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-        return jwtKey, nil
-    })
-
-    if err != nil {
-        return nil, err
-    }
-
-    if !token.Valid {
-        return nil, jwt.ErrSignatureInvalid
-    }
-
-    return claims, nil
-}
\ No newline at end of file
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// jwtKey задаётся при старте через ConfigureJWT; с пустым ключом токены не выпускаются
+var jwtKey []byte
+
+var (
+	// AccessTokenTTL — время жизни access-токена
+	AccessTokenTTL = 15 * time.Minute
+	// RefreshTokenTTL — время жизни refresh-токена (и сессии)
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// ConfigureJWT задаёт секрет подписи и время жизни токенов
+func ConfigureJWT(secret string, accessTTL, refreshTTL time.Duration) {
+	jwtKey = []byte(secret)
+	AccessTokenTTL = accessTTL
+	RefreshTokenTTL = refreshTTL
+}
+
+type Claims struct {
+	UserID    int    `json:"user_id"`
+	RoleID    int    `json:"role_id"`
+	SessionID string `json:"sid"`
+	jwt.StandardClaims
+}
+
+// GenerateToken выпускает короткоживущий access-токен, привязанный к сессии
+func GenerateToken(userID int, roleID int, sessionID string) (string, error) {
+	if len(jwtKey) == 0 {
+		return "", errors.New("jwt secret is not configured")
+	}
+
+	tokenID, err := GenerateTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		UserID:    userID,
+		RoleID:    roleID,
+		SessionID: sessionID,
+		StandardClaims: jwt.StandardClaims{
+			Id:        tokenID,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(AccessTokenTTL).Unix(),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtKey)
+}
+
+func ValidateToken(tokenString string) (*Claims, error) {
+	if len(jwtKey) == 0 {
+		return nil, errors.New("jwt secret is not configured")
+	}
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// Разрешаем только HMAC, чтобы нельзя было подменить алгоритм подписи
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return jwtKey, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, jwt.ErrSignatureInvalid
+	}
+
+	return claims, nil
+}
+
+// GenerateTokenID генерирует случайный идентификатор (для jti и ID сессии)
+func GenerateTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// GenerateRefreshToken генерирует непрозрачный refresh-токен
+func GenerateRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashRefreshToken возвращает хеш refresh-токена; в базе хранится только он
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateCalendarToken генерирует секрет подписки на ленту расписания; он передаётся в URL
+func GenerateCalendarToken() (string, error) {
+	return GenerateRefreshToken()
+}
+
+// HashCalendarToken возвращает хеш токена подписки; в базе хранится только он
+func HashCalendarToken(token string) string {
+	return HashRefreshToken(token)
+}
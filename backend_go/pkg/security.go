@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"math/big"
 
 	"golang.org/x/crypto/argon2"
 )
@@ -114,3 +115,19 @@ func CreateHashWithSalt(password string) (*HashResult, error) {
 		Hash: hashResult.Hash,
 	}, nil
 }
+
+// passwordAlphabet — символы сгенерированных паролей без похожих друг на друга (0/O, 1/l/I)
+const passwordAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// GeneratePassword генерирует случайный пароль из length символов
+func GeneratePassword(length int) (string, error) {
+	password := make([]byte, length)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(passwordAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		password[i] = passwordAlphabet[n.Int64()]
+	}
+	return string(password), nil
+}